@@ -89,6 +89,6 @@ CREATE INDEX IF NOT EXISTS idx_audit_logs_created ON audit_logs(created_at);
 -- 有効なアイテムのみを対象とする場合
 -- CREATE INDEX IF NOT EXISTS idx_items_active_only ON items(code, name) WHERE deleted_at IS NULL AND status = 'active';
 
--- 全文検索が必要な場合:
--- PostgreSQLのto_tsvectorを使用したGINインデックス
--- CREATE INDEX IF NOT EXISTS idx_items_fulltext ON items USING GIN(to_tsvector('japanese', name || ' ' || COALESCE(code, '')));
+-- 全文検索:
+-- 日本語対応の検索用インデックスは 13_create_item_search.sql で作成します
+-- （to_tsvector('japanese', ...) は標準の PostgreSQL では利用できないため、正規化テキストの pg_trgm インデックスを使用）
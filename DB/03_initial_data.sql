@@ -4,7 +4,7 @@
 
 -- ユーザー（email: admin@homestock.local, password: admin123）
 INSERT INTO users (email, password_hash, role) VALUES
-    ('admin@homestock.local', '$2a$10$X7ZQKZjxkZ5y5Zw5xZXZxeXZxZxZxZxZxZxZxZxZxZxZxZxZxZxZx', 'admin')
+    ('admin@homestock.local', '$2a$10$9/RRVJl70fVlH6/.77vBaODhE7oJGuimWTQa4uRLPFDOjA6Pm7hq6', 'admin')
 ON CONFLICT (email) DO NOTHING;
 
 -- カテゴリマスタ
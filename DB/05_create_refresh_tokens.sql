@@ -0,0 +1,43 @@
+-- ======================================================
+-- Migration: リフレッシュトークンテーブルの作成
+-- ======================================================
+-- 説明: リフレッシュトークンをサーバー側で管理し、
+--       ローテーションと失効（トークンファミリー単位）を可能にします。
+-- 実行順序: 01_create_tables.sql の後に実行してください
+--
+-- 運用ルール:
+--   - トークン本体は保存せず、SHA-256 ハッシュのみを保存します
+--   - リフレッシュのたびに新しいトークンを発行し、旧トークンは rotated_at を記録します
+--   - ローテーション済みトークンが再利用された場合は、同一ファミリーを全て失効させます
+-- ======================================================
+
+CREATE SEQUENCE IF NOT EXISTS refresh_tokens_id_seq START WITH 1;
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+  id             TEXT PRIMARY KEY DEFAULT 'RT' || LPAD(nextval('refresh_tokens_id_seq')::TEXT, 8, '0'),
+  user_id        TEXT NOT NULL REFERENCES users(id),
+  family_id      TEXT NOT NULL,
+  token_hash     TEXT UNIQUE NOT NULL,
+  expires_at     TIMESTAMPTZ NOT NULL,
+  rotated_at     TIMESTAMPTZ,
+  replaced_by    TEXT REFERENCES refresh_tokens(id),
+  revoked_at     TIMESTAMPTZ,
+  revoked_reason TEXT,
+  user_agent     TEXT,
+  ip_address     TEXT,
+  created_at     TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+COMMENT ON TABLE refresh_tokens IS 'リフレッシュトークンテーブル。ローテーションと失効リストを管理';
+COMMENT ON COLUMN refresh_tokens.id IS 'トークンID（RT + 8桁の連番、例: RT00000001）';
+COMMENT ON COLUMN refresh_tokens.user_id IS 'トークンの所有ユーザーID';
+COMMENT ON COLUMN refresh_tokens.family_id IS 'トークンファミリーID（ログイン単位のセッションID）';
+COMMENT ON COLUMN refresh_tokens.token_hash IS 'トークンの SHA-256 ハッシュ（平文は保存しない）';
+COMMENT ON COLUMN refresh_tokens.expires_at IS '有効期限';
+COMMENT ON COLUMN refresh_tokens.rotated_at IS 'ローテーション日時（NULL = 未使用）';
+COMMENT ON COLUMN refresh_tokens.replaced_by IS 'ローテーション後の新しいトークンID';
+COMMENT ON COLUMN refresh_tokens.revoked_at IS '失効日時（NULL = 有効）';
+COMMENT ON COLUMN refresh_tokens.revoked_reason IS '失効理由（logout, reuse_detected, admin_revoked 等）';
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user ON refresh_tokens(user_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family ON refresh_tokens(family_id);
@@ -0,0 +1,37 @@
+-- ======================================================
+-- Migration: ユーザートークンテーブルの作成
+-- ======================================================
+-- 説明: 招待とパスワード再設定に使用する一回限りのトークンを管理します。
+-- 実行順序: 01_create_tables.sql の後に実行してください
+--
+-- 運用ルール:
+--   - トークン本体は保存せず、SHA-256 ハッシュのみを保存します
+--   - 使用済み（used_at）・失効済み（revoked_at）・期限切れのトークンは使用できません
+--   - 同じ用途のトークンを再発行すると、未使用の旧トークンは失効します
+-- ======================================================
+
+CREATE SEQUENCE IF NOT EXISTS user_tokens_id_seq START WITH 1;
+
+CREATE TABLE IF NOT EXISTS user_tokens (
+  id            TEXT PRIMARY KEY DEFAULT 'UT' || LPAD(nextval('user_tokens_id_seq')::TEXT, 8, '0'),
+  user_id       TEXT NOT NULL REFERENCES users(id),
+  purpose       TEXT NOT NULL CHECK (purpose IN ('invite','password_reset')),
+  token_hash    TEXT UNIQUE NOT NULL,
+  expires_at    TIMESTAMPTZ NOT NULL,
+  used_at       TIMESTAMPTZ,
+  revoked_at    TIMESTAMPTZ,
+  created_by    TEXT REFERENCES users(id),
+  created_at    TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+COMMENT ON TABLE user_tokens IS 'ユーザートークンテーブル。招待・パスワード再設定用の一回限りのトークン';
+COMMENT ON COLUMN user_tokens.id IS 'トークンID（UT + 8桁の連番、例: UT00000001）';
+COMMENT ON COLUMN user_tokens.user_id IS '対象ユーザーID';
+COMMENT ON COLUMN user_tokens.purpose IS '用途（invite: 招待, password_reset: パスワード再設定）';
+COMMENT ON COLUMN user_tokens.token_hash IS 'トークンの SHA-256 ハッシュ（平文は保存しない）';
+COMMENT ON COLUMN user_tokens.expires_at IS '有効期限';
+COMMENT ON COLUMN user_tokens.used_at IS '使用日時（NULL = 未使用）';
+COMMENT ON COLUMN user_tokens.revoked_at IS '失効日時（再発行により無効化された場合）';
+COMMENT ON COLUMN user_tokens.created_by IS '発行者（招待の場合は管理者のユーザーID）';
+
+CREATE INDEX IF NOT EXISTS idx_user_tokens_user ON user_tokens(user_id, purpose);
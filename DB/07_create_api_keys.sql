@@ -0,0 +1,41 @@
+-- ======================================================
+-- Migration: API キーテーブルの作成
+-- ======================================================
+-- 説明: スクリプトやデバイスから利用する長期有効な API キーを管理します。
+-- 実行順序: 01_create_tables.sql の後に実行してください
+--
+-- 運用ルール:
+--   - キーの形式は hsk_<prefix>_<secret> です。prefix は識別用に平文で保存します
+--   - キー全体は保存せず、SHA-256 ハッシュのみを保存します
+--   - roles はユーザー自身の権限以下のロールに限定されます
+-- ======================================================
+
+CREATE SEQUENCE IF NOT EXISTS api_keys_id_seq START WITH 1;
+
+CREATE TABLE IF NOT EXISTS api_keys (
+  id            TEXT PRIMARY KEY DEFAULT 'K' || LPAD(nextval('api_keys_id_seq')::TEXT, 8, '0'),
+  user_id       TEXT NOT NULL REFERENCES users(id),
+  name          TEXT NOT NULL,
+  prefix        TEXT UNIQUE NOT NULL,
+  key_hash      TEXT NOT NULL,
+  roles         TEXT[] NOT NULL CHECK (cardinality(roles) > 0 AND roles <@ ARRAY['admin','operator','viewer']::TEXT[]),
+  expires_at    TIMESTAMPTZ,
+  last_used_at  TIMESTAMPTZ,
+  last_used_ip  TEXT,
+  revoked_at    TIMESTAMPTZ,
+  created_at    TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+COMMENT ON TABLE api_keys IS 'API キーテーブル。スクリプトやデバイス向けの長期認証情報';
+COMMENT ON COLUMN api_keys.id IS 'API キーID（K + 8桁の連番、例: K00000001）';
+COMMENT ON COLUMN api_keys.user_id IS 'キーの所有ユーザーID';
+COMMENT ON COLUMN api_keys.name IS 'キーの名称（用途の説明）';
+COMMENT ON COLUMN api_keys.prefix IS 'キーの識別用プレフィックス（一意、平文）';
+COMMENT ON COLUMN api_keys.key_hash IS 'キー全体の SHA-256 ハッシュ';
+COMMENT ON COLUMN api_keys.roles IS 'キーに許可するロール（ユーザーの権限以下）';
+COMMENT ON COLUMN api_keys.expires_at IS '有効期限（NULL = 無期限）';
+COMMENT ON COLUMN api_keys.last_used_at IS '最終使用日時';
+COMMENT ON COLUMN api_keys.last_used_ip IS '最終使用元IP';
+COMMENT ON COLUMN api_keys.revoked_at IS '失効日時（NULL = 有効）';
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_user ON api_keys(user_id);
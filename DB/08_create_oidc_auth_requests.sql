@@ -0,0 +1,32 @@
+-- ======================================================
+-- Migration: OIDC 認可リクエストテーブルの作成
+-- ======================================================
+-- 説明: OpenID Connect ログイン（認可コード + PKCE）の開始から
+--       コールバックまでの間、state・nonce・コードベリファイアを保持します。
+-- 実行順序: 01_create_tables.sql の後に実行してください
+--
+-- 運用ルール:
+--   - state は SHA-256 ハッシュのみを保存します
+--   - レコードはコールバック時に削除され、一度しか使用できません
+--   - 期限切れのレコードは新しいログイン開始時に削除されます
+-- ======================================================
+
+CREATE SEQUENCE IF NOT EXISTS oidc_auth_requests_id_seq START WITH 1;
+
+CREATE TABLE IF NOT EXISTS oidc_auth_requests (
+  id             TEXT PRIMARY KEY DEFAULT 'OA' || LPAD(nextval('oidc_auth_requests_id_seq')::TEXT, 8, '0'),
+  state_hash     TEXT UNIQUE NOT NULL,
+  nonce          TEXT NOT NULL,
+  code_verifier  TEXT NOT NULL,
+  expires_at     TIMESTAMPTZ NOT NULL,
+  created_at     TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+COMMENT ON TABLE oidc_auth_requests IS 'OIDC 認可リクエストテーブル。ログイン開始からコールバックまでの一時データ';
+COMMENT ON COLUMN oidc_auth_requests.id IS '認可リクエストID（OA + 8桁の連番、例: OA00000001）';
+COMMENT ON COLUMN oidc_auth_requests.state_hash IS 'state パラメータの SHA-256 ハッシュ（一意）';
+COMMENT ON COLUMN oidc_auth_requests.nonce IS 'ID トークンの nonce';
+COMMENT ON COLUMN oidc_auth_requests.code_verifier IS 'PKCE のコードベリファイア';
+COMMENT ON COLUMN oidc_auth_requests.expires_at IS '有効期限';
+
+CREATE INDEX IF NOT EXISTS idx_oidc_auth_requests_expires ON oidc_auth_requests(expires_at);
@@ -0,0 +1,33 @@
+-- ======================================================
+-- Migration: 認証失敗トラッキングテーブルの作成
+-- ======================================================
+-- 説明: ログイン・API キー・アクセストークンの認証失敗を
+--       アカウント単位および IP 単位で記録し、ブルートフォース攻撃を抑止します。
+-- 実行順序: 01_create_tables.sql の後に実行してください
+--
+-- 運用ルール:
+--   - scope は account（メールアドレス）/ api_key（キーのプレフィックス）/ ip（接続元IP）のいずれかです
+--   - 一定回数の失敗以降は指数的に待機時間を延ばし（backoff）、閾値に達すると一時ロックします（lockout）
+--   - ロックのたびにロック時間は倍増し、ログイン成功または管理者のロック解除でリセットされます
+--   - ロック・ロック解除は audit_logs に記録されます
+-- ======================================================
+
+CREATE TABLE IF NOT EXISTS auth_throttles (
+  scope            TEXT NOT NULL CHECK (scope IN ('account','api_key','ip')),
+  subject          TEXT NOT NULL,
+  failure_count    INTEGER NOT NULL DEFAULT 0,
+  lockout_count    INTEGER NOT NULL DEFAULT 0,
+  last_failure_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  locked_until     TIMESTAMPTZ,
+  lock_reason      TEXT CHECK (lock_reason IN ('backoff','lockout')),
+  PRIMARY KEY (scope, subject)
+);
+
+COMMENT ON TABLE auth_throttles IS '認証失敗トラッキングテーブル。ブルートフォース対策のバックオフ・ロック状態';
+COMMENT ON COLUMN auth_throttles.scope IS '対象の種別（account, api_key, ip）';
+COMMENT ON COLUMN auth_throttles.subject IS '対象（メールアドレス、API キーのプレフィックス、IPアドレス）';
+COMMENT ON COLUMN auth_throttles.failure_count IS '直近の連続失敗回数（ロック時にリセット）';
+COMMENT ON COLUMN auth_throttles.lockout_count IS 'これまでのロック回数（ロック時間の倍率に使用）';
+COMMENT ON COLUMN auth_throttles.last_failure_at IS '最終失敗日時';
+COMMENT ON COLUMN auth_throttles.locked_until IS 'この日時まで認証を受け付けない（NULL = 制限なし）';
+COMMENT ON COLUMN auth_throttles.lock_reason IS '制限の種類（backoff: 待機, lockout: 一時ロック）';
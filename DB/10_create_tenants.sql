@@ -0,0 +1,136 @@
+-- ======================================================
+-- Migration: テナント（世帯・組織）の導入
+-- ======================================================
+-- 説明: 1 つのデプロイメントを複数の世帯・組織で共有できるよう、
+--       テナントテーブルを作成し、在庫関連の全テーブルにテナントを付与します。
+-- 実行順序: 01_create_tables.sql 〜 04_insert_sample_stock_history.sql の後に実行してください
+--
+-- 運用ルール:
+--   - ユーザーはいずれか 1 つのテナントに所属します（users.tenant_id）
+--   - 既存データは全てデフォルトテナント（T00000001）に移行します
+--   - code の一意制約はテナント単位になります（別テナントでは同じ code を使用可能）
+--   - (tenant_id, id) の複合外部キーにより、別テナントのデータは参照できません
+-- ======================================================
+
+CREATE SEQUENCE IF NOT EXISTS tenants_id_seq START WITH 1;
+
+CREATE TABLE IF NOT EXISTS tenants (
+  id            TEXT PRIMARY KEY DEFAULT 'T' || LPAD(nextval('tenants_id_seq')::TEXT, 8, '0'),
+  code          TEXT UNIQUE NOT NULL,
+  name          TEXT NOT NULL,
+  created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+  deleted_at    TIMESTAMPTZ
+);
+
+COMMENT ON TABLE tenants IS 'テナントテーブル。データを分離する単位（世帯・組織）';
+COMMENT ON COLUMN tenants.id IS 'テナントID（T + 8桁の連番、例: T00000001）';
+COMMENT ON COLUMN tenants.code IS 'テナントコード（一意）';
+COMMENT ON COLUMN tenants.name IS 'テナント名称';
+COMMENT ON COLUMN tenants.deleted_at IS '論理削除日時（NULL = 有効）';
+
+-- デフォルトテナント（既存データの移行先）
+INSERT INTO tenants (id, code, name)
+VALUES ('T' || LPAD(nextval('tenants_id_seq')::TEXT, 8, '0'), 'default', 'デフォルト')
+ON CONFLICT (code) DO NOTHING;
+
+-- ======================================================
+-- テナント列の追加と既存データの移行
+-- ======================================================
+
+ALTER TABLE users           ADD COLUMN IF NOT EXISTS tenant_id TEXT REFERENCES tenants(id);
+ALTER TABLE categories      ADD COLUMN IF NOT EXISTS tenant_id TEXT REFERENCES tenants(id);
+ALTER TABLE units           ADD COLUMN IF NOT EXISTS tenant_id TEXT REFERENCES tenants(id);
+ALTER TABLE attributes      ADD COLUMN IF NOT EXISTS tenant_id TEXT REFERENCES tenants(id);
+ALTER TABLE locations       ADD COLUMN IF NOT EXISTS tenant_id TEXT REFERENCES tenants(id);
+ALTER TABLE items           ADD COLUMN IF NOT EXISTS tenant_id TEXT REFERENCES tenants(id);
+ALTER TABLE item_attributes ADD COLUMN IF NOT EXISTS tenant_id TEXT REFERENCES tenants(id);
+ALTER TABLE stocks          ADD COLUMN IF NOT EXISTS tenant_id TEXT REFERENCES tenants(id);
+ALTER TABLE stock_history   ADD COLUMN IF NOT EXISTS tenant_id TEXT REFERENCES tenants(id);
+
+UPDATE users           SET tenant_id = (SELECT id FROM tenants WHERE code = 'default') WHERE tenant_id IS NULL;
+UPDATE categories      SET tenant_id = (SELECT id FROM tenants WHERE code = 'default') WHERE tenant_id IS NULL;
+UPDATE units           SET tenant_id = (SELECT id FROM tenants WHERE code = 'default') WHERE tenant_id IS NULL;
+UPDATE attributes      SET tenant_id = (SELECT id FROM tenants WHERE code = 'default') WHERE tenant_id IS NULL;
+UPDATE locations       SET tenant_id = (SELECT id FROM tenants WHERE code = 'default') WHERE tenant_id IS NULL;
+UPDATE items           SET tenant_id = (SELECT id FROM tenants WHERE code = 'default') WHERE tenant_id IS NULL;
+UPDATE item_attributes SET tenant_id = (SELECT id FROM tenants WHERE code = 'default') WHERE tenant_id IS NULL;
+UPDATE stocks          SET tenant_id = (SELECT id FROM tenants WHERE code = 'default') WHERE tenant_id IS NULL;
+UPDATE stock_history   SET tenant_id = (SELECT id FROM tenants WHERE code = 'default') WHERE tenant_id IS NULL;
+
+ALTER TABLE users           ALTER COLUMN tenant_id SET NOT NULL;
+ALTER TABLE categories      ALTER COLUMN tenant_id SET NOT NULL;
+ALTER TABLE units           ALTER COLUMN tenant_id SET NOT NULL;
+ALTER TABLE attributes      ALTER COLUMN tenant_id SET NOT NULL;
+ALTER TABLE locations       ALTER COLUMN tenant_id SET NOT NULL;
+ALTER TABLE items           ALTER COLUMN tenant_id SET NOT NULL;
+ALTER TABLE item_attributes ALTER COLUMN tenant_id SET NOT NULL;
+ALTER TABLE stocks          ALTER COLUMN tenant_id SET NOT NULL;
+ALTER TABLE stock_history   ALTER COLUMN tenant_id SET NOT NULL;
+
+COMMENT ON COLUMN users.tenant_id IS '所属テナントID';
+COMMENT ON COLUMN categories.tenant_id IS 'テナントID';
+COMMENT ON COLUMN units.tenant_id IS 'テナントID';
+COMMENT ON COLUMN attributes.tenant_id IS 'テナントID';
+COMMENT ON COLUMN locations.tenant_id IS 'テナントID';
+COMMENT ON COLUMN items.tenant_id IS 'テナントID';
+COMMENT ON COLUMN item_attributes.tenant_id IS 'テナントID（アイテム・属性と同一であること）';
+COMMENT ON COLUMN stocks.tenant_id IS 'テナントID（アイテム・ロケーションと同一であること）';
+COMMENT ON COLUMN stock_history.tenant_id IS 'テナントID（アイテム・ロケーションと同一であること）';
+
+-- ======================================================
+-- code の一意制約をテナント単位に変更
+-- ======================================================
+
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS categories_code_key;
+ALTER TABLE units      DROP CONSTRAINT IF EXISTS units_code_key;
+ALTER TABLE attributes DROP CONSTRAINT IF EXISTS attributes_code_key;
+ALTER TABLE locations  DROP CONSTRAINT IF EXISTS locations_code_key;
+ALTER TABLE items      DROP CONSTRAINT IF EXISTS items_code_key;
+
+ALTER TABLE categories ADD CONSTRAINT categories_tenant_code_key UNIQUE (tenant_id, code);
+ALTER TABLE units      ADD CONSTRAINT units_tenant_code_key      UNIQUE (tenant_id, code);
+ALTER TABLE attributes ADD CONSTRAINT attributes_tenant_code_key UNIQUE (tenant_id, code);
+ALTER TABLE locations  ADD CONSTRAINT locations_tenant_code_key  UNIQUE (tenant_id, code);
+ALTER TABLE items      ADD CONSTRAINT items_tenant_code_key      UNIQUE (tenant_id, code);
+
+-- ======================================================
+-- テナントをまたぐ参照を防ぐ複合外部キー
+-- ======================================================
+
+ALTER TABLE categories ADD CONSTRAINT categories_tenant_id_key UNIQUE (tenant_id, id);
+ALTER TABLE units      ADD CONSTRAINT units_tenant_id_key      UNIQUE (tenant_id, id);
+ALTER TABLE attributes ADD CONSTRAINT attributes_tenant_id_key UNIQUE (tenant_id, id);
+ALTER TABLE locations  ADD CONSTRAINT locations_tenant_id_key  UNIQUE (tenant_id, id);
+ALTER TABLE items      ADD CONSTRAINT items_tenant_id_key      UNIQUE (tenant_id, id);
+
+ALTER TABLE items
+  ADD CONSTRAINT items_tenant_category_fkey FOREIGN KEY (tenant_id, category_id) REFERENCES categories(tenant_id, id),
+  ADD CONSTRAINT items_tenant_unit_fkey     FOREIGN KEY (tenant_id, unit_id)     REFERENCES units(tenant_id, id);
+
+ALTER TABLE item_attributes
+  ADD CONSTRAINT item_attributes_tenant_item_fkey      FOREIGN KEY (tenant_id, item_id)      REFERENCES items(tenant_id, id) ON DELETE CASCADE,
+  ADD CONSTRAINT item_attributes_tenant_attribute_fkey FOREIGN KEY (tenant_id, attribute_id) REFERENCES attributes(tenant_id, id);
+
+ALTER TABLE locations
+  ADD CONSTRAINT locations_tenant_parent_fkey FOREIGN KEY (tenant_id, parent_id) REFERENCES locations(tenant_id, id);
+
+ALTER TABLE stocks
+  ADD CONSTRAINT stocks_tenant_item_fkey     FOREIGN KEY (tenant_id, item_id)     REFERENCES items(tenant_id, id),
+  ADD CONSTRAINT stocks_tenant_location_fkey FOREIGN KEY (tenant_id, location_id) REFERENCES locations(tenant_id, id);
+
+ALTER TABLE stock_history
+  ADD CONSTRAINT stock_history_tenant_item_fkey          FOREIGN KEY (tenant_id, item_id)       REFERENCES items(tenant_id, id),
+  ADD CONSTRAINT stock_history_tenant_location_from_fkey FOREIGN KEY (tenant_id, location_from) REFERENCES locations(tenant_id, id),
+  ADD CONSTRAINT stock_history_tenant_location_to_fkey   FOREIGN KEY (tenant_id, location_to)   REFERENCES locations(tenant_id, id);
+
+-- ======================================================
+-- インデックス
+-- ======================================================
+
+-- テナント内のユーザー一覧用
+CREATE INDEX IF NOT EXISTS idx_users_tenant ON users(tenant_id);
+
+-- テナント内の在庫・履歴検索用
+CREATE INDEX IF NOT EXISTS idx_stocks_tenant ON stocks(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_stock_history_tenant_created ON stock_history(tenant_id, created_at DESC);
@@ -0,0 +1,41 @@
+-- ======================================================
+-- Migration: ロケーション・カテゴリ単位の権限付与テーブルの作成
+-- ======================================================
+-- 説明: ユーザーに「ロケーション L00000003 とその子孫で operator」
+--       「カテゴリ C00000002 とその子孫で viewer」のような範囲を限定した権限を付与します。
+-- 実行順序: 10_create_tenants.sql の後に実行してください
+--
+-- 運用ルール:
+--   - 権限付与が 1 件もないユーザーは、従来どおりユーザーのロールで全データを扱えます
+--   - 権限付与があるユーザーは、付与された範囲のデータのみ扱えます（admin は制限されません）
+--   - ロケーションの付与は locations.parent_id をたどって子孫ロケーションにも適用されます
+--   - カテゴリの付与は categories.parent_id（18_add_category_hierarchy.sql）をたどって子孫カテゴリにも適用されます
+--   - 付与するロールはユーザー自身のロールを超えられません
+--   - アイテム一覧・入出庫・在庫履歴の取得で適用されます
+-- ======================================================
+
+CREATE SEQUENCE IF NOT EXISTS user_grants_id_seq START WITH 1;
+
+CREATE TABLE IF NOT EXISTS user_grants (
+  id            TEXT PRIMARY KEY DEFAULT 'G' || LPAD(nextval('user_grants_id_seq')::TEXT, 8, '0'),
+  tenant_id     TEXT NOT NULL REFERENCES tenants(id),
+  user_id       TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  scope_type    TEXT NOT NULL CHECK (scope_type IN ('location','category')),
+  scope_id      TEXT NOT NULL,
+  role          TEXT NOT NULL CHECK (role IN ('operator','viewer')),
+  created_by    TEXT REFERENCES users(id),
+  created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (user_id, scope_type, scope_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_grants_user ON user_grants (user_id, scope_type);
+
+COMMENT ON TABLE user_grants IS '権限付与テーブル。ロケーション・カテゴリ単位でユーザーの扱える範囲を限定';
+COMMENT ON COLUMN user_grants.id IS '権限付与ID（G + 8桁の連番、例: G00000001）';
+COMMENT ON COLUMN user_grants.tenant_id IS 'テナントID';
+COMMENT ON COLUMN user_grants.user_id IS '付与先のユーザーID';
+COMMENT ON COLUMN user_grants.scope_type IS '範囲の種別（location: ロケーションと子孫, category: カテゴリと子孫）';
+COMMENT ON COLUMN user_grants.scope_id IS '範囲の対象（locations.id または categories.id）';
+COMMENT ON COLUMN user_grants.role IS '範囲内で許可するロール（operator, viewer）';
+COMMENT ON COLUMN user_grants.created_by IS '付与した管理者のユーザーID';
+COMMENT ON COLUMN user_grants.created_at IS '作成日時';
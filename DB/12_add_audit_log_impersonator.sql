@@ -0,0 +1,20 @@
+-- ======================================================
+-- Migration: 監査ログへのなりすまし実行者の追加
+-- ======================================================
+-- 説明: 管理者がサポートのためにユーザーになりすまして操作した場合に、
+--       操作対象のユーザーと管理者の両方を監査ログに記録します。
+-- 実行順序: 01_create_tables.sql の後に実行してください
+--
+-- 運用ルール:
+--   - なりすまし中の書き込み操作は、user_id（なりすまし対象）と impersonator_id（管理者）の両方を記録します
+--   - なりすましの開始は action = 'impersonate' として管理者の user_id で記録します
+--   - 管理者へのなりすましはできません
+-- ======================================================
+
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS impersonator_id TEXT REFERENCES users(id);
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_impersonator ON audit_logs (impersonator_id, created_at DESC)
+  WHERE impersonator_id IS NOT NULL;
+
+COMMENT ON COLUMN audit_logs.user_id IS '実行ユーザーID（なりすまし中はなりすまし対象のユーザー）';
+COMMENT ON COLUMN audit_logs.impersonator_id IS 'なりすましを行った管理者のユーザーID（NULL = 本人による操作）';
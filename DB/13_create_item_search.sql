@@ -0,0 +1,91 @@
+-- ======================================================
+-- Migration: アイテムの日本語対応検索
+-- ======================================================
+-- 説明: アイテムのコード・名称・属性値を正規化した検索用テキストを items.search_text に保持し、
+--       ひらがな/カタカナ・全角/半角・大文字/小文字の違いを吸収した部分一致検索と前方一致の候補表示を行います。
+--       例:「ｷｯﾁﾝﾍﾟｰﾊﾟｰ」「きっちんぺーぱー」で「キッチンペーパー」が検索できます。
+-- 実行順序: 10_create_tenants.sql の後に実行してください
+--
+-- 運用ルール:
+--   - 正規化は normalize_search_text() に一本化し、検索語もアプリケーションから同じ関数で正規化します
+--   - search_text はトリガーで自動更新されるため、アプリケーションから直接更新しないでください
+--   - normalize() を使用するため PostgreSQL 13 以上かつ UTF8 エンコーディングのデータベースが必要です
+-- ======================================================
+
+-- 部分一致検索（LIKE '%...%'）をインデックスで高速化するための拡張機能
+CREATE EXTENSION IF NOT EXISTS "pg_trgm";
+
+-- 検索用の正規化関数
+-- 1. NFKC 正規化（全角英数字 → 半角、半角カナ → 全角カナ、濁点・半濁点の結合）
+-- 2. ひらがな → カタカナ
+-- 3. 英字を小文字に統一
+CREATE OR REPLACE FUNCTION normalize_search_text(input TEXT) RETURNS TEXT
+LANGUAGE sql IMMUTABLE STRICT PARALLEL SAFE AS $$
+  SELECT lower(translate(
+    normalize(input, NFKC),
+    'ぁあぃいぅうぇえぉおかがきぎくぐけげこごさざしじすずせぜそぞただちぢっつづてでとどなにぬねのはばぱひびぴふぶぷへべぺほぼぽまみむめもゃやゅゆょよらりるれろゎわゐゑをんゔゕゖゝゞ',
+    'ァアィイゥウェエォオカガキギクグケゲコゴサザシジスズセゼソゾタダチヂッツヅテデトドナニヌネノハバパヒビピフブプヘベペホボポマミムメモャヤュユョヨラリルレロヮワヰヱヲンヴヵヶヽヾ'
+  ))
+$$;
+
+COMMENT ON FUNCTION normalize_search_text(TEXT) IS '検索用の正規化（NFKC・ひらがな→カタカナ・小文字化）';
+
+ALTER TABLE items ADD COLUMN IF NOT EXISTS search_text TEXT NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN items.search_text IS '検索用テキスト（コード・名称・属性値を正規化して連結。トリガーで自動更新）';
+
+-- アイテムの検索用テキストを組み立てます
+CREATE OR REPLACE FUNCTION item_search_text(p_item_id TEXT, p_code TEXT, p_name TEXT) RETURNS TEXT
+LANGUAGE sql STABLE AS $$
+  SELECT normalize_search_text(concat_ws(' ', p_code, p_name, (
+    SELECT string_agg(ia.value, ' ' ORDER BY ia.attribute_id)
+    FROM item_attributes ia
+    WHERE ia.item_id = p_item_id
+  )))
+$$;
+
+-- アイテムのコード・名称の変更時に検索用テキストを更新
+CREATE OR REPLACE FUNCTION items_refresh_search_text() RETURNS TRIGGER
+LANGUAGE plpgsql AS $$
+BEGIN
+  NEW.search_text := item_search_text(NEW.id, NEW.code, NEW.name);
+  RETURN NEW;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS trg_items_search_text ON items;
+CREATE TRIGGER trg_items_search_text
+  BEFORE INSERT OR UPDATE OF code, name ON items
+  FOR EACH ROW EXECUTE FUNCTION items_refresh_search_text();
+
+-- アイテム属性の追加・変更・削除時に検索用テキストを更新
+CREATE OR REPLACE FUNCTION item_attributes_refresh_search_text() RETURNS TRIGGER
+LANGUAGE plpgsql AS $$
+BEGIN
+  IF TG_OP IN ('UPDATE', 'DELETE') THEN
+    UPDATE items SET search_text = item_search_text(id, code, name) WHERE id = OLD.item_id;
+  END IF;
+  IF TG_OP IN ('INSERT', 'UPDATE') AND (TG_OP = 'INSERT' OR NEW.item_id <> OLD.item_id) THEN
+    UPDATE items SET search_text = item_search_text(id, code, name) WHERE id = NEW.item_id;
+  END IF;
+  RETURN NULL;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS trg_item_attributes_search_text ON item_attributes;
+CREATE TRIGGER trg_item_attributes_search_text
+  AFTER INSERT OR UPDATE OR DELETE ON item_attributes
+  FOR EACH ROW EXECUTE FUNCTION item_attributes_refresh_search_text();
+
+-- 既存アイテムの検索用テキストを作成
+UPDATE items SET search_text = item_search_text(id, code, name);
+
+-- 部分一致検索用（GET /api/items?q=）
+CREATE INDEX IF NOT EXISTS idx_items_search_text ON items USING GIN (search_text gin_trgm_ops)
+  WHERE deleted_at IS NULL;
+
+-- 前方一致の候補表示用（GET /api/items/suggest）
+CREATE INDEX IF NOT EXISTS idx_items_search_name ON items (tenant_id, normalize_search_text(name) text_pattern_ops)
+  WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_items_search_code ON items (tenant_id, normalize_search_text(code) text_pattern_ops)
+  WHERE deleted_at IS NULL;
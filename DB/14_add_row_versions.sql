@@ -0,0 +1,25 @@
+-- ======================================================
+-- Migration: 楽観的ロック用のバージョン列の追加
+-- ======================================================
+-- 説明: アイテムとマスタ（カテゴリ・単位・属性・ユーザー）に version 列を追加し、
+--       同じレコードを同時に編集した場合に後からの更新が先の更新を上書きしないようにします。
+-- 実行順序: 01_create_tables.sql の後に実行してください
+--
+-- 運用ルール:
+--   - version は 1 から始まり、更新のたびにアプリケーションが 1 ずつ増やします
+--   - API は version を ETag（例: "3"）として返し、クライアントは PUT/PATCH の If-Match に指定します
+--   - If-Match の version が現在の値と異なる場合、更新せずに 409 と現在の状態を返します
+--   - 入出庫による在庫数量の更新でもアイテムの version を増やします
+-- ======================================================
+
+ALTER TABLE items      ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE units      ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE attributes ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE users      ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+
+COMMENT ON COLUMN items.version      IS 'バージョン（楽観的ロック用。更新のたびに +1）';
+COMMENT ON COLUMN categories.version IS 'バージョン（楽観的ロック用。更新のたびに +1）';
+COMMENT ON COLUMN units.version      IS 'バージョン（楽観的ロック用。更新のたびに +1）';
+COMMENT ON COLUMN attributes.version IS 'バージョン（楽観的ロック用。更新のたびに +1）';
+COMMENT ON COLUMN users.version      IS 'バージョン（楽観的ロック用。更新のたびに +1）';
@@ -0,0 +1,43 @@
+-- ======================================================
+-- Migration: 属性値の型付き比較用の関数とインデックス
+-- ======================================================
+-- 説明: TEXT で保存している item_attributes.value を attributes.value_type に応じて
+--       数値・日付として比較できるよう、変換関数と式インデックスを作成します。
+--       アイテム一覧の属性フィルタ（lt, gt, between など）で使用します。
+-- 実行順序: 01_create_tables.sql の後に実行してください
+--
+-- 運用ルール:
+--   - 変換できない値（型を検証する前に登録された値など）は NULL として扱い、比較に一致しません
+--   - 属性値の書き込み時は API が型に応じて正規化します（number: 10 進表記, date: YYYY-MM-DD）
+-- ======================================================
+
+-- 属性値を数値に変換します（変換できない場合は NULL）
+CREATE OR REPLACE FUNCTION attribute_numeric(value TEXT) RETURNS NUMERIC
+LANGUAGE plpgsql IMMUTABLE STRICT PARALLEL SAFE AS $$
+BEGIN
+  RETURN value::NUMERIC;
+EXCEPTION WHEN OTHERS THEN
+  RETURN NULL;
+END;
+$$;
+
+-- 属性値を日付に変換します（YYYY-MM-DD 形式でない場合、存在しない日付の場合は NULL）
+CREATE OR REPLACE FUNCTION attribute_date(value TEXT) RETURNS DATE
+LANGUAGE plpgsql IMMUTABLE STRICT PARALLEL SAFE AS $$
+BEGIN
+  IF value !~ '^\d{4}-\d{2}-\d{2}$' THEN
+    RETURN NULL;
+  END IF;
+  RETURN to_date(value, 'YYYY-MM-DD');
+EXCEPTION WHEN OTHERS THEN
+  RETURN NULL;
+END;
+$$;
+
+COMMENT ON FUNCTION attribute_numeric(TEXT) IS '属性値を数値に変換（変換できない場合は NULL）';
+COMMENT ON FUNCTION attribute_date(TEXT) IS '属性値を日付に変換（YYYY-MM-DD 以外は NULL）';
+
+-- 属性ごとの範囲検索・完全一致用インデックス
+CREATE INDEX IF NOT EXISTS idx_item_attributes_numeric ON item_attributes(attribute_id, attribute_numeric(value));
+CREATE INDEX IF NOT EXISTS idx_item_attributes_date ON item_attributes(attribute_id, attribute_date(value));
+CREATE INDEX IF NOT EXISTS idx_item_attributes_value ON item_attributes(attribute_id, value);
@@ -0,0 +1,46 @@
+-- ======================================================
+-- Migration: 列挙型（enum）の属性と選択肢テーブルの作成
+-- ======================================================
+-- 説明: 属性値の型に enum を追加し、enum 属性で選択できる値（選択肢）を attribute_options で管理します。
+--       例: 属性「色」の選択肢 red（赤）, blue（青）, green（緑）
+-- 実行順序: 10_create_tenants.sql の後に実行してください
+--
+-- 運用ルール:
+--   - enum 属性の item_attributes.value には選択肢のコード（attribute_options.code）を保存します
+--   - 表示名（label）は参照時に結合して取得するため、表示名の変更でアイテムのデータを書き換える必要はありません
+--   - 選択肢のコードは変更できません（変更する場合は選択肢を追加して値を移し替えてください）
+--   - アイテムで使用中の選択肢は削除できません
+-- ======================================================
+
+ALTER TABLE attributes DROP CONSTRAINT IF EXISTS attributes_value_type_check;
+ALTER TABLE attributes ADD CONSTRAINT attributes_value_type_check
+  CHECK (value_type IN ('text','number','boolean','date','enum'));
+
+COMMENT ON COLUMN attributes.value_type IS '属性値の型（text, number, boolean, date, enum）';
+
+CREATE SEQUENCE IF NOT EXISTS attribute_options_id_seq START WITH 1;
+
+CREATE TABLE IF NOT EXISTS attribute_options (
+  id            TEXT PRIMARY KEY DEFAULT 'O' || LPAD(nextval('attribute_options_id_seq')::TEXT, 8, '0'),
+  tenant_id     TEXT NOT NULL REFERENCES tenants(id),
+  attribute_id  TEXT NOT NULL,
+  code          TEXT NOT NULL,
+  label         TEXT NOT NULL,
+  sort_order    INTEGER NOT NULL DEFAULT 0,
+  created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (attribute_id, code),
+  FOREIGN KEY (tenant_id, attribute_id) REFERENCES attributes(tenant_id, id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_attribute_options_order ON attribute_options (attribute_id, sort_order, code);
+
+COMMENT ON TABLE attribute_options IS '属性の選択肢テーブル。enum 属性で選択できる値と表示名・表示順';
+COMMENT ON COLUMN attribute_options.id IS '選択肢ID（O + 8桁の連番、例: O00000001）';
+COMMENT ON COLUMN attribute_options.tenant_id IS 'テナントID';
+COMMENT ON COLUMN attribute_options.attribute_id IS '属性ID（value_type = enum の属性）';
+COMMENT ON COLUMN attribute_options.code IS '選択肢コード（属性内で一意。item_attributes.value に保存する値）';
+COMMENT ON COLUMN attribute_options.label IS '表示名（例: 赤）';
+COMMENT ON COLUMN attribute_options.sort_order IS '表示順（昇順）';
+COMMENT ON COLUMN attribute_options.created_at IS '作成日時';
+COMMENT ON COLUMN attribute_options.updated_at IS '更新日時';
@@ -0,0 +1,66 @@
+-- ======================================================
+-- Migration: カテゴリの属性テンプレートの作成
+-- ======================================================
+-- 説明: カテゴリごとに、所属アイテムに適用する属性・必須の属性・既定値を定義します。
+--       例: カテゴリ「食品」は best_before（賞味期限）を必須とする
+-- 実行順序: 16_create_attribute_options.sql の後に実行してください
+--
+-- 運用ルール:
+--   - テンプレートを定義したカテゴリのアイテムは、テンプレートにある属性のみ設定できます
+--   - テンプレートのないカテゴリ・カテゴリ未設定のアイテムは、従来どおり任意の属性を設定できます
+--   - アイテムの作成時・カテゴリ変更時は、未設定の属性に既定値を設定します
+--     （必須の属性は、値が削除された場合も既定値を設定します）
+--   - アイテムの作成・更新時にテンプレートを満たさない場合はエラーになります
+--   - テンプレートの変更前から存在するアイテムの違反は item_template_violations で確認できます
+-- ======================================================
+
+CREATE TABLE IF NOT EXISTS category_attributes (
+  tenant_id     TEXT NOT NULL REFERENCES tenants(id),
+  category_id   TEXT NOT NULL,
+  attribute_id  TEXT NOT NULL,
+  required      BOOLEAN NOT NULL DEFAULT FALSE,
+  default_value TEXT,
+  sort_order    INTEGER NOT NULL DEFAULT 0,
+  created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (category_id, attribute_id),
+  FOREIGN KEY (tenant_id, category_id)  REFERENCES categories(tenant_id, id) ON DELETE CASCADE,
+  FOREIGN KEY (tenant_id, attribute_id) REFERENCES attributes(tenant_id, id) ON DELETE CASCADE
+);
+
+COMMENT ON TABLE category_attributes IS 'カテゴリの属性テンプレート。カテゴリのアイテムに適用する属性・必須・既定値';
+COMMENT ON COLUMN category_attributes.tenant_id IS 'テナントID';
+COMMENT ON COLUMN category_attributes.category_id IS 'カテゴリID';
+COMMENT ON COLUMN category_attributes.attribute_id IS '属性ID';
+COMMENT ON COLUMN category_attributes.required IS '必須かどうか';
+COMMENT ON COLUMN category_attributes.default_value IS '既定値（属性の型に応じた正規形。任意）';
+COMMENT ON COLUMN category_attributes.sort_order IS '表示順（昇順）';
+COMMENT ON COLUMN category_attributes.created_at IS '作成日時';
+COMMENT ON COLUMN category_attributes.updated_at IS '更新日時';
+
+-- ======================================================
+-- テンプレート違反のビュー
+-- ======================================================
+
+-- missing: 必須の属性に値がない / not_applicable: カテゴリのテンプレートにない属性に値がある
+CREATE OR REPLACE VIEW item_template_violations AS
+SELECT i.tenant_id, i.id AS item_id, i.category_id, a.id AS attribute_id, a.code AS attribute_code, 'missing' AS violation
+FROM items i
+INNER JOIN category_attributes ca ON ca.category_id = i.category_id AND ca.required
+INNER JOIN attributes a ON a.id = ca.attribute_id AND a.deleted_at IS NULL
+WHERE i.deleted_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM item_attributes ia WHERE ia.item_id = i.id AND ia.attribute_id = ca.attribute_id
+  )
+UNION ALL
+SELECT i.tenant_id, i.id, i.category_id, a.id, a.code, 'not_applicable'
+FROM items i
+INNER JOIN item_attributes ia ON ia.item_id = i.id
+INNER JOIN attributes a ON a.id = ia.attribute_id AND a.deleted_at IS NULL
+WHERE i.deleted_at IS NULL
+  AND EXISTS (SELECT 1 FROM category_attributes ca WHERE ca.category_id = i.category_id)
+  AND NOT EXISTS (
+    SELECT 1 FROM category_attributes ca WHERE ca.category_id = i.category_id AND ca.attribute_id = ia.attribute_id
+  );
+
+COMMENT ON VIEW item_template_violations IS 'カテゴリの属性テンプレートを満たさないアイテムの属性（missing: 必須の値がない, not_applicable: 適用外の属性）';
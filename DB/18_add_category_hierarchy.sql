@@ -0,0 +1,25 @@
+-- ======================================================
+-- Migration: カテゴリの階層化
+-- ======================================================
+-- 説明: categories に parent_id を追加し、「キッチン > スパイス」のような親子関係を持てるようにします。
+--       locations と同じく隣接リスト（parent_id）で表現し、子孫は再帰 CTE でたどります。
+-- 実行順序: 10_create_tenants.sql の後に実行してください
+--
+-- 運用ルール:
+--   - parent_id が NULL のカテゴリが最上位です
+--   - 親の変更（サブツリーの移動）は API の PUT /api/categories/:id/parent で行い、
+--     循環（自身や子孫を親にする）はアプリケーションがトランザクション内で検出して拒否します
+--   - 親を変更すると、子孫のカテゴリとアイテムはそのまま移動先の配下になります
+--   - カテゴリの権限付与（user_grants）は子孫カテゴリにも適用されます
+--   - 子カテゴリのあるカテゴリは削除できません
+-- ======================================================
+
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS parent_id TEXT;
+
+ALTER TABLE categories
+  ADD CONSTRAINT categories_tenant_parent_fkey FOREIGN KEY (tenant_id, parent_id) REFERENCES categories(tenant_id, id),
+  ADD CONSTRAINT categories_parent_not_self CHECK (parent_id <> id);
+
+CREATE INDEX IF NOT EXISTS idx_categories_parent ON categories(parent_id) WHERE deleted_at IS NULL;
+
+COMMENT ON COLUMN categories.parent_id IS '親カテゴリID（NULL の場合は最上位）';
@@ -0,0 +1,63 @@
+-- ======================================================
+-- Migration: 単位換算と荷姿（パッケージ）テーブルの作成
+-- ======================================================
+-- 説明: 単位間の換算係数を unit_conversions で、アイテムごとの荷姿（入数）を item_packagings で管理します。
+--       例: 1 kg = 1000 g（unit_conversions）、米 1 袋 = 5000 g・缶詰 1 ケース = 24 個（item_packagings）
+-- 実行順序: 10_create_tenants.sql の後に実行してください
+--
+-- 運用ルール:
+--   - 在庫数量（stocks.qty, stock_history.qty_delta）は常にアイテムの基本単位（items.unit_id）で記録します
+--   - 入出庫で基本単位以外の単位を指定した場合、荷姿 → 単位換算の順に係数を探して基本単位に換算します
+--   - 単位換算は 2 つの単位の組につき 1 方向のみ登録します（逆方向は係数の逆数で換算します）
+--   - 荷姿の係数は基本単位での入数です。アイテムの基本単位を変更する場合は荷姿も見直してください
+-- ======================================================
+
+CREATE TABLE IF NOT EXISTS unit_conversions (
+  tenant_id     TEXT NOT NULL REFERENCES tenants(id),
+  from_unit_id  TEXT NOT NULL,
+  to_unit_id    TEXT NOT NULL,
+  factor        NUMERIC(20,8) NOT NULL CHECK (factor > 0),
+  created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (from_unit_id, to_unit_id),
+  CONSTRAINT unit_conversions_not_self CHECK (from_unit_id <> to_unit_id),
+  FOREIGN KEY (tenant_id, from_unit_id) REFERENCES units(tenant_id, id) ON DELETE CASCADE,
+  FOREIGN KEY (tenant_id, to_unit_id)   REFERENCES units(tenant_id, id) ON DELETE CASCADE
+);
+
+-- 同じ単位の組を逆方向にも登録できないようにする
+CREATE UNIQUE INDEX IF NOT EXISTS idx_unit_conversions_pair
+  ON unit_conversions (LEAST(from_unit_id, to_unit_id), GREATEST(from_unit_id, to_unit_id));
+CREATE INDEX IF NOT EXISTS idx_unit_conversions_to ON unit_conversions (to_unit_id);
+
+COMMENT ON TABLE unit_conversions IS '単位換算テーブル。1 from_unit_id = factor to_unit_id';
+COMMENT ON COLUMN unit_conversions.tenant_id IS 'テナントID';
+COMMENT ON COLUMN unit_conversions.from_unit_id IS '換算元の単位ID';
+COMMENT ON COLUMN unit_conversions.to_unit_id IS '換算先の単位ID';
+COMMENT ON COLUMN unit_conversions.factor IS '換算係数（換算元 1 あたりの換算先の数量、正の数）';
+COMMENT ON COLUMN unit_conversions.created_at IS '作成日時';
+COMMENT ON COLUMN unit_conversions.updated_at IS '更新日時';
+
+CREATE TABLE IF NOT EXISTS item_packagings (
+  tenant_id     TEXT NOT NULL REFERENCES tenants(id),
+  item_id       TEXT NOT NULL,
+  unit_id       TEXT NOT NULL,
+  factor        NUMERIC(20,8) NOT NULL CHECK (factor > 0),
+  created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (item_id, unit_id),
+  FOREIGN KEY (tenant_id, item_id) REFERENCES items(tenant_id, id) ON DELETE CASCADE,
+  FOREIGN KEY (tenant_id, unit_id) REFERENCES units(tenant_id, id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_item_packagings_unit ON item_packagings (unit_id);
+
+COMMENT ON TABLE item_packagings IS 'アイテムの荷姿テーブル。荷姿の単位 1 あたりの基本単位での入数';
+COMMENT ON COLUMN item_packagings.tenant_id IS 'テナントID';
+COMMENT ON COLUMN item_packagings.item_id IS 'アイテムID';
+COMMENT ON COLUMN item_packagings.unit_id IS '荷姿の単位ID（例: 袋, ケース。アイテムの基本単位以外）';
+COMMENT ON COLUMN item_packagings.factor IS '入数（荷姿 1 あたりのアイテムの基本単位での数量、正の数）';
+COMMENT ON COLUMN item_packagings.created_at IS '作成日時';
+COMMENT ON COLUMN item_packagings.updated_at IS '更新日時';
+
+COMMENT ON COLUMN stock_history.meta IS '補足情報（JSON形式、参照番号など。基本単位以外で入出庫した場合は指定した単位 unit_id・数量 qty・換算係数 factor）';
@@ -0,0 +1,31 @@
+-- ======================================================
+-- Migration: 論理削除後のコードの再利用
+-- ======================================================
+-- 説明: items / categories / units / attributes の code の一意制約を、有効なデータ（deleted_at IS NULL）のみを
+--       対象とする部分一意インデックスに置き換えます。
+--       例: 「MILK」を削除した後に、新しい「MILK」を作成できるようにします
+-- 実行順序: 10_create_tenants.sql の後に実行してください
+--
+-- 運用ルール:
+--   - 既存データは従来の (tenant_id, code) の一意制約を満たしているため、データの変換は不要です
+--   - 削除済みのデータ同士、および削除済みのデータと有効なデータはコードが重複することがあります
+--   - 削除済みのデータを復元する際に、同じコードの有効なデータがある場合は復元を拒否します
+--     （先に有効なデータのコードを変更するか、削除してください）
+--   - コードで検索・照合する場合は deleted_at IS NULL を条件に含めてください
+--   - ON CONFLICT (tenant_id, code) を使う場合は WHERE deleted_at IS NULL を指定してください
+-- ======================================================
+
+ALTER TABLE items      DROP CONSTRAINT IF EXISTS items_tenant_code_key;
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS categories_tenant_code_key;
+ALTER TABLE units      DROP CONSTRAINT IF EXISTS units_tenant_code_key;
+ALTER TABLE attributes DROP CONSTRAINT IF EXISTS attributes_tenant_code_key;
+
+CREATE UNIQUE INDEX IF NOT EXISTS items_tenant_code_key      ON items (tenant_id, code)      WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS categories_tenant_code_key ON categories (tenant_id, code) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS units_tenant_code_key      ON units (tenant_id, code)      WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS attributes_tenant_code_key ON attributes (tenant_id, code) WHERE deleted_at IS NULL;
+
+COMMENT ON INDEX items_tenant_code_key IS '有効なアイテムのコードの一意性（テナント単位）';
+COMMENT ON INDEX categories_tenant_code_key IS '有効なカテゴリのコードの一意性（テナント単位）';
+COMMENT ON INDEX units_tenant_code_key IS '有効な単位のコードの一意性（テナント単位）';
+COMMENT ON INDEX attributes_tenant_code_key IS '有効な属性のコードの一意性（テナント単位）';
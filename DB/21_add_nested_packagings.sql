@@ -0,0 +1,29 @@
+-- ======================================================
+-- Migration: 荷姿の入れ子（ケース → パック → 缶）
+-- ======================================================
+-- 説明: item_packagings に内容物の単位（contains_unit_id）を追加し、荷姿の中身を別の荷姿で表せるようにします。
+--       例: 缶詰 1 ケース = 4 パック、1 パック = 6 缶（基本単位）→ 1 ケース = 24 缶
+-- 実行順序: 19_create_unit_conversions.sql の後に実行してください
+--
+-- 運用ルール:
+--   - contains_unit_id が NULL の荷姿は、従来どおり factor をアイテムの基本単位での入数とします
+--   - contains_unit_id を指定した荷姿は、factor を内容物の単位での入数とします
+--   - 換算は荷姿・単位換算をたどって基本単位まで掛け合わせます（たどる段数には上限があります）
+--   - 他の荷姿の内容物になっている荷姿は、先にその荷姿を見直してから削除してください
+-- ======================================================
+
+ALTER TABLE item_packagings ADD COLUMN IF NOT EXISTS contains_unit_id TEXT;
+
+ALTER TABLE item_packagings DROP CONSTRAINT IF EXISTS item_packagings_contains_unit_fkey;
+ALTER TABLE item_packagings ADD CONSTRAINT item_packagings_contains_unit_fkey
+  FOREIGN KEY (tenant_id, contains_unit_id) REFERENCES units(tenant_id, id);
+
+ALTER TABLE item_packagings DROP CONSTRAINT IF EXISTS item_packagings_not_self;
+ALTER TABLE item_packagings ADD CONSTRAINT item_packagings_not_self
+  CHECK (contains_unit_id IS NULL OR contains_unit_id <> unit_id);
+
+CREATE INDEX IF NOT EXISTS idx_item_packagings_contains ON item_packagings (item_id, contains_unit_id);
+
+COMMENT ON TABLE item_packagings IS 'アイテムの荷姿テーブル。荷姿の単位 1 あたりの内容物（未指定の場合は基本単位）の入数';
+COMMENT ON COLUMN item_packagings.contains_unit_id IS '内容物の単位ID（例: ケースの中身のパック。NULL の場合はアイテムの基本単位）';
+COMMENT ON COLUMN item_packagings.factor IS '入数（荷姿 1 あたりの内容物の単位での数量、正の数）';
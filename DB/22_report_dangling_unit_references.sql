@@ -0,0 +1,41 @@
+-- ======================================================
+-- Migration: 削除済みの単位への参照の確認
+-- ======================================================
+-- 説明: 削除済み（deleted_at IS NOT NULL）の単位を参照している有効なアイテム・荷姿を一覧するビューを作成します。
+--       以前のバージョンでは、単位の削除時に荷姿からの参照を確認していなかったため、
+--       アイテムの一覧・詳細から消えたり、削除済みの荷姿で換算されたりするデータが残っている場合があります
+-- 実行順序: 21_add_nested_packagings.sql の後に実行してください
+--
+-- 運用ルール:
+--   - 実行時に該当件数を NOTICE で出力します（0 件であれば対応は不要です）
+--   - 該当するデータは、単位を復元する（POST /api/units/:id/restore）か、
+--     アイテムの単位・荷姿を有効な単位に変更してから、改めて単位を削除してください
+--   - データは自動では変更しません（換算の要否を判断できないため）
+-- ======================================================
+
+-- reference: items.unit_id（基本単位）/ item_packagings.unit_id（荷姿の単位）/ item_packagings.contains_unit_id（荷姿の内容物）
+CREATE OR REPLACE VIEW dangling_unit_references AS
+SELECT i.tenant_id, i.id AS item_id, i.code AS item_code, u.id AS unit_id, u.code AS unit_code, 'items.unit_id' AS reference
+FROM items i
+INNER JOIN units u ON u.id = i.unit_id AND u.deleted_at IS NOT NULL
+WHERE i.deleted_at IS NULL
+UNION ALL
+SELECT i.tenant_id, i.id, i.code, u.id, u.code, 'item_packagings.unit_id'
+FROM item_packagings p
+INNER JOIN items i ON i.id = p.item_id AND i.deleted_at IS NULL
+INNER JOIN units u ON u.id = p.unit_id AND u.deleted_at IS NOT NULL
+UNION ALL
+SELECT i.tenant_id, i.id, i.code, u.id, u.code, 'item_packagings.contains_unit_id'
+FROM item_packagings p
+INNER JOIN items i ON i.id = p.item_id AND i.deleted_at IS NULL
+INNER JOIN units u ON u.id = p.contains_unit_id AND u.deleted_at IS NOT NULL;
+
+COMMENT ON VIEW dangling_unit_references IS '削除済みの単位を参照している有効なアイテム・荷姿（reference: 参照元の列）';
+
+DO $$
+DECLARE
+  dangling INTEGER;
+BEGIN
+  SELECT COUNT(*) INTO dangling FROM dangling_unit_references;
+  RAISE NOTICE '削除済みの単位への参照: % 件（dangling_unit_references を確認してください）', dangling;
+END $$;
@@ -1,30 +1,43 @@
 -- サンプルデータ挿入スクリプト
 -- 既存のinit.sqlで作成されたテーブルにデータを追加
+-- データはデフォルトテナント（code: default）に投入します
 
 -- ======================================================
 -- マスタデータの挿入
 -- ======================================================
 
 -- カテゴリマスタ
-INSERT INTO categories (code, name, description) VALUES
+INSERT INTO categories (tenant_id, code, name, description)
+SELECT t.id, v.code, v.name, v.description
+FROM tenants t, (VALUES
   ('HW', '金具', '金属製の部品や金具類'),
   ('EC', '電子部品', '電子回路に使用する部品'),
   ('CS', '消耗品', '日常的に消耗する物品')
-ON CONFLICT (code) DO NOTHING;
+) AS v(code, name, description)
+WHERE t.code = 'default'
+ON CONFLICT (tenant_id, code) WHERE deleted_at IS NULL DO NOTHING;
 
 -- 単位マスタ
-INSERT INTO units (code, name, description) VALUES
+INSERT INTO units (tenant_id, code, name, description)
+SELECT t.id, v.code, v.name, v.description
+FROM tenants t, (VALUES
   ('pc', '個', '個数単位'),
   ('box', '箱', '箱単位'),
   ('kg', 'キログラム', '重量単位'),
   ('m', 'メートル', '長さ単位')
-ON CONFLICT (code) DO NOTHING;
+) AS v(code, name, description)
+WHERE t.code = 'default'
+ON CONFLICT (tenant_id, code) WHERE deleted_at IS NULL DO NOTHING;
 
 -- ユーザー（テスト用）
-INSERT INTO users (email, password_hash, role) VALUES
+INSERT INTO users (tenant_id, email, password_hash, role)
+SELECT t.id, v.email, v.password_hash, v.role
+FROM tenants t, (VALUES
   ('admin@example.com', '$2a$10$dummyhashforthisexample12345678901234567890', 'admin'),
   ('operator@example.com', '$2a$10$dummyhashforthisexample12345678901234567890', 'operator'),
   ('viewer@example.com', '$2a$10$dummyhashforthisexample12345678901234567890', 'viewer')
+) AS v(email, password_hash, role)
+WHERE t.code = 'default'
 ON CONFLICT (email) DO NOTHING;
 
 -- ======================================================
@@ -34,6 +47,7 @@ ON CONFLICT (email) DO NOTHING;
 -- カテゴリと単位のIDを取得
 DO $$
 DECLARE
+  tenant TEXT;
   cat_hw_id TEXT;
   cat_ec_id TEXT;
   cat_cs_id TEXT;
@@ -41,19 +55,22 @@ DECLARE
   unit_box_id TEXT;
   i INTEGER;
 BEGIN
+  SELECT id INTO tenant FROM tenants WHERE code = 'default';
+
   -- カテゴリIDを取得
-  SELECT id INTO cat_hw_id FROM categories WHERE code = 'HW';
-  SELECT id INTO cat_ec_id FROM categories WHERE code = 'EC';
-  SELECT id INTO cat_cs_id FROM categories WHERE code = 'CS';
+  SELECT id INTO cat_hw_id FROM categories WHERE tenant_id = tenant AND code = 'HW';
+  SELECT id INTO cat_ec_id FROM categories WHERE tenant_id = tenant AND code = 'EC';
+  SELECT id INTO cat_cs_id FROM categories WHERE tenant_id = tenant AND code = 'CS';
   
   -- 単位IDを取得
-  SELECT id INTO unit_pc_id FROM units WHERE code = 'pc';
-  SELECT id INTO unit_box_id FROM units WHERE code = 'box';
+  SELECT id INTO unit_pc_id FROM units WHERE tenant_id = tenant AND code = 'pc';
+  SELECT id INTO unit_box_id FROM units WHERE tenant_id = tenant AND code = 'box';
 
   -- 57件のサンプルアイテムを挿入
   FOR i IN 1..57 LOOP
-    INSERT INTO items (code, name, category_id, unit_id, quantity, status, created_at, updated_at)
+    INSERT INTO items (tenant_id, code, name, category_id, unit_id, quantity, status, created_at, updated_at)
     VALUES (
+      tenant,
       'SKU-' || LPAD(i::TEXT, 4, '0'),
       'サンプル品目 ' || i,
       CASE 
@@ -73,7 +90,7 @@ BEGIN
       NOW() - (i || ' days')::INTERVAL,
       NOW() - (i * 0.5 || ' days')::INTERVAL
     )
-    ON CONFLICT (code) DO NOTHING;
+    ON CONFLICT (tenant_id, code) WHERE deleted_at IS NULL DO NOTHING;
   END LOOP;
 END $$;
 
@@ -81,11 +98,15 @@ END $$;
 -- ロケーションデータの挿入
 -- ======================================================
 
-INSERT INTO locations (code, name) VALUES
+INSERT INTO locations (tenant_id, code, name)
+SELECT t.id, v.code, v.name
+FROM tenants t, (VALUES
   ('WH-A', '倉庫A'),
   ('WH-B', '倉庫B'),
   ('WH-C', '倉庫C')
-ON CONFLICT (code) DO NOTHING;
+) AS v(code, name)
+WHERE t.code = 'default'
+ON CONFLICT (tenant_id, code) DO NOTHING;
 
 -- ======================================================
 -- 在庫データの挿入
@@ -97,10 +118,11 @@ DECLARE
   loc_rec RECORD;
 BEGIN
   -- 各アイテムに対してランダムなロケーションに在庫を配置
-  FOR item_rec IN SELECT id FROM items LIMIT 20 LOOP
-    FOR loc_rec IN SELECT id FROM locations ORDER BY RANDOM() LIMIT 1 LOOP
-      INSERT INTO stocks (item_id, location_id, qty)
+  FOR item_rec IN SELECT id, tenant_id FROM items LIMIT 20 LOOP
+    FOR loc_rec IN SELECT id FROM locations WHERE tenant_id = item_rec.tenant_id ORDER BY RANDOM() LIMIT 1 LOOP
+      INSERT INTO stocks (tenant_id, item_id, location_id, qty)
       VALUES (
+        item_rec.tenant_id,
         item_rec.id,
         loc_rec.id,
         FLOOR(RANDOM() * 100)::NUMERIC
@@ -0,0 +1,125 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"go-hsm-app/internal/repository"
+)
+
+// APIKeyScheme は API キーの先頭に付与する識別子です
+const APIKeyScheme = "hsk_"
+
+// HeaderAPIKey は API キーを指定するためのヘッダ名です（Authorization: Bearer でも指定可能）
+const HeaderAPIKey = "X-API-Key"
+
+// ErrInvalidAPIKey は API キーが不正・失効済み・期限切れの場合のエラーです
+var ErrInvalidAPIKey = errors.New("invalid, revoked or expired API key")
+
+// GenerateAPIKey は新しい API キーを生成します。
+// キーの形式は hsk_<prefix>_<secret> で、prefix は識別用に DB へ平文で保存します。
+func GenerateAPIKey() (key, prefix, hash string, err error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", "", err
+	}
+	prefix = hex.EncodeToString(b)
+
+	secret, err := randomToken(32)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	key = APIKeyScheme + prefix + "_" + secret
+	return key, prefix, HashToken(key), nil
+}
+
+// IsAPIKey は文字列が API キーの形式か判定します
+func IsAPIKey(s string) bool {
+	return strings.HasPrefix(s, APIKeyScheme)
+}
+
+// EffectiveRole は API キーに許可されたロールのうち、所有ユーザーの現在の権限で
+// 行使できる最も強いロールを返します。該当がない場合は空文字を返します。
+func EffectiveRole(keyRoles []string, userRole string) string {
+	effective := ""
+	for _, r := range keyRoles {
+		if HasRole(userRole, r) && (effective == "" || HasRole(r, effective)) {
+			effective = r
+		}
+	}
+	return effective
+}
+
+// apiKeyPrefix は API キーから識別用プレフィックスを取り出します
+func apiKeyPrefix(key string) (string, bool) {
+	rest, ok := strings.CutPrefix(key, APIKeyScheme)
+	if !ok {
+		return "", false
+	}
+	prefix, _, ok := strings.Cut(rest, "_")
+	return prefix, ok && prefix != ""
+}
+
+// authenticateAPIKey は API キーを検証し、Principal を返します。
+// 接続元IPとキーごとに失敗を記録し、制限中は *ThrottledError を返します。
+func authenticateAPIKey(key string, ev AuthEvent) (*Principal, error) {
+	prefix, ok := apiKeyPrefix(key)
+	keys := []ThrottleKey{IPKey(ev.IPAddress)}
+	if ok {
+		keys = append(keys, APIKeyKey(prefix))
+	}
+	if err := CheckThrottle(keys...); err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		RecordAuthFailure(ev, keys...)
+		return nil, ErrInvalidAPIKey
+	}
+
+	p, err := verifyAPIKey(key, prefix, ev.IPAddress)
+	if errors.Is(err, ErrInvalidAPIKey) {
+		RecordAuthFailure(ev, keys...)
+	}
+	return p, err
+}
+
+// verifyAPIKey はプレフィックスで API キーを検索し、ハッシュ・失効・有効期限・ロールを検証します
+func verifyAPIKey(key, prefix, ipAddress string) (*Principal, error) {
+	apiKey, keyHash, owner, err := repository.FetchAPIKeyByPrefix(prefix)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(keyHash), []byte(HashToken(key))) != 1 {
+		return nil, ErrInvalidAPIKey
+	}
+	if apiKey.RevokedAt != nil || (apiKey.ExpiresAt != nil && time.Now().After(*apiKey.ExpiresAt)) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	role := EffectiveRole(apiKey.Roles, owner.Role)
+	if role == "" {
+		log.Printf("[Auth] API キーに行使可能なロールがありません (key_id: %s)", apiKey.ID)
+		return nil, ErrInvalidAPIKey
+	}
+
+	repository.TouchAPIKey(apiKey.ID, ipAddress)
+
+	return &Principal{
+		UserID:   apiKey.UserID,
+		TenantID: owner.TenantID,
+		Role:     role,
+		APIKeyID: apiKey.ID,
+	}, nil
+}
@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Principal は認証済みのリクエスト主体を表します
+type Principal struct {
+	UserID         string // ユーザーID（なりすまし中はなりすまし対象）
+	TenantID       string // 所属テナントID
+	Role           string // ユーザー権限
+	SessionID      string // セッションID（JWT の sid クレーム）
+	APIKeyID       string // API キーで認証した場合のキーID
+	ImpersonatorID string // なりすまし中の場合の管理者のユーザーID
+}
+
+// Impersonating はなりすまし中のリクエストかを返します
+func (p *Principal) Impersonating() bool {
+	return p != nil && p.ImpersonatorID != ""
+}
+
+// principalKey は context.Context / echo.Context に Principal を格納するキーです
+type principalKey struct{}
+
+// echoPrincipalKey は echo.Context に Principal を格納するキーです
+const echoPrincipalKey = "auth.principal"
+
+// WithPrincipal は Principal を格納した context を返します
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext は context から Principal を取得します（未認証の場合は nil）
+func PrincipalFromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
+
+// CurrentPrincipal は echo.Context から Principal を取得します（未認証の場合は nil）
+func CurrentPrincipal(c echo.Context) *Principal {
+	p, _ := c.Get(echoPrincipalKey).(*Principal)
+	return p
+}
+
+// setPrincipal は echo.Context とリクエストの context の両方に Principal を格納します。
+// GraphQL リゾルバはリクエストの context から Principal を参照します。
+func setPrincipal(c echo.Context, p *Principal) {
+	c.Set(echoPrincipalKey, p)
+	c.SetRequest(c.Request().WithContext(WithPrincipal(c.Request().Context(), p)))
+}
@@ -0,0 +1,143 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+
+	"go-hsm-app/internal/model"
+	"go-hsm-app/internal/repository"
+
+	"github.com/labstack/echo/v4"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+// AuditImpersonation はなりすまし中の書き込み操作を監査ログに記録する Echo ミドルウェアです。
+// 監査ログには操作対象のユーザー（user_id）と管理者（impersonator_id）の両方を記録します。
+// 操作が成功（2xx）した場合のみ、ハンドラの実行後に記録します。
+// GraphQL は HTTP メソッドではなく実行する操作の種類で判定し、mutation のみ記録します。
+// Authenticate の後に登録してください。
+func AuditImpersonation() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			p := CurrentPrincipal(c)
+			if !p.Impersonating() || !isWriteMethod(c.Request().Method) {
+				return next(c)
+			}
+
+			diff := map[string]interface{}{
+				"method": c.Request().Method,
+				"path":   c.Request().URL.Path,
+			}
+			if names := c.ParamNames(); len(names) > 0 {
+				params := map[string]string{}
+				for _, name := range names {
+					params[name] = c.Param(name)
+				}
+				diff["params"] = params
+			}
+
+			action := writeAction(c.Request().Method)
+			if c.Path() == "/graphql" {
+				mutation, operationName := graphQLMutation(c)
+				if !mutation {
+					return next(c)
+				}
+				action = "graphql"
+				if operationName != "" {
+					diff["operation"] = operationName
+				}
+			}
+
+			if err := next(c); err != nil {
+				return err
+			}
+			if status := c.Response().Status; status < 200 || status >= 300 {
+				return nil
+			}
+
+			raw, _ := json.Marshal(diff)
+			entry := model.AuditLog{
+				UserID:         &p.UserID,
+				ImpersonatorID: &p.ImpersonatorID,
+				Action:         "impersonated_" + action,
+				Resource:       c.Path(),
+				Diff:           raw,
+			}
+			if id := c.Param("id"); id != "" {
+				entry.ResourceID = &id
+			}
+			if requestID := c.Response().Header().Get(echo.HeaderXRequestID); requestID != "" {
+				entry.RequestID = &requestID
+			}
+
+			// 操作は完了しているため、記録できない場合もレスポンスは変えずにログに残す
+			if err := repository.CreateAuditLog(entry); err != nil {
+				log.Printf("[Auth] エラー: なりすまし操作の監査ログを記録できませんでした (user_id: %s, impersonator_id: %s, %s %s): %v",
+					p.UserID, p.ImpersonatorID, c.Request().Method, c.Request().URL.Path, err)
+			}
+			return nil
+		}
+	}
+}
+
+// graphQLMutation は GraphQL リクエストで実行する操作が mutation かを判定し、操作名とともに返します。
+// 本文を読み取った後はハンドラが再度読めるように戻します。
+// JSON 以外の本文など操作を特定できない場合は、記録漏れを防ぐため mutation として扱います
+func graphQLMutation(c echo.Context) (bool, string) {
+	body, err := io.ReadAll(c.Request().Body)
+	c.Request().Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return true, ""
+	}
+
+	var params struct {
+		Query         string `json:"query"`
+		OperationName string `json:"operationName"`
+	}
+	if err := json.Unmarshal(body, &params); err != nil {
+		return true, ""
+	}
+	doc, gqlErr := parser.ParseQuery(&ast.Source{Input: params.Query})
+	if gqlErr != nil {
+		// 構文エラーのリクエストは実行されない
+		return false, ""
+	}
+
+	var operation *ast.OperationDefinition
+	if params.OperationName != "" {
+		operation = doc.Operations.ForName(params.OperationName)
+	} else if len(doc.Operations) == 1 {
+		operation = doc.Operations[0]
+	}
+	if operation == nil {
+		// 実行する操作を特定できないリクエストは実行されない
+		return false, ""
+	}
+	return operation.Operation == ast.Mutation, operation.Name
+}
+
+// isWriteMethod は書き込み操作の HTTP メソッドかを判定します
+func isWriteMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return true
+}
+
+// writeAction は HTTP メソッドに対応する監査ログの操作内容を返します
+func writeAction(method string) string {
+	switch method {
+	case http.MethodPost:
+		return "create"
+	case http.MethodPut, http.MethodPatch:
+		return "update"
+	case http.MethodDelete:
+		return "delete"
+	}
+	return "write"
+}
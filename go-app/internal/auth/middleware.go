@@ -0,0 +1,211 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go-hsm-app/internal/repository"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+)
+
+// Authenticate は Authorization ヘッダのアクセストークン、または API キーを検証し、
+// リクエストに Principal を設定する Echo ミドルウェアです。
+// API キーは X-API-Key ヘッダ、または Authorization: Bearer hsk_... で指定できます。
+// 認証情報がない場合は未認証のまま次へ進み、可否の判定は Authorize に委ねます。
+// 認証失敗が続いて制限中の接続元IPは、アクセストークン・API キーとも検証せずに 429 を返します。
+func Authenticate() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if key := c.Request().Header.Get(HeaderAPIKey); key != "" {
+				return authenticateWithAPIKey(c, next, key)
+			}
+
+			header := c.Request().Header.Get(echo.HeaderAuthorization)
+			if header == "" {
+				return next(c)
+			}
+
+			tokenString, ok := strings.CutPrefix(header, "Bearer ")
+			if !ok || tokenString == "" {
+				return Unauthorized(c, "Authorization header must use the Bearer scheme")
+			}
+
+			if IsAPIKey(tokenString) {
+				return authenticateWithAPIKey(c, next, tokenString)
+			}
+
+			// 失敗が続いている接続元IPからは、トークンを検証せずに制限する（REST・GraphQL 共通）
+			if err := CheckThrottle(IPKey(c.RealIP())); err != nil {
+				var throttled *ThrottledError
+				if errors.As(err, &throttled) {
+					log.Printf("[Auth] アクセストークン認証を制限中です: %v", throttled)
+					return TooManyAttempts(c, throttled)
+				}
+				log.Printf("[Auth] 認証制限の確認エラー: %v", err)
+				return c.JSON(http.StatusInternalServerError, map[string]string{
+					"error":   "internal_error",
+					"message": "Failed to verify access token",
+				})
+			}
+
+			claims, err := ParseToken(tokenString, TokenTypeAccess, TokenTypeImpersonation)
+			if err != nil {
+				log.Printf("[Auth] アクセストークンが無効です: %v", err)
+				// 期限切れは通常の利用でも発生するため、改ざん・偽造のみ失敗として記録する
+				if !errors.Is(err, jwt.ErrTokenExpired) {
+					RecordAuthFailure(EventFromContext(c), IPKey(c.RealIP()))
+				}
+				return Unauthorized(c, "Access token is invalid or expired")
+			}
+
+			if claims.SessionID != "" {
+				active, err := repository.IsSessionActive(claims.SessionID)
+				if err != nil {
+					return c.JSON(http.StatusInternalServerError, map[string]string{
+						"error":   "internal_error",
+						"message": "Failed to verify session",
+					})
+				}
+				if !active {
+					log.Printf("[Auth] 失効済みセッションのトークンです (user_id: %s)", claims.Subject)
+					return Unauthorized(c, "Session has been revoked")
+				}
+			}
+
+			p := &Principal{
+				UserID:    claims.Subject,
+				TenantID:  claims.TenantID,
+				Role:      claims.Role,
+				SessionID: claims.SessionID,
+			}
+			if claims.Actor != nil {
+				p.ImpersonatorID = claims.Actor.Subject
+			}
+			setPrincipal(c, p)
+			return next(c)
+		}
+	}
+}
+
+// authenticateWithAPIKey は API キーで認証し、次のハンドラを呼び出します
+func authenticateWithAPIKey(c echo.Context, next echo.HandlerFunc, key string) error {
+	p, err := authenticateAPIKey(key, EventFromContext(c))
+	if err != nil {
+		var throttled *ThrottledError
+		if errors.As(err, &throttled) {
+			log.Printf("[Auth] API キー認証を制限中です: %v", throttled)
+			return TooManyAttempts(c, throttled)
+		}
+		if errors.Is(err, ErrInvalidAPIKey) {
+			log.Printf("[Auth] API キーが無効です")
+			return Unauthorized(c, "API key is invalid, revoked or expired")
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error":   "internal_error",
+			"message": "Failed to verify API key",
+		})
+	}
+
+	setPrincipal(c, p)
+	return next(c)
+}
+
+// Authorize は権限マトリクス（Permissions）に基づいてルートへのアクセスを制御する Echo ミドルウェアです。
+// Authenticate の後に登録してください。
+func Authorize() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			// ルートに一致しない場合は 404 を返すため判定しない
+			if c.Path() == "" {
+				return next(c)
+			}
+
+			required, ok := RequiredRole(c.Request().Method, c.Path())
+			if !ok {
+				log.Printf("[Auth] 権限マトリクスに定義のないルートです: %s %s", c.Request().Method, c.Path())
+				return Forbidden(c, "")
+			}
+			if required == Public {
+				return next(c)
+			}
+
+			p := CurrentPrincipal(c)
+			if p == nil {
+				return Unauthorized(c, "Authentication required")
+			}
+			if required == SystemAdmin && IsSystemAdmin(p) {
+				return next(c)
+			}
+			if !HasRole(p.Role, required) {
+				log.Printf("[Auth] 権限不足: user_id=%s role=%s required=%s (%s %s)",
+					p.UserID, p.Role, required, c.Request().Method, c.Path())
+				return Forbidden(c, required)
+			}
+			return next(c)
+		}
+	}
+}
+
+// Unauthorized は認証エラー（401）のレスポンスを返します
+func Unauthorized(c echo.Context, message string) error {
+	c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer realm="homestock"`)
+	return c.JSON(http.StatusUnauthorized, map[string]string{
+		"error":   "unauthorized",
+		"message": message,
+	})
+}
+
+// TooManyAttempts は認証失敗が続いたことによる制限（429）のレスポンスを返します
+func TooManyAttempts(c echo.Context, throttled *ThrottledError) error {
+	seconds := int(math.Ceil(throttled.RetryAfter.Seconds()))
+	c.Response().Header().Set("Retry-After", strconv.Itoa(seconds))
+
+	body := map[string]interface{}{
+		"error":       "too_many_attempts",
+		"message":     "Too many failed attempts. Please retry later",
+		"retry_after": seconds,
+	}
+	if throttled.Locked {
+		body["error"] = "locked"
+		body["message"] = fmt.Sprintf("Authentication for this %s is temporarily locked", strings.ReplaceAll(throttled.Scope, "_", " "))
+	}
+	return c.JSON(http.StatusTooManyRequests, body)
+}
+
+// EventFromContext はリクエストから認証イベントの記録に使用する情報を取得します
+func EventFromContext(c echo.Context) AuthEvent {
+	ev := AuthEvent{
+		IPAddress: c.RealIP(),
+		RequestID: c.Response().Header().Get(echo.HeaderXRequestID),
+	}
+	if p := CurrentPrincipal(c); p != nil {
+		ev.UserID = &p.UserID
+	}
+	return ev
+}
+
+// Forbidden は権限エラー（403）のレスポンスを返します。
+// 全てのルートで同じ形式のボディを返します。
+func Forbidden(c echo.Context, requiredRole string) error {
+	return c.JSON(http.StatusForbidden, ForbiddenBody(requiredRole))
+}
+
+// ForbiddenBody は権限エラーのレスポンスボディを生成します
+func ForbiddenBody(requiredRole string) map[string]string {
+	body := map[string]string{
+		"error":   "forbidden",
+		"message": "You do not have permission to perform this operation",
+	}
+	if requiredRole != "" {
+		body["message"] = fmt.Sprintf("This operation requires the %s role", requiredRole)
+		body["required_role"] = requiredRole
+	}
+	return body
+}
@@ -0,0 +1,169 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"go-hsm-app/internal/common"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+var (
+	// ErrOIDCDisabled は OIDC ログインが設定されていない場合のエラーです
+	ErrOIDCDisabled = errors.New("oidc login is not configured")
+	// ErrOIDCEmailMissing は ID トークンに検証済みのメールアドレスが含まれない場合のエラーです
+	ErrOIDCEmailMissing = errors.New("id token does not contain a verified email")
+)
+
+// OIDCConfig は OIDC ログインの設定です
+type OIDCConfig struct {
+	IssuerURL            string   // IdP の発行者 URL（ディスカバリに使用）
+	ClientID             string   // クライアントID
+	ClientSecret         string   // クライアントシークレット
+	RedirectURL          string   // コールバックの URL
+	Scopes               []string // 要求するスコープ
+	RequireVerifiedEmail bool     // 検証済みのメールアドレスのみ受け付ける
+}
+
+// OIDCIdentity は ID トークンから取得した利用者の情報です
+type OIDCIdentity struct {
+	Issuer  string // 発行者
+	Subject string // IdP 上の利用者ID
+	Email   string // メールアドレス
+}
+
+// oidcClient はディスカバリ済みのプロバイダと OAuth2 設定です
+type oidcClient struct {
+	config               oauth2.Config
+	verifier             *oidc.IDTokenVerifier
+	requireVerifiedEmail bool
+}
+
+var (
+	oidcMu     sync.Mutex
+	oidcCached *oidcClient
+	oidcConfig = OIDCConfig{
+		IssuerURL:            common.GetEnv("OIDC_ISSUER_URL", ""),
+		ClientID:             common.GetEnv("OIDC_CLIENT_ID", ""),
+		ClientSecret:         common.GetEnv("OIDC_CLIENT_SECRET", ""),
+		RedirectURL:          common.GetEnv("OIDC_REDIRECT_URL", "http://localhost:8080/api/auth/oidc/callback"),
+		Scopes:               strings.Fields(common.GetEnv("OIDC_SCOPES", "openid email profile")),
+		RequireVerifiedEmail: common.GetEnv("OIDC_REQUIRE_VERIFIED_EMAIL", "true") != "false",
+	}
+)
+
+// ConfigureOIDC は OIDC ログインの設定を置き換え、キャッシュしたディスカバリの結果を破棄します
+// 既定では環境変数（OIDC_*）の設定を使用します。テストでモックの IdP に向ける場合などに使用します
+func ConfigureOIDC(config OIDCConfig) {
+	oidcMu.Lock()
+	defer oidcMu.Unlock()
+	oidcConfig = config
+	oidcCached = nil
+}
+
+// OIDCEnabled は OIDC ログインが設定されているか判定します
+func OIDCEnabled() bool {
+	oidcMu.Lock()
+	defer oidcMu.Unlock()
+	return oidcConfig.IssuerURL != "" && oidcConfig.ClientID != ""
+}
+
+// getOIDCClient はプロバイダのディスカバリを行い、結果をキャッシュします。
+// IdP が後から起動する場合に備え、失敗時はキャッシュせず次回再試行します。
+func getOIDCClient(ctx context.Context) (*oidcClient, error) {
+	if !OIDCEnabled() {
+		return nil, ErrOIDCDisabled
+	}
+
+	oidcMu.Lock()
+	defer oidcMu.Unlock()
+	if oidcCached != nil {
+		return oidcCached, nil
+	}
+
+	provider, err := oidc.NewProvider(ctx, oidcConfig.IssuerURL)
+	if err != nil {
+		return nil, fmt.Errorf("oidc discovery failed: %w", err)
+	}
+
+	oidcCached = &oidcClient{
+		config: oauth2.Config{
+			ClientID:     oidcConfig.ClientID,
+			ClientSecret: oidcConfig.ClientSecret,
+			RedirectURL:  oidcConfig.RedirectURL,
+			Endpoint:     provider.Endpoint(),
+			Scopes:       oidcConfig.Scopes,
+		},
+		verifier:             provider.Verifier(&oidc.Config{ClientID: oidcConfig.ClientID}),
+		requireVerifiedEmail: oidcConfig.RequireVerifiedEmail,
+	}
+	return oidcCached, nil
+}
+
+// NewOIDCVerifier は PKCE のコードベリファイアを生成します
+func NewOIDCVerifier() string {
+	return oauth2.GenerateVerifier()
+}
+
+// OIDCAuthCodeURL は認可エンドポイントへのリダイレクト先 URL を生成します（PKCE S256）
+func OIDCAuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	client, err := getOIDCClient(ctx)
+	if err != nil {
+		return "", err
+	}
+	return client.config.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier)), nil
+}
+
+// ExchangeOIDCCode は認可コードをトークンに交換し、ID トークンを検証して利用者情報を返します
+func ExchangeOIDCCode(ctx context.Context, code, verifier, nonce string) (*OIDCIdentity, error) {
+	client, err := getOIDCClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := client.config.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("%w: code exchange failed: %v", ErrInvalidToken, err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, fmt.Errorf("%w: token response does not contain an id_token", ErrInvalidToken)
+	}
+
+	idToken, err := client.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if idToken.Nonce != nonce {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrInvalidToken)
+	}
+
+	var claims struct {
+		Email         string `json:"email"`
+		EmailVerified *bool  `json:"email_verified"`
+	}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	email := strings.TrimSpace(claims.Email)
+	if email == "" {
+		return nil, ErrOIDCEmailMissing
+	}
+	// 未検証のメールアドレスで既存アカウントに紐付けられないようにする
+	if client.requireVerifiedEmail && (claims.EmailVerified == nil || !*claims.EmailVerified) {
+		return nil, ErrOIDCEmailMissing
+	}
+
+	return &OIDCIdentity{
+		Issuer:  idToken.Issuer,
+		Subject: idToken.Subject,
+		Email:   email,
+	}, nil
+}
@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"errors"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// MinPasswordLength はパスワードに求める最小文字数です
+const MinPasswordLength = 8
+
+// ErrWeakPassword はパスワードがポリシーを満たさない場合のエラーです
+var ErrWeakPassword = errors.New("password does not satisfy the policy")
+
+// dummyHash はユーザーが存在しない場合にも比較処理を行い、
+// 応答時間からメールアドレスの存在を推測されないようにするためのハッシュです
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("homestock-dummy-password"), bcrypt.DefaultCost)
+
+// ValidatePassword はパスワードがポリシーを満たすか検証します
+func ValidatePassword(password string) error {
+	if utf8.RuneCountInString(password) < MinPasswordLength {
+		return ErrWeakPassword
+	}
+	// bcrypt は 72 バイトを超える入力を扱えない
+	if len(password) > 72 {
+		return ErrWeakPassword
+	}
+	return nil
+}
+
+// HashPassword はパスワードを bcrypt でハッシュ化します
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// CheckPassword はハッシュとパスワードが一致するか検証します。
+// ハッシュが空（パスワード未設定）の場合も比較を行った上で false を返します。
+func CheckPassword(hash, password string) bool {
+	if hash == "" {
+		bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		return false
+	}
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
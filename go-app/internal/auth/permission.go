@@ -0,0 +1,194 @@
+package auth
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"go-hsm-app/internal/common"
+	"go-hsm-app/internal/model"
+)
+
+// Public は認証不要のルートを表す権限指定です
+const Public = ""
+
+// SystemAdmin はテナントをまたぐ運用操作（テナント作成など）に必要な権限指定です。
+// テナント内のロール（admin など）では満たせず、SYSTEM_ADMIN_USER_IDS に列挙したユーザーのみが持ちます。
+const SystemAdmin = "system"
+
+// systemAdminIDs はシステム管理者のユーザーIDの集合です
+var systemAdminIDs = parseSystemAdminIDs(common.GetEnv("SYSTEM_ADMIN_USER_IDS", ""))
+
+func parseSystemAdminIDs(value string) map[string]bool {
+	ids := map[string]bool{}
+	for _, id := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' }) {
+		ids[id] = true
+	}
+	return ids
+}
+
+// IsSystemAdmin は利用者がシステム管理者か判定します。
+// なりすまし中や API キーによるリクエストはシステム管理者として扱いません。
+func IsSystemAdmin(p *Principal) bool {
+	return p != nil && !p.Impersonating() && p.APIKeyID == "" && systemAdminIDs[p.UserID]
+}
+
+// roleRank は権限の強さを表します（大きいほど強い）
+var roleRank = model.RoleRank
+
+// HasRole は role が required 以上の権限を持つか判定します
+func HasRole(role, required string) bool {
+	if required == Public {
+		return true
+	}
+	if required == SystemAdmin {
+		return false
+	}
+	rank, ok := roleRank[role]
+	return ok && rank >= roleRank[required]
+}
+
+// Permissions はルートごとに必要な最小権限を定義する権限マトリクスです。
+// キーは "METHOD ルートパス"（Echo に登録したパス）です。
+// マトリクスに存在しないルートは拒否されます。
+//
+//	viewer:   参照のみ
+//	operator: アイテム・マスタの登録/更新（削除は不可）
+//	admin:    削除、ユーザー/権限管理
+//	system:   テナントの作成（SystemAdmin を参照）
+var Permissions = map[string]string{
+	// ヘルスチェック・認証
+	"GET /health":                      Public,
+	"POST /api/auth/login":             Public,
+	"POST /api/auth/refresh":           Public,
+	"POST /api/auth/logout":            Public,
+	"POST /api/auth/invitation/accept": Public,
+	"POST /api/auth/password/forgot":   Public,
+	"POST /api/auth/password/reset":    Public,
+	"GET /api/auth/oidc/login":         Public,
+	"GET /api/auth/oidc/callback":      Public,
+
+	// GraphQL（フィールド単位で @hasRole ディレクティブにより制御）
+	"GET /graphql":  Public,
+	"POST /graphql": Public,
+
+	// 参照
+	"GET /api/items":                     model.RoleViewer,
+	"GET /api/items/suggest":             model.RoleViewer,
+	"GET /api/items/template-violations": model.RoleViewer,
+	"GET /api/items/:id":                 model.RoleViewer,
+	"GET /api/items/:id/packagings":      model.RoleViewer,
+	"GET /api/categories":                model.RoleViewer,
+	"GET /api/categories/tree":           model.RoleViewer,
+	"GET /api/categories/:id":            model.RoleViewer,
+	"GET /api/categories/:id/attributes": model.RoleViewer,
+	"GET /api/units":                     model.RoleViewer,
+	"GET /api/units/:id":                 model.RoleViewer,
+	"GET /api/units/:id/conversions":     model.RoleViewer,
+	"GET /api/attributes":                model.RoleViewer,
+	"GET /api/attributes/:id":            model.RoleViewer,
+	"GET /api/attributes/:id/options":    model.RoleViewer,
+	"GET /api/stock-history":             model.RoleViewer,
+
+	// アイテム
+	"POST /api/items":                        model.RoleOperator,
+	"PUT /api/items/:id":                     model.RoleOperator,
+	"PATCH /api/items/:id":                   model.RoleOperator,
+	"DELETE /api/items/:id":                  model.RoleAdmin,
+	"POST /api/items/:id/restore":            model.RoleAdmin,
+	"POST /api/items/:id/merge":              model.RoleAdmin,
+	"PUT /api/items/:id/attributes/:code":    model.RoleOperator,
+	"DELETE /api/items/:id/attributes/:code": model.RoleOperator,
+	"PUT /api/items/:id/packagings/:unit":    model.RoleOperator,
+	"DELETE /api/items/:id/packagings/:unit": model.RoleOperator,
+
+	// 入出庫
+	"POST /api/stock-movements": model.RoleOperator,
+
+	// カテゴリ
+	"POST /api/categories":               model.RoleOperator,
+	"PUT /api/categories/:id":            model.RoleOperator,
+	"PATCH /api/categories/:id":          model.RoleOperator,
+	"DELETE /api/categories/:id":         model.RoleAdmin,
+	"POST /api/categories/:id/restore":   model.RoleAdmin,
+	"PUT /api/categories/:id/attributes": model.RoleOperator,
+	"PUT /api/categories/:id/parent":     model.RoleOperator,
+
+	// 単位
+	"POST /api/units":                       model.RoleOperator,
+	"PUT /api/units/:id":                    model.RoleOperator,
+	"PATCH /api/units/:id":                  model.RoleOperator,
+	"DELETE /api/units/:id":                 model.RoleAdmin,
+	"POST /api/units/:id/restore":           model.RoleAdmin,
+	"PUT /api/units/:id/conversions/:to":    model.RoleOperator,
+	"DELETE /api/units/:id/conversions/:to": model.RoleAdmin,
+
+	// 属性
+	"POST /api/attributes":                     model.RoleOperator,
+	"PUT /api/attributes/:id":                  model.RoleOperator,
+	"PATCH /api/attributes/:id":                model.RoleOperator,
+	"DELETE /api/attributes/:id":               model.RoleAdmin,
+	"POST /api/attributes/:id/restore":         model.RoleAdmin,
+	"POST /api/attributes/:id/options":         model.RoleOperator,
+	"PUT /api/attributes/:id/options/:code":    model.RoleOperator,
+	"DELETE /api/attributes/:id/options/:code": model.RoleAdmin,
+
+	// ゴミ箱（復元は各リソースの /:id/restore）
+	"GET /api/trash":        model.RoleOperator,
+	"POST /api/trash/purge": model.RoleAdmin,
+
+	// ユーザー
+	"GET /api/users":                        model.RoleAdmin,
+	"GET /api/users/:id":                    model.RoleAdmin,
+	"POST /api/users":                       model.RoleAdmin,
+	"PUT /api/users/:id":                    model.RoleAdmin,
+	"PATCH /api/users/:id":                  model.RoleAdmin,
+	"DELETE /api/users/:id":                 model.RoleAdmin,
+	"DELETE /api/users/:id/sessions":        model.RoleAdmin,
+	"POST /api/users/:id/invitation":        model.RoleAdmin,
+	"POST /api/users/:id/unlock":            model.RoleAdmin,
+	"POST /api/users/:id/impersonate":       model.RoleAdmin,
+	"GET /api/users/:id/grants":             model.RoleAdmin,
+	"POST /api/users/:id/grants":            model.RoleAdmin,
+	"DELETE /api/users/:id/grants/:grantId": model.RoleAdmin,
+
+	// API キー（本人または管理者のみ。所有者の確認はハンドラで行う）
+	"GET /api/users/:id/api-keys":           model.RoleViewer,
+	"POST /api/users/:id/api-keys":          model.RoleViewer,
+	"DELETE /api/users/:id/api-keys/:keyId": model.RoleViewer,
+
+	// テナント
+	"GET /api/tenant":   model.RoleViewer,
+	"POST /api/tenants": SystemAdmin,
+}
+
+// RequiredRole はルートに必要な最小権限を返します。
+// マトリクスに定義がない場合は ok = false を返します。
+func RequiredRole(method, path string) (role string, ok bool) {
+	if method == http.MethodHead {
+		method = http.MethodGet
+	}
+	role, ok = Permissions[method+" "+path]
+	return role, ok
+}
+
+// RolesAtLeast は required 以上の権限を持つロールの一覧を返します
+func RolesAtLeast(required string) []string {
+	roles := []string{}
+	for role := range roleRank {
+		if HasRole(role, required) {
+			roles = append(roles, role)
+		}
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+// AccessScopeFor は利用者が required の権限で扱えるデータの範囲を返します。
+// 管理者は権限付与による制限を受けないため nil を返します。
+func AccessScopeFor(p *Principal, required string) *model.AccessScope {
+	if p == nil || p.Role == model.RoleAdmin {
+		return nil
+	}
+	return &model.AccessScope{UserID: p.UserID, Roles: RolesAtLeast(required)}
+}
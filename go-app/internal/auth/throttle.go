@@ -0,0 +1,249 @@
+package auth
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"go-hsm-app/internal/common"
+	"go-hsm-app/internal/model"
+	"go-hsm-app/internal/repository"
+)
+
+// 認証失敗をトラッキングする対象の種別
+const (
+	ThrottleScopeAccount = "account" // メールアドレス単位
+	ThrottleScopeAPIKey  = "api_key" // API キーのプレフィックス単位
+	ThrottleScopeIP      = "ip"      // 接続元IP単位
+)
+
+// 認証制限の種類
+const (
+	lockReasonBackoff = "backoff"
+	lockReasonLockout = "lockout"
+)
+
+// ThrottleKey は認証失敗をトラッキングする対象です
+type ThrottleKey struct {
+	Scope   string
+	Subject string
+}
+
+// String は監査ログ等で使用する "scope:subject" 形式の文字列を返します
+func (k ThrottleKey) String() string {
+	return k.Scope + ":" + k.Subject
+}
+
+// AccountKey はメールアドレス単位のトラッキング対象を返します
+func AccountKey(email string) ThrottleKey {
+	return ThrottleKey{Scope: ThrottleScopeAccount, Subject: strings.ToLower(strings.TrimSpace(email))}
+}
+
+// APIKeyKey は API キー（プレフィックス）単位のトラッキング対象を返します
+func APIKeyKey(prefix string) ThrottleKey {
+	return ThrottleKey{Scope: ThrottleScopeAPIKey, Subject: prefix}
+}
+
+// IPKey は接続元IP単位のトラッキング対象を返します
+func IPKey(ipAddress string) ThrottleKey {
+	return ThrottleKey{Scope: ThrottleScopeIP, Subject: ipAddress}
+}
+
+// ThrottledError は認証失敗が続いたため一時的に認証を受け付けない場合のエラーです
+type ThrottledError struct {
+	Scope      string        // 制限されている対象の種別
+	RetryAfter time.Duration // 再試行できるまでの時間
+	Locked     bool          // true: 一時ロック, false: バックオフ中
+}
+
+func (e *ThrottledError) Error() string {
+	if e.Locked {
+		return fmt.Sprintf("%s is temporarily locked; retry after %s", e.Scope, e.RetryAfter.Round(time.Second))
+	}
+	return fmt.Sprintf("too many failed attempts for %s; retry after %s", e.Scope, e.RetryAfter.Round(time.Second))
+}
+
+// throttlePolicy はバックオフとロックの閾値です
+type throttlePolicy struct {
+	backoffAfter     int           // この回数以上失敗すると待機時間を設ける
+	lockoutThreshold int           // この回数に達すると一時ロックする
+	baseDelay        time.Duration // バックオフの初期待機時間（失敗ごとに倍増）
+	lockoutDuration  time.Duration // 初回ロック時間（ロックごとに倍増）
+	maxLockout       time.Duration // ロック時間の上限
+	window           time.Duration // この期間失敗がなければ失敗回数をリセット
+}
+
+var throttlePolicies = map[string]throttlePolicy{
+	ThrottleScopeAccount: loadThrottlePolicy("LOGIN", 3, 10),
+	ThrottleScopeAPIKey:  loadThrottlePolicy("LOGIN", 3, 10),
+	ThrottleScopeIP:      loadThrottlePolicy("LOGIN_IP", 10, 50),
+}
+
+// loadThrottlePolicy は環境変数からポリシーを読み込みます
+func loadThrottlePolicy(prefix string, backoffAfter, lockoutThreshold int) throttlePolicy {
+	return throttlePolicy{
+		backoffAfter:     common.GetEnvInt(prefix+"_BACKOFF_AFTER", backoffAfter),
+		lockoutThreshold: common.GetEnvInt(prefix+"_LOCKOUT_THRESHOLD", lockoutThreshold),
+		baseDelay:        common.GetEnvDuration("LOGIN_BACKOFF_BASE", time.Second),
+		lockoutDuration:  common.GetEnvDuration("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+		maxLockout:       common.GetEnvDuration("LOGIN_LOCKOUT_MAX", 24*time.Hour),
+		window:           common.GetEnvDuration("LOGIN_FAILURE_WINDOW", time.Hour),
+	}
+}
+
+// AuthEvent は認証失敗・ロック解除を記録する際のリクエスト情報です
+type AuthEvent struct {
+	UserID    *string // 操作を行ったユーザー（ロック解除時の管理者）
+	IPAddress string  // 接続元IP
+	RequestID string  // リクエストID
+}
+
+// CheckThrottle は対象のいずれかが認証制限中であれば *ThrottledError を返します
+func CheckThrottle(keys ...ThrottleKey) error {
+	var throttled *ThrottledError
+	for _, key := range keys {
+		if key.Subject == "" {
+			continue
+		}
+		t, err := repository.FetchActiveThrottle(key.Scope, key.Subject)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		retryAfter := time.Until(*t.LockedUntil)
+		if throttled == nil || retryAfter > throttled.RetryAfter {
+			throttled = &ThrottledError{
+				Scope:      key.Scope,
+				RetryAfter: retryAfter,
+				Locked:     t.LockReason != nil && *t.LockReason == lockReasonLockout,
+			}
+		}
+	}
+	if throttled != nil {
+		return throttled
+	}
+	return nil
+}
+
+// RecordAuthFailure は認証失敗を記録し、ポリシーに応じてバックオフまたは一時ロックを設定します。
+// ロックした場合は audit_logs に記録します。
+// 記録の失敗で認証処理自体を失敗させないよう、エラーはログ出力のみ行います。
+func RecordAuthFailure(ev AuthEvent, keys ...ThrottleKey) {
+	for _, key := range keys {
+		if key.Subject == "" {
+			continue
+		}
+		policy := throttlePolicies[key.Scope]
+
+		t, err := repository.IncrementAuthFailure(key.Scope, key.Subject, policy.window)
+		if err != nil {
+			continue
+		}
+
+		now := time.Now()
+		switch {
+		case t.FailureCount >= policy.lockoutThreshold:
+			lockedUntil := now.Add(scaleDuration(policy.lockoutDuration, t.LockoutCount, policy.maxLockout))
+			if err := repository.SetAuthThrottleLock(key.Scope, key.Subject, lockedUntil, lockReasonLockout); err != nil {
+				continue
+			}
+			log.Printf("[Auth] 認証失敗が続いたため一時ロックしました: %s (%s まで)", key, lockedUntil.Format(time.RFC3339))
+			recordAuthAudit("lockout", key, ev, map[string]interface{}{
+				"failure_count": t.FailureCount,
+				"lockout_count": t.LockoutCount + 1,
+				"locked_until":  lockedUntil,
+			})
+		case t.FailureCount >= policy.backoffAfter:
+			delay := scaleDuration(policy.baseDelay, t.FailureCount-policy.backoffAfter, policy.lockoutDuration)
+			repository.SetAuthThrottleLock(key.Scope, key.Subject, now.Add(delay), lockReasonBackoff)
+		}
+	}
+}
+
+// RecordAuthSuccess は認証成功時に対象の失敗記録をリセットします
+func RecordAuthSuccess(keys ...ThrottleKey) {
+	for _, key := range keys {
+		if key.Subject == "" {
+			continue
+		}
+		repository.ClearAuthThrottle(key.Scope, key.Subject)
+	}
+}
+
+// Unlock は管理者による認証制限の解除を行い、ロック中だった対象を返します。
+// ロック中だった対象は audit_logs に記録します。
+func Unlock(ev AuthEvent, keys ...ThrottleKey) ([]ThrottleKey, error) {
+	unlocked := []ThrottleKey{}
+	for _, key := range keys {
+		if key.Subject == "" {
+			continue
+		}
+		wasLocked, err := repository.ClearAuthThrottle(key.Scope, key.Subject)
+		if err != nil {
+			return nil, err
+		}
+		if wasLocked {
+			unlocked = append(unlocked, key)
+			recordAuthAudit("unlock", key, ev, nil)
+		}
+	}
+	return unlocked, nil
+}
+
+// scaleDuration は base × 2^exponent を上限 max で返します
+func scaleDuration(base time.Duration, exponent int, max time.Duration) time.Duration {
+	if exponent < 0 {
+		exponent = 0
+	}
+	d := float64(base) * math.Pow(2, float64(exponent))
+	if d > float64(max) {
+		return max
+	}
+	return time.Duration(d)
+}
+
+// recordAuthAudit はロック・ロック解除を audit_logs に記録します
+func recordAuthAudit(action string, key ThrottleKey, ev AuthEvent, extra map[string]interface{}) {
+	diff := map[string]interface{}{
+		"scope":   key.Scope,
+		"subject": key.Subject,
+	}
+	if ev.IPAddress != "" {
+		diff["ip_address"] = ev.IPAddress
+	}
+	if key.Scope == ThrottleScopeAccount {
+		if user, err := repository.FetchUserByEmail(key.Subject); err == nil {
+			diff["target_user_id"] = user.ID
+		}
+	}
+	for k, v := range extra {
+		diff[k] = v
+	}
+
+	raw, err := json.Marshal(diff)
+	if err != nil {
+		log.Printf("[Auth] 監査ログの生成に失敗しました: %v", err)
+		return
+	}
+
+	resourceID := key.String()
+	entry := model.AuditLog{
+		UserID:     ev.UserID,
+		Action:     action,
+		Resource:   "auth_throttles",
+		ResourceID: &resourceID,
+		Diff:       raw,
+	}
+	if ev.RequestID != "" {
+		entry.RequestID = &ev.RequestID
+	}
+	repository.CreateAuditLog(entry)
+}
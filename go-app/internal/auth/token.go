@@ -0,0 +1,209 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"go-hsm-app/internal/common"
+	"go-hsm-app/internal/model"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// トークン種別
+const (
+	TokenTypeAccess        = "access"        // アクセストークン
+	TokenTypeImpersonation = "impersonation" // 管理者によるなりすまし用アクセストークン
+)
+
+// ErrInvalidToken はトークンが不正または期限切れの場合のエラーです
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+// Claims は HomeStock が発行する JWT のクレームです
+type Claims struct {
+	Role      string `json:"role"`          // ユーザー権限
+	TenantID  string `json:"tid"`           // 所属テナントID
+	TokenType string `json:"typ"`           // トークン種別
+	SessionID string `json:"sid,omitempty"` // セッションID（リフレッシュトークンのファミリーID）
+	Actor     *Actor `json:"act,omitempty"` // なりすましを行っている管理者（RFC 8693 の act クレーム）
+	jwt.RegisteredClaims
+}
+
+// Actor はなりすましトークンで実際に操作している主体です
+type Actor struct {
+	Subject string `json:"sub"` // 管理者のユーザーID
+}
+
+// ImpersonationToken はなりすまし用に発行するアクセストークンです（リフレッシュトークンは発行しません）
+type ImpersonationToken struct {
+	AccessToken    string `json:"access_token"`    // なりすまし用アクセストークン（JWT）
+	TokenType      string `json:"token_type"`      // 常に "Bearer"
+	ExpiresIn      int    `json:"expires_in"`      // 有効秒数
+	Impersonation  bool   `json:"impersonation"`   // 常に true（なりすまし用トークンであることを示す）
+	ImpersonatorID string `json:"impersonator_id"` // なりすましを行う管理者のユーザーID
+}
+
+// TokenPair はログイン・リフレッシュ時に返却するトークンの組です
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`  // アクセストークン（JWT）
+	RefreshToken string `json:"refresh_token"` // リフレッシュトークン（不透明な文字列）
+	TokenType    string `json:"token_type"`    // 常に "Bearer"
+	ExpiresIn    int    `json:"expires_in"`    // アクセストークンの有効秒数
+}
+
+var (
+	jwtSecret       = loadSecret()
+	accessTokenTTL  = common.GetEnvDuration("ACCESS_TOKEN_TTL", 15*time.Minute)
+	refreshTokenTTL = common.GetEnvDuration("REFRESH_TOKEN_TTL", 7*24*time.Hour)
+	// なりすましトークンは更新できないため、短い有効期限とする
+	impersonationTokenTTL = common.GetEnvDuration("IMPERSONATION_TOKEN_TTL", 30*time.Minute)
+)
+
+const issuer = "homestock"
+
+// loadSecret は JWT_SECRET を読み込みます。
+// 未設定の場合はランダムな鍵を生成します（再起動で発行済みトークンは無効になります）。
+func loadSecret() []byte {
+	if secret := common.GetEnv("JWT_SECRET", ""); secret != "" {
+		return []byte(secret)
+	}
+	log.Println("⚠ JWT_SECRET が未設定のため、一時的な署名鍵を生成しました")
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		log.Fatalf("署名鍵の生成に失敗しました: %v", err)
+	}
+	return b
+}
+
+// NewTokenPair はアクセストークンと新しいリフレッシュトークンを生成します。
+// 戻り値の refreshHash はリフレッシュトークンの保存用ハッシュです。
+func NewTokenPair(user *model.User, sessionID string) (*TokenPair, string, error) {
+	access, err := signToken(user, sessionID, accessTokenTTL)
+	if err != nil {
+		return nil, "", err
+	}
+
+	refresh, refreshHash, err := NewOpaqueToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &TokenPair{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(accessTokenTTL.Seconds()),
+	}, refreshHash, nil
+}
+
+// NewImpersonationToken は管理者 adminID が target になりすますためのアクセストークンを生成します。
+// sessionID には管理者のセッションを指定し、管理者のログアウト・セッション失効でなりすましも無効になります。
+func NewImpersonationToken(adminID string, target *model.User, sessionID string) (*ImpersonationToken, error) {
+	claims := newClaims(target, TokenTypeImpersonation, sessionID, impersonationTokenTTL)
+	claims.Actor = &Actor{Subject: adminID}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(jwtSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ImpersonationToken{
+		AccessToken:    token,
+		TokenType:      "Bearer",
+		ExpiresIn:      int(impersonationTokenTTL.Seconds()),
+		Impersonation:  true,
+		ImpersonatorID: adminID,
+	}, nil
+}
+
+// RefreshTokenExpiry は現在時刻から算出したリフレッシュトークンの有効期限を返します
+func RefreshTokenExpiry() time.Time {
+	return time.Now().Add(refreshTokenTTL)
+}
+
+// NewSessionID はログインごとに一意なセッションID（トークンファミリーID）を生成します
+func NewSessionID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// NewOpaqueToken は推測困難な不透明トークンと、その保存用ハッシュを生成します
+func NewOpaqueToken() (token, hash string, err error) {
+	token, err = randomToken(32)
+	if err != nil {
+		return "", "", err
+	}
+	return token, HashToken(token), nil
+}
+
+// HashToken は不透明トークンを保存・照合用に SHA-256 でハッシュ化します
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// ParseToken は JWT を検証し、指定したいずれかの種別のトークンであればクレームを返します
+func ParseToken(tokenString string, tokenTypes ...string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		return jwtSecret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(issuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+	if !slices.Contains(tokenTypes, claims.TokenType) {
+		return nil, fmt.Errorf("%w: unexpected token type %q", ErrInvalidToken, claims.TokenType)
+	}
+	if (claims.TokenType == TokenTypeImpersonation) != (claims.Actor != nil && claims.Actor.Subject != "") {
+		return nil, fmt.Errorf("%w: inconsistent impersonation claims", ErrInvalidToken)
+	}
+	if claims.TenantID == "" {
+		return nil, fmt.Errorf("%w: missing tenant", ErrInvalidToken)
+	}
+	return claims, nil
+}
+
+// signToken は署名済みのアクセストークンを生成します
+func signToken(user *model.User, sessionID string, ttl time.Duration) (string, error) {
+	claims := newClaims(user, TokenTypeAccess, sessionID, ttl)
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(jwtSecret)
+}
+
+// newClaims はユーザーのトークンのクレームを生成します
+func newClaims(user *model.User, tokenType, sessionID string, ttl time.Duration) Claims {
+	now := time.Now()
+	return Claims{
+		Role:      user.Role,
+		TenantID:  user.TenantID,
+		TokenType: tokenType,
+		SessionID: sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    issuer,
+			Subject:   user.ID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			ID:        NewSessionID(),
+		},
+	}
+}
+
+// randomToken は URL セーフな乱数文字列を生成します
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -1,4 +1,51 @@
 package common
 
-// このファイルは gqlgen による生成と依存関係解決のために空ファイルとして残しています。
-// 必要に応じてここに共通処理を追加してください。
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GetEnv は環境変数を取得し、存在しない場合はデフォルト値を返します。
+// 環境変数は .env や docker-compose の environment 経由で設定されます。
+func GetEnv(key, defaultValue string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
+
+// GetEnvDuration は環境変数を time.Duration として取得します。
+// time.ParseDuration の書式に加え、日数指定（例: 7d）も受け付けます。
+// 解析できない場合はデフォルト値を返します。
+func GetEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n > 0 {
+			return time.Duration(n) * 24 * time.Hour
+		}
+		return defaultValue
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return defaultValue
+	}
+	return d
+}
+
+// GetEnvInt は環境変数を正の整数として取得します。
+// 解析できない場合はデフォルト値を返します。
+func GetEnvInt(key string, defaultValue int) int {
+	n, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || n <= 0 {
+		return defaultValue
+	}
+	return n
+}
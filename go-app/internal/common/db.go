@@ -4,7 +4,6 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
-	"os"
 
 	_ "github.com/lib/pq"
 )
@@ -16,11 +15,11 @@ var DB *sql.DB
 // 環境変数から接続情報を取得し、sql.Open でコネクションプールを作成します。
 // 接続確認として Ping を実行します。
 func InitDB() error {
-	host := getEnv("DB_HOST", "localhost")
-	port := getEnv("DB_PORT", "5432")
-	user := getEnv("DB_USER", "hsm")
-	password := getEnv("DB_PASSWORD", "hsm")
-	dbname := getEnv("DB_NAME", "hsm-db")
+	host := GetEnv("DB_HOST", "localhost")
+	port := GetEnv("DB_PORT", "5432")
+	user := GetEnv("DB_USER", "hsm")
+	password := GetEnv("DB_PASSWORD", "hsm")
+	dbname := GetEnv("DB_NAME", "hsm-db")
 
 	connStr := fmt.Sprintf(
 		"host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
@@ -47,13 +46,3 @@ func CloseDB() {
 		DB.Close()
 	}
 }
-
-// getEnv は環境変数を取得し、存在しない場合はデフォルト値を返します。
-// 環境変数は .env や docker-compose の environment 経由で設定されます。
-func getEnv(key, defaultValue string) string {
-	value := os.Getenv(key)
-	if value == "" {
-		return defaultValue
-	}
-	return value
-}
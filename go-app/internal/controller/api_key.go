@@ -0,0 +1,166 @@
+package controller
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"go-hsm-app/internal/auth"
+	"go-hsm-app/internal/model"
+	"go-hsm-app/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GetAPIKeys は GET /api/users/:id/api-keys リクエストを処理します
+func GetAPIKeys(c echo.Context) error {
+	id := c.Param("id")
+	log.Printf("[Controller] GET /api/users/%s/api-keys - リクエスト受信", id)
+
+	if ok, err := authorizeAPIKeyOwner(c, id, true); !ok {
+		return err
+	}
+
+	keys, err := service.GetAPIKeys(currentTenantID(c), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return userNotFound(c)
+		}
+		log.Printf("[Controller] エラー: API キーの取得に失敗しました: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "API キーの取得に失敗しました",
+		})
+	}
+
+	log.Printf("[Controller] 成功: %d件の API キーを返却します", len(keys))
+	return c.JSON(http.StatusOK, keys)
+}
+
+// CreateAPIKey は POST /api/users/:id/api-keys リクエストを処理します
+// キー本体はこのレスポンスでのみ返却します。発行できるのは本人のキーのみです
+func CreateAPIKey(c echo.Context) error {
+	id := c.Param("id")
+	log.Printf("[Controller] POST /api/users/%s/api-keys - リクエスト受信", id)
+
+	if ok, err := authorizeAPIKeyOwner(c, id, false); !ok {
+		return err
+	}
+
+	var req struct {
+		Name      string     `json:"name"`
+		Roles     []string   `json:"roles"`
+		ExpiresAt *time.Time `json:"expires_at"`
+	}
+
+	if err := c.Bind(&req); err != nil {
+		log.Printf("[Controller] エラー: リクエストのバインドに失敗しました: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error":   "invalid_request",
+			"message": "expires_at must be an RFC 3339 timestamp",
+		})
+	}
+
+	if strings.TrimSpace(req.Name) == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error":   "invalid_request",
+			"message": "name is required",
+		})
+	}
+
+	key, err := service.CreateAPIKey(currentTenantID(c), id, req.Name, req.Roles, req.ExpiresAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return userNotFound(c)
+		case errors.Is(err, service.ErrInvalidAPIKeyRoles),
+			errors.Is(err, service.ErrAPIKeyRoleExceedsUser),
+			errors.Is(err, service.ErrAPIKeyExpiryInPast):
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error":   "invalid_request",
+				"message": err.Error(),
+			})
+		}
+		log.Printf("[Controller] エラー: API キーの作成に失敗しました: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "API キーの作成に失敗しました",
+		})
+	}
+
+	log.Printf("[Controller] 成功: API キーを作成しました (ID: %s, prefix: %s)", key.ID, key.Prefix)
+	return c.JSON(http.StatusCreated, key)
+}
+
+// RevokeAPIKey は DELETE /api/users/:id/api-keys/:keyId リクエストを処理します
+func RevokeAPIKey(c echo.Context) error {
+	id := c.Param("id")
+	keyID := c.Param("keyId")
+	log.Printf("[Controller] DELETE /api/users/%s/api-keys/%s - リクエスト受信", id, keyID)
+
+	if ok, err := authorizeAPIKeyOwner(c, id, true); !ok {
+		return err
+	}
+
+	if err := service.RevokeAPIKey(currentTenantID(c), id, keyID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error":   "not_found",
+				"message": "API key not found or already revoked",
+			})
+		}
+		log.Printf("[Controller] エラー: API キーの失効に失敗しました: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "API キーの失効に失敗しました",
+		})
+	}
+
+	log.Printf("[Controller] 成功: API キーを失効させました (ID: %s)", keyID)
+	return c.NoContent(http.StatusNoContent)
+}
+
+// authorizeAPIKeyOwner は API キーの管理を本人に限定します。allowAdmin が true の場合（一覧・無効化）は管理者にも許可します。
+// 発行は本人のみです。管理者が他のユーザーのキーを発行すると、なりすましの制限や監査ログを経ずに
+// そのユーザーとして操作できてしまうためです。
+// キーが漏洩した場合に新たなキーを発行されないよう、API キーによる認証では管理できません。
+// 許可されない場合はエラーレスポンスを書き込み、ok = false を返します。
+func authorizeAPIKeyOwner(c echo.Context, userID string, allowAdmin bool) (ok bool, err error) {
+	p := auth.CurrentPrincipal(c)
+	if p == nil {
+		return false, auth.Unauthorized(c, "Authentication required")
+	}
+	if p.APIKeyID != "" {
+		log.Printf("[Controller] API キーによる API キー管理は許可されていません (key_id: %s)", p.APIKeyID)
+		return false, c.JSON(http.StatusForbidden, map[string]string{
+			"error":   "forbidden",
+			"message": "API keys cannot be managed with an API key",
+		})
+	}
+	if p.Impersonating() {
+		log.Printf("[Controller] なりすまし中の API キー管理は許可されていません (admin: %s)", p.ImpersonatorID)
+		return false, c.JSON(http.StatusForbidden, map[string]string{
+			"error":   "forbidden",
+			"message": "API keys cannot be managed while impersonating",
+		})
+	}
+	if p.UserID != userID && !allowAdmin {
+		log.Printf("[Controller] 他のユーザーの API キーは発行できません (user: %s, target: %s)", p.UserID, userID)
+		return false, c.JSON(http.StatusForbidden, map[string]string{
+			"error":   "forbidden",
+			"message": "API keys can only be issued for your own user",
+		})
+	}
+	if p.UserID != userID && !auth.HasRole(p.Role, model.RoleAdmin) {
+		return false, auth.Forbidden(c, model.RoleAdmin)
+	}
+	return true, nil
+}
+
+// userNotFound はユーザーが存在しない場合のレスポンスを返します
+func userNotFound(c echo.Context) error {
+	return c.JSON(http.StatusNotFound, map[string]string{
+		"error":   "not_found",
+		"message": "User not found",
+	})
+}
@@ -0,0 +1,134 @@
+package controller
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+
+	"go-hsm-app/internal/repository"
+	"go-hsm-app/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+// attributeOptionRequest は選択肢の追加・更新のリクエストボディです
+type attributeOptionRequest struct {
+	Code      string `json:"code"`
+	Label     string `json:"label"`
+	SortOrder *int   `json:"sort_order"`
+}
+
+// GetAttributeOptions は GET /api/attributes/:id/options リクエストを処理します
+func GetAttributeOptions(c echo.Context) error {
+	id := c.Param("id")
+	log.Printf("[Controller] GET /api/attributes/%s/options - リクエスト受信", id)
+
+	options, err := service.GetAttributeOptions(currentTenantID(c), id)
+	if err != nil {
+		return respondAttributeOptionError(c, err, "Attribute not found", "Failed to fetch attribute options")
+	}
+
+	log.Printf("[Controller] 成功: %d件の選択肢を取得しました", len(options))
+	return c.JSON(http.StatusOK, options)
+}
+
+// CreateAttributeOption は POST /api/attributes/:id/options リクエストを処理します
+// sort_order を省略した場合は末尾に追加します
+func CreateAttributeOption(c echo.Context) error {
+	id := c.Param("id")
+	log.Printf("[Controller] POST /api/attributes/%s/options - リクエスト受信", id)
+
+	var req attributeOptionRequest
+	if err := c.Bind(&req); err != nil {
+		log.Printf("[Controller] エラー: リクエストのバインドに失敗しました: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error":   "invalid_request",
+			"message": "Invalid request body",
+		})
+	}
+
+	option, err := service.CreateAttributeOption(currentTenantID(c), id, req.Code, req.Label, req.SortOrder)
+	if err != nil {
+		return respondAttributeOptionError(c, err, "Attribute not found", "Failed to create attribute option")
+	}
+
+	log.Printf("[Controller] 成功: 選択肢を追加しました (ID: %s)", option.ID)
+	return c.JSON(http.StatusCreated, option)
+}
+
+// UpdateAttributeOption は PUT /api/attributes/:id/options/:code リクエストを処理します
+// 表示名（label）と表示順（sort_order、省略時は変更しない）を更新します。選択肢のコードは変更できません
+func UpdateAttributeOption(c echo.Context) error {
+	id, code := c.Param("id"), c.Param("code")
+	log.Printf("[Controller] PUT /api/attributes/%s/options/%s - リクエスト受信", id, code)
+
+	var req attributeOptionRequest
+	if err := c.Bind(&req); err != nil {
+		log.Printf("[Controller] エラー: リクエストのバインドに失敗しました: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error":   "invalid_request",
+			"message": "Invalid request body",
+		})
+	}
+	if req.Code != "" && req.Code != code {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error":   "invalid_request",
+			"message": "Option code cannot be changed",
+		})
+	}
+
+	option, err := service.UpdateAttributeOption(currentTenantID(c), id, code, req.Label, req.SortOrder)
+	if err != nil {
+		return respondAttributeOptionError(c, err, "Attribute option not found", "Failed to update attribute option")
+	}
+
+	log.Printf("[Controller] 成功: 選択肢を更新しました (ID: %s)", option.ID)
+	return c.JSON(http.StatusOK, option)
+}
+
+// DeleteAttributeOption は DELETE /api/attributes/:id/options/:code リクエストを処理します
+// アイテムで使用中の選択肢は削除できません（409）
+func DeleteAttributeOption(c echo.Context) error {
+	id, code := c.Param("id"), c.Param("code")
+	log.Printf("[Controller] DELETE /api/attributes/%s/options/%s - リクエスト受信", id, code)
+
+	if err := service.DeleteAttributeOption(currentTenantID(c), id, code); err != nil {
+		return respondAttributeOptionError(c, err, "Attribute option not found", "Failed to delete attribute option")
+	}
+
+	log.Printf("[Controller] 成功: 選択肢を削除しました (code: %s)", code)
+	return c.NoContent(http.StatusNoContent)
+}
+
+// respondAttributeOptionError は選択肢の操作のエラーレスポンスを返します
+func respondAttributeOptionError(c echo.Context, err error, notFoundMessage, failureMessage string) error {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error":   "not_found",
+			"message": notFoundMessage,
+		})
+	case errors.Is(err, service.ErrAttributeNotEnum),
+		errors.Is(err, service.ErrInvalidAttributeOption):
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error":   "invalid_request",
+			"message": err.Error(),
+		})
+	case errors.Is(err, repository.ErrAttributeOptionExists):
+		return c.JSON(http.StatusConflict, map[string]string{
+			"error":   "duplicate_code",
+			"message": err.Error(),
+		})
+	case errors.Is(err, repository.ErrAttributeOptionInUse):
+		return c.JSON(http.StatusConflict, map[string]string{
+			"error":   "option_in_use",
+			"message": err.Error(),
+		})
+	}
+	log.Printf("[Controller] エラー: 選択肢の操作に失敗しました: %v", err)
+	return c.JSON(http.StatusInternalServerError, map[string]string{
+		"error":   "internal_error",
+		"message": failureMessage,
+	})
+}
@@ -0,0 +1,221 @@
+package controller
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"go-hsm-app/internal/auth"
+	"go-hsm-app/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Login は POST /api/auth/login リクエストを処理します
+func Login(c echo.Context) error {
+	log.Printf("[Controller] POST /api/auth/login - リクエスト受信")
+
+	var req struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+	}
+
+	if err := c.Bind(&req); err != nil {
+		log.Printf("[Controller] エラー: リクエストのバインドに失敗しました: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid_request",
+		})
+	}
+
+	req.Email = strings.TrimSpace(req.Email)
+	if req.Email == "" || req.Password == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error":   "invalid_request",
+			"message": "email and password are required",
+		})
+	}
+
+	tokens, user, err := service.Login(req.Email, req.Password, clientInfo(c))
+	if err != nil {
+		var throttled *auth.ThrottledError
+		if errors.As(err, &throttled) {
+			log.Printf("[Controller] ログインを制限中です: %s (%v)", req.Email, throttled)
+			return auth.TooManyAttempts(c, throttled)
+		}
+		if errors.Is(err, service.ErrInvalidCredentials) {
+			log.Printf("[Controller] 認証失敗: %s", req.Email)
+			return c.JSON(http.StatusUnauthorized, map[string]string{
+				"error":   "invalid_credentials",
+				"message": "Invalid email or password",
+			})
+		}
+		log.Printf("[Controller] エラー: ログインに失敗しました: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error":   "internal_error",
+			"message": "Failed to login",
+		})
+	}
+
+	log.Printf("[Controller] 成功: ログインしました (ID: %s)", user.ID)
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"access_token":  tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"token_type":    tokens.TokenType,
+		"expires_in":    tokens.ExpiresIn,
+		"user":          user,
+	})
+}
+
+// RefreshToken は POST /api/auth/refresh リクエストを処理します
+func RefreshToken(c echo.Context) error {
+	log.Printf("[Controller] POST /api/auth/refresh - リクエスト受信")
+
+	var req struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+
+	if err := c.Bind(&req); err != nil || req.RefreshToken == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error":   "invalid_request",
+			"message": "refresh_token is required",
+		})
+	}
+
+	tokens, err := service.RefreshTokens(req.RefreshToken, clientInfo(c))
+	if err != nil {
+		if errors.Is(err, service.ErrRefreshTokenReused) {
+			log.Printf("[Controller] リフレッシュトークンの再利用を検知したため、セッションを失効させました")
+			return c.JSON(http.StatusUnauthorized, map[string]string{
+				"error":   "token_reused",
+				"message": "Refresh token was already used; the session has been revoked",
+			})
+		}
+		if errors.Is(err, auth.ErrInvalidToken) {
+			log.Printf("[Controller] リフレッシュトークンが無効です: %v", err)
+			return c.JSON(http.StatusUnauthorized, map[string]string{
+				"error":   "invalid_token",
+				"message": "Refresh token is invalid or expired",
+			})
+		}
+		log.Printf("[Controller] エラー: トークン更新に失敗しました: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error":   "internal_error",
+			"message": "Failed to refresh token",
+		})
+	}
+
+	log.Printf("[Controller] 成功: トークンを更新しました")
+	return c.JSON(http.StatusOK, tokens)
+}
+
+// Logout は POST /api/auth/logout リクエストを処理します
+func Logout(c echo.Context) error {
+	log.Printf("[Controller] POST /api/auth/logout - リクエスト受信")
+
+	var req struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+
+	if err := c.Bind(&req); err != nil || req.RefreshToken == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error":   "invalid_request",
+			"message": "refresh_token is required",
+		})
+	}
+
+	if err := service.Logout(req.RefreshToken); err != nil {
+		if errors.Is(err, auth.ErrInvalidToken) {
+			return c.JSON(http.StatusUnauthorized, map[string]string{
+				"error":   "invalid_token",
+				"message": "Refresh token is invalid or expired",
+			})
+		}
+		log.Printf("[Controller] エラー: ログアウトに失敗しました: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error":   "internal_error",
+			"message": "Failed to logout",
+		})
+	}
+
+	log.Printf("[Controller] 成功: ログアウトしました")
+	return c.NoContent(http.StatusNoContent)
+}
+
+// RevokeUserSessions は DELETE /api/users/:id/sessions リクエストを処理します
+func RevokeUserSessions(c echo.Context) error {
+	id := c.Param("id")
+	log.Printf("[Controller] DELETE /api/users/%s/sessions - リクエスト受信", id)
+
+	revoked, err := service.RevokeUserSessions(currentTenantID(c), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error":   "not_found",
+				"message": "User not found",
+			})
+		}
+		log.Printf("[Controller] エラー: セッション失効に失敗しました: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error":   "internal_error",
+			"message": "Failed to revoke sessions",
+		})
+	}
+
+	log.Printf("[Controller] 成功: %d件のセッションを失効させました (ID: %s)", revoked, id)
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message":          "セッションを失効させました",
+		"revoked_sessions": revoked,
+	})
+}
+
+// UnlockUser は POST /api/users/:id/unlock リクエストを処理します
+// ログイン・API キーのロックを解除し、ip_address を指定した場合はその IP のロックも解除します
+func UnlockUser(c echo.Context) error {
+	id := c.Param("id")
+	log.Printf("[Controller] POST /api/users/%s/unlock - リクエスト受信", id)
+
+	var req struct {
+		IPAddress string `json:"ip_address"`
+	}
+
+	if err := c.Bind(&req); err != nil {
+		log.Printf("[Controller] エラー: リクエストのバインドに失敗しました: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid_request",
+		})
+	}
+
+	unlocked, err := service.UnlockUser(currentTenantID(c), id, strings.TrimSpace(req.IPAddress), principalID(c), clientInfo(c))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return userNotFound(c)
+		}
+		log.Printf("[Controller] エラー: ロック解除に失敗しました: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error":   "internal_error",
+			"message": "Failed to unlock user",
+		})
+	}
+
+	targets := make([]string, 0, len(unlocked))
+	for _, key := range unlocked {
+		targets = append(targets, key.String())
+	}
+
+	log.Printf("[Controller] 成功: %d件のロックを解除しました (ID: %s)", len(targets), id)
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message":  "ロックを解除しました",
+		"unlocked": targets,
+	})
+}
+
+// clientInfo はリクエストからクライアント情報を取得します
+func clientInfo(c echo.Context) service.ClientInfo {
+	return service.ClientInfo{
+		UserAgent: c.Request().UserAgent(),
+		IPAddress: c.RealIP(),
+		RequestID: c.Response().Header().Get(echo.HeaderXRequestID),
+	}
+}
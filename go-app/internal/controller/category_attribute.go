@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+
+	"go-hsm-app/internal/model"
+	"go-hsm-app/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GetCategoryAttributes は GET /api/categories/:id/attributes リクエストを処理します
+// カテゴリの属性テンプレート（適用する属性・必須・既定値）を返します
+func GetCategoryAttributes(c echo.Context) error {
+	id := c.Param("id")
+	log.Printf("[Controller] GET /api/categories/%s/attributes - リクエスト受信", id)
+
+	attributes, err := service.GetCategoryAttributes(currentTenantID(c), id)
+	if err != nil {
+		return respondCategoryAttributeError(c, err, "Failed to fetch category attributes")
+	}
+
+	log.Printf("[Controller] 成功: %d件のテンプレート属性を取得しました", len(attributes))
+	return c.JSON(http.StatusOK, attributes)
+}
+
+// SetCategoryAttributes は PUT /api/categories/:id/attributes リクエストを処理します
+// カテゴリの属性テンプレートをリクエストの内容で置き換えます（表示順は attributes の並び順）
+func SetCategoryAttributes(c echo.Context) error {
+	id := c.Param("id")
+	log.Printf("[Controller] PUT /api/categories/%s/attributes - リクエスト受信", id)
+
+	var req struct {
+		Attributes []service.CategoryAttributeInput `json:"attributes"`
+	}
+	if err := c.Bind(&req); err != nil {
+		log.Printf("[Controller] エラー: リクエストのバインドに失敗しました: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error":   "invalid_request",
+			"message": "Invalid request body",
+		})
+	}
+
+	attributes, err := service.SetCategoryAttributes(currentTenantID(c), id, req.Attributes)
+	if err != nil {
+		return respondCategoryAttributeError(c, err, "Failed to update category attributes")
+	}
+
+	log.Printf("[Controller] 成功: テンプレートを更新しました (ID: %s, %d件)", id, len(attributes))
+	return c.JSON(http.StatusOK, attributes)
+}
+
+// GetTemplateViolations は GET /api/items/template-violations リクエストを処理します
+// カテゴリの属性テンプレートを満たさない既存のアイテムを返します（category でカテゴリIDを指定可）
+func GetTemplateViolations(c echo.Context) error {
+	log.Printf("[Controller] GET /api/items/template-violations - リクエスト受信")
+
+	violations, err := service.GetTemplateViolations(currentTenantID(c), accessScope(c, model.RoleViewer), c.QueryParam("category"))
+	if err != nil {
+		log.Printf("[Controller] エラー: テンプレート違反の取得に失敗しました: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error":   "internal_error",
+			"message": "Failed to fetch template violations",
+		})
+	}
+
+	log.Printf("[Controller] 成功: %d件のテンプレート違反を取得しました", len(violations))
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"violations": violations,
+		"total":      len(violations),
+	})
+}
+
+// respondCategoryAttributeError は属性テンプレートの操作のエラーレスポンスを返します
+func respondCategoryAttributeError(c echo.Context, err error, failureMessage string) error {
+	if handled, resp := respondValidationError(c, err); handled {
+		return resp
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error":   "not_found",
+			"message": "Category not found",
+		})
+	}
+	log.Printf("[Controller] エラー: 属性テンプレートの操作に失敗しました: %v", err)
+	return c.JSON(http.StatusInternalServerError, map[string]string{
+		"error":   "internal_error",
+		"message": failureMessage,
+	})
+}
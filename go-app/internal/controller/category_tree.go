@@ -0,0 +1,90 @@
+package controller
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+
+	"go-hsm-app/internal/repository"
+	"go-hsm-app/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GetCategoryTree は GET /api/categories/tree リクエストを処理します
+// 最上位のカテゴリの一覧を、子カテゴリ（children）を入れ子にして返します
+func GetCategoryTree(c echo.Context) error {
+	log.Printf("[Controller] GET /api/categories/tree - リクエスト受信")
+
+	tree, err := service.GetCategoryTree(currentTenantID(c))
+	if err != nil {
+		log.Printf("[Controller] エラー: カテゴリ階層の取得に失敗しました: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error":   "internal_error",
+			"message": "Failed to fetch category tree",
+		})
+	}
+
+	log.Printf("[Controller] 成功: %d件の最上位カテゴリを取得しました", len(tree))
+	return c.JSON(http.StatusOK, tree)
+}
+
+// MoveCategory は PUT /api/categories/:id/parent リクエストを処理します
+// カテゴリの親を変更し、子孫を含むサブツリーを移動します（parent_id が null の場合は最上位に移動）
+func MoveCategory(c echo.Context) error {
+	id := c.Param("id")
+	log.Printf("[Controller] PUT /api/categories/%s/parent - リクエスト受信", id)
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return invalidIfMatch(c, err)
+	}
+
+	var req struct {
+		ParentID *string `json:"parent_id"`
+	}
+	if err := c.Bind(&req); err != nil {
+		log.Printf("[Controller] エラー: リクエストのバインドに失敗しました: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error":   "invalid_request",
+			"message": "Invalid request body",
+		})
+	}
+
+	category, err := service.MoveCategory(currentTenantID(c), id, req.ParentID, version)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error":   "not_found",
+				"message": "Category not found",
+			})
+		case errors.Is(err, repository.ErrParentCategoryNotFound):
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error":   "invalid_request",
+				"message": err.Error(),
+			})
+		case errors.Is(err, repository.ErrCategoryCycle):
+			return c.JSON(http.StatusConflict, map[string]string{
+				"error":   "category_cycle",
+				"message": err.Error(),
+			})
+		case errors.Is(err, repository.ErrVersionConflict):
+			current, fetchErr := service.GetCategoryByID(currentTenantID(c), id)
+			if fetchErr == nil {
+				return respondVersionConflict(c, current, current.Version)
+			}
+			log.Printf("[Controller] エラー: 競合時のカテゴリ取得に失敗しました: %v", fetchErr)
+		}
+		log.Printf("[Controller] エラー: カテゴリの移動に失敗しました: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error":   "internal_error",
+			"message": "Failed to move category",
+		})
+	}
+
+	log.Printf("[Controller] 成功: カテゴリを移動しました (ID: %s)", id)
+	setETag(c, category.Version)
+	return c.JSON(http.StatusOK, category)
+}
@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	// errInvalidIfMatch は If-Match ヘッダーの形式が不正な場合のエラーです
+	errInvalidIfMatch = errors.New(`If-Match must be a single ETag such as "3"`)
+	// errIfMatchRequired は更新系のリクエストに If-Match ヘッダーがない場合のエラーです
+	errIfMatchRequired = errors.New(`If-Match is required: send the ETag of the resource, or "*" to overwrite regardless of the version`)
+)
+
+// setETag はレスポンスにバージョンを ETag として設定します
+func setETag(c echo.Context, version int) {
+	c.Response().Header().Set("ETag", strconv.Quote(strconv.Itoa(version)))
+}
+
+// ifMatchVersion は If-Match ヘッダーから更新の前提となるバージョンを取得します
+// ヘッダーがない場合は errIfMatchRequired を返し、"*"（バージョンを確認せずに上書きする明示的な指定）の場合は nil を返します
+func ifMatchVersion(c echo.Context) (*int, error) {
+	value := strings.TrimSpace(c.Request().Header.Get("If-Match"))
+	switch value {
+	case "":
+		return nil, errIfMatchRequired
+	case "*":
+		return nil, nil
+	}
+
+	// 弱い ETag（W/"3"）も受け付ける
+	value = strings.TrimPrefix(value, "W/")
+	unquoted, err := strconv.Unquote(value)
+	if err != nil {
+		return nil, errInvalidIfMatch
+	}
+	version, err := strconv.Atoi(unquoted)
+	if err != nil || version <= 0 {
+		return nil, errInvalidIfMatch
+	}
+	return &version, nil
+}
+
+// invalidIfMatch は If-Match ヘッダーがない場合の 428、形式が不正な場合の 400 レスポンスを返します
+func invalidIfMatch(c echo.Context, err error) error {
+	if errors.Is(err, errIfMatchRequired) {
+		return c.JSON(http.StatusPreconditionRequired, map[string]string{
+			"error":   "precondition_required",
+			"message": errIfMatchRequired.Error(),
+		})
+	}
+	return c.JSON(http.StatusBadRequest, map[string]string{
+		"error":   "invalid_request",
+		"message": errInvalidIfMatch.Error(),
+	})
+}
+
+// respondVersionConflict は他の更新と競合した場合の 409 レスポンスを、現在の状態とともに返します
+// クライアントは current と ETag を使って再編集・再送信できます
+func respondVersionConflict(c echo.Context, current interface{}, version int) error {
+	setETag(c, version)
+	return c.JSON(http.StatusConflict, map[string]interface{}{
+		"error":   "version_conflict",
+		"message": "The resource was modified by another request",
+		"current": current,
+	})
+}
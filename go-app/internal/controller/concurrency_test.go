@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestIfMatchVersion(t *testing.T) {
+	version := func(v int) *int { return &v }
+	for name, tc := range map[string]struct {
+		header string
+		want   *int
+		err    error
+	}{
+		"missing":      {header: "", err: errIfMatchRequired},
+		"wildcard":     {header: "*", want: nil},
+		"strong etag":  {header: `"3"`, want: version(3)},
+		"weak etag":    {header: `W/"4"`, want: version(4)},
+		"unquoted":     {header: "3", err: errInvalidIfMatch},
+		"zero version": {header: `"0"`, err: errInvalidIfMatch},
+		"etag list":    {header: `"3", "4"`, err: errInvalidIfMatch},
+	} {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/api/items/I1", nil)
+			if tc.header != "" {
+				req.Header.Set("If-Match", tc.header)
+			}
+			got, err := ifMatchVersion(echo.New().NewContext(req, httptest.NewRecorder()))
+			if err != tc.err {
+				t.Fatalf("expected error %v, got %v", tc.err, err)
+			}
+			if (got == nil) != (tc.want == nil) || (got != nil && *got != *tc.want) {
+				t.Fatalf("expected version %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestUpdateWithoutIfMatchRequiresPrecondition(t *testing.T) {
+	for name, handler := range map[string]echo.HandlerFunc{
+		"PUT /api/items/:id":        UpdateItem,
+		"PATCH /api/items/:id":      PatchItem,
+		"POST /api/items/:id/merge": MergeItem,
+	} {
+		t.Run(name, func(t *testing.T) {
+			method := strings.Fields(name)[0]
+			req := httptest.NewRequest(method, "/api/items/I1", strings.NewReader(`{}`))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+
+			if err := handler(echo.New().NewContext(req, rec)); err != nil {
+				t.Fatal(err)
+			}
+			if rec.Code != http.StatusPreconditionRequired || !strings.Contains(rec.Body.String(), "precondition_required") {
+				t.Fatalf("expected 428 precondition_required, got %d %s", rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestRespondVersionConflict(t *testing.T) {
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(httptest.NewRequest(http.MethodPut, "/api/items/I1", nil), rec)
+
+	current := map[string]interface{}{"id": "I1", "version": 5}
+	if err := respondVersionConflict(c, current, 5); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d", rec.Code)
+	}
+	if etag := rec.Header().Get("ETag"); etag != `"5"` {
+		t.Fatalf(`expected ETag "5", got %q`, etag)
+	}
+
+	var body struct {
+		Error   string                 `json:"error"`
+		Current map[string]interface{} `json:"current"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Error != "version_conflict" || body.Current["version"] != float64(5) {
+		t.Fatalf("unexpected conflict response: %s", rec.Body.String())
+	}
+}
@@ -1,34 +1,61 @@
 package controller
 
 import (
+	"database/sql"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 
+	"go-hsm-app/internal/auth"
+	"go-hsm-app/internal/model"
+	"go-hsm-app/internal/repository"
 	"go-hsm-app/internal/service"
 
 	"github.com/labstack/echo/v4"
 )
 
-// GetRecentItems は GET /api/items リクエストを処理します
-func GetRecentItems(c echo.Context) error {
+// GetItems は GET /api/items リクエストを処理します
+//
+// クエリパラメータ:
+//
+//	q                キーワード検索（コード・名称・属性値。ひらがな/カタカナ・全角/半角・大文字/小文字を区別しない）
+//	code, name       部分一致検索
+//	category         カテゴリID またはコード（カンマ区切りで複数指定可）
+//	                 include_descendants=true の場合は子孫カテゴリのアイテムも含める
+//	status           active / inactive
+//	stock            in（在庫あり）/ out（在庫なし）
+//	attr.<code>      属性値の完全一致（例: attr.color=red）
+//	attr.<code>[op]  属性値の型に応じた比較。op は eq, ne, lt, gt, between, contains, in
+//	                 （例: attr.weight_g[gt]=500, attr.best_before[between]=2025-01-01,2025-12-31, attr.color[in]=red,blue）
+//	sort             relevance, code, name, updated_at, quantity, created_at（先頭に - で降順、既定は q 指定時 relevance、それ以外 -created_at）
+//	limit, offset    ページネーション（offset の代わりに 1 始まりの page も指定可）
+func GetItems(c echo.Context) error {
 	log.Printf("[Controller] GET /api/items - リクエスト受信")
 
-	// クエリ文字列から limit パラメータを取得
-	limitStr := c.QueryParam("limit")
-	limit := 10
-	if limitStr != "" {
-		if limitNum, err := strconv.Atoi(limitStr); err == nil && limitNum > 0 {
-			limit = limitNum
-		}
+	query, err := parseItemQuery(c)
+	if err != nil {
+		log.Printf("[Controller] エラー: 検索条件が不正です: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error":   "invalid_request",
+			"message": err.Error(),
+		})
 	}
 
-	log.Printf("[Controller] limit: %d", limit)
+	log.Printf("[Controller] query: %+v", query)
 
 	// サービス層からアイテムを取得
-	items, err := service.GetRecentItems(limit)
+	items, total, err := service.GetItems(currentTenantID(c), accessScope(c, model.RoleViewer), query)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidItemQuery) {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error":   "invalid_request",
+				"message": err.Error(),
+			})
+		}
 		log.Printf("[Controller] エラー: アイテム取得に失敗しました: %v", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error":   "internal_error",
@@ -36,12 +63,153 @@ func GetRecentItems(c echo.Context) error {
 		})
 	}
 
-	log.Printf("[Controller] 成功: %d件のアイテムを取得しました", len(items))
+	if items == nil {
+		items = []model.Item{}
+	}
+
+	log.Printf("[Controller] 成功: %d件のアイテムを取得しました (総件数: %d)", len(items), total)
 
 	// Return JSON response
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"items": items,
-		"total": len(items),
+		"items":  items,
+		"total":  total,
+		"limit":  query.Limit,
+		"offset": query.Offset,
+	})
+}
+
+// parseItemQuery はクエリパラメータからアイテムの検索条件を組み立てます
+func parseItemQuery(c echo.Context) (model.ItemQuery, error) {
+	params := c.QueryParams()
+	query := model.ItemQuery{
+		Keyword: params.Get("q"),
+		Code:    strings.TrimSpace(params.Get("code")),
+		Name:    strings.TrimSpace(params.Get("name")),
+		Status:  params.Get("status"),
+		Stock:   params.Get("stock"),
+		Sort:    params.Get("sort"),
+	}
+
+	if query.Keyword == "" {
+		query.Keyword = params.Get("query")
+	}
+
+	for _, v := range params["category"] {
+		for _, category := range strings.Split(v, ",") {
+			if category = strings.TrimSpace(category); category != "" {
+				query.Categories = append(query.Categories, category)
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if strings.HasPrefix(key, "attr.") {
+			filters, err := parseAttributeFilters(key, params[key])
+			if err != nil {
+				return query, err
+			}
+			query.Attributes = append(query.Attributes, filters...)
+		}
+	}
+
+	if v := params.Get("include_descendants"); v != "" {
+		include, err := strconv.ParseBool(v)
+		if err != nil {
+			return query, errors.New("include_descendants must be true or false")
+		}
+		query.IncludeDescendants = include
+	}
+
+	if v := params.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			return query, errors.New("limit must be a positive integer")
+		}
+		query.Limit = limit
+	} else {
+		query.Limit = service.DefaultItemLimit
+	}
+
+	switch {
+	case params.Get("offset") != "":
+		offset, err := strconv.Atoi(params.Get("offset"))
+		if err != nil || offset < 0 {
+			return query, errors.New("offset must be a non-negative integer")
+		}
+		query.Offset = offset
+	case params.Get("page") != "":
+		page, err := strconv.Atoi(params.Get("page"))
+		if err != nil || page <= 0 {
+			return query, errors.New("page must be a positive integer")
+		}
+		query.Offset = (page - 1) * query.Limit
+	}
+
+	return query, nil
+}
+
+// parseAttributeFilters は attr.<code> または attr.<code>[op] のクエリパラメータを属性フィルタに変換します
+// between と in の値はカンマ区切りで指定します。同じキーを複数指定した場合は全てを満たすものに絞り込みます
+func parseAttributeFilters(key string, values []string) ([]model.AttributeFilter, error) {
+	code, operator := strings.TrimPrefix(key, "attr."), model.AttributeFilterEq
+	if open := strings.Index(code, "["); open >= 0 {
+		if !strings.HasSuffix(code, "]") {
+			return nil, fmt.Errorf("%s: attribute filter must be attr.<code> or attr.<code>[op]", key)
+		}
+		code, operator = code[:open], code[open+1:len(code)-1]
+	}
+
+	filters := make([]model.AttributeFilter, 0, len(values))
+	for _, value := range values {
+		filter := model.AttributeFilter{Code: code, Operator: operator, Values: []string{value}}
+		if operator == model.AttributeFilterBetween || operator == model.AttributeFilterIn {
+			filter.Values = strings.Split(value, ",")
+		}
+		filters = append(filters, filter)
+	}
+	return filters, nil
+}
+
+// SuggestItems は GET /api/items/suggest リクエストを処理します
+// 入力途中の検索語（q）に名称またはコードが前方一致するアイテムを返します
+func SuggestItems(c echo.Context) error {
+	q := c.QueryParam("q")
+	log.Printf("[Controller] GET /api/items/suggest - リクエスト受信 (q: %s)", q)
+
+	limit := 0
+	if v := c.QueryParam("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error":   "invalid_request",
+				"message": "limit must be a positive integer",
+			})
+		}
+		limit = n
+	}
+
+	suggestions, err := service.SuggestItems(currentTenantID(c), accessScope(c, model.RoleViewer), q, limit)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidItemQuery) {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error":   "invalid_request",
+				"message": err.Error(),
+			})
+		}
+		log.Printf("[Controller] エラー: 入力補完候補の取得に失敗しました: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error":   "internal_error",
+			"message": "Failed to fetch suggestions",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"suggestions": suggestions,
 	})
 }
 
@@ -51,7 +219,7 @@ func GetItemByID(c echo.Context) error {
 	log.Printf("[Controller] GET /api/items/%s - リクエスト受信", id)
 
 	// サービス層からアイテムを取得
-	item, err := service.GetItemByID(id)
+	item, err := service.GetItemByID(currentTenantID(c), accessScope(c, model.RoleViewer), id)
 	if err != nil {
 		if err.Error() == "sql: no rows in result set" {
 			log.Printf("[Controller] アイテムが見つかりません: %s", id)
@@ -68,6 +236,7 @@ func GetItemByID(c echo.Context) error {
 	}
 
 	log.Printf("[Controller] 成功: アイテムを取得しました (ID: %s)", id)
+	setETag(c, item.Version)
 	return c.JSON(http.StatusOK, item)
 }
 
@@ -75,7 +244,7 @@ func GetItemByID(c echo.Context) error {
 func GetCategories(c echo.Context) error {
 	log.Printf("[Controller] GET /api/categories - リクエスト受信")
 
-	categories, err := service.GetCategories()
+	categories, err := service.GetCategories(currentTenantID(c))
 	if err != nil {
 		log.Printf("[Controller] エラー: カテゴリ取得に失敗しました: %v", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{
@@ -88,11 +257,38 @@ func GetCategories(c echo.Context) error {
 	return c.JSON(http.StatusOK, categories)
 }
 
+// GetCategoryByID は GET /api/categories/:id リクエストを処理します
+// レスポンスの ETag を PUT の If-Match に指定すると、競合する更新を検出できます
+func GetCategoryByID(c echo.Context) error {
+	id := c.Param("id")
+	log.Printf("[Controller] GET /api/categories/%s - リクエスト受信", id)
+
+	category, err := service.GetCategoryByID(currentTenantID(c), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Printf("[Controller] カテゴリが見つかりません: %s", id)
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error":   "not_found",
+				"message": "Category not found",
+			})
+		}
+		log.Printf("[Controller] エラー: カテゴリ取得に失敗しました: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error":   "internal_error",
+			"message": "Failed to fetch category",
+		})
+	}
+
+	log.Printf("[Controller] 成功: カテゴリを取得しました (ID: %s)", id)
+	setETag(c, category.Version)
+	return c.JSON(http.StatusOK, category)
+}
+
 // GetUnits は GET /api/units リクエストを処理します
 func GetUnits(c echo.Context) error {
 	log.Printf("[Controller] GET /api/units - リクエスト受信")
 
-	units, err := service.GetUnits()
+	units, err := service.GetUnits(currentTenantID(c))
 	if err != nil {
 		log.Printf("[Controller] エラー: 単位取得に失敗しました: %v", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{
@@ -105,11 +301,38 @@ func GetUnits(c echo.Context) error {
 	return c.JSON(http.StatusOK, units)
 }
 
+// GetUnitByID は GET /api/units/:id リクエストを処理します
+// レスポンスの ETag を PUT の If-Match に指定すると、競合する更新を検出できます
+func GetUnitByID(c echo.Context) error {
+	id := c.Param("id")
+	log.Printf("[Controller] GET /api/units/%s - リクエスト受信", id)
+
+	unit, err := service.GetUnitByID(currentTenantID(c), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Printf("[Controller] 単位が見つかりません: %s", id)
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error":   "not_found",
+				"message": "Unit not found",
+			})
+		}
+		log.Printf("[Controller] エラー: 単位取得に失敗しました: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error":   "internal_error",
+			"message": "Failed to fetch unit",
+		})
+	}
+
+	log.Printf("[Controller] 成功: 単位を取得しました (ID: %s)", id)
+	setETag(c, unit.Version)
+	return c.JSON(http.StatusOK, unit)
+}
+
 // GetAttributes は GET /api/attributes リクエストを処理します
 func GetAttributes(c echo.Context) error {
 	log.Printf("[Controller] GET /api/attributes - リクエスト受信")
 
-	attributes, err := service.GetAttributes()
+	attributes, err := service.GetAttributes(currentTenantID(c))
 	if err != nil {
 		log.Printf("[Controller] エラー: 属性取得に失敗しました: %v", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{
@@ -122,11 +345,38 @@ func GetAttributes(c echo.Context) error {
 	return c.JSON(http.StatusOK, attributes)
 }
 
+// GetAttributeByID は GET /api/attributes/:id リクエストを処理します
+// レスポンスの ETag を PUT の If-Match に指定すると、競合する更新を検出できます
+func GetAttributeByID(c echo.Context) error {
+	id := c.Param("id")
+	log.Printf("[Controller] GET /api/attributes/%s - リクエスト受信", id)
+
+	attribute, err := service.GetAttributeByID(currentTenantID(c), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Printf("[Controller] 属性が見つかりません: %s", id)
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error":   "not_found",
+				"message": "Attribute not found",
+			})
+		}
+		log.Printf("[Controller] エラー: 属性取得に失敗しました: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error":   "internal_error",
+			"message": "Failed to fetch attribute",
+		})
+	}
+
+	log.Printf("[Controller] 成功: 属性を取得しました (ID: %s)", id)
+	setETag(c, attribute.Version)
+	return c.JSON(http.StatusOK, attribute)
+}
+
 // GetUsers は GET /api/users リクエストを処理します
 func GetUsers(c echo.Context) error {
 	log.Printf("[Controller] GET /api/users - リクエスト受信")
 
-	users, err := service.GetUsers()
+	users, err := service.GetUsers(currentTenantID(c))
 	if err != nil {
 		log.Printf("[Controller] エラー: ユーザー取得に失敗しました: %v", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{
@@ -139,14 +389,42 @@ func GetUsers(c echo.Context) error {
 	return c.JSON(http.StatusOK, users)
 }
 
+// GetUserByID は GET /api/users/:id リクエストを処理します
+// レスポンスの ETag を PUT の If-Match に指定すると、競合する更新を検出できます
+func GetUserByID(c echo.Context) error {
+	id := c.Param("id")
+	log.Printf("[Controller] GET /api/users/%s - リクエスト受信", id)
+
+	user, err := service.GetUserByID(currentTenantID(c), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Printf("[Controller] ユーザーが見つかりません: %s", id)
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error":   "not_found",
+				"message": "User not found",
+			})
+		}
+		log.Printf("[Controller] エラー: ユーザー取得に失敗しました: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error":   "internal_error",
+			"message": "Failed to fetch user",
+		})
+	}
+
+	log.Printf("[Controller] 成功: ユーザーを取得しました (ID: %s)", id)
+	setETag(c, user.Version)
+	return c.JSON(http.StatusOK, user)
+}
+
 // CreateCategory は POST /api/categories リクエストを処理します
 func CreateCategory(c echo.Context) error {
 	log.Printf("[Controller] POST /api/categories - リクエスト受信")
 
 	var req struct {
-		Code        string `json:"code"`
-		Name        string `json:"name"`
-		Description string `json:"description"`
+		Code        string  `json:"code"`
+		Name        string  `json:"name"`
+		Description string  `json:"description"`
+		ParentID    *string `json:"parent_id"`
 	}
 
 	if err := c.Bind(&req); err != nil {
@@ -155,16 +433,23 @@ func CreateCategory(c echo.Context) error {
 			"error": "リクエストが不正です",
 		})
 	}
+	if req.ParentID != nil && *req.ParentID == "" {
+		req.ParentID = nil
+	}
 
-	category, err := service.CreateCategory(req.Code, req.Name, req.Description)
+	category, err := service.CreateCategory(currentTenantID(c), req.Code, req.Name, req.Description, req.ParentID)
 	if err != nil {
-		log.Printf("[Controller] エラー: カテゴリ作成に失敗しました: %v", err)
-		errorMsg := "カテゴリの作成に失敗しました"
-		if strings.Contains(err.Error(), "duplicate key") {
-			errorMsg = "このカテゴリコードは既に使用されています"
+		if errors.Is(err, repository.ErrParentCategoryNotFound) {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "親カテゴリが見つかりません",
+			})
+		}
+		if handled, resp := respondDuplicateCode(c, err, "このカテゴリコードは既に使用されています"); handled {
+			return resp
 		}
+		log.Printf("[Controller] エラー: カテゴリ作成に失敗しました: %v", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": errorMsg,
+			"error": "カテゴリの作成に失敗しました",
 		})
 	}
 
@@ -177,6 +462,11 @@ func UpdateCategory(c echo.Context) error {
 	id := c.Param("id")
 	log.Printf("[Controller] PUT /api/categories/%s - リクエスト受信", id)
 
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return invalidIfMatch(c, err)
+	}
+
 	var req struct {
 		Code        string `json:"code"`
 		Name        string `json:"name"`
@@ -190,8 +480,24 @@ func UpdateCategory(c echo.Context) error {
 		})
 	}
 
-	category, err := service.UpdateCategory(id, req.Code, req.Name, req.Description)
+	category, err := service.UpdateCategory(currentTenantID(c), id, req.Code, req.Name, &req.Description, version)
 	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrVersionConflict):
+			current, fetchErr := service.GetCategoryByID(currentTenantID(c), id)
+			if fetchErr == nil {
+				log.Printf("[Controller] 競合: カテゴリは他の更新により変更されています (ID: %s, version: %d)", id, current.Version)
+				return respondVersionConflict(c, current, current.Version)
+			}
+			log.Printf("[Controller] エラー: 競合時のカテゴリ取得に失敗しました: %v", fetchErr)
+		case errors.Is(err, sql.ErrNoRows):
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error": "カテゴリが見つかりません",
+			})
+		}
+		if handled, resp := respondDuplicateCode(c, err, "このカテゴリコードは既に使用されています"); handled {
+			return resp
+		}
 		log.Printf("[Controller] エラー: カテゴリ更新に失敗しました: %v", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "カテゴリの更新に失敗しました",
@@ -199,15 +505,25 @@ func UpdateCategory(c echo.Context) error {
 	}
 
 	log.Printf("[Controller] 成功: カテゴリを更新しました (ID: %s)", id)
+	setETag(c, category.Version)
 	return c.JSON(http.StatusOK, category)
 }
 
 // DeleteCategory は DELETE /api/categories/:id リクエストを処理します
+// アイテムから参照されている場合は 409 を返します。reassign_to にカテゴリIDを指定すると、アイテムを付け替えてから削除します
 func DeleteCategory(c echo.Context) error {
 	id := c.Param("id")
 	log.Printf("[Controller] DELETE /api/categories/%s - リクエスト受信", id)
 
-	if err := service.DeleteCategory(id); err != nil {
+	if err := service.DeleteCategory(currentTenantID(c), id, c.QueryParam("reassign_to")); err != nil {
+		if handled, resp := respondMasterDeleteError(c, err, "Category not found"); handled {
+			return resp
+		}
+		if errors.Is(err, repository.ErrCategoryHasChildren) {
+			return c.JSON(http.StatusConflict, map[string]string{
+				"error": "子カテゴリがあるため削除できません",
+			})
+		}
 		log.Printf("[Controller] エラー: カテゴリ削除に失敗しました: %v", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "カテゴリの削除に失敗しました",
@@ -237,15 +553,14 @@ func CreateUnit(c echo.Context) error {
 		})
 	}
 
-	unit, err := service.CreateUnit(req.Code, req.Name, req.Description)
+	unit, err := service.CreateUnit(currentTenantID(c), req.Code, req.Name, req.Description)
 	if err != nil {
-		log.Printf("[Controller] エラー: 単位作成に失敗しました: %v", err)
-		errorMsg := "単位の作成に失敗しました"
-		if strings.Contains(err.Error(), "duplicate key") {
-			errorMsg = "この単位コードは既に使用されています"
+		if handled, resp := respondDuplicateCode(c, err, "この単位コードは既に使用されています"); handled {
+			return resp
 		}
+		log.Printf("[Controller] エラー: 単位作成に失敗しました: %v", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": errorMsg,
+			"error": "単位の作成に失敗しました",
 		})
 	}
 
@@ -258,6 +573,11 @@ func UpdateUnit(c echo.Context) error {
 	id := c.Param("id")
 	log.Printf("[Controller] PUT /api/units/%s - リクエスト受信", id)
 
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return invalidIfMatch(c, err)
+	}
+
 	var req struct {
 		Code        string `json:"code"`
 		Name        string `json:"name"`
@@ -271,8 +591,24 @@ func UpdateUnit(c echo.Context) error {
 		})
 	}
 
-	unit, err := service.UpdateUnit(id, req.Code, req.Name, req.Description)
+	unit, err := service.UpdateUnit(currentTenantID(c), id, req.Code, req.Name, &req.Description, version)
 	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrVersionConflict):
+			current, fetchErr := service.GetUnitByID(currentTenantID(c), id)
+			if fetchErr == nil {
+				log.Printf("[Controller] 競合: 単位は他の更新により変更されています (ID: %s, version: %d)", id, current.Version)
+				return respondVersionConflict(c, current, current.Version)
+			}
+			log.Printf("[Controller] エラー: 競合時の単位取得に失敗しました: %v", fetchErr)
+		case errors.Is(err, sql.ErrNoRows):
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error": "単位が見つかりません",
+			})
+		}
+		if handled, resp := respondDuplicateCode(c, err, "この単位コードは既に使用されています"); handled {
+			return resp
+		}
 		log.Printf("[Controller] エラー: 単位更新に失敗しました: %v", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "単位の更新に失敗しました",
@@ -280,15 +616,20 @@ func UpdateUnit(c echo.Context) error {
 	}
 
 	log.Printf("[Controller] 成功: 単位を更新しました (ID: %s)", id)
+	setETag(c, unit.Version)
 	return c.JSON(http.StatusOK, unit)
 }
 
 // DeleteUnit は DELETE /api/units/:id リクエストを処理します
+// アイテムから参照されている場合は 409 を返します。reassign_to に単位IDを指定すると、参照を付け替えてから削除します
 func DeleteUnit(c echo.Context) error {
 	id := c.Param("id")
 	log.Printf("[Controller] DELETE /api/units/%s - リクエスト受信", id)
 
-	if err := service.DeleteUnit(id); err != nil {
+	if err := service.DeleteUnit(currentTenantID(c), id, c.QueryParam("reassign_to")); err != nil {
+		if handled, resp := respondMasterDeleteError(c, err, "Unit not found"); handled {
+			return resp
+		}
 		log.Printf("[Controller] エラー: 単位削除に失敗しました: %v", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "単位の削除に失敗しました",
@@ -328,23 +669,22 @@ func CreateAttribute(c echo.Context) error {
 		req.ValueType = "text" // デフォルト値
 		log.Printf("[Controller] value_typeが空のため、デフォルト値'text'を設定")
 	}
-	validTypes := map[string]bool{"text": true, "number": true, "boolean": true, "date": true}
+	validTypes := map[string]bool{"text": true, "number": true, "boolean": true, "date": true, "enum": true}
 	if !validTypes[req.ValueType] {
 		log.Printf("[Controller] エラー: 無効なvalue_type: %s", req.ValueType)
 		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": "value_typeは text, number, boolean, date のいずれかである必要があります",
+			"error": "value_typeは text, number, boolean, date, enum のいずれかである必要があります",
 		})
 	}
 
-	attribute, err := service.CreateAttribute(req.Code, req.Name, req.ValueType, req.Description)
+	attribute, err := service.CreateAttribute(currentTenantID(c), req.Code, req.Name, req.ValueType, req.Description)
 	if err != nil {
-		log.Printf("[Controller] エラー: 属性作成に失敗しました: %v", err)
-		errorMsg := "属性の作成に失敗しました"
-		if strings.Contains(err.Error(), "duplicate key") {
-			errorMsg = "この属性コードは既に使用されています"
+		if handled, resp := respondDuplicateCode(c, err, "この属性コードは既に使用されています"); handled {
+			return resp
 		}
+		log.Printf("[Controller] エラー: 属性作成に失敗しました: %v", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": errorMsg,
+			"error": "属性の作成に失敗しました",
 		})
 	}
 
@@ -357,6 +697,11 @@ func UpdateAttribute(c echo.Context) error {
 	id := c.Param("id")
 	log.Printf("[Controller] PUT /api/attributes/%s - リクエスト受信", id)
 
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return invalidIfMatch(c, err)
+	}
+
 	var req struct {
 		Code        string `json:"code"`
 		Name        string `json:"name"`
@@ -380,16 +725,34 @@ func UpdateAttribute(c echo.Context) error {
 		req.ValueType = "text" // デフォルト値
 		log.Printf("[Controller] value_typeが空のため、デフォルト値'text'を設定")
 	}
-	validTypes := map[string]bool{"text": true, "number": true, "boolean": true, "date": true}
+	validTypes := map[string]bool{"text": true, "number": true, "boolean": true, "date": true, "enum": true}
 	if !validTypes[req.ValueType] {
 		log.Printf("[Controller] エラー: 無効なvalue_type: %s", req.ValueType)
 		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": "value_typeは text, number, boolean, date のいずれかである必要があります",
+			"error": "value_typeは text, number, boolean, date, enum のいずれかである必要があります",
 		})
 	}
 
-	attribute, err := service.UpdateAttribute(id, req.Code, req.Name, req.ValueType, req.Description)
+	attribute, err := service.UpdateAttribute(currentTenantID(c), id, req.Code, req.Name, req.ValueType, &req.Description, version)
 	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrVersionConflict):
+			current, fetchErr := service.GetAttributeByID(currentTenantID(c), id)
+			if fetchErr == nil {
+				log.Printf("[Controller] 競合: 属性は他の更新により変更されています (ID: %s, version: %d)", id, current.Version)
+				return respondVersionConflict(c, current, current.Version)
+			}
+			log.Printf("[Controller] エラー: 競合時の属性取得に失敗しました: %v", fetchErr)
+		case errors.Is(err, sql.ErrNoRows):
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error": "属性が見つかりません",
+			})
+		case errors.Is(err, repository.ErrValueTypeInUse):
+			return respondValueTypeInUse(c)
+		}
+		if handled, resp := respondDuplicateCode(c, err, "この属性コードは既に使用されています"); handled {
+			return resp
+		}
 		log.Printf("[Controller] エラー: 属性更新に失敗しました: %v", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "属性の更新に失敗しました",
@@ -397,15 +760,20 @@ func UpdateAttribute(c echo.Context) error {
 	}
 
 	log.Printf("[Controller] 成功: 属性を更新しました (ID: %s)", id)
+	setETag(c, attribute.Version)
 	return c.JSON(http.StatusOK, attribute)
 }
 
 // DeleteAttribute は DELETE /api/attributes/:id リクエストを処理します
+// アイテムから参照されている場合は 409 を返します。reassign_to に属性IDを指定すると、参照を付け替えてから削除します
 func DeleteAttribute(c echo.Context) error {
 	id := c.Param("id")
 	log.Printf("[Controller] DELETE /api/attributes/%s - リクエスト受信", id)
 
-	if err := service.DeleteAttribute(id); err != nil {
+	if err := service.DeleteAttribute(currentTenantID(c), id, c.QueryParam("reassign_to")); err != nil {
+		if handled, resp := respondMasterDeleteError(c, err, "Attribute not found"); handled {
+			return resp
+		}
 		log.Printf("[Controller] エラー: 属性削除に失敗しました: %v", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "属性の削除に失敗しました",
@@ -423,8 +791,9 @@ func CreateUser(c echo.Context) error {
 	log.Printf("[Controller] POST /api/users - リクエスト受信")
 
 	var req struct {
-		Email string `json:"email"`
-		Role  string `json:"role"`
+		Email    string `json:"email"`
+		Password string `json:"password"` // 任意（未指定の場合は招待メールを送信する）
+		Role     string `json:"role"`
 	}
 
 	if err := c.Bind(&req); err != nil {
@@ -434,7 +803,7 @@ func CreateUser(c echo.Context) error {
 		})
 	}
 
-	// リクエストの内容をログ出力
+	// リクエストの内容をログ出力（パスワードは出力しない）
 	log.Printf("[Controller] リクエスト内容 - email: %s, role: %s", req.Email, req.Role)
 
 	// roleのバリデーション
@@ -450,14 +819,27 @@ func CreateUser(c echo.Context) error {
 		})
 	}
 
-	user, err := service.CreateUser(req.Email, req.Role)
+	user, err := service.CreateUser(currentTenantID(c), req.Email, req.Password, req.Role)
 	if err != nil {
+		if errors.Is(err, auth.ErrWeakPassword) {
+			return weakPassword(c)
+		}
+		if handled, resp := respondDuplicateEmail(c, err); handled {
+			return resp
+		}
 		log.Printf("[Controller] エラー: ユーザー作成に失敗しました: %v", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "ユーザーの作成に失敗しました",
 		})
 	}
 
+	// パスワード未指定の場合は招待メールを送信する（失敗しても作成は成功とし、再送で対応する）
+	if req.Password == "" {
+		if err := service.SendInvitation(currentTenantID(c), user.ID, principalID(c)); err != nil {
+			log.Printf("[Controller] 警告: 招待メールの送信に失敗しました (ID: %s): %v", user.ID, err)
+		}
+	}
+
 	log.Printf("[Controller] 成功: ユーザーを作成しました (ID: %s)", user.ID)
 	return c.JSON(http.StatusCreated, user)
 }
@@ -467,6 +849,11 @@ func UpdateUser(c echo.Context) error {
 	id := c.Param("id")
 	log.Printf("[Controller] PUT /api/users/%s - リクエスト受信", id)
 
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return invalidIfMatch(c, err)
+	}
+
 	var req struct {
 		Email string `json:"email"`
 		Role  string `json:"role"`
@@ -495,8 +882,24 @@ func UpdateUser(c echo.Context) error {
 		})
 	}
 
-	user, err := service.UpdateUser(id, req.Email, req.Role)
+	user, err := service.UpdateUser(currentTenantID(c), id, req.Email, req.Role, version)
 	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrVersionConflict):
+			current, fetchErr := service.GetUserByID(currentTenantID(c), id)
+			if fetchErr == nil {
+				log.Printf("[Controller] 競合: ユーザーは他の更新により変更されています (ID: %s, version: %d)", id, current.Version)
+				return respondVersionConflict(c, current, current.Version)
+			}
+			log.Printf("[Controller] エラー: 競合時のユーザー取得に失敗しました: %v", fetchErr)
+		case errors.Is(err, sql.ErrNoRows):
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error": "ユーザーが見つかりません",
+			})
+		}
+		if handled, resp := respondDuplicateEmail(c, err); handled {
+			return resp
+		}
 		log.Printf("[Controller] エラー: ユーザー更新に失敗しました: %v", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "ユーザーの更新に失敗しました",
@@ -504,6 +907,7 @@ func UpdateUser(c echo.Context) error {
 	}
 
 	log.Printf("[Controller] 成功: ユーザーを更新しました (ID: %s)", id)
+	setETag(c, user.Version)
 	return c.JSON(http.StatusOK, user)
 }
 
@@ -512,7 +916,7 @@ func DeleteUser(c echo.Context) error {
 	id := c.Param("id")
 	log.Printf("[Controller] DELETE /api/users/%s - リクエスト受信", id)
 
-	if err := service.DeleteUser(id); err != nil {
+	if err := service.DeleteUser(currentTenantID(c), id); err != nil {
 		log.Printf("[Controller] エラー: ユーザー削除に失敗しました: %v", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "ユーザーの削除に失敗しました",
@@ -552,7 +956,7 @@ func GetStockHistory(c echo.Context) error {
 	log.Printf("[Controller] page: %d, limit: %d, offset: %d", page, limit, offset)
 
 	// サービス層から在庫履歴を取得
-	histories, total, err := service.GetStockHistory(limit, offset)
+	histories, total, err := service.GetStockHistory(currentTenantID(c), accessScope(c, model.RoleViewer), limit, offset)
 	if err != nil {
 		log.Printf("[Controller] エラー: 在庫履歴取得に失敗しました: %v", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{
@@ -582,6 +986,8 @@ func CreateItem(c echo.Context) error {
 		UnitID     string  `json:"unit_id"`
 		Quantity   *int    `json:"quantity"`
 		UnitPrice  *int    `json:"unit_price"`
+		// 属性コード → 値（値は属性の型に応じて検証・正規化されます）
+		Attributes map[string]interface{} `json:"attributes"`
 	}
 
 	if err := c.Bind(&payload); err != nil {
@@ -591,8 +997,17 @@ func CreateItem(c echo.Context) error {
 		})
 	}
 
-	item, err := service.CreateItem(payload.Code, payload.Name, payload.UnitID, payload.CategoryID, payload.Quantity, payload.UnitPrice)
+	item, err := service.CreateItem(currentTenantID(c), accessScope(c, model.RoleOperator), payload.Code, payload.Name, payload.UnitID, payload.CategoryID, payload.Quantity, payload.UnitPrice, payload.Attributes)
 	if err != nil {
+		if handled, resp := respondValidationError(c, err); handled {
+			return resp
+		}
+		if handled, resp := respondDuplicateCode(c, err, "このアイテムコードは既に使用されています"); handled {
+			return resp
+		}
+		if errors.Is(err, repository.ErrAccessDenied) {
+			return auth.Forbidden(c, "")
+		}
 		log.Printf("[Controller] エラー: アイテム作成に失敗しました: %v", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "アイテムの作成に失敗しました",
@@ -608,6 +1023,11 @@ func UpdateItem(c echo.Context) error {
 	id := c.Param("id")
 	log.Printf("[Controller] PUT /api/items/%s - リクエスト受信", id)
 
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return invalidIfMatch(c, err)
+	}
+
 	var payload struct {
 		Code       string  `json:"code"`
 		Name       string  `json:"name"`
@@ -616,6 +1036,8 @@ func UpdateItem(c echo.Context) error {
 		Quantity   *int    `json:"quantity"`
 		UnitPrice  *int    `json:"unit_price"`
 		Status     string  `json:"status"`
+		// 属性コード → 値。指定した場合は属性値をこの内容で置き換え、省略した場合は変更しません
+		Attributes map[string]interface{} `json:"attributes"`
 	}
 
 	if err := c.Bind(&payload); err != nil {
@@ -625,8 +1047,32 @@ func UpdateItem(c echo.Context) error {
 		})
 	}
 
-	item, err := service.UpdateItem(id, payload.Code, payload.Name, payload.UnitID, payload.CategoryID, payload.Quantity, payload.UnitPrice, payload.Status)
+	item, err := service.UpdateItem(currentTenantID(c), accessScope(c, model.RoleOperator), id, payload.Code, payload.Name, payload.UnitID, payload.CategoryID, payload.Quantity, payload.UnitPrice, payload.Status, payload.Attributes, version)
 	if err != nil {
+		if handled, resp := respondValidationError(c, err); handled {
+			return resp
+		}
+		switch {
+		case errors.Is(err, repository.ErrVersionConflict):
+			current, fetchErr := service.GetItemByID(currentTenantID(c), accessScope(c, model.RoleViewer), id)
+			if fetchErr == nil {
+				log.Printf("[Controller] 競合: アイテムは他の更新により変更されています (ID: %s, version: %d)", id, current.Version)
+				return respondVersionConflict(c, current, current.Version)
+			}
+			log.Printf("[Controller] エラー: 競合時のアイテム取得に失敗しました: %v", fetchErr)
+		case errors.Is(err, sql.ErrNoRows):
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error":   "not_found",
+				"message": "Item not found",
+			})
+		case errors.Is(err, repository.ErrItemUnitInUse):
+			return respondItemUnitInUse(c)
+		case errors.Is(err, repository.ErrAccessDenied):
+			return auth.Forbidden(c, "")
+		}
+		if handled, resp := respondDuplicateCode(c, err, "このアイテムコードは既に使用されています"); handled {
+			return resp
+		}
 		log.Printf("[Controller] エラー: アイテム更新に失敗しました: %v", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "アイテムの更新に失敗しました",
@@ -634,23 +1080,50 @@ func UpdateItem(c echo.Context) error {
 	}
 
 	log.Printf("[Controller] 成功: アイテムを更新しました (ID: %s)", id)
+	setETag(c, item.Version)
 	return c.JSON(http.StatusOK, item)
 }
 
 // DeleteItem は DELETE /api/items/:id リクエストを処理します
+// 在庫・入出庫履歴があり削除のポリシーで削除できない場合は、拒否した規則とともに 409 を返します。
+// ポリシーが force の場合は、在庫の除却として記録した調整（ADJUST）の履歴を write_offs に返します
 func DeleteItem(c echo.Context) error {
 	id := c.Param("id")
 	log.Printf("[Controller] DELETE /api/items/%s - リクエスト受信", id)
 
-	if err := service.DeleteItem(id); err != nil {
+	writeOffs, err := service.DeleteItem(currentTenantID(c), accessScope(c, model.RoleAdmin), id, principalID(c))
+	if err != nil {
+		var forbidden *repository.ItemDeleteForbiddenError
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error": "アイテムが見つかりません",
+			})
+		case errors.As(err, &forbidden):
+			return c.JSON(http.StatusConflict, map[string]interface{}{
+				"error":         "item_delete_forbidden",
+				"message":       itemDeleteRuleMessages[forbidden.Rule],
+				"policy":        forbidden.Policy,
+				"rule":          forbidden.Rule,
+				"stock":         forbidden.Stock,
+				"history_count": forbidden.HistoryCount,
+			})
+		}
 		log.Printf("[Controller] エラー: アイテム削除に失敗しました: %v", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "アイテムの削除に失敗しました",
 		})
 	}
 
-	log.Printf("[Controller] 成功: アイテムを削除しました (ID: %s)", id)
-	return c.JSON(http.StatusOK, map[string]string{
-		"message": "アイテムを削除しました",
+	log.Printf("[Controller] 成功: アイテムを削除しました (ID: %s, 除却: %d件)", id, len(writeOffs))
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message":    "アイテムを削除しました",
+		"write_offs": writeOffs,
 	})
 }
+
+// itemDeleteRuleMessages はアイテムの削除を拒否した規則ごとの説明です
+var itemDeleteRuleMessages = map[string]string{
+	model.ItemDeleteRuleStockExists:   "在庫が残っているため削除できません",
+	model.ItemDeleteRuleHistoryExists: "入出庫履歴があるため削除できません",
+}
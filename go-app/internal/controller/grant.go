@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+
+	"go-hsm-app/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GetUserGrants は GET /api/users/:id/grants リクエストを処理します
+func GetUserGrants(c echo.Context) error {
+	id := c.Param("id")
+	log.Printf("[Controller] GET /api/users/%s/grants - リクエスト受信", id)
+
+	grants, err := service.GetUserGrants(currentTenantID(c), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return userNotFound(c)
+		}
+		log.Printf("[Controller] エラー: 権限付与の取得に失敗しました: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error":   "internal_error",
+			"message": "Failed to fetch grants",
+		})
+	}
+
+	log.Printf("[Controller] 成功: %d件の権限付与を返却します", len(grants))
+	return c.JSON(http.StatusOK, grants)
+}
+
+// CreateUserGrant は POST /api/users/:id/grants リクエストを処理します
+// 同じ範囲に付与済みの場合はロールを更新します
+func CreateUserGrant(c echo.Context) error {
+	id := c.Param("id")
+	log.Printf("[Controller] POST /api/users/%s/grants - リクエスト受信", id)
+
+	var req struct {
+		ScopeType string `json:"scope_type"`
+		ScopeID   string `json:"scope_id"`
+		Role      string `json:"role"`
+	}
+
+	if err := c.Bind(&req); err != nil {
+		log.Printf("[Controller] エラー: リクエストのバインドに失敗しました: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error":   "invalid_request",
+			"message": "Invalid request body",
+		})
+	}
+
+	grant, err := service.CreateUserGrant(currentTenantID(c), id, req.ScopeType, req.ScopeID, req.Role, principalID(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return userNotFound(c)
+		case errors.Is(err, service.ErrGrantScopeNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error":   "not_found",
+				"message": "Location or category not found",
+			})
+		case errors.Is(err, service.ErrInvalidGrant),
+			errors.Is(err, service.ErrGrantRoleExceedsUser),
+			errors.Is(err, service.ErrGrantForAdmin):
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error":   "invalid_request",
+				"message": err.Error(),
+			})
+		}
+		log.Printf("[Controller] エラー: 権限付与に失敗しました: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error":   "internal_error",
+			"message": "Failed to create grant",
+		})
+	}
+
+	log.Printf("[Controller] 成功: 権限を付与しました (ID: %s)", grant.ID)
+	return c.JSON(http.StatusCreated, grant)
+}
+
+// DeleteUserGrant は DELETE /api/users/:id/grants/:grantId リクエストを処理します
+func DeleteUserGrant(c echo.Context) error {
+	id := c.Param("id")
+	grantID := c.Param("grantId")
+	log.Printf("[Controller] DELETE /api/users/%s/grants/%s - リクエスト受信", id, grantID)
+
+	if err := service.DeleteUserGrant(currentTenantID(c), id, grantID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error":   "not_found",
+				"message": "Grant not found",
+			})
+		}
+		log.Printf("[Controller] エラー: 権限付与の削除に失敗しました: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error":   "internal_error",
+			"message": "Failed to delete grant",
+		})
+	}
+
+	log.Printf("[Controller] 成功: 権限付与を削除しました (ID: %s)", grantID)
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+
+	"go-hsm-app/internal/auth"
+	"go-hsm-app/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Impersonate は POST /api/users/:id/impersonate リクエストを処理します
+// 管理者が対象ユーザーとして操作するためのなりすまし用トークンを返します（管理者へのなりすましは不可）
+func Impersonate(c echo.Context) error {
+	id := c.Param("id")
+	log.Printf("[Controller] POST /api/users/%s/impersonate - リクエスト受信", id)
+
+	p := auth.CurrentPrincipal(c)
+	if p == nil {
+		return auth.Unauthorized(c, "Authentication required")
+	}
+	// なりすましは管理者のセッションに紐づけるため、API キーやなりすまし中のトークンでは開始できない
+	if p.APIKeyID != "" || p.SessionID == "" || p.Impersonating() {
+		log.Printf("[Controller] セッションのないトークンによるなりすましは許可されていません (user_id: %s)", p.UserID)
+		return c.JSON(http.StatusForbidden, map[string]string{
+			"error":   "forbidden",
+			"message": "Impersonation requires an interactive admin session",
+		})
+	}
+
+	token, user, err := service.Impersonate(currentTenantID(c), p.UserID, p.SessionID, id, clientInfo(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return userNotFound(c)
+		case errors.Is(err, service.ErrImpersonateAdmin), errors.Is(err, service.ErrImpersonateSelf):
+			return c.JSON(http.StatusForbidden, map[string]string{
+				"error":   "forbidden",
+				"message": err.Error(),
+			})
+		}
+		log.Printf("[Controller] エラー: なりすましトークンの発行に失敗しました: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error":   "internal_error",
+			"message": "Failed to start impersonation",
+		})
+	}
+
+	log.Printf("[Controller] 成功: なりすましトークンを発行しました (admin: %s, target: %s)", p.UserID, user.ID)
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"access_token":    token.AccessToken,
+		"token_type":      token.TokenType,
+		"expires_in":      token.ExpiresIn,
+		"impersonation":   token.Impersonation,
+		"impersonator_id": token.ImpersonatorID,
+		"user":            user,
+	})
+}
@@ -0,0 +1,181 @@
+package controller
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"go-hsm-app/internal/auth"
+	"go-hsm-app/internal/model"
+	"go-hsm-app/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+// SendInvitation は POST /api/users/:id/invitation リクエストを処理します
+func SendInvitation(c echo.Context) error {
+	id := c.Param("id")
+	log.Printf("[Controller] POST /api/users/%s/invitation - リクエスト受信", id)
+
+	if err := service.SendInvitation(currentTenantID(c), id, principalID(c)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error":   "not_found",
+				"message": "User not found",
+			})
+		}
+		if errors.Is(err, service.ErrUserAlreadyActive) {
+			return c.JSON(http.StatusConflict, map[string]string{
+				"error":   "already_active",
+				"message": "User has already set a password",
+			})
+		}
+		log.Printf("[Controller] エラー: 招待メールの送信に失敗しました: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error":   "internal_error",
+			"message": "Failed to send invitation",
+		})
+	}
+
+	log.Printf("[Controller] 成功: 招待メールを送信しました (ID: %s)", id)
+	return c.JSON(http.StatusAccepted, map[string]string{
+		"message": "招待メールを送信しました",
+	})
+}
+
+// AcceptInvitation は POST /api/auth/invitation/accept リクエストを処理します
+func AcceptInvitation(c echo.Context) error {
+	log.Printf("[Controller] POST /api/auth/invitation/accept - リクエスト受信")
+
+	var req struct {
+		Token    string `json:"token"`
+		Password string `json:"password"`
+	}
+
+	if err := c.Bind(&req); err != nil || req.Token == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error":   "invalid_request",
+			"message": "token and password are required",
+		})
+	}
+
+	user, err := service.AcceptInvitation(req.Token, req.Password)
+	if err != nil {
+		return userTokenError(c, err)
+	}
+
+	log.Printf("[Controller] 成功: 招待を承認しました (ID: %s)", user.ID)
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "パスワードを設定しました",
+		"user":    user,
+	})
+}
+
+// ForgotPassword は POST /api/auth/password/forgot リクエストを処理します
+// メールアドレスの存在有無や送信の成否に関わらず 202 を返します（送信回数の制限中は 429）
+func ForgotPassword(c echo.Context) error {
+	log.Printf("[Controller] POST /api/auth/password/forgot - リクエスト受信")
+
+	var req struct {
+		Email string `json:"email"`
+	}
+
+	if err := c.Bind(&req); err != nil || strings.TrimSpace(req.Email) == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error":   "invalid_request",
+			"message": "email is required",
+		})
+	}
+
+	if err := service.RequestPasswordReset(strings.TrimSpace(req.Email), clientInfo(c)); err != nil {
+		var throttled *auth.ThrottledError
+		if errors.As(err, &throttled) {
+			log.Printf("[Controller] パスワード再設定を制限中です: %v", throttled)
+			return auth.TooManyAttempts(c, throttled)
+		}
+		// 応答でメールアドレスの存在や送信の成否を推測されないよう、失敗してもログ出力のみとする
+		log.Printf("[Controller] エラー: パスワード再設定の受付に失敗しました: %v", err)
+	}
+
+	return c.JSON(http.StatusAccepted, map[string]string{
+		"message": "登録済みのメールアドレスの場合、パスワード再設定のご案内を送信しました",
+	})
+}
+
+// ResetPassword は POST /api/auth/password/reset リクエストを処理します
+func ResetPassword(c echo.Context) error {
+	log.Printf("[Controller] POST /api/auth/password/reset - リクエスト受信")
+
+	var req struct {
+		Token    string `json:"token"`
+		Password string `json:"password"`
+	}
+
+	if err := c.Bind(&req); err != nil || req.Token == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error":   "invalid_request",
+			"message": "token and password are required",
+		})
+	}
+
+	user, err := service.ResetPassword(req.Token, req.Password)
+	if err != nil {
+		return userTokenError(c, err)
+	}
+
+	log.Printf("[Controller] 成功: パスワードを再設定しました (ID: %s)", user.ID)
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "パスワードを再設定しました",
+	})
+}
+
+// userTokenError は招待・パスワード再設定時のエラーをレスポンスに変換します
+func userTokenError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, auth.ErrWeakPassword):
+		return weakPassword(c)
+	case errors.Is(err, service.ErrInvalidUserToken):
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error":   "invalid_token",
+			"message": "Token is invalid, already used or expired",
+		})
+	}
+	log.Printf("[Controller] エラー: パスワード設定に失敗しました: %v", err)
+	return c.JSON(http.StatusInternalServerError, map[string]string{
+		"error":   "internal_error",
+		"message": "Failed to set password",
+	})
+}
+
+// weakPassword はパスワードポリシー違反のレスポンスを返します
+func weakPassword(c echo.Context) error {
+	return c.JSON(http.StatusBadRequest, map[string]string{
+		"error": fmt.Sprintf("パスワードは%d文字以上で指定してください", auth.MinPasswordLength),
+	})
+}
+
+// principalID は認証済みユーザーのIDを返します（未認証の場合は nil）
+func principalID(c echo.Context) *string {
+	p := auth.CurrentPrincipal(c)
+	if p == nil {
+		return nil
+	}
+	return &p.UserID
+}
+
+// currentTenantID はリクエスト中の利用者が所属するテナントの ID を返します
+func currentTenantID(c echo.Context) string {
+	p := auth.CurrentPrincipal(c)
+	if p == nil {
+		return ""
+	}
+	return p.TenantID
+}
+
+// accessScope は利用者が required の権限で扱えるデータの範囲（権限付与）を返します
+func accessScope(c echo.Context, required string) *model.AccessScope {
+	return auth.AccessScopeFor(auth.CurrentPrincipal(c), required)
+}
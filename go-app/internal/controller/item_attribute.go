@@ -0,0 +1,95 @@
+package controller
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+
+	"go-hsm-app/internal/model"
+	"go-hsm-app/internal/repository"
+	"go-hsm-app/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+// SetItemAttribute は PUT /api/items/:id/attributes/:code リクエストを処理します
+// リクエストボディの value を属性の型に応じて検証・正規化して設定し、更新後のアイテムを返します
+func SetItemAttribute(c echo.Context) error {
+	id, code := c.Param("id"), c.Param("code")
+	log.Printf("[Controller] PUT /api/items/%s/attributes/%s - リクエスト受信", id, code)
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return invalidIfMatch(c, err)
+	}
+
+	var req struct {
+		Value interface{} `json:"value"`
+	}
+	if err := c.Bind(&req); err != nil {
+		log.Printf("[Controller] リクエストボディのパースエラー: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid_request",
+		})
+	}
+
+	item, err := service.SetItemAttribute(currentTenantID(c), accessScope(c, model.RoleOperator), id, code, req.Value, version)
+	if err != nil {
+		return respondItemAttributeError(c, id, err)
+	}
+
+	log.Printf("[Controller] 成功: 属性値を設定しました (ID: %s, code: %s)", id, code)
+	setETag(c, item.Version)
+	return c.JSON(http.StatusOK, item)
+}
+
+// DeleteItemAttribute は DELETE /api/items/:id/attributes/:code リクエストを処理します
+func DeleteItemAttribute(c echo.Context) error {
+	id, code := c.Param("id"), c.Param("code")
+	log.Printf("[Controller] DELETE /api/items/%s/attributes/%s - リクエスト受信", id, code)
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return invalidIfMatch(c, err)
+	}
+
+	item, err := service.DeleteItemAttribute(currentTenantID(c), accessScope(c, model.RoleOperator), id, code, version)
+	if err != nil {
+		return respondItemAttributeError(c, id, err)
+	}
+
+	log.Printf("[Controller] 成功: 属性値を削除しました (ID: %s, code: %s)", id, code)
+	setETag(c, item.Version)
+	return c.JSON(http.StatusOK, item)
+}
+
+// respondItemAttributeError は属性値の設定・削除のエラーレスポンスを返します
+func respondItemAttributeError(c echo.Context, itemID string, err error) error {
+	if handled, resp := respondValidationError(c, err); handled {
+		return resp
+	}
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error":   "not_found",
+			"message": "Item not found",
+		})
+	case errors.Is(err, service.ErrItemAttributeNotSet):
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error":   "not_found",
+			"message": err.Error(),
+		})
+	case errors.Is(err, repository.ErrVersionConflict):
+		current, fetchErr := service.GetItemByID(currentTenantID(c), accessScope(c, model.RoleViewer), itemID)
+		if fetchErr == nil {
+			return respondVersionConflict(c, current, current.Version)
+		}
+		log.Printf("[Controller] エラー: 競合時のアイテム取得に失敗しました: %v", fetchErr)
+	}
+	log.Printf("[Controller] エラー: 属性値の更新に失敗しました: %v", err)
+	return c.JSON(http.StatusInternalServerError, map[string]string{
+		"error":   "internal_error",
+		"message": "Failed to update item attribute",
+	})
+}
@@ -0,0 +1,101 @@
+package controller
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+
+	"go-hsm-app/internal/auth"
+	"go-hsm-app/internal/model"
+	"go-hsm-app/internal/repository"
+	"go-hsm-app/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+// MergeItem は POST /api/items/:id/merge リクエストを処理します
+// source_id のアイテムを :id のアイテムに統合します（入出庫履歴・在庫・属性値を付け替え、統合元は論理削除）。
+// 一方だけがロケーション別在庫を持つ場合は、もう一方の在庫数量を計上するロケーションを unlocated_location_id に指定します。
+// dry_run に true を指定した場合は変更を保存せず、統合した場合の結果を返します
+func MergeItem(c echo.Context) error {
+	id := c.Param("id")
+	log.Printf("[Controller] POST /api/items/%s/merge - リクエスト受信", id)
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return invalidIfMatch(c, err)
+	}
+
+	var req struct {
+		SourceID            string  `json:"source_id"`
+		UnlocatedLocationID *string `json:"unlocated_location_id"`
+		DryRun              bool    `json:"dry_run"`
+	}
+	if err := c.Bind(&req); err != nil {
+		log.Printf("[Controller] エラー: リクエストのバインドに失敗しました: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error":   "invalid_request",
+			"message": "Invalid request body",
+		})
+	}
+
+	result, err := service.MergeItems(currentTenantID(c), accessScope(c, model.RoleAdmin), req.SourceID, id, req.UnlocatedLocationID, version, req.DryRun, principalID(c))
+	if err != nil {
+		if handled, resp := respondValidationError(c, err); handled {
+			return resp
+		}
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error":   "not_found",
+				"message": "Item not found",
+			})
+		case errors.Is(err, repository.ErrLocationNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error":   "not_found",
+				"message": "Location not found",
+			})
+		case errors.Is(err, repository.ErrAccessDenied):
+			return auth.Forbidden(c, "")
+		case errors.Is(err, repository.ErrInsufficientStock):
+			return c.JSON(http.StatusConflict, map[string]string{
+				"error":   "insufficient_stock",
+				"message": "Merged stock at the location would be negative",
+			})
+		case errors.Is(err, service.ErrInvalidItemMerge),
+			errors.Is(err, repository.ErrMergeSourceNotFound),
+			errors.Is(err, repository.ErrUnlocatedStock):
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error":   "invalid_request",
+				"message": err.Error(),
+			})
+		case errors.Is(err, repository.ErrUnitNotFound),
+			errors.Is(err, repository.ErrIncompatibleUnit):
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error":   "incompatible_unit",
+				"message": err.Error(),
+			})
+		case errors.Is(err, repository.ErrVersionConflict):
+			current, fetchErr := service.GetItemByID(currentTenantID(c), accessScope(c, model.RoleViewer), id)
+			if fetchErr == nil {
+				log.Printf("[Controller] 競合: アイテムは他の更新により変更されています (ID: %s, version: %d)", id, current.Version)
+				return respondVersionConflict(c, current, current.Version)
+			}
+			log.Printf("[Controller] エラー: 競合時のアイテム取得に失敗しました: %v", fetchErr)
+		}
+		log.Printf("[Controller] エラー: アイテムの統合に失敗しました: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error":   "internal_error",
+			"message": "Failed to merge items",
+		})
+	}
+
+	if result.DryRun {
+		log.Printf("[Controller] 成功: アイテムの統合をプレビューしました (%s → %s)", result.SourceID, id)
+		return c.JSON(http.StatusOK, result)
+	}
+	log.Printf("[Controller] 成功: アイテムを統合しました (%s → %s)", result.SourceID, id)
+	setETag(c, result.Item.Version)
+	return c.JSON(http.StatusOK, result)
+}
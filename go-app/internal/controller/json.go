@@ -0,0 +1,32 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// JSONSerializer はリクエストボディの数値を float64 ではなく json.Number として読み込む Echo の JSON シリアライザです。
+// 属性値などの数値を桁の丸めなしに扱うため、main で Echo に設定します
+type JSONSerializer struct {
+	echo.DefaultJSONSerializer
+}
+
+// Deserialize はリクエストボディを i に読み込みます（interface{} の数値は json.Number になります）
+func (JSONSerializer) Deserialize(c echo.Context, i interface{}) error {
+	decoder := json.NewDecoder(c.Request().Body)
+	decoder.UseNumber()
+	err := decoder.Decode(i)
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &typeErr):
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Unmarshal type error: expected=%v, got=%v, field=%v, offset=%v", typeErr.Type, typeErr.Value, typeErr.Field, typeErr.Offset)).SetInternal(err)
+	case errors.As(err, &syntaxErr):
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Syntax error: offset=%v, error=%v", syntaxErr.Offset, syntaxErr.Error())).SetInternal(err)
+	}
+	return err
+}
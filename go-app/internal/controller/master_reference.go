@@ -0,0 +1,37 @@
+package controller
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"go-hsm-app/internal/repository"
+
+	"github.com/labstack/echo/v4"
+)
+
+// respondMasterDeleteError はマスタ削除の 404・409・400 レスポンスを返します
+// アイテムから参照されている場合は 409 とともに参照元のアイテムを返します。該当しないエラーは handled = false を返します
+func respondMasterDeleteError(c echo.Context, err error, notFoundMessage string) (handled bool, resp error) {
+	var inUse *repository.MasterInUseError
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return true, c.JSON(http.StatusNotFound, map[string]string{
+			"error":   "not_found",
+			"message": notFoundMessage,
+		})
+	case errors.As(err, &inUse):
+		return true, c.JSON(http.StatusConflict, map[string]interface{}{
+			"error":   "master_in_use",
+			"message": "Referenced by items. Specify reassign_to to move the references to another master",
+			"items":   inUse.Items,
+			"total":   inUse.Total,
+		})
+	case errors.Is(err, repository.ErrInvalidReplacement):
+		return true, c.JSON(http.StatusBadRequest, map[string]string{
+			"error":   "invalid_request",
+			"message": err.Error(),
+		})
+	}
+	return false, nil
+}
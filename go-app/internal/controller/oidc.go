@@ -0,0 +1,142 @@
+package controller
+
+import (
+	"crypto/subtle"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"go-hsm-app/internal/auth"
+	"go-hsm-app/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+// oidcStateCookie は OIDC ログインを開始したブラウザに state を保存する Cookie の名前です
+const oidcStateCookie = "oidc_state"
+
+// setOIDCStateCookie は state を保存する Cookie を設定します。expires がゼロ値の場合は Cookie を削除します
+// コールバックで照合し、他のブラウザで開始したログインを完了させられない（ログイン CSRF）ようにします
+func setOIDCStateCookie(c echo.Context, state string, expires time.Time) {
+	cookie := &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    state,
+		Path:     "/api/auth/oidc",
+		HttpOnly: true,
+		Secure:   c.Scheme() == "https",
+		// IdP からのリダイレクト（トップレベルの GET）では送信されるよう Lax にする
+		SameSite: http.SameSiteLaxMode,
+	}
+	if expires.IsZero() {
+		cookie.MaxAge = -1
+	} else {
+		cookie.Expires = expires
+		cookie.MaxAge = int(time.Until(expires).Seconds())
+	}
+	c.SetCookie(cookie)
+}
+
+// OIDCLogin は GET /api/auth/oidc/login リクエストを処理します
+// IdP の認可エンドポイントへリダイレクトします
+func OIDCLogin(c echo.Context) error {
+	log.Printf("[Controller] GET /api/auth/oidc/login - リクエスト受信")
+
+	request, err := service.StartOIDCLogin(c.Request().Context())
+	if err != nil {
+		if errors.Is(err, auth.ErrOIDCDisabled) {
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error":   "oidc_disabled",
+				"message": "OIDC login is not configured",
+			})
+		}
+		log.Printf("[Controller] エラー: OIDC ログインの開始に失敗しました: %v", err)
+		return c.JSON(http.StatusBadGateway, map[string]string{
+			"error":   "oidc_unavailable",
+			"message": "Failed to start OIDC login",
+		})
+	}
+
+	setOIDCStateCookie(c, request.State, request.ExpiresAt)
+	return c.Redirect(http.StatusFound, request.AuthURL)
+}
+
+// OIDCCallback は GET /api/auth/oidc/callback リクエストを処理します
+// state を開始時の Cookie と照合したうえで認可コードを検証し、ログインと同じ形式でトークンを返却します
+func OIDCCallback(c echo.Context) error {
+	log.Printf("[Controller] GET /api/auth/oidc/callback - リクエスト受信")
+
+	if idpErr := c.QueryParam("error"); idpErr != "" {
+		log.Printf("[Controller] IdP がエラーを返しました: %s (%s)", idpErr, c.QueryParam("error_description"))
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error":   "oidc_error",
+			"message": idpErr,
+		})
+	}
+
+	state := c.QueryParam("state")
+	code := c.QueryParam("code")
+	if state == "" || code == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error":   "invalid_request",
+			"message": "state and code are required",
+		})
+	}
+
+	// state は開始したブラウザの Cookie と一致する場合のみ受け付ける
+	cookie, err := c.Cookie(oidcStateCookie)
+	setOIDCStateCookie(c, "", time.Time{})
+	if err != nil || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(state)) != 1 {
+		log.Printf("[Controller] OIDC: state が開始したブラウザの Cookie と一致しません")
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error":   "invalid_state",
+			"message": "Login request is invalid, already used or expired",
+		})
+	}
+
+	tokens, user, err := service.CompleteOIDCLogin(c.Request().Context(), state, code, clientInfo(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrOIDCStateInvalid):
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error":   "invalid_state",
+				"message": "Login request is invalid, already used or expired",
+			})
+		case errors.Is(err, auth.ErrOIDCEmailMissing):
+			return c.JSON(http.StatusUnauthorized, map[string]string{
+				"error":   "email_not_verified",
+				"message": "The identity provider did not return a verified email",
+			})
+		case errors.Is(err, service.ErrOIDCUserNotProvisioned):
+			return c.JSON(http.StatusForbidden, map[string]string{
+				"error":   "user_not_registered",
+				"message": "No user is registered for this email",
+			})
+		case errors.Is(err, auth.ErrInvalidToken):
+			log.Printf("[Controller] OIDC 認証失敗: %v", err)
+			return c.JSON(http.StatusUnauthorized, map[string]string{
+				"error":   "invalid_token",
+				"message": "Failed to verify the identity provider response",
+			})
+		case errors.Is(err, auth.ErrOIDCDisabled):
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error":   "oidc_disabled",
+				"message": "OIDC login is not configured",
+			})
+		}
+		log.Printf("[Controller] エラー: OIDC ログインに失敗しました: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error":   "internal_error",
+			"message": "Failed to login",
+		})
+	}
+
+	log.Printf("[Controller] 成功: OIDC でログインしました (ID: %s)", user.ID)
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"access_token":  tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"token_type":    tokens.TokenType,
+		"expires_in":    tokens.ExpiresIn,
+		"user":          user,
+	})
+}
@@ -0,0 +1,35 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestOIDCCallbackRejectsStateFromAnotherBrowser(t *testing.T) {
+	for name, cookie := range map[string]*http.Cookie{
+		"no cookie":      nil,
+		"other browsers": {Name: oidcStateCookie, Value: "state-of-another-login"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/auth/oidc/callback?state=attacker-state&code=attacker-code", nil)
+			if cookie != nil {
+				req.AddCookie(cookie)
+			}
+			rec := httptest.NewRecorder()
+
+			if err := OIDCCallback(echo.New().NewContext(req, rec)); err != nil {
+				t.Fatal(err)
+			}
+			if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "invalid_state") {
+				t.Fatalf("expected 400 invalid_state, got %d %s", rec.Code, rec.Body.String())
+			}
+			if !strings.Contains(rec.Header().Get("Set-Cookie"), oidcStateCookie+"=;") {
+				t.Fatalf("expected the state cookie to be cleared, got %q", rec.Header().Get("Set-Cookie"))
+			}
+		})
+	}
+}
@@ -0,0 +1,294 @@
+package controller
+
+import (
+	"database/sql"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+
+	"go-hsm-app/internal/auth"
+	"go-hsm-app/internal/model"
+	"go-hsm-app/internal/repository"
+	"go-hsm-app/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+// maxMergePatchSize は PATCH のリクエストボディの上限（バイト）です
+const maxMergePatchSize = 1 << 20
+
+var (
+	// errUnsupportedPatchType は PATCH の Content-Type が JSON Merge Patch でない場合のエラーです
+	errUnsupportedPatchType = errors.New("Content-Type must be application/merge-patch+json or application/json")
+	// errPatchTooLarge は PATCH のリクエストボディが上限を超えた場合のエラーです
+	errPatchTooLarge = errors.New("request body must be at most 1 MiB")
+)
+
+// readMergePatch はリクエストボディを JSON Merge Patch（RFC 7396）として読み込みます
+// 上限（maxMergePatchSize）を超える場合は途中で切り詰めずに errPatchTooLarge を返します
+func readMergePatch(c echo.Context) ([]byte, error) {
+	mediaType, _, err := mime.ParseMediaType(c.Request().Header.Get(echo.HeaderContentType))
+	if err != nil || (mediaType != "application/merge-patch+json" && mediaType != echo.MIMEApplicationJSON) {
+		return nil, errUnsupportedPatchType
+	}
+	patch, err := io.ReadAll(http.MaxBytesReader(c.Response(), c.Request().Body, maxMergePatchSize))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return nil, errPatchTooLarge
+	}
+	return patch, err
+}
+
+// respondPatchError は PATCH に共通のエラー（Content-Type・サイズ・パッチ内容・入力項目・コードやメールアドレスの重複・競合・未存在）のレスポンスを返します
+// current は競合時に現在の状態を取得する関数です。共通のエラーでない場合は handled = false を返します
+func respondPatchError(c echo.Context, err error, current func() (interface{}, int, error)) (handled bool, resp error) {
+	if handled, resp := respondValidationError(c, err); handled {
+		return true, resp
+	}
+	if handled, resp := respondDuplicateCode(c, err, "The code is already in use"); handled {
+		return true, resp
+	}
+	if handled, resp := respondDuplicateEmail(c, err); handled {
+		return true, resp
+	}
+	switch {
+	case errors.Is(err, errPatchTooLarge):
+		return true, c.JSON(http.StatusRequestEntityTooLarge, map[string]string{
+			"error":   "payload_too_large",
+			"message": err.Error(),
+		})
+	case errors.Is(err, errUnsupportedPatchType):
+		return true, c.JSON(http.StatusUnsupportedMediaType, map[string]string{
+			"error":   "unsupported_media_type",
+			"message": err.Error(),
+		})
+	case errors.Is(err, service.ErrInvalidPatch):
+		return true, c.JSON(http.StatusBadRequest, map[string]string{
+			"error":   "invalid_request",
+			"message": err.Error(),
+		})
+	case errors.Is(err, sql.ErrNoRows):
+		return true, c.JSON(http.StatusNotFound, map[string]string{
+			"error":   "not_found",
+			"message": "Resource not found",
+		})
+	case errors.Is(err, repository.ErrVersionConflict):
+		state, version, fetchErr := current()
+		if fetchErr != nil {
+			log.Printf("[Controller] エラー: 競合時の現在の状態の取得に失敗しました: %v", fetchErr)
+			return false, nil
+		}
+		log.Printf("[Controller] 競合: %s は他の更新により変更されています (version: %d)", c.Request().URL.Path, version)
+		return true, respondVersionConflict(c, state, version)
+	}
+	return false, nil
+}
+
+// PatchItem は PATCH /api/items/:id リクエストを処理します
+// 指定したフィールドのみ更新し、null を指定したフィールド（category_id, quantity, unit_price）はクリアします
+func PatchItem(c echo.Context) error {
+	id := c.Param("id")
+	log.Printf("[Controller] PATCH /api/items/%s - リクエスト受信", id)
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return invalidIfMatch(c, err)
+	}
+
+	patch, err := readMergePatch(c)
+	if err == nil {
+		var item *model.Item
+		item, err = service.PatchItem(currentTenantID(c), accessScope(c, model.RoleOperator), id, patch, version)
+		if err == nil {
+			log.Printf("[Controller] 成功: アイテムを更新しました (ID: %s)", id)
+			setETag(c, item.Version)
+			return c.JSON(http.StatusOK, item)
+		}
+	}
+
+	if errors.Is(err, repository.ErrItemUnitInUse) {
+		return respondItemUnitInUse(c)
+	}
+	if errors.Is(err, repository.ErrAccessDenied) {
+		return auth.Forbidden(c, "")
+	}
+	if handled, resp := respondPatchError(c, err, func() (interface{}, int, error) {
+		item, err := service.GetItemByID(currentTenantID(c), accessScope(c, model.RoleViewer), id)
+		if err != nil {
+			return nil, 0, err
+		}
+		return item, item.Version, nil
+	}); handled {
+		return resp
+	}
+	log.Printf("[Controller] エラー: アイテム更新に失敗しました: %v", err)
+	return c.JSON(http.StatusInternalServerError, map[string]string{
+		"error":   "internal_error",
+		"message": "Failed to update item",
+	})
+}
+
+// PatchCategory は PATCH /api/categories/:id リクエストを処理します
+func PatchCategory(c echo.Context) error {
+	id := c.Param("id")
+	log.Printf("[Controller] PATCH /api/categories/%s - リクエスト受信", id)
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return invalidIfMatch(c, err)
+	}
+
+	patch, err := readMergePatch(c)
+	if err == nil {
+		var category *model.Category
+		category, err = service.PatchCategory(currentTenantID(c), id, patch, version)
+		if err == nil {
+			log.Printf("[Controller] 成功: カテゴリを更新しました (ID: %s)", id)
+			setETag(c, category.Version)
+			return c.JSON(http.StatusOK, category)
+		}
+	}
+
+	if handled, resp := respondPatchError(c, err, func() (interface{}, int, error) {
+		category, err := service.GetCategoryByID(currentTenantID(c), id)
+		if err != nil {
+			return nil, 0, err
+		}
+		return category, category.Version, nil
+	}); handled {
+		return resp
+	}
+	log.Printf("[Controller] エラー: カテゴリ更新に失敗しました: %v", err)
+	return c.JSON(http.StatusInternalServerError, map[string]string{
+		"error": "カテゴリの更新に失敗しました",
+	})
+}
+
+// PatchUnit は PATCH /api/units/:id リクエストを処理します
+func PatchUnit(c echo.Context) error {
+	id := c.Param("id")
+	log.Printf("[Controller] PATCH /api/units/%s - リクエスト受信", id)
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return invalidIfMatch(c, err)
+	}
+
+	patch, err := readMergePatch(c)
+	if err == nil {
+		var unit *model.Unit
+		unit, err = service.PatchUnit(currentTenantID(c), id, patch, version)
+		if err == nil {
+			log.Printf("[Controller] 成功: 単位を更新しました (ID: %s)", id)
+			setETag(c, unit.Version)
+			return c.JSON(http.StatusOK, unit)
+		}
+	}
+
+	if handled, resp := respondPatchError(c, err, func() (interface{}, int, error) {
+		unit, err := service.GetUnitByID(currentTenantID(c), id)
+		if err != nil {
+			return nil, 0, err
+		}
+		return unit, unit.Version, nil
+	}); handled {
+		return resp
+	}
+	log.Printf("[Controller] エラー: 単位更新に失敗しました: %v", err)
+	return c.JSON(http.StatusInternalServerError, map[string]string{
+		"error": "単位の更新に失敗しました",
+	})
+}
+
+// PatchAttribute は PATCH /api/attributes/:id リクエストを処理します
+func PatchAttribute(c echo.Context) error {
+	id := c.Param("id")
+	log.Printf("[Controller] PATCH /api/attributes/%s - リクエスト受信", id)
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return invalidIfMatch(c, err)
+	}
+
+	patch, err := readMergePatch(c)
+	if err == nil {
+		var attribute *model.Attribute
+		attribute, err = service.PatchAttribute(currentTenantID(c), id, patch, version)
+		if err == nil {
+			log.Printf("[Controller] 成功: 属性を更新しました (ID: %s)", id)
+			setETag(c, attribute.Version)
+			return c.JSON(http.StatusOK, attribute)
+		}
+	}
+
+	if errors.Is(err, repository.ErrValueTypeInUse) {
+		return respondValueTypeInUse(c)
+	}
+	if handled, resp := respondPatchError(c, err, func() (interface{}, int, error) {
+		attribute, err := service.GetAttributeByID(currentTenantID(c), id)
+		if err != nil {
+			return nil, 0, err
+		}
+		return attribute, attribute.Version, nil
+	}); handled {
+		return resp
+	}
+	log.Printf("[Controller] エラー: 属性更新に失敗しました: %v", err)
+	return c.JSON(http.StatusInternalServerError, map[string]string{
+		"error": "属性の更新に失敗しました",
+	})
+}
+
+// PatchUser は PATCH /api/users/:id リクエストを処理します
+func PatchUser(c echo.Context) error {
+	id := c.Param("id")
+	log.Printf("[Controller] PATCH /api/users/%s - リクエスト受信", id)
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return invalidIfMatch(c, err)
+	}
+
+	patch, err := readMergePatch(c)
+	if err == nil {
+		var user *model.User
+		user, err = service.PatchUser(currentTenantID(c), id, patch, version)
+		if err == nil {
+			log.Printf("[Controller] 成功: ユーザーを更新しました (ID: %s)", id)
+			setETag(c, user.Version)
+			return c.JSON(http.StatusOK, user)
+		}
+	}
+
+	if handled, resp := respondPatchError(c, err, func() (interface{}, int, error) {
+		user, err := service.GetUserByID(currentTenantID(c), id)
+		if err != nil {
+			return nil, 0, err
+		}
+		return user, user.Version, nil
+	}); handled {
+		return resp
+	}
+	log.Printf("[Controller] エラー: ユーザー更新に失敗しました: %v", err)
+	return c.JSON(http.StatusInternalServerError, map[string]string{
+		"error": "ユーザーの更新に失敗しました",
+	})
+}
+
+// respondValueTypeInUse は属性値がある属性の型を変更しようとした場合の 409 レスポンスを返します
+func respondValueTypeInUse(c echo.Context) error {
+	return c.JSON(http.StatusConflict, map[string]string{
+		"error":   "value_type_in_use",
+		"message": "value_type cannot be changed while items or category templates have values for the attribute",
+	})
+}
+
+// respondItemUnitInUse は在庫や入出庫履歴があるアイテムの単位を変更しようとした場合の 409 レスポンスを返します
+func respondItemUnitInUse(c echo.Context) error {
+	return c.JSON(http.StatusConflict, map[string]string{
+		"error":   "unit_in_use",
+		"message": "unit_id cannot be changed while the item has stock or stock history",
+	})
+}
@@ -0,0 +1,25 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestPatchRejectsOversizedBody(t *testing.T) {
+	body := `{"name":"` + strings.Repeat("a", maxMergePatchSize) + `"}`
+	req := httptest.NewRequest(http.MethodPatch, "/api/items/I1", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, "application/merge-patch+json")
+	req.Header.Set("If-Match", `"1"`)
+	rec := httptest.NewRecorder()
+
+	if err := PatchItem(echo.New().NewContext(req, rec)); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusRequestEntityTooLarge || !strings.Contains(rec.Body.String(), "payload_too_large") {
+		t.Fatalf("expected 413 payload_too_large, got %d %s", rec.Code, rec.Body.String())
+	}
+}
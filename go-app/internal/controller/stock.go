@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+
+	"go-hsm-app/internal/auth"
+	"go-hsm-app/internal/model"
+	"go-hsm-app/internal/repository"
+	"go-hsm-app/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+// CreateStockMovement は POST /api/stock-movements リクエストを処理します
+// 入出庫を記録し、ロケーション別の在庫を更新します
+func CreateStockMovement(c echo.Context) error {
+	log.Printf("[Controller] POST /api/stock-movements - リクエスト受信")
+
+	var req model.StockMovement
+	if err := c.Bind(&req); err != nil {
+		log.Printf("[Controller] エラー: リクエストのバインドに失敗しました: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error":   "invalid_request",
+			"message": "Invalid request body",
+		})
+	}
+	req.CreatedBy = principalID(c)
+
+	history, err := service.CreateStockMovement(currentTenantID(c), accessScope(c, model.RoleOperator), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidStockMovement),
+			errors.Is(err, repository.ErrUnlocatedStock):
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error":   "invalid_request",
+				"message": err.Error(),
+			})
+		case errors.Is(err, sql.ErrNoRows):
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error":   "not_found",
+				"message": "Item not found",
+			})
+		case errors.Is(err, repository.ErrLocationNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error":   "not_found",
+				"message": "Location not found",
+			})
+		case errors.Is(err, repository.ErrUnitNotFound),
+			errors.Is(err, repository.ErrIncompatibleUnit):
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error":   "incompatible_unit",
+				"message": err.Error(),
+			})
+		case errors.Is(err, repository.ErrAccessDenied):
+			return auth.Forbidden(c, "")
+		case errors.Is(err, repository.ErrInsufficientStock):
+			return c.JSON(http.StatusConflict, map[string]string{
+				"error":   "insufficient_stock",
+				"message": "Stock at the source location is insufficient",
+			})
+		}
+		log.Printf("[Controller] エラー: 入出庫の記録に失敗しました: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error":   "internal_error",
+			"message": "Failed to record stock movement",
+		})
+	}
+
+	log.Printf("[Controller] 成功: 入出庫を記録しました (ID: %s)", history.ID)
+	return c.JSON(http.StatusCreated, history)
+}
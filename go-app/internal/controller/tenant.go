@@ -0,0 +1,82 @@
+package controller
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"go-hsm-app/internal/repository"
+	"go-hsm-app/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GetCurrentTenant は GET /api/tenant リクエストを処理します
+// 利用者が所属するテナントを返します
+func GetCurrentTenant(c echo.Context) error {
+	log.Printf("[Controller] GET /api/tenant - リクエスト受信")
+
+	tenant, err := service.GetTenant(currentTenantID(c))
+	if err != nil {
+		log.Printf("[Controller] エラー: テナント取得に失敗しました: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error":   "internal_error",
+			"message": "Failed to fetch tenant",
+		})
+	}
+
+	return c.JSON(http.StatusOK, tenant)
+}
+
+// CreateTenant は POST /api/tenants リクエストを処理します
+// テナントと管理者ユーザーを作成し、管理者に招待メールを送信します
+func CreateTenant(c echo.Context) error {
+	log.Printf("[Controller] POST /api/tenants - リクエスト受信")
+
+	var req struct {
+		Code       string `json:"code"`
+		Name       string `json:"name"`
+		AdminEmail string `json:"admin_email"`
+	}
+
+	if err := c.Bind(&req); err != nil {
+		log.Printf("[Controller] エラー: リクエストのバインドに失敗しました: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error":   "invalid_request",
+			"message": "Invalid request body",
+		})
+	}
+
+	tenant, admin, err := service.CreateTenant(req.Code, req.Name, req.AdminEmail, principalID(c))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidTenant) {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error":   "invalid_request",
+				"message": err.Error(),
+			})
+		}
+		if errors.Is(err, repository.ErrDuplicateCode) {
+			return c.JSON(http.StatusConflict, map[string]string{
+				"error":   "conflict",
+				"message": "Tenant code is already in use",
+			})
+		}
+		if errors.Is(err, repository.ErrDuplicateEmail) {
+			return c.JSON(http.StatusConflict, map[string]string{
+				"error":   "conflict",
+				"message": "Admin email is already in use",
+			})
+		}
+		log.Printf("[Controller] エラー: テナント作成に失敗しました: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error":   "internal_error",
+			"message": "Failed to create tenant",
+		})
+	}
+
+	log.Printf("[Controller] 成功: テナントを作成しました (ID: %s, admin: %s)", tenant.ID, admin.ID)
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"tenant": tenant,
+		"admin":  admin,
+	})
+}
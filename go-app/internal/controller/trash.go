@@ -0,0 +1,168 @@
+package controller
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"go-hsm-app/internal/model"
+	"go-hsm-app/internal/repository"
+	"go-hsm-app/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GetTrash は GET /api/trash リクエストを処理します
+// 論理削除したアイテム・マスタを削除日時の新しい順に返します（resource で種別を指定可、page・limit でページネーション）
+func GetTrash(c echo.Context) error {
+	resource := c.QueryParam("resource")
+	log.Printf("[Controller] GET /api/trash - リクエスト受信 (resource: %s)", resource)
+
+	page := 1
+	if pageNum, err := strconv.Atoi(c.QueryParam("page")); err == nil && pageNum > 0 {
+		page = pageNum
+	}
+	limit := service.DefaultTrashLimit
+	if v := c.QueryParam("limit"); v != "" {
+		limitNum, err := strconv.Atoi(v)
+		if err != nil || limitNum <= 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error":   "invalid_request",
+				"message": "limit must be a positive integer",
+			})
+		}
+		limit = limitNum
+	}
+
+	entries, total, err := service.GetTrash(currentTenantID(c), accessScope(c, model.RoleOperator), resource, limit, (page-1)*limit)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidTrashResource) {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error":   "invalid_request",
+				"message": err.Error(),
+			})
+		}
+		log.Printf("[Controller] エラー: ゴミ箱の取得に失敗しました: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error":   "internal_error",
+			"message": "Failed to fetch trash",
+		})
+	}
+
+	log.Printf("[Controller] 成功: %d件のゴミ箱のデータを取得しました (total: %d)", len(entries), total)
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"entries": entries,
+		"total":   total,
+		"page":    page,
+		"limit":   limit,
+	})
+}
+
+// RestoreItem は POST /api/items/:id/restore リクエストを処理します
+func RestoreItem(c echo.Context) error {
+	id := c.Param("id")
+	log.Printf("[Controller] POST /api/items/%s/restore - リクエスト受信", id)
+
+	item, err := service.RestoreItem(currentTenantID(c), accessScope(c, model.RoleAdmin), id, principalID(c))
+	if err != nil {
+		return respondRestoreError(c, err, "Deleted item not found")
+	}
+
+	log.Printf("[Controller] 成功: アイテムを復元しました (ID: %s)", id)
+	setETag(c, item.Version)
+	return c.JSON(http.StatusOK, item)
+}
+
+// RestoreCategory は POST /api/categories/:id/restore リクエストを処理します
+func RestoreCategory(c echo.Context) error {
+	id := c.Param("id")
+	log.Printf("[Controller] POST /api/categories/%s/restore - リクエスト受信", id)
+
+	category, err := service.RestoreCategory(currentTenantID(c), id, principalID(c))
+	if err != nil {
+		return respondRestoreError(c, err, "Deleted category not found")
+	}
+
+	log.Printf("[Controller] 成功: カテゴリを復元しました (ID: %s)", id)
+	setETag(c, category.Version)
+	return c.JSON(http.StatusOK, category)
+}
+
+// RestoreUnit は POST /api/units/:id/restore リクエストを処理します
+func RestoreUnit(c echo.Context) error {
+	id := c.Param("id")
+	log.Printf("[Controller] POST /api/units/%s/restore - リクエスト受信", id)
+
+	unit, err := service.RestoreUnit(currentTenantID(c), id, principalID(c))
+	if err != nil {
+		return respondRestoreError(c, err, "Deleted unit not found")
+	}
+
+	log.Printf("[Controller] 成功: 単位を復元しました (ID: %s)", id)
+	setETag(c, unit.Version)
+	return c.JSON(http.StatusOK, unit)
+}
+
+// RestoreAttribute は POST /api/attributes/:id/restore リクエストを処理します
+func RestoreAttribute(c echo.Context) error {
+	id := c.Param("id")
+	log.Printf("[Controller] POST /api/attributes/%s/restore - リクエスト受信", id)
+
+	attribute, err := service.RestoreAttribute(currentTenantID(c), id, principalID(c))
+	if err != nil {
+		return respondRestoreError(c, err, "Deleted attribute not found")
+	}
+
+	log.Printf("[Controller] 成功: 属性を復元しました (ID: %s)", id)
+	setETag(c, attribute.Version)
+	return c.JSON(http.StatusOK, attribute)
+}
+
+// PurgeTrash は POST /api/trash/purge リクエストを処理します
+// 保持期間を過ぎたゴミ箱のデータを物理削除します。入出庫履歴・参照が残っているデータは skipped に件数を返します
+func PurgeTrash(c echo.Context) error {
+	log.Printf("[Controller] POST /api/trash/purge - リクエスト受信")
+
+	result, err := service.PurgeTrash(currentTenantID(c), principalID(c))
+	if err != nil {
+		log.Printf("[Controller] エラー: ゴミ箱の物理削除に失敗しました: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error":   "internal_error",
+			"message": "Failed to purge trash",
+		})
+	}
+
+	log.Printf("[Controller] 成功: ゴミ箱のデータを物理削除しました (before: %s)", result.Before)
+	return c.JSON(http.StatusOK, result)
+}
+
+// respondRestoreError は復元のエラーレスポンスを返します
+func respondRestoreError(c echo.Context, err error, notFoundMessage string) error {
+	var conflict *repository.CodeConflictError
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error":   "not_found",
+			"message": notFoundMessage,
+		})
+	case errors.As(err, &conflict):
+		return c.JSON(http.StatusConflict, map[string]string{
+			"error":          "code_conflict",
+			"message":        "The code is used by another record. Rename or delete it before restoring",
+			"code":           conflict.Code,
+			"conflicting_id": conflict.ConflictingID,
+		})
+	case errors.Is(err, repository.ErrRestoreDependency):
+		return c.JSON(http.StatusConflict, map[string]string{
+			"error":   "restore_dependency",
+			"message": err.Error(),
+		})
+	}
+	log.Printf("[Controller] エラー: 復元に失敗しました: %v", err)
+	return c.JSON(http.StatusInternalServerError, map[string]string{
+		"error":   "internal_error",
+		"message": "Failed to restore",
+	})
+}
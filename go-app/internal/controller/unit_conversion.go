@@ -0,0 +1,163 @@
+package controller
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+
+	"go-hsm-app/internal/model"
+	"go-hsm-app/internal/repository"
+	"go-hsm-app/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+// factorRequest は単位換算・荷姿の登録のリクエストボディです
+type factorRequest struct {
+	Factor float64 `json:"factor"`
+}
+
+// packagingRequest は荷姿の登録のリクエストボディです
+// contains_unit_id を省略した場合、入数はアイテムの基本単位での数量になります
+type packagingRequest struct {
+	Factor         float64 `json:"factor"`
+	ContainsUnitID *string `json:"contains_unit_id"`
+}
+
+// GetUnitConversions は GET /api/units/:id/conversions リクエストを処理します
+// 単位を換算元または換算先とする単位換算を返します
+func GetUnitConversions(c echo.Context) error {
+	id := c.Param("id")
+	log.Printf("[Controller] GET /api/units/%s/conversions - リクエスト受信", id)
+
+	conversions, err := service.GetUnitConversions(currentTenantID(c), id)
+	if err != nil {
+		return respondUnitConversionError(c, err, "Unit not found", "Failed to fetch unit conversions")
+	}
+
+	log.Printf("[Controller] 成功: %d件の単位換算を取得しました", len(conversions))
+	return c.JSON(http.StatusOK, conversions)
+}
+
+// SetUnitConversion は PUT /api/units/:id/conversions/:to リクエストを処理します
+// 1 :id = factor :to の単位換算を登録します（逆方向の換算が登録済みの場合は置き換えます）
+func SetUnitConversion(c echo.Context) error {
+	id, to := c.Param("id"), c.Param("to")
+	log.Printf("[Controller] PUT /api/units/%s/conversions/%s - リクエスト受信", id, to)
+
+	var req factorRequest
+	if err := c.Bind(&req); err != nil {
+		log.Printf("[Controller] エラー: リクエストのバインドに失敗しました: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error":   "invalid_request",
+			"message": "Invalid request body",
+		})
+	}
+
+	conversion, err := service.SetUnitConversion(currentTenantID(c), id, to, req.Factor)
+	if err != nil {
+		return respondUnitConversionError(c, err, "Unit not found", "Failed to set unit conversion")
+	}
+
+	log.Printf("[Controller] 成功: 単位換算を登録しました (%s -> %s)", id, to)
+	return c.JSON(http.StatusOK, conversion)
+}
+
+// DeleteUnitConversion は DELETE /api/units/:id/conversions/:to リクエストを処理します
+// 登録の向きにかかわらず、2 つの単位の間の単位換算を削除します
+func DeleteUnitConversion(c echo.Context) error {
+	id, to := c.Param("id"), c.Param("to")
+	log.Printf("[Controller] DELETE /api/units/%s/conversions/%s - リクエスト受信", id, to)
+
+	if err := service.DeleteUnitConversion(currentTenantID(c), id, to); err != nil {
+		return respondUnitConversionError(c, err, "Unit conversion not found", "Failed to delete unit conversion")
+	}
+
+	log.Printf("[Controller] 成功: 単位換算を削除しました (%s, %s)", id, to)
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetItemPackagings は GET /api/items/:id/packagings リクエストを処理します
+func GetItemPackagings(c echo.Context) error {
+	id := c.Param("id")
+	log.Printf("[Controller] GET /api/items/%s/packagings - リクエスト受信", id)
+
+	packagings, err := service.GetItemPackagings(currentTenantID(c), accessScope(c, model.RoleViewer), id)
+	if err != nil {
+		return respondUnitConversionError(c, err, "Item not found", "Failed to fetch item packagings")
+	}
+
+	log.Printf("[Controller] 成功: %d件の荷姿を取得しました", len(packagings))
+	return c.JSON(http.StatusOK, packagings)
+}
+
+// SetItemPackaging は PUT /api/items/:id/packagings/:unit リクエストを処理します
+// 荷姿の単位 1 あたりの内容物の単位（contains_unit_id、省略時は基本単位）での入数（factor）を登録します
+func SetItemPackaging(c echo.Context) error {
+	id, unit := c.Param("id"), c.Param("unit")
+	log.Printf("[Controller] PUT /api/items/%s/packagings/%s - リクエスト受信", id, unit)
+
+	var req packagingRequest
+	if err := c.Bind(&req); err != nil {
+		log.Printf("[Controller] エラー: リクエストのバインドに失敗しました: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error":   "invalid_request",
+			"message": "Invalid request body",
+		})
+	}
+
+	packaging, err := service.SetItemPackaging(currentTenantID(c), accessScope(c, model.RoleOperator), id, unit, req.ContainsUnitID, req.Factor)
+	if err != nil {
+		return respondUnitConversionError(c, err, "Item not found", "Failed to set item packaging")
+	}
+
+	log.Printf("[Controller] 成功: 荷姿を登録しました (ID: %s, unit: %s)", id, unit)
+	return c.JSON(http.StatusOK, packaging)
+}
+
+// DeleteItemPackaging は DELETE /api/items/:id/packagings/:unit リクエストを処理します
+func DeleteItemPackaging(c echo.Context) error {
+	id, unit := c.Param("id"), c.Param("unit")
+	log.Printf("[Controller] DELETE /api/items/%s/packagings/%s - リクエスト受信", id, unit)
+
+	if err := service.DeleteItemPackaging(currentTenantID(c), accessScope(c, model.RoleOperator), id, unit); err != nil {
+		return respondUnitConversionError(c, err, "Item packaging not found", "Failed to delete item packaging")
+	}
+
+	log.Printf("[Controller] 成功: 荷姿を削除しました (ID: %s, unit: %s)", id, unit)
+	return c.NoContent(http.StatusNoContent)
+}
+
+// respondUnitConversionError は単位換算・荷姿の操作のエラーレスポンスを返します
+func respondUnitConversionError(c echo.Context, err error, notFoundMessage, failureMessage string) error {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error":   "not_found",
+			"message": notFoundMessage,
+		})
+	case errors.Is(err, repository.ErrUnitNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error":   "not_found",
+			"message": "Unit not found",
+		})
+	case errors.Is(err, repository.ErrPackagingInUse):
+		return c.JSON(http.StatusConflict, map[string]string{
+			"error":   "packaging_in_use",
+			"message": "Packaging is contained in another packaging",
+		})
+	case errors.Is(err, service.ErrInvalidUnitConversion),
+		errors.Is(err, repository.ErrPackagingIsBaseUnit),
+		errors.Is(err, repository.ErrIncompatibleUnit):
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error":   "invalid_request",
+			"message": err.Error(),
+		})
+	}
+	log.Printf("[Controller] エラー: 単位換算の操作に失敗しました: %v", err)
+	return c.JSON(http.StatusInternalServerError, map[string]string{
+		"error":   "internal_error",
+		"message": failureMessage,
+	})
+}
@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"go-hsm-app/internal/repository"
+	"go-hsm-app/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+// respondValidationError は入力項目ごとのエラーを含む 400 レスポンスを返します
+// err が ValidationError でない場合は handled = false を返します
+func respondValidationError(c echo.Context, err error) (handled bool, resp error) {
+	var verr *service.ValidationError
+	if !errors.As(err, &verr) {
+		// 検証後に属性・選択肢が削除された場合
+		switch {
+		case errors.Is(err, repository.ErrAttributeNotFound):
+			verr = &service.ValidationError{Fields: []service.FieldError{
+				{Field: "attributes", Code: "unknown_attribute", Message: err.Error()},
+			}}
+		case errors.Is(err, repository.ErrAttributeOptionNotFound):
+			verr = &service.ValidationError{Fields: []service.FieldError{
+				{Field: "attributes", Code: "invalid_option", Message: err.Error()},
+			}}
+		default:
+			return false, nil
+		}
+	}
+	return true, c.JSON(http.StatusBadRequest, map[string]interface{}{
+		"error":   "validation_failed",
+		"message": "One or more fields are invalid",
+		"fields":  verr.Fields,
+	})
+}
+
+// respondDuplicateCode はコードが有効なデータと重複する場合の 409 レスポンスを返します
+// err が ErrDuplicateCode でない場合は handled = false を返します
+func respondDuplicateCode(c echo.Context, err error, message string) (handled bool, resp error) {
+	if !errors.Is(err, repository.ErrDuplicateCode) {
+		return false, nil
+	}
+	return true, c.JSON(http.StatusConflict, map[string]string{
+		"error":   "duplicate_code",
+		"message": message,
+	})
+}
+
+// respondDuplicateEmail はメールアドレスが他のユーザーと重複する場合の 409 レスポンスを返します
+// err が ErrDuplicateEmail でない場合は handled = false を返します
+func respondDuplicateEmail(c echo.Context, err error) (handled bool, resp error) {
+	if !errors.Is(err, repository.ErrDuplicateEmail) {
+		return false, nil
+	}
+	return true, c.JSON(http.StatusConflict, map[string]string{
+		"error":   "duplicate_email",
+		"message": "The email is already in use",
+	})
+}
@@ -43,6 +43,7 @@ type ResolverRoot interface {
 }
 
 type DirectiveRoot struct {
+	HasRole func(ctx context.Context, obj any, next graphql.Resolver, role model.Role) (res any, err error)
 }
 
 type ComplexityRoot struct {
@@ -54,7 +55,7 @@ type MutationResolver interface {
 	DeleteItem(ctx context.Context, id string) (bool, error)
 }
 type QueryResolver interface {
-	Items(ctx context.Context) ([]*model.Item, error)
+	Items(ctx context.Context, limit *int, filter *model.ItemFilter) ([]*model.Item, error)
 	Item(ctx context.Context, id string) (*model.Item, error)
 }
 
@@ -83,6 +84,9 @@ func (e *executableSchema) Exec(ctx context.Context) graphql.ResponseHandler {
 	opCtx := graphql.GetOperationContext(ctx)
 	ec := executionContext{opCtx, e, 0, 0, make(chan graphql.DeferredResult)}
 	inputUnmarshalMap := graphql.BuildUnmarshalerMap(
+		ec.unmarshalInputAttributeFilterInput,
+		ec.unmarshalInputAttributeValueInput,
+		ec.unmarshalInputItemFilter,
 		ec.unmarshalInputNewItem,
 		ec.unmarshalInputUpdateItem,
 	)
@@ -182,36 +186,113 @@ func (ec *executionContext) introspectType(name string) (*introspection.Type, er
 }
 
 var sources = []*ast.Source{
-	{Name: "../../schema.graphql", Input: `type Query {
-  items: [Item!]!
-  item(id: ID!): Item
+	{Name: "../schema/schema.graphql", Input: `# 認可ディレクティブ: 指定した権限以上のユーザーのみフィールドを解決できます
+directive @hasRole(role: Role!) on FIELD_DEFINITION
+
+enum Role {
+  ADMIN
+  OPERATOR
+  VIEWER
+}
+
+type Query {
+  items(limit: Int = 10, filter: ItemFilter): [Item!]! @hasRole(role: VIEWER)
+  item(id: ID!): Item @hasRole(role: VIEWER)
 }
 
 type Mutation {
-  createItem(input: NewItem!): Item!
-  updateItem(id: ID!, input: UpdateItem!): Item!
-  deleteItem(id: ID!): Boolean!
+  createItem(input: NewItem!): Item! @hasRole(role: OPERATOR)
+  updateItem(id: ID!, input: UpdateItem!): Item! @hasRole(role: OPERATOR)
+  deleteItem(id: ID!): Boolean! @hasRole(role: ADMIN)
 }
 
+# アイテムは利用者の所属テナントのデータのみ参照・更新できます
 type Item {
   id: ID!
+  code: String!
   name: String!
-  description: String
-  quantity: Int!
+  categoryId: ID
+  unitId: ID!
+  quantity: Int
+  unitPrice: Int
+  status: String!
+  # 楽観的ロック用のバージョン（updateItem の input.version に指定します）
+  version: Int!
   createdAt: String!
   updatedAt: String!
+  attributes: [ItemAttribute!]!
+}
+
+# アイテムの属性値（値は属性の型に応じた正規形の文字列）
+type ItemAttribute {
+  code: String!
+  name: String!
+  valueType: String!
+  # enum の場合は選択肢のコード
+  value: String!
+  # 選択肢の表示名（enum のみ）
+  label: String
+}
+
+# 属性コードと値の組。値は属性の型（text, number, boolean, date, enum）に応じて検証・正規化されます
+input AttributeValueInput {
+  code: String!
+  # updateItem で null を指定した属性は削除します
+  value: String
+}
+
+# アイテム一覧の絞り込み条件（指定した条件を全て満たすもの）
+input ItemFilter {
+  attributes: [AttributeFilterInput!]
+}
+
+# 属性値の比較演算子。値は属性の型で比較します（number は数値、date は日付として大小を比較）
+enum AttributeFilterOperator {
+  EQ
+  # 属性値が未設定のアイテムも含みます
+  NE
+  # number, date のみ
+  LT
+  # number, date のみ
+  GT
+  # 下限以上・上限以下（values に下限・上限の 2 つを指定。number, date のみ）
+  BETWEEN
+  # 大文字小文字を区別しない部分一致（text のみ）
+  CONTAINS
+  # values のいずれかに等しい
+  IN
+}
+
+input AttributeFilterInput {
+  code: String!
+  op: AttributeFilterOperator! = EQ
+  values: [String!]!
 }
 
 input NewItem {
+  code: String!
   name: String!
-  description: String
-  quantity: Int!
+  categoryId: ID
+  unitId: ID!
+  quantity: Int
+  unitPrice: Int
+  attributes: [AttributeValueInput!]
 }
 
+# 指定したフィールドのみ更新します
+# version は必須です。現在のバージョンと一致するときのみ更新し、一致しなければ VERSION_CONFLICT エラー、
+# 指定がなければ PRECONDITION_REQUIRED エラーになります
 input UpdateItem {
+  code: String
   name: String
-  description: String
+  categoryId: ID
+  unitId: ID
   quantity: Int
+  unitPrice: Int
+  status: String
+  # 指定した属性のみ変更します
+  attributes: [AttributeValueInput!]
+  version: Int
 }
 `, BuiltIn: false},
 }
@@ -221,10 +302,21 @@ var parsedSchema = gqlparser.MustLoadSchema(sources...)
 
 // region    ***************************** args.gotpl *****************************
 
+func (ec *executionContext) dir_hasRole_args(ctx context.Context, rawArgs map[string]any) (map[string]any, error) {
+	var err error
+	args := map[string]any{}
+	arg0, err := graphql.ProcessArgField(ctx, rawArgs, "role", ec.unmarshalNRole2goᚑhsmᚑappᚋinternalᚋlibᚋgraphᚋmodelᚐRole)
+	if err != nil {
+		return nil, err
+	}
+	args["role"] = arg0
+	return args, nil
+}
+
 func (ec *executionContext) field_Mutation_createItem_args(ctx context.Context, rawArgs map[string]any) (map[string]any, error) {
 	var err error
 	args := map[string]any{}
-	arg0, err := graphql.ProcessArgField(ctx, rawArgs, "input", ec.unmarshalNNewItem2goᚑhsmᚑappᚋgraphᚋmodelᚐNewItem)
+	arg0, err := graphql.ProcessArgField(ctx, rawArgs, "input", ec.unmarshalNNewItem2goᚑhsmᚑappᚋinternalᚋlibᚋgraphᚋmodelᚐNewItem)
 	if err != nil {
 		return nil, err
 	}
@@ -251,7 +343,7 @@ func (ec *executionContext) field_Mutation_updateItem_args(ctx context.Context,
 		return nil, err
 	}
 	args["id"] = arg0
-	arg1, err := graphql.ProcessArgField(ctx, rawArgs, "input", ec.unmarshalNUpdateItem2goᚑhsmᚑappᚋgraphᚋmodelᚐUpdateItem)
+	arg1, err := graphql.ProcessArgField(ctx, rawArgs, "input", ec.unmarshalNUpdateItem2goᚑhsmᚑappᚋinternalᚋlibᚋgraphᚋmodelᚐUpdateItem)
 	if err != nil {
 		return nil, err
 	}
@@ -281,6 +373,22 @@ func (ec *executionContext) field_Query_item_args(ctx context.Context, rawArgs m
 	return args, nil
 }
 
+func (ec *executionContext) field_Query_items_args(ctx context.Context, rawArgs map[string]any) (map[string]any, error) {
+	var err error
+	args := map[string]any{}
+	arg0, err := graphql.ProcessArgField(ctx, rawArgs, "limit", ec.unmarshalOInt2ᚖint)
+	if err != nil {
+		return nil, err
+	}
+	args["limit"] = arg0
+	arg1, err := graphql.ProcessArgField(ctx, rawArgs, "filter", ec.unmarshalOItemFilter2ᚖgoᚑhsmᚑappᚋinternalᚋlibᚋgraphᚋmodelᚐItemFilter)
+	if err != nil {
+		return nil, err
+	}
+	args["filter"] = arg1
+	return args, nil
+}
+
 func (ec *executionContext) field___Directive_args_args(ctx context.Context, rawArgs map[string]any) (map[string]any, error) {
 	var err error
 	args := map[string]any{}
@@ -362,6 +470,35 @@ func (ec *executionContext) fieldContext_Item_id(_ context.Context, field graphq
 	return fc, nil
 }
 
+func (ec *executionContext) _Item_code(ctx context.Context, field graphql.CollectedField, obj *model.Item) (ret graphql.Marshaler) {
+	return graphql.ResolveField(
+		ctx,
+		ec.OperationContext,
+		field,
+		ec.fieldContext_Item_code,
+		func(ctx context.Context) (any, error) {
+			return obj.Code, nil
+		},
+		nil,
+		ec.marshalNString2string,
+		true,
+		true,
+	)
+}
+
+func (ec *executionContext) fieldContext_Item_code(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "Item",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
 func (ec *executionContext) _Item_name(ctx context.Context, field graphql.CollectedField, obj *model.Item) (ret graphql.Marshaler) {
 	return graphql.ResolveField(
 		ctx,
@@ -391,30 +528,59 @@ func (ec *executionContext) fieldContext_Item_name(_ context.Context, field grap
 	return fc, nil
 }
 
-func (ec *executionContext) _Item_description(ctx context.Context, field graphql.CollectedField, obj *model.Item) (ret graphql.Marshaler) {
+func (ec *executionContext) _Item_categoryId(ctx context.Context, field graphql.CollectedField, obj *model.Item) (ret graphql.Marshaler) {
 	return graphql.ResolveField(
 		ctx,
 		ec.OperationContext,
 		field,
-		ec.fieldContext_Item_description,
+		ec.fieldContext_Item_categoryId,
 		func(ctx context.Context) (any, error) {
-			return obj.Description, nil
+			return obj.CategoryID, nil
 		},
 		nil,
-		ec.marshalOString2ᚖstring,
+		ec.marshalOID2ᚖstring,
 		true,
 		false,
 	)
 }
 
-func (ec *executionContext) fieldContext_Item_description(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_Item_categoryId(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
 		Object:     "Item",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			return nil, errors.New("field of type String does not have child fields")
+			return nil, errors.New("field of type ID does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _Item_unitId(ctx context.Context, field graphql.CollectedField, obj *model.Item) (ret graphql.Marshaler) {
+	return graphql.ResolveField(
+		ctx,
+		ec.OperationContext,
+		field,
+		ec.fieldContext_Item_unitId,
+		func(ctx context.Context) (any, error) {
+			return obj.UnitID, nil
+		},
+		nil,
+		ec.marshalNID2string,
+		true,
+		true,
+	)
+}
+
+func (ec *executionContext) fieldContext_Item_unitId(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "Item",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type ID does not have child fields")
 		},
 	}
 	return fc, nil
@@ -430,13 +596,100 @@ func (ec *executionContext) _Item_quantity(ctx context.Context, field graphql.Co
 			return obj.Quantity, nil
 		},
 		nil,
+		ec.marshalOInt2ᚖint,
+		true,
+		false,
+	)
+}
+
+func (ec *executionContext) fieldContext_Item_quantity(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "Item",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type Int does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _Item_unitPrice(ctx context.Context, field graphql.CollectedField, obj *model.Item) (ret graphql.Marshaler) {
+	return graphql.ResolveField(
+		ctx,
+		ec.OperationContext,
+		field,
+		ec.fieldContext_Item_unitPrice,
+		func(ctx context.Context) (any, error) {
+			return obj.UnitPrice, nil
+		},
+		nil,
+		ec.marshalOInt2ᚖint,
+		true,
+		false,
+	)
+}
+
+func (ec *executionContext) fieldContext_Item_unitPrice(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "Item",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type Int does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _Item_status(ctx context.Context, field graphql.CollectedField, obj *model.Item) (ret graphql.Marshaler) {
+	return graphql.ResolveField(
+		ctx,
+		ec.OperationContext,
+		field,
+		ec.fieldContext_Item_status,
+		func(ctx context.Context) (any, error) {
+			return obj.Status, nil
+		},
+		nil,
+		ec.marshalNString2string,
+		true,
+		true,
+	)
+}
+
+func (ec *executionContext) fieldContext_Item_status(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "Item",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _Item_version(ctx context.Context, field graphql.CollectedField, obj *model.Item) (ret graphql.Marshaler) {
+	return graphql.ResolveField(
+		ctx,
+		ec.OperationContext,
+		field,
+		ec.fieldContext_Item_version,
+		func(ctx context.Context) (any, error) {
+			return obj.Version, nil
+		},
+		nil,
 		ec.marshalNInt2int,
 		true,
 		true,
 	)
 }
 
-func (ec *executionContext) fieldContext_Item_quantity(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_Item_version(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
 		Object:     "Item",
 		Field:      field,
@@ -507,6 +760,192 @@ func (ec *executionContext) fieldContext_Item_updatedAt(_ context.Context, field
 	return fc, nil
 }
 
+func (ec *executionContext) _Item_attributes(ctx context.Context, field graphql.CollectedField, obj *model.Item) (ret graphql.Marshaler) {
+	return graphql.ResolveField(
+		ctx,
+		ec.OperationContext,
+		field,
+		ec.fieldContext_Item_attributes,
+		func(ctx context.Context) (any, error) {
+			return obj.Attributes, nil
+		},
+		nil,
+		ec.marshalNItemAttribute2ᚕᚖgoᚑhsmᚑappᚋinternalᚋlibᚋgraphᚋmodelᚐItemAttributeᚄ,
+		true,
+		true,
+	)
+}
+
+func (ec *executionContext) fieldContext_Item_attributes(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "Item",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			switch field.Name {
+			case "code":
+				return ec.fieldContext_ItemAttribute_code(ctx, field)
+			case "name":
+				return ec.fieldContext_ItemAttribute_name(ctx, field)
+			case "valueType":
+				return ec.fieldContext_ItemAttribute_valueType(ctx, field)
+			case "value":
+				return ec.fieldContext_ItemAttribute_value(ctx, field)
+			case "label":
+				return ec.fieldContext_ItemAttribute_label(ctx, field)
+			}
+			return nil, fmt.Errorf("no field named %q was found under type ItemAttribute", field.Name)
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _ItemAttribute_code(ctx context.Context, field graphql.CollectedField, obj *model.ItemAttribute) (ret graphql.Marshaler) {
+	return graphql.ResolveField(
+		ctx,
+		ec.OperationContext,
+		field,
+		ec.fieldContext_ItemAttribute_code,
+		func(ctx context.Context) (any, error) {
+			return obj.Code, nil
+		},
+		nil,
+		ec.marshalNString2string,
+		true,
+		true,
+	)
+}
+
+func (ec *executionContext) fieldContext_ItemAttribute_code(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "ItemAttribute",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _ItemAttribute_name(ctx context.Context, field graphql.CollectedField, obj *model.ItemAttribute) (ret graphql.Marshaler) {
+	return graphql.ResolveField(
+		ctx,
+		ec.OperationContext,
+		field,
+		ec.fieldContext_ItemAttribute_name,
+		func(ctx context.Context) (any, error) {
+			return obj.Name, nil
+		},
+		nil,
+		ec.marshalNString2string,
+		true,
+		true,
+	)
+}
+
+func (ec *executionContext) fieldContext_ItemAttribute_name(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "ItemAttribute",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _ItemAttribute_valueType(ctx context.Context, field graphql.CollectedField, obj *model.ItemAttribute) (ret graphql.Marshaler) {
+	return graphql.ResolveField(
+		ctx,
+		ec.OperationContext,
+		field,
+		ec.fieldContext_ItemAttribute_valueType,
+		func(ctx context.Context) (any, error) {
+			return obj.ValueType, nil
+		},
+		nil,
+		ec.marshalNString2string,
+		true,
+		true,
+	)
+}
+
+func (ec *executionContext) fieldContext_ItemAttribute_valueType(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "ItemAttribute",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _ItemAttribute_value(ctx context.Context, field graphql.CollectedField, obj *model.ItemAttribute) (ret graphql.Marshaler) {
+	return graphql.ResolveField(
+		ctx,
+		ec.OperationContext,
+		field,
+		ec.fieldContext_ItemAttribute_value,
+		func(ctx context.Context) (any, error) {
+			return obj.Value, nil
+		},
+		nil,
+		ec.marshalNString2string,
+		true,
+		true,
+	)
+}
+
+func (ec *executionContext) fieldContext_ItemAttribute_value(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "ItemAttribute",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _ItemAttribute_label(ctx context.Context, field graphql.CollectedField, obj *model.ItemAttribute) (ret graphql.Marshaler) {
+	return graphql.ResolveField(
+		ctx,
+		ec.OperationContext,
+		field,
+		ec.fieldContext_ItemAttribute_label,
+		func(ctx context.Context) (any, error) {
+			return obj.Label, nil
+		},
+		nil,
+		ec.marshalOString2ᚖstring,
+		true,
+		false,
+	)
+}
+
+func (ec *executionContext) fieldContext_ItemAttribute_label(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "ItemAttribute",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
 func (ec *executionContext) _Mutation_createItem(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
 	return graphql.ResolveField(
 		ctx,
@@ -517,8 +956,26 @@ func (ec *executionContext) _Mutation_createItem(ctx context.Context, field grap
 			fc := graphql.GetFieldContext(ctx)
 			return ec.resolvers.Mutation().CreateItem(ctx, fc.Args["input"].(model.NewItem))
 		},
-		nil,
-		ec.marshalNItem2ᚖgoᚑhsmᚑappᚋgraphᚋmodelᚐItem,
+		func(ctx context.Context, next graphql.Resolver) graphql.Resolver {
+			directive0 := next
+
+			directive1 := func(ctx context.Context) (any, error) {
+				role, err := ec.unmarshalNRole2goᚑhsmᚑappᚋinternalᚋlibᚋgraphᚋmodelᚐRole(ctx, "OPERATOR")
+				if err != nil {
+					var zeroVal *model.Item
+					return zeroVal, err
+				}
+				if ec.directives.HasRole == nil {
+					var zeroVal *model.Item
+					return zeroVal, errors.New("directive hasRole is not implemented")
+				}
+				return ec.directives.HasRole(ctx, nil, directive0, role)
+			}
+
+			next = directive1
+			return next
+		},
+		ec.marshalNItem2ᚖgoᚑhsmᚑappᚋinternalᚋlibᚋgraphᚋmodelᚐItem,
 		true,
 		true,
 	)
@@ -534,16 +991,28 @@ func (ec *executionContext) fieldContext_Mutation_createItem(ctx context.Context
 			switch field.Name {
 			case "id":
 				return ec.fieldContext_Item_id(ctx, field)
+			case "code":
+				return ec.fieldContext_Item_code(ctx, field)
 			case "name":
 				return ec.fieldContext_Item_name(ctx, field)
-			case "description":
-				return ec.fieldContext_Item_description(ctx, field)
+			case "categoryId":
+				return ec.fieldContext_Item_categoryId(ctx, field)
+			case "unitId":
+				return ec.fieldContext_Item_unitId(ctx, field)
 			case "quantity":
 				return ec.fieldContext_Item_quantity(ctx, field)
+			case "unitPrice":
+				return ec.fieldContext_Item_unitPrice(ctx, field)
+			case "status":
+				return ec.fieldContext_Item_status(ctx, field)
+			case "version":
+				return ec.fieldContext_Item_version(ctx, field)
 			case "createdAt":
 				return ec.fieldContext_Item_createdAt(ctx, field)
 			case "updatedAt":
 				return ec.fieldContext_Item_updatedAt(ctx, field)
+			case "attributes":
+				return ec.fieldContext_Item_attributes(ctx, field)
 			}
 			return nil, fmt.Errorf("no field named %q was found under type Item", field.Name)
 		},
@@ -572,8 +1041,26 @@ func (ec *executionContext) _Mutation_updateItem(ctx context.Context, field grap
 			fc := graphql.GetFieldContext(ctx)
 			return ec.resolvers.Mutation().UpdateItem(ctx, fc.Args["id"].(string), fc.Args["input"].(model.UpdateItem))
 		},
-		nil,
-		ec.marshalNItem2ᚖgoᚑhsmᚑappᚋgraphᚋmodelᚐItem,
+		func(ctx context.Context, next graphql.Resolver) graphql.Resolver {
+			directive0 := next
+
+			directive1 := func(ctx context.Context) (any, error) {
+				role, err := ec.unmarshalNRole2goᚑhsmᚑappᚋinternalᚋlibᚋgraphᚋmodelᚐRole(ctx, "OPERATOR")
+				if err != nil {
+					var zeroVal *model.Item
+					return zeroVal, err
+				}
+				if ec.directives.HasRole == nil {
+					var zeroVal *model.Item
+					return zeroVal, errors.New("directive hasRole is not implemented")
+				}
+				return ec.directives.HasRole(ctx, nil, directive0, role)
+			}
+
+			next = directive1
+			return next
+		},
+		ec.marshalNItem2ᚖgoᚑhsmᚑappᚋinternalᚋlibᚋgraphᚋmodelᚐItem,
 		true,
 		true,
 	)
@@ -589,16 +1076,28 @@ func (ec *executionContext) fieldContext_Mutation_updateItem(ctx context.Context
 			switch field.Name {
 			case "id":
 				return ec.fieldContext_Item_id(ctx, field)
+			case "code":
+				return ec.fieldContext_Item_code(ctx, field)
 			case "name":
 				return ec.fieldContext_Item_name(ctx, field)
-			case "description":
-				return ec.fieldContext_Item_description(ctx, field)
+			case "categoryId":
+				return ec.fieldContext_Item_categoryId(ctx, field)
+			case "unitId":
+				return ec.fieldContext_Item_unitId(ctx, field)
 			case "quantity":
 				return ec.fieldContext_Item_quantity(ctx, field)
+			case "unitPrice":
+				return ec.fieldContext_Item_unitPrice(ctx, field)
+			case "status":
+				return ec.fieldContext_Item_status(ctx, field)
+			case "version":
+				return ec.fieldContext_Item_version(ctx, field)
 			case "createdAt":
 				return ec.fieldContext_Item_createdAt(ctx, field)
 			case "updatedAt":
 				return ec.fieldContext_Item_updatedAt(ctx, field)
+			case "attributes":
+				return ec.fieldContext_Item_attributes(ctx, field)
 			}
 			return nil, fmt.Errorf("no field named %q was found under type Item", field.Name)
 		},
@@ -627,7 +1126,25 @@ func (ec *executionContext) _Mutation_deleteItem(ctx context.Context, field grap
 			fc := graphql.GetFieldContext(ctx)
 			return ec.resolvers.Mutation().DeleteItem(ctx, fc.Args["id"].(string))
 		},
-		nil,
+		func(ctx context.Context, next graphql.Resolver) graphql.Resolver {
+			directive0 := next
+
+			directive1 := func(ctx context.Context) (any, error) {
+				role, err := ec.unmarshalNRole2goᚑhsmᚑappᚋinternalᚋlibᚋgraphᚋmodelᚐRole(ctx, "ADMIN")
+				if err != nil {
+					var zeroVal bool
+					return zeroVal, err
+				}
+				if ec.directives.HasRole == nil {
+					var zeroVal bool
+					return zeroVal, errors.New("directive hasRole is not implemented")
+				}
+				return ec.directives.HasRole(ctx, nil, directive0, role)
+			}
+
+			next = directive1
+			return next
+		},
 		ec.marshalNBoolean2bool,
 		true,
 		true,
@@ -665,16 +1182,35 @@ func (ec *executionContext) _Query_items(ctx context.Context, field graphql.Coll
 		field,
 		ec.fieldContext_Query_items,
 		func(ctx context.Context) (any, error) {
-			return ec.resolvers.Query().Items(ctx)
+			fc := graphql.GetFieldContext(ctx)
+			return ec.resolvers.Query().Items(ctx, fc.Args["limit"].(*int), fc.Args["filter"].(*model.ItemFilter))
 		},
-		nil,
-		ec.marshalNItem2ᚕᚖgoᚑhsmᚑappᚋgraphᚋmodelᚐItemᚄ,
+		func(ctx context.Context, next graphql.Resolver) graphql.Resolver {
+			directive0 := next
+
+			directive1 := func(ctx context.Context) (any, error) {
+				role, err := ec.unmarshalNRole2goᚑhsmᚑappᚋinternalᚋlibᚋgraphᚋmodelᚐRole(ctx, "VIEWER")
+				if err != nil {
+					var zeroVal []*model.Item
+					return zeroVal, err
+				}
+				if ec.directives.HasRole == nil {
+					var zeroVal []*model.Item
+					return zeroVal, errors.New("directive hasRole is not implemented")
+				}
+				return ec.directives.HasRole(ctx, nil, directive0, role)
+			}
+
+			next = directive1
+			return next
+		},
+		ec.marshalNItem2ᚕᚖgoᚑhsmᚑappᚋinternalᚋlibᚋgraphᚋmodelᚐItemᚄ,
 		true,
 		true,
 	)
 }
 
-func (ec *executionContext) fieldContext_Query_items(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_Query_items(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
 		Object:     "Query",
 		Field:      field,
@@ -684,20 +1220,43 @@ func (ec *executionContext) fieldContext_Query_items(_ context.Context, field gr
 			switch field.Name {
 			case "id":
 				return ec.fieldContext_Item_id(ctx, field)
+			case "code":
+				return ec.fieldContext_Item_code(ctx, field)
 			case "name":
 				return ec.fieldContext_Item_name(ctx, field)
-			case "description":
-				return ec.fieldContext_Item_description(ctx, field)
+			case "categoryId":
+				return ec.fieldContext_Item_categoryId(ctx, field)
+			case "unitId":
+				return ec.fieldContext_Item_unitId(ctx, field)
 			case "quantity":
 				return ec.fieldContext_Item_quantity(ctx, field)
+			case "unitPrice":
+				return ec.fieldContext_Item_unitPrice(ctx, field)
+			case "status":
+				return ec.fieldContext_Item_status(ctx, field)
+			case "version":
+				return ec.fieldContext_Item_version(ctx, field)
 			case "createdAt":
 				return ec.fieldContext_Item_createdAt(ctx, field)
 			case "updatedAt":
 				return ec.fieldContext_Item_updatedAt(ctx, field)
+			case "attributes":
+				return ec.fieldContext_Item_attributes(ctx, field)
 			}
 			return nil, fmt.Errorf("no field named %q was found under type Item", field.Name)
 		},
 	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = ec.Recover(ctx, r)
+			ec.Error(ctx, err)
+		}
+	}()
+	ctx = graphql.WithFieldContext(ctx, fc)
+	if fc.Args, err = ec.field_Query_items_args(ctx, field.ArgumentMap(ec.Variables)); err != nil {
+		ec.Error(ctx, err)
+		return fc, err
+	}
 	return fc, nil
 }
 
@@ -711,8 +1270,26 @@ func (ec *executionContext) _Query_item(ctx context.Context, field graphql.Colle
 			fc := graphql.GetFieldContext(ctx)
 			return ec.resolvers.Query().Item(ctx, fc.Args["id"].(string))
 		},
-		nil,
-		ec.marshalOItem2ᚖgoᚑhsmᚑappᚋgraphᚋmodelᚐItem,
+		func(ctx context.Context, next graphql.Resolver) graphql.Resolver {
+			directive0 := next
+
+			directive1 := func(ctx context.Context) (any, error) {
+				role, err := ec.unmarshalNRole2goᚑhsmᚑappᚋinternalᚋlibᚋgraphᚋmodelᚐRole(ctx, "VIEWER")
+				if err != nil {
+					var zeroVal *model.Item
+					return zeroVal, err
+				}
+				if ec.directives.HasRole == nil {
+					var zeroVal *model.Item
+					return zeroVal, errors.New("directive hasRole is not implemented")
+				}
+				return ec.directives.HasRole(ctx, nil, directive0, role)
+			}
+
+			next = directive1
+			return next
+		},
+		ec.marshalOItem2ᚖgoᚑhsmᚑappᚋinternalᚋlibᚋgraphᚋmodelᚐItem,
 		true,
 		false,
 	)
@@ -728,16 +1305,28 @@ func (ec *executionContext) fieldContext_Query_item(ctx context.Context, field g
 			switch field.Name {
 			case "id":
 				return ec.fieldContext_Item_id(ctx, field)
+			case "code":
+				return ec.fieldContext_Item_code(ctx, field)
 			case "name":
 				return ec.fieldContext_Item_name(ctx, field)
-			case "description":
-				return ec.fieldContext_Item_description(ctx, field)
+			case "categoryId":
+				return ec.fieldContext_Item_categoryId(ctx, field)
+			case "unitId":
+				return ec.fieldContext_Item_unitId(ctx, field)
 			case "quantity":
 				return ec.fieldContext_Item_quantity(ctx, field)
+			case "unitPrice":
+				return ec.fieldContext_Item_unitPrice(ctx, field)
+			case "status":
+				return ec.fieldContext_Item_status(ctx, field)
+			case "version":
+				return ec.fieldContext_Item_version(ctx, field)
 			case "createdAt":
 				return ec.fieldContext_Item_createdAt(ctx, field)
 			case "updatedAt":
 				return ec.fieldContext_Item_updatedAt(ctx, field)
+			case "attributes":
+				return ec.fieldContext_Item_attributes(ctx, field)
 			}
 			return nil, fmt.Errorf("no field named %q was found under type Item", field.Name)
 		},
@@ -2306,9 +2895,115 @@ func (ec *executionContext) fieldContext___Type_isOneOf(_ context.Context, field
 	return fc, nil
 }
 
-// endregion **************************** field.gotpl *****************************
+// endregion **************************** field.gotpl *****************************
+
+// region    **************************** input.gotpl *****************************
+
+func (ec *executionContext) unmarshalInputAttributeFilterInput(ctx context.Context, obj any) (model.AttributeFilterInput, error) {
+	var it model.AttributeFilterInput
+	asMap := map[string]any{}
+	for k, v := range obj.(map[string]any) {
+		asMap[k] = v
+	}
+
+	if _, present := asMap["op"]; !present {
+		asMap["op"] = "EQ"
+	}
+
+	fieldsInOrder := [...]string{"code", "op", "values"}
+	for _, k := range fieldsInOrder {
+		v, ok := asMap[k]
+		if !ok {
+			continue
+		}
+		switch k {
+		case "code":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("code"))
+			data, err := ec.unmarshalNString2string(ctx, v)
+			if err != nil {
+				return it, err
+			}
+			it.Code = data
+		case "op":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("op"))
+			data, err := ec.unmarshalNAttributeFilterOperator2goᚑhsmᚑappᚋinternalᚋlibᚋgraphᚋmodelᚐAttributeFilterOperator(ctx, v)
+			if err != nil {
+				return it, err
+			}
+			it.Op = data
+		case "values":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("values"))
+			data, err := ec.unmarshalNString2ᚕstringᚄ(ctx, v)
+			if err != nil {
+				return it, err
+			}
+			it.Values = data
+		}
+	}
+
+	return it, nil
+}
+
+func (ec *executionContext) unmarshalInputAttributeValueInput(ctx context.Context, obj any) (model.AttributeValueInput, error) {
+	var it model.AttributeValueInput
+	asMap := map[string]any{}
+	for k, v := range obj.(map[string]any) {
+		asMap[k] = v
+	}
+
+	fieldsInOrder := [...]string{"code", "value"}
+	for _, k := range fieldsInOrder {
+		v, ok := asMap[k]
+		if !ok {
+			continue
+		}
+		switch k {
+		case "code":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("code"))
+			data, err := ec.unmarshalNString2string(ctx, v)
+			if err != nil {
+				return it, err
+			}
+			it.Code = data
+		case "value":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("value"))
+			data, err := ec.unmarshalOString2ᚖstring(ctx, v)
+			if err != nil {
+				return it, err
+			}
+			it.Value = data
+		}
+	}
+
+	return it, nil
+}
+
+func (ec *executionContext) unmarshalInputItemFilter(ctx context.Context, obj any) (model.ItemFilter, error) {
+	var it model.ItemFilter
+	asMap := map[string]any{}
+	for k, v := range obj.(map[string]any) {
+		asMap[k] = v
+	}
+
+	fieldsInOrder := [...]string{"attributes"}
+	for _, k := range fieldsInOrder {
+		v, ok := asMap[k]
+		if !ok {
+			continue
+		}
+		switch k {
+		case "attributes":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("attributes"))
+			data, err := ec.unmarshalOAttributeFilterInput2ᚕᚖgoᚑhsmᚑappᚋinternalᚋlibᚋgraphᚋmodelᚐAttributeFilterInputᚄ(ctx, v)
+			if err != nil {
+				return it, err
+			}
+			it.Attributes = data
+		}
+	}
 
-// region    **************************** input.gotpl *****************************
+	return it, nil
+}
 
 func (ec *executionContext) unmarshalInputNewItem(ctx context.Context, obj any) (model.NewItem, error) {
 	var it model.NewItem
@@ -2317,13 +3012,20 @@ func (ec *executionContext) unmarshalInputNewItem(ctx context.Context, obj any)
 		asMap[k] = v
 	}
 
-	fieldsInOrder := [...]string{"name", "description", "quantity"}
+	fieldsInOrder := [...]string{"code", "name", "categoryId", "unitId", "quantity", "unitPrice", "attributes"}
 	for _, k := range fieldsInOrder {
 		v, ok := asMap[k]
 		if !ok {
 			continue
 		}
 		switch k {
+		case "code":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("code"))
+			data, err := ec.unmarshalNString2string(ctx, v)
+			if err != nil {
+				return it, err
+			}
+			it.Code = data
 		case "name":
 			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("name"))
 			data, err := ec.unmarshalNString2string(ctx, v)
@@ -2331,20 +3033,41 @@ func (ec *executionContext) unmarshalInputNewItem(ctx context.Context, obj any)
 				return it, err
 			}
 			it.Name = data
-		case "description":
-			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("description"))
-			data, err := ec.unmarshalOString2ᚖstring(ctx, v)
+		case "categoryId":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("categoryId"))
+			data, err := ec.unmarshalOID2ᚖstring(ctx, v)
+			if err != nil {
+				return it, err
+			}
+			it.CategoryID = data
+		case "unitId":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("unitId"))
+			data, err := ec.unmarshalNID2string(ctx, v)
 			if err != nil {
 				return it, err
 			}
-			it.Description = data
+			it.UnitID = data
 		case "quantity":
 			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("quantity"))
-			data, err := ec.unmarshalNInt2int(ctx, v)
+			data, err := ec.unmarshalOInt2ᚖint(ctx, v)
 			if err != nil {
 				return it, err
 			}
 			it.Quantity = data
+		case "unitPrice":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("unitPrice"))
+			data, err := ec.unmarshalOInt2ᚖint(ctx, v)
+			if err != nil {
+				return it, err
+			}
+			it.UnitPrice = data
+		case "attributes":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("attributes"))
+			data, err := ec.unmarshalOAttributeValueInput2ᚕᚖgoᚑhsmᚑappᚋinternalᚋlibᚋgraphᚋmodelᚐAttributeValueInputᚄ(ctx, v)
+			if err != nil {
+				return it, err
+			}
+			it.Attributes = data
 		}
 	}
 
@@ -2358,13 +3081,20 @@ func (ec *executionContext) unmarshalInputUpdateItem(ctx context.Context, obj an
 		asMap[k] = v
 	}
 
-	fieldsInOrder := [...]string{"name", "description", "quantity"}
+	fieldsInOrder := [...]string{"code", "name", "categoryId", "unitId", "quantity", "unitPrice", "status", "attributes", "version"}
 	for _, k := range fieldsInOrder {
 		v, ok := asMap[k]
 		if !ok {
 			continue
 		}
 		switch k {
+		case "code":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("code"))
+			data, err := ec.unmarshalOString2ᚖstring(ctx, v)
+			if err != nil {
+				return it, err
+			}
+			it.Code = data
 		case "name":
 			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("name"))
 			data, err := ec.unmarshalOString2ᚖstring(ctx, v)
@@ -2372,13 +3102,20 @@ func (ec *executionContext) unmarshalInputUpdateItem(ctx context.Context, obj an
 				return it, err
 			}
 			it.Name = data
-		case "description":
-			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("description"))
-			data, err := ec.unmarshalOString2ᚖstring(ctx, v)
+		case "categoryId":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("categoryId"))
+			data, err := ec.unmarshalOID2ᚖstring(ctx, v)
+			if err != nil {
+				return it, err
+			}
+			it.CategoryID = data
+		case "unitId":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("unitId"))
+			data, err := ec.unmarshalOID2ᚖstring(ctx, v)
 			if err != nil {
 				return it, err
 			}
-			it.Description = data
+			it.UnitID = data
 		case "quantity":
 			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("quantity"))
 			data, err := ec.unmarshalOInt2ᚖint(ctx, v)
@@ -2386,6 +3123,34 @@ func (ec *executionContext) unmarshalInputUpdateItem(ctx context.Context, obj an
 				return it, err
 			}
 			it.Quantity = data
+		case "unitPrice":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("unitPrice"))
+			data, err := ec.unmarshalOInt2ᚖint(ctx, v)
+			if err != nil {
+				return it, err
+			}
+			it.UnitPrice = data
+		case "status":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("status"))
+			data, err := ec.unmarshalOString2ᚖstring(ctx, v)
+			if err != nil {
+				return it, err
+			}
+			it.Status = data
+		case "attributes":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("attributes"))
+			data, err := ec.unmarshalOAttributeValueInput2ᚕᚖgoᚑhsmᚑappᚋinternalᚋlibᚋgraphᚋmodelᚐAttributeValueInputᚄ(ctx, v)
+			if err != nil {
+				return it, err
+			}
+			it.Attributes = data
+		case "version":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("version"))
+			data, err := ec.unmarshalOInt2ᚖint(ctx, v)
+			if err != nil {
+				return it, err
+			}
+			it.Version = data
 		}
 	}
 
@@ -2416,15 +3181,34 @@ func (ec *executionContext) _Item(ctx context.Context, sel ast.SelectionSet, obj
 			if out.Values[i] == graphql.Null {
 				out.Invalids++
 			}
+		case "code":
+			out.Values[i] = ec._Item_code(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
 		case "name":
 			out.Values[i] = ec._Item_name(ctx, field, obj)
 			if out.Values[i] == graphql.Null {
 				out.Invalids++
 			}
-		case "description":
-			out.Values[i] = ec._Item_description(ctx, field, obj)
+		case "categoryId":
+			out.Values[i] = ec._Item_categoryId(ctx, field, obj)
+		case "unitId":
+			out.Values[i] = ec._Item_unitId(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
 		case "quantity":
 			out.Values[i] = ec._Item_quantity(ctx, field, obj)
+		case "unitPrice":
+			out.Values[i] = ec._Item_unitPrice(ctx, field, obj)
+		case "status":
+			out.Values[i] = ec._Item_status(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "version":
+			out.Values[i] = ec._Item_version(ctx, field, obj)
 			if out.Values[i] == graphql.Null {
 				out.Invalids++
 			}
@@ -2438,6 +3222,67 @@ func (ec *executionContext) _Item(ctx context.Context, sel ast.SelectionSet, obj
 			if out.Values[i] == graphql.Null {
 				out.Invalids++
 			}
+		case "attributes":
+			out.Values[i] = ec._Item_attributes(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		default:
+			panic("unknown field " + strconv.Quote(field.Name))
+		}
+	}
+	out.Dispatch(ctx)
+	if out.Invalids > 0 {
+		return graphql.Null
+	}
+
+	atomic.AddInt32(&ec.deferred, int32(len(deferred)))
+
+	for label, dfs := range deferred {
+		ec.processDeferredGroup(graphql.DeferredGroup{
+			Label:    label,
+			Path:     graphql.GetPath(ctx),
+			FieldSet: dfs,
+			Context:  ctx,
+		})
+	}
+
+	return out
+}
+
+var itemAttributeImplementors = []string{"ItemAttribute"}
+
+func (ec *executionContext) _ItemAttribute(ctx context.Context, sel ast.SelectionSet, obj *model.ItemAttribute) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, itemAttributeImplementors)
+
+	out := graphql.NewFieldSet(fields)
+	deferred := make(map[string]*graphql.FieldSet)
+	for i, field := range fields {
+		switch field.Name {
+		case "__typename":
+			out.Values[i] = graphql.MarshalString("ItemAttribute")
+		case "code":
+			out.Values[i] = ec._ItemAttribute_code(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "name":
+			out.Values[i] = ec._ItemAttribute_name(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "valueType":
+			out.Values[i] = ec._ItemAttribute_valueType(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "value":
+			out.Values[i] = ec._ItemAttribute_value(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "label":
+			out.Values[i] = ec._ItemAttribute_label(ctx, field, obj)
 		default:
 			panic("unknown field " + strconv.Quote(field.Name))
 		}
@@ -2950,6 +3795,26 @@ func (ec *executionContext) ___Type(ctx context.Context, sel ast.SelectionSet, o
 
 // region    ***************************** type.gotpl *****************************
 
+func (ec *executionContext) unmarshalNAttributeFilterInput2ᚖgoᚑhsmᚑappᚋinternalᚋlibᚋgraphᚋmodelᚐAttributeFilterInput(ctx context.Context, v any) (*model.AttributeFilterInput, error) {
+	res, err := ec.unmarshalInputAttributeFilterInput(ctx, v)
+	return &res, graphql.ErrorOnPath(ctx, err)
+}
+
+func (ec *executionContext) unmarshalNAttributeFilterOperator2goᚑhsmᚑappᚋinternalᚋlibᚋgraphᚋmodelᚐAttributeFilterOperator(ctx context.Context, v any) (model.AttributeFilterOperator, error) {
+	var res model.AttributeFilterOperator
+	err := res.UnmarshalGQL(v)
+	return res, graphql.ErrorOnPath(ctx, err)
+}
+
+func (ec *executionContext) marshalNAttributeFilterOperator2goᚑhsmᚑappᚋinternalᚋlibᚋgraphᚋmodelᚐAttributeFilterOperator(ctx context.Context, sel ast.SelectionSet, v model.AttributeFilterOperator) graphql.Marshaler {
+	return v
+}
+
+func (ec *executionContext) unmarshalNAttributeValueInput2ᚖgoᚑhsmᚑappᚋinternalᚋlibᚋgraphᚋmodelᚐAttributeValueInput(ctx context.Context, v any) (*model.AttributeValueInput, error) {
+	res, err := ec.unmarshalInputAttributeValueInput(ctx, v)
+	return &res, graphql.ErrorOnPath(ctx, err)
+}
+
 func (ec *executionContext) unmarshalNBoolean2bool(ctx context.Context, v any) (bool, error) {
 	res, err := graphql.UnmarshalBoolean(v)
 	return res, graphql.ErrorOnPath(ctx, err)
@@ -2998,11 +3863,11 @@ func (ec *executionContext) marshalNInt2int(ctx context.Context, sel ast.Selecti
 	return res
 }
 
-func (ec *executionContext) marshalNItem2goᚑhsmᚑappᚋgraphᚋmodelᚐItem(ctx context.Context, sel ast.SelectionSet, v model.Item) graphql.Marshaler {
+func (ec *executionContext) marshalNItem2goᚑhsmᚑappᚋinternalᚋlibᚋgraphᚋmodelᚐItem(ctx context.Context, sel ast.SelectionSet, v model.Item) graphql.Marshaler {
 	return ec._Item(ctx, sel, &v)
 }
 
-func (ec *executionContext) marshalNItem2ᚕᚖgoᚑhsmᚑappᚋgraphᚋmodelᚐItemᚄ(ctx context.Context, sel ast.SelectionSet, v []*model.Item) graphql.Marshaler {
+func (ec *executionContext) marshalNItem2ᚕᚖgoᚑhsmᚑappᚋinternalᚋlibᚋgraphᚋmodelᚐItemᚄ(ctx context.Context, sel ast.SelectionSet, v []*model.Item) graphql.Marshaler {
 	ret := make(graphql.Array, len(v))
 	var wg sync.WaitGroup
 	isLen1 := len(v) == 1
@@ -3026,7 +3891,7 @@ func (ec *executionContext) marshalNItem2ᚕᚖgoᚑhsmᚑappᚋgraphᚋmodelᚐ
 			if !isLen1 {
 				defer wg.Done()
 			}
-			ret[i] = ec.marshalNItem2ᚖgoᚑhsmᚑappᚋgraphᚋmodelᚐItem(ctx, sel, v[i])
+			ret[i] = ec.marshalNItem2ᚖgoᚑhsmᚑappᚋinternalᚋlibᚋgraphᚋmodelᚐItem(ctx, sel, v[i])
 		}
 		if isLen1 {
 			f(i)
@@ -3046,7 +3911,7 @@ func (ec *executionContext) marshalNItem2ᚕᚖgoᚑhsmᚑappᚋgraphᚋmodelᚐ
 	return ret
 }
 
-func (ec *executionContext) marshalNItem2ᚖgoᚑhsmᚑappᚋgraphᚋmodelᚐItem(ctx context.Context, sel ast.SelectionSet, v *model.Item) graphql.Marshaler {
+func (ec *executionContext) marshalNItem2ᚖgoᚑhsmᚑappᚋinternalᚋlibᚋgraphᚋmodelᚐItem(ctx context.Context, sel ast.SelectionSet, v *model.Item) graphql.Marshaler {
 	if v == nil {
 		if !graphql.HasFieldError(ctx, graphql.GetFieldContext(ctx)) {
 			ec.Errorf(ctx, "the requested element is null which the schema does not allow")
@@ -3056,11 +3921,75 @@ func (ec *executionContext) marshalNItem2ᚖgoᚑhsmᚑappᚋgraphᚋmodelᚐIte
 	return ec._Item(ctx, sel, v)
 }
 
-func (ec *executionContext) unmarshalNNewItem2goᚑhsmᚑappᚋgraphᚋmodelᚐNewItem(ctx context.Context, v any) (model.NewItem, error) {
+func (ec *executionContext) marshalNItemAttribute2ᚕᚖgoᚑhsmᚑappᚋinternalᚋlibᚋgraphᚋmodelᚐItemAttributeᚄ(ctx context.Context, sel ast.SelectionSet, v []*model.ItemAttribute) graphql.Marshaler {
+	ret := make(graphql.Array, len(v))
+	var wg sync.WaitGroup
+	isLen1 := len(v) == 1
+	if !isLen1 {
+		wg.Add(len(v))
+	}
+	for i := range v {
+		i := i
+		fc := &graphql.FieldContext{
+			Index:  &i,
+			Result: &v[i],
+		}
+		ctx := graphql.WithFieldContext(ctx, fc)
+		f := func(i int) {
+			defer func() {
+				if r := recover(); r != nil {
+					ec.Error(ctx, ec.Recover(ctx, r))
+					ret = nil
+				}
+			}()
+			if !isLen1 {
+				defer wg.Done()
+			}
+			ret[i] = ec.marshalNItemAttribute2ᚖgoᚑhsmᚑappᚋinternalᚋlibᚋgraphᚋmodelᚐItemAttribute(ctx, sel, v[i])
+		}
+		if isLen1 {
+			f(i)
+		} else {
+			go f(i)
+		}
+
+	}
+	wg.Wait()
+
+	for _, e := range ret {
+		if e == graphql.Null {
+			return graphql.Null
+		}
+	}
+
+	return ret
+}
+
+func (ec *executionContext) marshalNItemAttribute2ᚖgoᚑhsmᚑappᚋinternalᚋlibᚋgraphᚋmodelᚐItemAttribute(ctx context.Context, sel ast.SelectionSet, v *model.ItemAttribute) graphql.Marshaler {
+	if v == nil {
+		if !graphql.HasFieldError(ctx, graphql.GetFieldContext(ctx)) {
+			ec.Errorf(ctx, "the requested element is null which the schema does not allow")
+		}
+		return graphql.Null
+	}
+	return ec._ItemAttribute(ctx, sel, v)
+}
+
+func (ec *executionContext) unmarshalNNewItem2goᚑhsmᚑappᚋinternalᚋlibᚋgraphᚋmodelᚐNewItem(ctx context.Context, v any) (model.NewItem, error) {
 	res, err := ec.unmarshalInputNewItem(ctx, v)
 	return res, graphql.ErrorOnPath(ctx, err)
 }
 
+func (ec *executionContext) unmarshalNRole2goᚑhsmᚑappᚋinternalᚋlibᚋgraphᚋmodelᚐRole(ctx context.Context, v any) (model.Role, error) {
+	var res model.Role
+	err := res.UnmarshalGQL(v)
+	return res, graphql.ErrorOnPath(ctx, err)
+}
+
+func (ec *executionContext) marshalNRole2goᚑhsmᚑappᚋinternalᚋlibᚋgraphᚋmodelᚐRole(ctx context.Context, sel ast.SelectionSet, v model.Role) graphql.Marshaler {
+	return v
+}
+
 func (ec *executionContext) unmarshalNString2string(ctx context.Context, v any) (string, error) {
 	res, err := graphql.UnmarshalString(v)
 	return res, graphql.ErrorOnPath(ctx, err)
@@ -3077,7 +4006,37 @@ func (ec *executionContext) marshalNString2string(ctx context.Context, sel ast.S
 	return res
 }
 
-func (ec *executionContext) unmarshalNUpdateItem2goᚑhsmᚑappᚋgraphᚋmodelᚐUpdateItem(ctx context.Context, v any) (model.UpdateItem, error) {
+func (ec *executionContext) unmarshalNString2ᚕstringᚄ(ctx context.Context, v any) ([]string, error) {
+	var vSlice []any
+	vSlice = graphql.CoerceList(v)
+	var err error
+	res := make([]string, len(vSlice))
+	for i := range vSlice {
+		ctx := graphql.WithPathContext(ctx, graphql.NewPathWithIndex(i))
+		res[i], err = ec.unmarshalNString2string(ctx, vSlice[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func (ec *executionContext) marshalNString2ᚕstringᚄ(ctx context.Context, sel ast.SelectionSet, v []string) graphql.Marshaler {
+	ret := make(graphql.Array, len(v))
+	for i := range v {
+		ret[i] = ec.marshalNString2string(ctx, sel, v[i])
+	}
+
+	for _, e := range ret {
+		if e == graphql.Null {
+			return graphql.Null
+		}
+	}
+
+	return ret
+}
+
+func (ec *executionContext) unmarshalNUpdateItem2goᚑhsmᚑappᚋinternalᚋlibᚋgraphᚋmodelᚐUpdateItem(ctx context.Context, v any) (model.UpdateItem, error) {
 	res, err := ec.unmarshalInputUpdateItem(ctx, v)
 	return res, graphql.ErrorOnPath(ctx, err)
 }
@@ -3335,6 +4294,42 @@ func (ec *executionContext) marshalN__TypeKind2string(ctx context.Context, sel a
 	return res
 }
 
+func (ec *executionContext) unmarshalOAttributeFilterInput2ᚕᚖgoᚑhsmᚑappᚋinternalᚋlibᚋgraphᚋmodelᚐAttributeFilterInputᚄ(ctx context.Context, v any) ([]*model.AttributeFilterInput, error) {
+	if v == nil {
+		return nil, nil
+	}
+	var vSlice []any
+	vSlice = graphql.CoerceList(v)
+	var err error
+	res := make([]*model.AttributeFilterInput, len(vSlice))
+	for i := range vSlice {
+		ctx := graphql.WithPathContext(ctx, graphql.NewPathWithIndex(i))
+		res[i], err = ec.unmarshalNAttributeFilterInput2ᚖgoᚑhsmᚑappᚋinternalᚋlibᚋgraphᚋmodelᚐAttributeFilterInput(ctx, vSlice[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func (ec *executionContext) unmarshalOAttributeValueInput2ᚕᚖgoᚑhsmᚑappᚋinternalᚋlibᚋgraphᚋmodelᚐAttributeValueInputᚄ(ctx context.Context, v any) ([]*model.AttributeValueInput, error) {
+	if v == nil {
+		return nil, nil
+	}
+	var vSlice []any
+	vSlice = graphql.CoerceList(v)
+	var err error
+	res := make([]*model.AttributeValueInput, len(vSlice))
+	for i := range vSlice {
+		ctx := graphql.WithPathContext(ctx, graphql.NewPathWithIndex(i))
+		res[i], err = ec.unmarshalNAttributeValueInput2ᚖgoᚑhsmᚑappᚋinternalᚋlibᚋgraphᚋmodelᚐAttributeValueInput(ctx, vSlice[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
 func (ec *executionContext) unmarshalOBoolean2bool(ctx context.Context, v any) (bool, error) {
 	res, err := graphql.UnmarshalBoolean(v)
 	return res, graphql.ErrorOnPath(ctx, err)
@@ -3365,6 +4360,24 @@ func (ec *executionContext) marshalOBoolean2ᚖbool(ctx context.Context, sel ast
 	return res
 }
 
+func (ec *executionContext) unmarshalOID2ᚖstring(ctx context.Context, v any) (*string, error) {
+	if v == nil {
+		return nil, nil
+	}
+	res, err := graphql.UnmarshalID(v)
+	return &res, graphql.ErrorOnPath(ctx, err)
+}
+
+func (ec *executionContext) marshalOID2ᚖstring(ctx context.Context, sel ast.SelectionSet, v *string) graphql.Marshaler {
+	if v == nil {
+		return graphql.Null
+	}
+	_ = sel
+	_ = ctx
+	res := graphql.MarshalID(*v)
+	return res
+}
+
 func (ec *executionContext) unmarshalOInt2ᚖint(ctx context.Context, v any) (*int, error) {
 	if v == nil {
 		return nil, nil
@@ -3383,13 +4396,21 @@ func (ec *executionContext) marshalOInt2ᚖint(ctx context.Context, sel ast.Sele
 	return res
 }
 
-func (ec *executionContext) marshalOItem2ᚖgoᚑhsmᚑappᚋgraphᚋmodelᚐItem(ctx context.Context, sel ast.SelectionSet, v *model.Item) graphql.Marshaler {
+func (ec *executionContext) marshalOItem2ᚖgoᚑhsmᚑappᚋinternalᚋlibᚋgraphᚋmodelᚐItem(ctx context.Context, sel ast.SelectionSet, v *model.Item) graphql.Marshaler {
 	if v == nil {
 		return graphql.Null
 	}
 	return ec._Item(ctx, sel, v)
 }
 
+func (ec *executionContext) unmarshalOItemFilter2ᚖgoᚑhsmᚑappᚋinternalᚋlibᚋgraphᚋmodelᚐItemFilter(ctx context.Context, v any) (*model.ItemFilter, error) {
+	if v == nil {
+		return nil, nil
+	}
+	res, err := ec.unmarshalInputItemFilter(ctx, v)
+	return &res, graphql.ErrorOnPath(ctx, err)
+}
+
 func (ec *executionContext) unmarshalOString2ᚖstring(ctx context.Context, v any) (*string, error) {
 	if v == nil {
 		return nil, nil
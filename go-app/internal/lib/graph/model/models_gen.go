@@ -2,29 +2,197 @@
 
 package model
 
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+type AttributeFilterInput struct {
+	Code   string                  `json:"code"`
+	Op     AttributeFilterOperator `json:"op"`
+	Values []string                `json:"values"`
+}
+
+type AttributeValueInput struct {
+	Code  string  `json:"code"`
+	Value *string `json:"value,omitempty"`
+}
+
 type Item struct {
-	ID          string  `json:"id"`
-	Name        string  `json:"name"`
-	Description *string `json:"description,omitempty"`
-	Quantity    int     `json:"quantity"`
-	CreatedAt   string  `json:"createdAt"`
-	UpdatedAt   string  `json:"updatedAt"`
+	ID         string           `json:"id"`
+	Code       string           `json:"code"`
+	Name       string           `json:"name"`
+	CategoryID *string          `json:"categoryId,omitempty"`
+	UnitID     string           `json:"unitId"`
+	Quantity   *int             `json:"quantity,omitempty"`
+	UnitPrice  *int             `json:"unitPrice,omitempty"`
+	Status     string           `json:"status"`
+	Version    int              `json:"version"`
+	CreatedAt  string           `json:"createdAt"`
+	UpdatedAt  string           `json:"updatedAt"`
+	Attributes []*ItemAttribute `json:"attributes"`
+}
+
+type ItemAttribute struct {
+	Code      string  `json:"code"`
+	Name      string  `json:"name"`
+	ValueType string  `json:"valueType"`
+	Value     string  `json:"value"`
+	Label     *string `json:"label,omitempty"`
+}
+
+type ItemFilter struct {
+	Attributes []*AttributeFilterInput `json:"attributes,omitempty"`
 }
 
 type Mutation struct {
 }
 
 type NewItem struct {
-	Name        string  `json:"name"`
-	Description *string `json:"description,omitempty"`
-	Quantity    int     `json:"quantity"`
+	Code       string                 `json:"code"`
+	Name       string                 `json:"name"`
+	CategoryID *string                `json:"categoryId,omitempty"`
+	UnitID     string                 `json:"unitId"`
+	Quantity   *int                   `json:"quantity,omitempty"`
+	UnitPrice  *int                   `json:"unitPrice,omitempty"`
+	Attributes []*AttributeValueInput `json:"attributes,omitempty"`
 }
 
 type Query struct {
 }
 
 type UpdateItem struct {
-	Name        *string `json:"name,omitempty"`
-	Description *string `json:"description,omitempty"`
-	Quantity    *int    `json:"quantity,omitempty"`
+	Code       *string                `json:"code,omitempty"`
+	Name       *string                `json:"name,omitempty"`
+	CategoryID *string                `json:"categoryId,omitempty"`
+	UnitID     *string                `json:"unitId,omitempty"`
+	Quantity   *int                   `json:"quantity,omitempty"`
+	UnitPrice  *int                   `json:"unitPrice,omitempty"`
+	Status     *string                `json:"status,omitempty"`
+	Attributes []*AttributeValueInput `json:"attributes,omitempty"`
+	Version    *int                   `json:"version,omitempty"`
+}
+
+type AttributeFilterOperator string
+
+const (
+	AttributeFilterOperatorEq       AttributeFilterOperator = "EQ"
+	AttributeFilterOperatorNe       AttributeFilterOperator = "NE"
+	AttributeFilterOperatorLt       AttributeFilterOperator = "LT"
+	AttributeFilterOperatorGt       AttributeFilterOperator = "GT"
+	AttributeFilterOperatorBetween  AttributeFilterOperator = "BETWEEN"
+	AttributeFilterOperatorContains AttributeFilterOperator = "CONTAINS"
+	AttributeFilterOperatorIn       AttributeFilterOperator = "IN"
+)
+
+var AllAttributeFilterOperator = []AttributeFilterOperator{
+	AttributeFilterOperatorEq,
+	AttributeFilterOperatorNe,
+	AttributeFilterOperatorLt,
+	AttributeFilterOperatorGt,
+	AttributeFilterOperatorBetween,
+	AttributeFilterOperatorContains,
+	AttributeFilterOperatorIn,
+}
+
+func (e AttributeFilterOperator) IsValid() bool {
+	switch e {
+	case AttributeFilterOperatorEq, AttributeFilterOperatorNe, AttributeFilterOperatorLt, AttributeFilterOperatorGt, AttributeFilterOperatorBetween, AttributeFilterOperatorContains, AttributeFilterOperatorIn:
+		return true
+	}
+	return false
+}
+
+func (e AttributeFilterOperator) String() string {
+	return string(e)
+}
+
+func (e *AttributeFilterOperator) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = AttributeFilterOperator(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid AttributeFilterOperator", str)
+	}
+	return nil
+}
+
+func (e AttributeFilterOperator) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *AttributeFilterOperator) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e AttributeFilterOperator) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type Role string
+
+const (
+	RoleAdmin    Role = "ADMIN"
+	RoleOperator Role = "OPERATOR"
+	RoleViewer   Role = "VIEWER"
+)
+
+var AllRole = []Role{
+	RoleAdmin,
+	RoleOperator,
+	RoleViewer,
+}
+
+func (e Role) IsValid() bool {
+	switch e {
+	case RoleAdmin, RoleOperator, RoleViewer:
+		return true
+	}
+	return false
+}
+
+func (e Role) String() string {
+	return string(e)
+}
+
+func (e *Role) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = Role(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid Role", str)
+	}
+	return nil
+}
+
+func (e Role) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *Role) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e Role) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
 }
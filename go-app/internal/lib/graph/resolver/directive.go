@@ -0,0 +1,39 @@
+package graph
+
+import (
+	"context"
+	"strings"
+
+	"go-hsm-app/internal/auth"
+	"go-hsm-app/internal/lib/graph/model"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// HasRole は @hasRole ディレクティブの実装です。
+// REST の権限マトリクスと同じ権限判定を行い、権限不足の場合は FORBIDDEN エラーを返します。
+func HasRole(ctx context.Context, obj any, next graphql.Resolver, role model.Role) (any, error) {
+	required := strings.ToLower(role.String())
+
+	p := auth.PrincipalFromContext(ctx)
+	if p == nil {
+		return nil, &gqlerror.Error{
+			Message:    "Authentication required",
+			Extensions: map[string]any{"code": "UNAUTHENTICATED"},
+		}
+	}
+
+	if !auth.HasRole(p.Role, required) {
+		body := auth.ForbiddenBody(required)
+		return nil, &gqlerror.Error{
+			Message: body["message"],
+			Extensions: map[string]any{
+				"code":          "FORBIDDEN",
+				"required_role": required,
+			},
+		}
+	}
+
+	return next(ctx)
+}
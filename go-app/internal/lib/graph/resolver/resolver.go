@@ -1,11 +1,196 @@
 package graph
 
-import "database/sql"
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go-hsm-app/internal/auth"
+	"go-hsm-app/internal/lib/graph/model"
+	appmodel "go-hsm-app/internal/model"
+	"go-hsm-app/internal/repository"
+	"go-hsm-app/internal/service"
+
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
 
 // このファイルは自動生成で上書きされないファイルです。
 // アプリケーションの依存性注入ポイントとして機能します。
-// 必要な依存関係（DBクライアントなど）をここに追加してください。
+// データアクセスは REST と同じくサービス層を経由し、利用者の所属テナントに限定します。
+
+type Resolver struct{}
+
+// tenantID はリクエスト中の利用者が所属するテナントの ID を返します
+func tenantID(ctx context.Context) string {
+	p := auth.PrincipalFromContext(ctx)
+	if p == nil {
+		return ""
+	}
+	return p.TenantID
+}
+
+// principalID は利用者のユーザーIDを返します（未認証の場合は nil）
+func principalID(ctx context.Context) *string {
+	p := auth.PrincipalFromContext(ctx)
+	if p == nil {
+		return nil
+	}
+	return &p.UserID
+}
+
+// accessScope は利用者が required の権限で扱えるデータの範囲（権限付与）を返します
+func accessScope(ctx context.Context, required string) *appmodel.AccessScope {
+	return auth.AccessScopeFor(auth.PrincipalFromContext(ctx), required)
+}
+
+// toItem はアイテムを GraphQL のモデルに変換します
+func toItem(item *appmodel.Item) *model.Item {
+	return &model.Item{
+		ID:         item.ID,
+		Code:       item.Code,
+		Name:       item.Name,
+		CategoryID: item.CategoryID,
+		UnitID:     item.UnitID,
+		Quantity:   item.Quantity,
+		UnitPrice:  item.UnitPrice,
+		Status:     item.Status,
+		Version:    item.Version,
+		CreatedAt:  item.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:  item.UpdatedAt.Format(time.RFC3339),
+		Attributes: toItemAttributes(item.Attributes),
+	}
+}
+
+// toItemAttributes はアイテムの属性値を GraphQL のモデルに変換します
+func toItemAttributes(attributes []appmodel.ItemAttributeDetail) []*model.ItemAttribute {
+	result := make([]*model.ItemAttribute, 0, len(attributes))
+	for _, a := range attributes {
+		result = append(result, &model.ItemAttribute{
+			Code:      a.Code,
+			Name:      a.Name,
+			ValueType: a.ValueType,
+			Value:     a.Value,
+			Label:     a.Label,
+		})
+	}
+	return result
+}
+
+// attributeValues は現在の属性値に入力の属性値を重ねた、属性コード → 値の組を返します
+// 入力で値が null の属性は除きます。input が nil の場合は nil を返します
+func attributeValues(current []appmodel.ItemAttributeDetail, input []*model.AttributeValueInput) map[string]interface{} {
+	if input == nil {
+		return nil
+	}
+	values := make(map[string]interface{}, len(current)+len(input))
+	for _, a := range current {
+		values[a.Code] = a.Value
+	}
+	for _, a := range input {
+		if a.Value == nil {
+			delete(values, a.Code)
+			continue
+		}
+		values[a.Code] = *a.Value
+	}
+	return values
+}
+
+// toAttributeFilters は GraphQL の属性フィルタを検索条件の属性フィルタに変換します
+func toAttributeFilters(filter *model.ItemFilter) []appmodel.AttributeFilter {
+	if filter == nil {
+		return nil
+	}
+	result := make([]appmodel.AttributeFilter, 0, len(filter.Attributes))
+	for _, a := range filter.Attributes {
+		result = append(result, appmodel.AttributeFilter{
+			Code:     a.Code,
+			Operator: strings.ToLower(a.Op.String()),
+			Values:   a.Values,
+		})
+	}
+	return result
+}
+
+// invalidQueryError は検索条件が不正な場合の INVALID_QUERY エラーを返します
+func invalidQueryError(err error) error {
+	return &gqlerror.Error{
+		Message:    err.Error(),
+		Extensions: map[string]any{"code": "INVALID_QUERY"},
+	}
+}
+
+// preconditionRequiredError は更新の条件とするバージョンが指定されていないことを PRECONDITION_REQUIRED エラーとして返します
+func preconditionRequiredError() error {
+	return &gqlerror.Error{
+		Message:    "version is required to update the item",
+		Extensions: map[string]any{"code": "PRECONDITION_REQUIRED"},
+	}
+}
+
+// versionConflictError は楽観的ロックの競合を表す VERSION_CONFLICT エラーを、現在のバージョンとともに返します
+func versionConflictError(ctx context.Context, id string) error {
+	extensions := map[string]any{"code": "VERSION_CONFLICT"}
+	if current, err := service.GetItemByID(tenantID(ctx), accessScope(ctx, appmodel.RoleViewer), id); err == nil {
+		extensions["current_version"] = current.Version
+	}
+	return &gqlerror.Error{
+		Message:    "The item was modified by another request",
+		Extensions: extensions,
+	}
+}
+
+// duplicateCodeError はコードが有効なデータと重複することを DUPLICATE_CODE エラーとして返します
+func duplicateCodeError(code string) error {
+	return &gqlerror.Error{
+		Message: "The code is already in use",
+		Extensions: map[string]any{
+			"code":      "DUPLICATE_CODE",
+			"item_code": code,
+		},
+	}
+}
+
+// accessDeniedError は権限付与の範囲外になる操作を FORBIDDEN エラーとして返します
+func accessDeniedError() error {
+	return &gqlerror.Error{
+		Message:    "The item would be outside of your granted scope",
+		Extensions: map[string]any{"code": "FORBIDDEN"},
+	}
+}
+
+// itemUnitInUseError は在庫や入出庫履歴があるアイテムの単位を変更しようとしたことを UNIT_IN_USE エラーとして返します
+func itemUnitInUseError(id string) error {
+	return &gqlerror.Error{
+		Message: "unitId cannot be changed while the item has stock or stock history",
+		Extensions: map[string]any{
+			"code":    "UNIT_IN_USE",
+			"item_id": id,
+		},
+	}
+}
+
+// validationError は入力項目ごとのエラーを VALIDATION_FAILED エラーとして返します
+func validationError(verr *service.ValidationError) error {
+	return &gqlerror.Error{
+		Message: "One or more fields are invalid",
+		Extensions: map[string]any{
+			"code":   "VALIDATION_FAILED",
+			"fields": verr.Fields,
+		},
+	}
+}
 
-type Resolver struct {
-	DB *sql.DB
+// itemDeleteForbiddenError は削除のポリシーでアイテムを削除できないことを ITEM_DELETE_FORBIDDEN エラーとして返します
+func itemDeleteForbiddenError(err *repository.ItemDeleteForbiddenError) error {
+	return &gqlerror.Error{
+		Message: err.Error(),
+		Extensions: map[string]any{
+			"code":          "ITEM_DELETE_FORBIDDEN",
+			"policy":        err.Policy,
+			"rule":          err.Rule,
+			"stock":         err.Stock,
+			"history_count": err.HistoryCount,
+		},
+	}
 }
@@ -6,146 +6,155 @@ package graph
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"go-hsm-app/internal/lib/graph/generated"
 	"go-hsm-app/internal/lib/graph/model"
+	appmodel "go-hsm-app/internal/model"
+	"go-hsm-app/internal/repository"
+	"go-hsm-app/internal/service"
 )
 
 // CreateItem is the resolver for the createItem field.
 func (r *mutationResolver) CreateItem(ctx context.Context, input model.NewItem) (*model.Item, error) {
-	query := `
-		INSERT INTO items (name, description, quantity, created_at, updated_at)
-		VALUES ($1, $2, $3, NOW(), NOW())
-		RETURNING id, name, description, quantity, created_at, updated_at
-	`
-
-	item := &model.Item{}
-	var createdAt, updatedAt string
-	var description *string
-
-	err := r.DB.QueryRowContext(ctx, query, input.Name, input.Description, input.Quantity).
-		Scan(&item.ID, &item.Name, &description, &item.Quantity, &createdAt, &updatedAt)
-
+	item, err := service.CreateItem(tenantID(ctx), accessScope(ctx, appmodel.RoleOperator), input.Code, input.Name, input.UnitID, input.CategoryID, input.Quantity, input.UnitPrice, attributeValues(nil, input.Attributes))
 	if err != nil {
+		var verr *service.ValidationError
+		if errors.As(err, &verr) {
+			return nil, validationError(verr)
+		}
+		if errors.Is(err, repository.ErrDuplicateCode) {
+			return nil, duplicateCodeError(input.Code)
+		}
+		if errors.Is(err, repository.ErrAccessDenied) {
+			return nil, accessDeniedError()
+		}
 		return nil, fmt.Errorf("failed to create item: %w", err)
 	}
-
-	if description != nil {
-		item.Description = description
-	}
-	item.CreatedAt = createdAt
-	item.UpdatedAt = updatedAt
-
-	return item, nil
+	return toItem(item), nil
 }
 
 // UpdateItem is the resolver for the updateItem field.
 func (r *mutationResolver) UpdateItem(ctx context.Context, id string, input model.UpdateItem) (*model.Item, error) {
-	query := `
-		UPDATE items
-		SET name = COALESCE($1, name),
-		    description = COALESCE($2, description),
-		    quantity = COALESCE($3, quantity),
-		    updated_at = NOW()
-		WHERE id = $4
-		RETURNING id, name, description, quantity, created_at, updated_at
-	`
-
-	item := &model.Item{}
-	var createdAt, updatedAt string
-	var description *string
-
-	err := r.DB.QueryRowContext(ctx, query, input.Name, input.Description, input.Quantity, id).
-		Scan(&item.ID, &item.Name, &description, &item.Quantity, &createdAt, &updatedAt)
+	// 指定されなかったフィールドを読み込んだ時点の値で補うため、間の変更を上書きしないよう version を必須とする
+	if input.Version == nil {
+		return nil, preconditionRequiredError()
+	}
 
+	scope := accessScope(ctx, appmodel.RoleOperator)
+	current, err := service.GetItemByID(tenantID(ctx), scope, id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to update item: %w", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("item not found: %s", id)
+		}
+		return nil, fmt.Errorf("failed to query item: %w", err)
+	}
+
+	// 指定されなかったフィールドは現在の値を維持する
+	code, name, unitID, status := current.Code, current.Name, current.UnitID, current.Status
+	categoryID, quantity, unitPrice := current.CategoryID, current.Quantity, current.UnitPrice
+	if input.Code != nil {
+		code = *input.Code
+	}
+	if input.Name != nil {
+		name = *input.Name
+	}
+	if input.UnitID != nil {
+		unitID = *input.UnitID
+	}
+	if input.Status != nil {
+		status = *input.Status
+	}
+	if input.CategoryID != nil {
+		categoryID = input.CategoryID
+	}
+	if input.Quantity != nil {
+		quantity = input.Quantity
+	}
+	if input.UnitPrice != nil {
+		unitPrice = input.UnitPrice
 	}
 
-	if description != nil {
-		item.Description = description
+	// 属性は指定したもののみ変更し、現在の属性値とあわせて置き換える
+	var attributes map[string]interface{}
+	if input.Attributes != nil {
+		attributes = attributeValues(current.Attributes, input.Attributes)
 	}
-	item.CreatedAt = createdAt
-	item.UpdatedAt = updatedAt
 
-	return item, nil
+	item, err := service.UpdateItem(tenantID(ctx), scope, id, code, name, unitID, categoryID, quantity, unitPrice, status, attributes, input.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("item not found: %s", id)
+		}
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return nil, versionConflictError(ctx, id)
+		}
+		var verr *service.ValidationError
+		if errors.As(err, &verr) {
+			return nil, validationError(verr)
+		}
+		if errors.Is(err, repository.ErrDuplicateCode) {
+			return nil, duplicateCodeError(code)
+		}
+		if errors.Is(err, repository.ErrItemUnitInUse) {
+			return nil, itemUnitInUseError(id)
+		}
+		if errors.Is(err, repository.ErrAccessDenied) {
+			return nil, accessDeniedError()
+		}
+		return nil, fmt.Errorf("failed to update item: %w", err)
+	}
+	return toItem(item), nil
 }
 
 // DeleteItem is the resolver for the deleteItem field.
 func (r *mutationResolver) DeleteItem(ctx context.Context, id string) (bool, error) {
-	query := `DELETE FROM items WHERE id = $1`
-
-	result, err := r.DB.ExecContext(ctx, query, id)
-	if err != nil {
+	if _, err := service.DeleteItem(tenantID(ctx), accessScope(ctx, appmodel.RoleAdmin), id, principalID(ctx)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		var forbidden *repository.ItemDeleteForbiddenError
+		if errors.As(err, &forbidden) {
+			return false, itemDeleteForbiddenError(forbidden)
+		}
 		return false, fmt.Errorf("failed to delete item: %w", err)
 	}
-
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return false, fmt.Errorf("failed to get rows affected: %w", err)
-	}
-
-	return rowsAffected > 0, nil
+	return true, nil
 }
 
 // Items is the resolver for the items field.
-func (r *queryResolver) Items(ctx context.Context) ([]*model.Item, error) {
-	query := `SELECT id, name, description, quantity, created_at, updated_at FROM items ORDER BY created_at DESC`
-
-	rows, err := r.DB.QueryContext(ctx, query)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query items: %w", err)
+func (r *queryResolver) Items(ctx context.Context, limit *int, filter *model.ItemFilter) ([]*model.Item, error) {
+	n := 10
+	if limit != nil && *limit > 0 {
+		n = *limit
 	}
-	defer rows.Close()
-
-	var items []*model.Item
-	for rows.Next() {
-		item := &model.Item{}
-		var createdAt, updatedAt string
-		var description *string
 
-		if err := rows.Scan(&item.ID, &item.Name, &description, &item.Quantity, &createdAt, &updatedAt); err != nil {
-			return nil, fmt.Errorf("failed to scan item: %w", err)
-		}
-
-		if description != nil {
-			item.Description = description
+	items, _, err := service.GetItems(tenantID(ctx), accessScope(ctx, appmodel.RoleViewer), appmodel.ItemQuery{Limit: n, Attributes: toAttributeFilters(filter)})
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidItemQuery) {
+			return nil, invalidQueryError(err)
 		}
-		item.CreatedAt = createdAt
-		item.UpdatedAt = updatedAt
-
-		items = append(items, item)
+		return nil, fmt.Errorf("failed to query items: %w", err)
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating items: %w", err)
+	result := make([]*model.Item, 0, len(items))
+	for i := range items {
+		result = append(result, toItem(&items[i]))
 	}
-
-	return items, nil
+	return result, nil
 }
 
 // Item is the resolver for the item field.
 func (r *queryResolver) Item(ctx context.Context, id string) (*model.Item, error) {
-	query := `SELECT id, name, description, quantity, created_at, updated_at FROM items WHERE id = $1`
-
-	item := &model.Item{}
-	var createdAt, updatedAt string
-	var description *string
-
-	err := r.DB.QueryRowContext(ctx, query, id).
-		Scan(&item.ID, &item.Name, &description, &item.Quantity, &createdAt, &updatedAt)
-
+	item, err := service.GetItemByID(tenantID(ctx), accessScope(ctx, appmodel.RoleViewer), id)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
 		return nil, fmt.Errorf("failed to query item: %w", err)
 	}
-
-	if description != nil {
-		item.Description = description
-	}
-	item.CreatedAt = createdAt
-	item.UpdatedAt = updatedAt
-
-	return item, nil
+	return toItem(item), nil
 }
 
 // Mutation returns generated.MutationResolver implementation.
@@ -0,0 +1,35 @@
+package mail
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FileMailer はメールを送信せず、Dir に .eml ファイルとして書き出します。
+// メールサーバーのないローカル環境で招待・パスワード再設定を確認するために使用します。
+type FileMailer struct {
+	Dir  string // 出力先ディレクトリ
+	From string // 差出人
+}
+
+// Send はメールを .eml ファイルとして書き出します
+func (m *FileMailer) Send(msg Message) error {
+	if err := os.MkdirAll(m.Dir, 0o755); err != nil {
+		return err
+	}
+
+	safeTo := strings.NewReplacer("@", "_at_", "/", "_", "\\", "_").Replace(msg.To)
+	name := fmt.Sprintf("%s_%s.eml", time.Now().Format("20060102T150405.000000000"), safeTo)
+	path := filepath.Join(m.Dir, name)
+
+	if err := os.WriteFile(path, buildMessage(m.From, msg), 0o600); err != nil {
+		return err
+	}
+
+	log.Printf("[Mail] メールを書き出しました: %s", path)
+	return nil
+}
@@ -0,0 +1,53 @@
+package mail
+
+import (
+	"fmt"
+	"log"
+
+	"go-hsm-app/internal/common"
+)
+
+// Message は送信するメールを表します
+type Message struct {
+	To      string // 宛先メールアドレス
+	Subject string // 件名
+	Body    string // 本文（プレーンテキスト）
+}
+
+// Mailer はメール送信の抽象化です。
+// 本番では SMTPMailer、ローカル開発では FileMailer を使用します。
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// Default はアプリケーション全体で使用するメール送信クライアントです
+var Default Mailer
+
+// InitMailer は環境変数 MAIL_DRIVER に従ってメール送信クライアントを初期化します。
+//
+//	MAIL_DRIVER=file（デフォルト）: MAIL_OUTBOX_DIR に .eml ファイルとして書き出す
+//	MAIL_DRIVER=smtp: SMTP_HOST / SMTP_PORT / SMTP_USERNAME / SMTP_PASSWORD で送信する
+func InitMailer() error {
+	from := common.GetEnv("MAIL_FROM", "HomeStock <no-reply@homestock.local>")
+
+	switch driver := common.GetEnv("MAIL_DRIVER", "file"); driver {
+	case "smtp":
+		Default = &SMTPMailer{
+			Host:     common.GetEnv("SMTP_HOST", "localhost"),
+			Port:     common.GetEnv("SMTP_PORT", "587"),
+			Username: common.GetEnv("SMTP_USERNAME", ""),
+			Password: common.GetEnv("SMTP_PASSWORD", ""),
+			From:     from,
+		}
+	case "file":
+		Default = &FileMailer{
+			Dir:  common.GetEnv("MAIL_OUTBOX_DIR", "outbox"),
+			From: from,
+		}
+	default:
+		return fmt.Errorf("unknown MAIL_DRIVER: %s", driver)
+	}
+
+	log.Printf("✓ メール送信を初期化しました (driver: %T)", Default)
+	return nil
+}
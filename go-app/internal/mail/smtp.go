@@ -0,0 +1,32 @@
+package mail
+
+import (
+	"net"
+	"net/mail"
+	"net/smtp"
+)
+
+// SMTPMailer は SMTP サーバー経由でメールを送信します
+type SMTPMailer struct {
+	Host     string // SMTP ホスト
+	Port     string // SMTP ポート
+	Username string // 認証ユーザー（空の場合は認証なし）
+	Password string // 認証パスワード
+	From     string // 差出人
+}
+
+// Send はメールを SMTP サーバーに送信します
+func (m *SMTPMailer) Send(msg Message) error {
+	from, err := mail.ParseAddress(m.From)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+
+	addr := net.JoinHostPort(m.Host, m.Port)
+	return smtp.SendMail(addr, auth, from.Address, []string{msg.To}, buildMessage(m.From, msg))
+}
@@ -1,41 +1,179 @@
 package model
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // Category はカテゴリマスタのモデル
 type Category struct {
 	ID          string     `json:"id" db:"id"`                             // カテゴリID（UUID）
 	Code        string     `json:"code" db:"code"`                         // カテゴリコード（一意）
 	Name        string     `json:"name" db:"name"`                         // カテゴリ名称
+	ParentID    *string    `json:"parent_id" db:"parent_id"`               // 親カテゴリID（最上位は null）
 	Description *string    `json:"description,omitempty" db:"description"` // カテゴリの説明（任意）
+	Version     int        `json:"version" db:"version"`                   // バージョン（楽観的ロック用）
 	CreatedAt   time.Time  `json:"created_at" db:"created_at"`             // 作成日時
 	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`             // 更新日時
 	DeletedAt   *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`   // 削除日時（論理削除、任意）
 }
 
+// CategoryNode はカテゴリの階層（ツリー）の節点を表します
+type CategoryNode struct {
+	Category
+	Children []*CategoryNode `json:"children"` // 子カテゴリ（コード順）
+}
+
 // Unit は単位マスタのモデル
 type Unit struct {
 	ID          string     `json:"id" db:"id"`                             // 単位ID（UUID）
 	Code        string     `json:"code" db:"code"`                         // 単位コード（一意）
 	Name        string     `json:"name" db:"name"`                         // 単位名称
 	Description *string    `json:"description,omitempty" db:"description"` // 単位の説明（任意）
+	Version     int        `json:"version" db:"version"`                   // バージョン（楽観的ロック用）
 	CreatedAt   time.Time  `json:"created_at" db:"created_at"`             // 作成日時
 	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`             // 更新日時
 	DeletedAt   *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`   // 削除日時（論理削除、任意）
 }
 
+// UnitConversion は単位換算（1 換算元 = Factor 換算先）のモデル
+type UnitConversion struct {
+	FromUnitID   string    `json:"from_unit_id" db:"from_unit_id"` // 換算元の単位ID
+	FromUnitCode string    `json:"from_unit_code"`                 // 換算元の単位コード
+	ToUnitID     string    `json:"to_unit_id" db:"to_unit_id"`     // 換算先の単位ID
+	ToUnitCode   string    `json:"to_unit_code"`                   // 換算先の単位コード
+	Factor       float64   `json:"factor" db:"factor"`             // 換算係数（換算元 1 あたりの換算先の数量）
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`     // 作成日時
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`     // 更新日時
+}
+
+// ItemPackaging はアイテムの荷姿（袋・ケースなど）のモデル
+type ItemPackaging struct {
+	ItemID           string    `json:"item_id" db:"item_id"`                   // アイテムID
+	UnitID           string    `json:"unit_id" db:"unit_id"`                   // 荷姿の単位ID
+	UnitCode         string    `json:"unit_code"`                              // 荷姿の単位コード
+	UnitName         string    `json:"unit_name"`                              // 荷姿の単位名称
+	Factor           float64   `json:"factor" db:"factor"`                     // 入数（荷姿 1 あたりの内容物の単位での数量）
+	ContainsUnitID   *string   `json:"contains_unit_id" db:"contains_unit_id"` // 内容物の単位ID（nil の場合はアイテムの基本単位）
+	ContainsUnitCode *string   `json:"contains_unit_code"`                     // 内容物の単位コード
+	CreatedAt        time.Time `json:"created_at" db:"created_at"`             // 作成日時
+	UpdatedAt        time.Time `json:"updated_at" db:"updated_at"`             // 更新日時
+}
+
 // Attribute は属性マスタのモデル
 type Attribute struct {
 	ID          string     `json:"id" db:"id"`                             // 属性ID（UUID）
 	Code        string     `json:"code" db:"code"`                         // 属性コード（一意）
 	Name        string     `json:"name" db:"name"`                         // 属性名称
-	ValueType   string     `json:"value_type" db:"value_type"`             // 属性値の型（text, number, boolean, date）
+	ValueType   string     `json:"value_type" db:"value_type"`             // 属性値の型（text, number, boolean, date, enum）
 	Description *string    `json:"description,omitempty" db:"description"` // 属性の説明（任意）
+	Version     int        `json:"version" db:"version"`                   // バージョン（楽観的ロック用）
 	CreatedAt   time.Time  `json:"created_at" db:"created_at"`             // 作成日時
 	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`             // 更新日時
 	DeletedAt   *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`   // 削除日時（論理削除、任意）
 }
 
+// AttributeOption は列挙型（enum）の属性の選択肢のモデル
+type AttributeOption struct {
+	ID          string    `json:"id" db:"id"`                     // 選択肢ID
+	AttributeID string    `json:"attribute_id" db:"attribute_id"` // 属性ID
+	Code        string    `json:"code" db:"code"`                 // 選択肢コード（属性内で一意。属性値として保存する値）
+	Label       string    `json:"label" db:"label"`               // 表示名
+	SortOrder   int       `json:"sort_order" db:"sort_order"`     // 表示順（昇順）
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`     // 作成日時
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`     // 更新日時
+}
+
+// CategoryAttribute はカテゴリの属性テンプレートの項目（カテゴリのアイテムに適用する属性）のモデル
+type CategoryAttribute struct {
+	AttributeID  string  `json:"attribute_id"`            // 属性ID
+	Code         string  `json:"code"`                    // 属性コード
+	Name         string  `json:"name"`                    // 属性名称
+	ValueType    string  `json:"value_type"`              // 属性値の型
+	Required     bool    `json:"required"`                // 必須かどうか
+	DefaultValue *string `json:"default_value,omitempty"` // 既定値（任意）
+	SortOrder    int     `json:"sort_order"`              // 表示順（昇順）
+}
+
+// TemplateViolation はカテゴリの属性テンプレートを満たさないアイテムを表します
+type TemplateViolation struct {
+	ItemID        string   `json:"item_id"`        // アイテムID
+	ItemCode      string   `json:"item_code"`      // アイテムコード
+	ItemName      string   `json:"item_name"`      // アイテム名称
+	CategoryID    string   `json:"category_id"`    // カテゴリID
+	CategoryCode  string   `json:"category_code"`  // カテゴリコード
+	Missing       []string `json:"missing"`        // 値のない必須の属性コード
+	NotApplicable []string `json:"not_applicable"` // テンプレートにない属性コード
+}
+
+// ゴミ箱（論理削除したデータ）の種別
+const (
+	TrashResourceItems      = "items"      // アイテム
+	TrashResourceCategories = "categories" // カテゴリ
+	TrashResourceUnits      = "units"      // 単位
+	TrashResourceAttributes = "attributes" // 属性
+)
+
+// TrashEntry はゴミ箱の（論理削除した）データを表します
+type TrashEntry struct {
+	Resource  string    `json:"resource"`   // 種別（items, categories, units, attributes）
+	ID        string    `json:"id"`         // ID
+	Code      string    `json:"code"`       // コード
+	Name      string    `json:"name"`       // 名称
+	DeletedAt time.Time `json:"deleted_at"` // 削除日時
+}
+
+// TrashPurge はゴミ箱のデータの物理削除の結果を表します
+type TrashPurge struct {
+	Before  time.Time           `json:"before"`  // この日時より前に削除したデータを対象とした
+	Purged  map[string][]string `json:"purged"`  // 種別ごとの物理削除したID
+	Skipped map[string]int      `json:"skipped"` // 種別ごとの参照が残っているため物理削除しなかった件数
+}
+
+// ItemReference はマスタを参照しているアイテムを表します
+type ItemReference struct {
+	ID   string `json:"id"`   // アイテムID
+	Code string `json:"code"` // アイテムコード
+	Name string `json:"name"` // アイテム名称
+}
+
+// アイテムの統合での属性値の扱い
+const (
+	ItemMergeAttributeMoved = "moved" // 統合元の属性値を統合先に移した
+	ItemMergeAttributeKept  = "kept"  // 統合先に値があるため統合先の属性値を残した
+)
+
+// ItemMerge はアイテムの統合の結果（ドライランの場合は統合した場合の結果）を表します
+type ItemMerge struct {
+	SourceID     string               `json:"source_id"`      // 統合元のアイテムID（統合後に論理削除される）
+	TargetID     string               `json:"target_id"`      // 統合先のアイテムID
+	DryRun       bool                 `json:"dry_run"`        // true の場合は変更を保存していない
+	Factor       float64              `json:"factor"`         // 統合元の数量を統合先の基本単位に換算する係数
+	Quantity     float64              `json:"quantity"`       // 統合後の統合先の在庫数量
+	UnlocatedQty float64              `json:"unlocated_qty"`  // ロケーション別在庫のないアイテムの在庫数量（統合先の基本単位に換算済み）
+	UnlocatedTo  *string              `json:"unlocated_to"`   // UnlocatedQty を計上したロケーションID（ロケーション別在庫がない場合は nil）
+	StockHistory []string             `json:"stock_history"`  // 統合先に付け替えた入出庫履歴のID
+	Stocks       []ItemMergeStock     `json:"stocks"`         // ロケーションごとの在庫の合算
+	Attributes   []ItemMergeAttribute `json:"attributes"`     // 統合元の属性値の扱い
+	Item         *Item                `json:"item,omitempty"` // 統合後の統合先アイテム（ドライランの場合は省略）
+}
+
+// ItemMergeStock はアイテムの統合でのロケーション別在庫の合算を表します
+type ItemMergeStock struct {
+	LocationID string  `json:"location_id"` // ロケーションID
+	SourceQty  float64 `json:"source_qty"`  // 統合元の在庫数量（統合先の基本単位に換算済み）
+	TargetQty  float64 `json:"target_qty"`  // 統合前の統合先の在庫数量
+	MergedQty  float64 `json:"merged_qty"`  // 統合後の在庫数量
+}
+
+// ItemMergeAttribute はアイテムの統合での統合元の属性値の扱いを表します
+type ItemMergeAttribute struct {
+	Code        string  `json:"code"`                   // 属性コード
+	SourceValue string  `json:"source_value"`           // 統合元の属性値
+	TargetValue *string `json:"target_value,omitempty"` // 統合前の統合先の属性値（値がない場合は省略）
+	Action      string  `json:"action"`                 // 扱い（moved, kept）
+}
+
 // ItemAttribute はアイテムと属性の中間テーブルのモデル
 type ItemAttribute struct {
 	ItemID      string    `json:"item_id" db:"item_id"`           // アイテムID（UUID）
@@ -47,17 +185,60 @@ type ItemAttribute struct {
 
 // ItemAttributeDetail はアイテムの属性情報を属性マスタの情報と共に返すモデル
 type ItemAttributeDetail struct {
-	Code      string `json:"code"`       // 属性コード
-	Name      string `json:"name"`       // 属性名称
-	Value     string `json:"value"`      // 属性値
-	ValueType string `json:"value_type"` // 属性値の型
+	Code      string  `json:"code"`            // 属性コード
+	Name      string  `json:"name"`            // 属性名称
+	Value     string  `json:"value"`           // 属性値
+	ValueType string  `json:"value_type"`      // 属性値の型
+	Label     *string `json:"label,omitempty"` // 選択肢の表示名（enum のみ）
+}
+
+// 属性値の型
+const (
+	AttributeTypeText    = "text"    // 文字列
+	AttributeTypeNumber  = "number"  // 数値（正規形: 指数表記なしの10進数。例: 1.5, -20）
+	AttributeTypeBoolean = "boolean" // 真偽値（正規形: true / false）
+	AttributeTypeDate    = "date"    // 日付（正規形: YYYY-MM-DD）
+	AttributeTypeEnum    = "enum"    // 列挙（値は選択肢のコード）
+)
+
+// ItemAttributeChanges はアイテムの属性値の変更内容を表します
+// 値は属性の型に応じて正規化済みであること
+type ItemAttributeChanges struct {
+	Replace bool              // true の場合、Set に含まれない属性値を削除します
+	Set     map[string]string // 属性コード → 設定する値
+	Delete  []string          // 削除する属性コード
+}
+
+// ユーザー権限
+const (
+	RoleAdmin    = "admin"    // 管理者
+	RoleOperator = "operator" // 担当者
+	RoleViewer   = "viewer"   // 閲覧者
+)
+
+// RoleRank はユーザー権限の強さを表します（大きいほど強い）
+var RoleRank = map[string]int{
+	RoleViewer:   1,
+	RoleOperator: 2,
+	RoleAdmin:    3,
+}
+
+// Tenant はデータを分離する単位（世帯・組織）を表すモデル
+type Tenant struct {
+	ID        string    `json:"id" db:"id"`                 // テナントID
+	Code      string    `json:"code" db:"code"`             // テナントコード（一意）
+	Name      string    `json:"name" db:"name"`             // テナント名称
+	CreatedAt time.Time `json:"created_at" db:"created_at"` // 作成日時
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"` // 更新日時
 }
 
 // User はシステムを利用するユーザーを表すモデル
 type User struct {
 	ID        string     `json:"id" db:"id"`                           // ユーザーID（UUID）
+	TenantID  string     `json:"tenant_id" db:"tenant_id"`             // 所属テナントID
 	Email     string     `json:"email" db:"email"`                     // メールアドレス（一意）
 	Role      string     `json:"role" db:"role"`                       // 権限（admin: 管理者、operator: 担当者、viewer: 閲覧者）
+	Version   int        `json:"version" db:"version"`                 // バージョン（楽観的ロック用）
 	CreatedAt time.Time  `json:"created_at" db:"created_at"`           // 作成日時
 	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`           // 更新日時
 	DeletedAt *time.Time `json:"deleted_at,omitempty" db:"deleted_at"` // 削除日時（論理削除、任意）
@@ -74,6 +255,7 @@ type Item struct {
 	UnitPrice  *int       `json:"unit_price,omitempty" db:"unit_price"`   // 単価（円）
 	Status     string     `json:"status" db:"status"`                     // ステータス（active: 有効、inactive: 無効）
 	CreatedBy  *string    `json:"created_by,omitempty" db:"created_by"`   // 作成者のユーザーID（UUID、任意）
+	Version    int        `json:"version" db:"version"`                   // バージョン（楽観的ロック用）
 	CreatedAt  time.Time  `json:"created_at" db:"created_at"`             // 作成日時
 	UpdatedAt  time.Time  `json:"updated_at" db:"updated_at"`             // 更新日時
 	DeletedAt  *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`   // 削除日時（論理削除、任意）
@@ -99,3 +281,169 @@ type StockHistory struct {
 	CreatedBy    *string   `json:"created_by,omitempty" db:"created_by"`       // 実行者のユーザーID（任意）
 	CreatedAt    time.Time `json:"created_at" db:"created_at"`                 // 作成日時
 }
+
+// RefreshToken はサーバー側で管理するリフレッシュトークンを表すモデル
+type RefreshToken struct {
+	ID            string     `json:"id" db:"id"`                                   // トークンID
+	UserID        string     `json:"user_id" db:"user_id"`                         // 所有ユーザーID
+	FamilyID      string     `json:"family_id" db:"family_id"`                     // トークンファミリーID（セッションID）
+	ExpiresAt     time.Time  `json:"expires_at" db:"expires_at"`                   // 有効期限
+	RotatedAt     *time.Time `json:"rotated_at,omitempty" db:"rotated_at"`         // ローテーション日時
+	RevokedAt     *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`         // 失効日時
+	RevokedReason *string    `json:"revoked_reason,omitempty" db:"revoked_reason"` // 失効理由
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`                   // 作成日時
+}
+
+// ユーザートークンの用途
+const (
+	UserTokenPurposeInvite        = "invite"         // 招待
+	UserTokenPurposePasswordReset = "password_reset" // パスワード再設定
+)
+
+// APIKey はスクリプトやデバイスが利用する API キーを表すモデル
+// キー本体は作成時にのみ返却し、DB にはハッシュのみを保存します
+type APIKey struct {
+	ID         string     `json:"id" db:"id"`                               // API キーID
+	UserID     string     `json:"user_id" db:"user_id"`                     // 所有ユーザーID
+	Name       string     `json:"name" db:"name"`                           // キーの名称
+	Prefix     string     `json:"prefix" db:"prefix"`                       // 識別用プレフィックス
+	Roles      []string   `json:"roles" db:"roles"`                         // 許可するロール
+	ExpiresAt  *time.Time `json:"expires_at,omitempty" db:"expires_at"`     // 有効期限（任意）
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" db:"last_used_at"` // 最終使用日時
+	LastUsedIP *string    `json:"last_used_ip,omitempty" db:"last_used_ip"` // 最終使用元IP
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`     // 失効日時
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`               // 作成日時
+}
+
+// AuditLog は重要操作の監査ログを表すモデル
+type AuditLog struct {
+	ID             int64           `json:"id" db:"id"`                                     // ログID
+	UserID         *string         `json:"user_id,omitempty" db:"user_id"`                 // 実行ユーザーID（なりすまし中は対象ユーザー）
+	ImpersonatorID *string         `json:"impersonator_id,omitempty" db:"impersonator_id"` // なりすましを行った管理者のユーザーID
+	Action         string          `json:"action" db:"action"`                             // 操作内容
+	Resource       string          `json:"resource" db:"resource"`                         // リソース種別
+	ResourceID     *string         `json:"resource_id,omitempty" db:"resource_id"`         // リソースID
+	Diff           json.RawMessage `json:"diff,omitempty" db:"diff"`                       // 変更差分・補足情報（JSON形式）
+	RequestID      *string         `json:"request_id,omitempty" db:"request_id"`           // リクエストID
+	CreatedAt      time.Time       `json:"created_at" db:"created_at"`                     // 作成日時
+}
+
+// AuthThrottle は認証失敗のトラッキング状態を表すモデル
+type AuthThrottle struct {
+	Scope         string     `json:"scope" db:"scope"`                         // 対象の種別（account, api_key, ip）
+	Subject       string     `json:"subject" db:"subject"`                     // 対象
+	FailureCount  int        `json:"failure_count" db:"failure_count"`         // 連続失敗回数
+	LockoutCount  int        `json:"lockout_count" db:"lockout_count"`         // ロック回数
+	LastFailureAt time.Time  `json:"last_failure_at" db:"last_failure_at"`     // 最終失敗日時
+	LockedUntil   *time.Time `json:"locked_until,omitempty" db:"locked_until"` // 制限解除日時
+	LockReason    *string    `json:"lock_reason,omitempty" db:"lock_reason"`   // 制限の種類
+}
+
+// 権限付与の範囲の種別
+const (
+	GrantScopeLocation = "location" // ロケーションとその子孫
+	GrantScopeCategory = "category" // カテゴリとその子孫
+)
+
+// UserGrant はロケーション・カテゴリ単位でユーザーに付与した権限を表すモデル
+type UserGrant struct {
+	ID        string    `json:"id" db:"id"`                           // 権限付与ID
+	UserID    string    `json:"user_id" db:"user_id"`                 // 付与先のユーザーID
+	ScopeType string    `json:"scope_type" db:"scope_type"`           // 範囲の種別（location, category）
+	ScopeID   string    `json:"scope_id" db:"scope_id"`               // 範囲の対象（ロケーションID・カテゴリID）
+	Role      string    `json:"role" db:"role"`                       // 範囲内で許可するロール
+	CreatedBy *string   `json:"created_by,omitempty" db:"created_by"` // 付与した管理者のユーザーID
+	CreatedAt time.Time `json:"created_at" db:"created_at"`           // 作成日時
+}
+
+// AccessScope は権限付与によるデータの参照・操作範囲を表します。
+// nil の場合は制限しません（管理者など）。
+type AccessScope struct {
+	UserID string   // 権限付与を参照するユーザーID
+	Roles  []string // 必要な権限を満たす付与ロール
+}
+
+// 入出庫の種別
+const (
+	StockKindIn       = "IN"       // 入庫
+	StockKindOut      = "OUT"      // 出庫
+	StockKindAdjust   = "ADJUST"   // 調整
+	StockKindTransfer = "TRANSFER" // 移動
+)
+
+// アイテム削除のポリシー（在庫・入出庫履歴があるアイテムの削除の扱い）
+const (
+	ItemDeletePolicyForbid    = "forbid"     // 在庫・入出庫履歴がある場合は削除しない
+	ItemDeletePolicyZeroStock = "zero_stock" // 在庫が 0 の場合のみ削除する（入出庫履歴は問わない）
+	ItemDeletePolicyForce     = "force"      // 残っている在庫を調整（ADJUST）で 0 にしてから削除する
+)
+
+// アイテムの削除を拒否した規則
+const (
+	ItemDeleteRuleStockExists   = "stock_exists"   // 在庫が残っている
+	ItemDeleteRuleHistoryExists = "history_exists" // 入出庫履歴がある
+)
+
+// StockMovement は入出庫（在庫の増減・移動）の指示を表します
+type StockMovement struct {
+	ItemID       string  `json:"item_id"`                 // アイテムID
+	Kind         string  `json:"kind"`                    // 種別（IN, OUT, ADJUST, TRANSFER）
+	Qty          float64 `json:"qty"`                     // 数量（ADJUST の場合は符号付きの増減量）
+	UnitID       *string `json:"unit_id,omitempty"`       // 数量の単位ID（省略時はアイテムの基本単位。荷姿・換算可能な単位を指定可）
+	LocationFrom *string `json:"location_from,omitempty"` // 出庫元ロケーション（OUT, TRANSFER）
+	LocationTo   *string `json:"location_to,omitempty"`   // 入庫先ロケーション（IN, ADJUST, TRANSFER）
+	Reason       *string `json:"reason,omitempty"`        // 理由・備考
+	CreatedBy    *string `json:"-"`                       // 実行者のユーザーID
+
+	// UnlocatedLocationID はロケーション別在庫のないアイテムの在庫数量を計上するロケーションです
+	// （在庫数量がありロケーション別在庫がないアイテムの場合に必須）
+	UnlocatedLocationID *string `json:"unlocated_location_id,omitempty"`
+}
+
+// ItemQuery はアイテム一覧の検索条件・ソート・ページネーションを表します
+type ItemQuery struct {
+	Keyword            string            // キーワード（コード・名称・属性値を正規化して部分一致。空白区切りで AND 検索）
+	Code               string            // アイテムコード（部分一致）
+	Name               string            // アイテム名称（部分一致）
+	Categories         []string          // カテゴリID またはカテゴリコード（いずれかに一致）
+	IncludeDescendants bool              // Categories の子孫カテゴリのアイテムも含めるかどうか
+	Status             string            // ステータス（active, inactive）
+	Stock              string            // 在庫有無（in: 在庫あり, out: 在庫なし）
+	Attributes         []AttributeFilter // 属性値の条件（全て満たすもの）
+	Sort               string            // ソート項目（relevance, code, name, updated_at, quantity, created_at。先頭に - で降順）
+	Limit              int               // 取得件数
+	Offset             int               // 取得開始位置
+}
+
+// 在庫有無の検索条件
+const (
+	StockFilterIn  = "in"  // 在庫あり（在庫数量 > 0）
+	StockFilterOut = "out" // 在庫なし（在庫数量が 0 以下または未設定）
+)
+
+// AttributeFilter は属性値による絞り込み条件を表します
+// 値は attributes.value_type に応じた型で比較します（number は数値、date は日付として大小を比較）
+type AttributeFilter struct {
+	Code      string   // 属性コード
+	Operator  string   // 比較演算子（AttributeFilterEq など）
+	Values    []string // 比較する値（between は下限・上限の 2 つ、in は 1 つ以上、それ以外は 1 つ）
+	ValueType string   // 属性の型（サービス層で属性定義から設定）
+}
+
+// 属性値の比較演算子
+const (
+	AttributeFilterEq       = "eq"       // 等しい
+	AttributeFilterNe       = "ne"       // 等しくない（属性値が未設定のアイテムも含む）
+	AttributeFilterLt       = "lt"       // より小さい（number, date）
+	AttributeFilterGt       = "gt"       // より大きい（number, date）
+	AttributeFilterBetween  = "between"  // 下限以上・上限以下（number, date）
+	AttributeFilterContains = "contains" // 部分一致、大文字小文字を区別しない（text）
+	AttributeFilterIn       = "in"       // いずれかに等しい
+)
+
+// ItemSuggestion はアイテム検索の入力補完候補を表します
+type ItemSuggestion struct {
+	ID   string `json:"id"`
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
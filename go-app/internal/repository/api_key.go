@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"database/sql"
+	"go-hsm-app/internal/common"
+	"go-hsm-app/internal/model"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// CreateAPIKey は API キーを保存します
+func CreateAPIKey(userID, name, prefix, keyHash string, roles []string, expiresAt *time.Time) (*model.APIKey, error) {
+	log.Printf("[Repository] CreateAPIKey - user_id: %s, name: %s, prefix: %s", userID, name, prefix)
+
+	var key model.APIKey
+	err := common.DB.QueryRow(`
+		INSERT INTO api_keys (user_id, name, prefix, key_hash, roles, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, user_id, name, prefix, roles, expires_at, created_at
+	`, userID, name, prefix, keyHash, pq.Array(roles), expiresAt).Scan(
+		&key.ID,
+		&key.UserID,
+		&key.Name,
+		&key.Prefix,
+		pq.Array(&key.Roles),
+		&key.ExpiresAt,
+		&key.CreatedAt,
+	)
+
+	if err != nil {
+		log.Printf("[Repository] API キー作成エラー: %v", err)
+		return nil, err
+	}
+
+	log.Printf("[Repository] API キー作成成功: %s", key.ID)
+	return &key, nil
+}
+
+// FetchAPIKeysByUser はユーザーの API キー一覧を取得します（失効済みを含む）
+func FetchAPIKeysByUser(userID string) ([]model.APIKey, error) {
+	log.Printf("[Repository] FetchAPIKeysByUser - user_id: %s", userID)
+
+	rows, err := common.DB.Query(`
+		SELECT id, user_id, name, prefix, roles, expires_at, last_used_at, last_used_ip, revoked_at, created_at
+		FROM api_keys
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`, userID)
+	if err != nil {
+		log.Printf("[Repository] DB クエリエラー: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []model.APIKey{}
+	for rows.Next() {
+		var key model.APIKey
+		if err := rows.Scan(
+			&key.ID,
+			&key.UserID,
+			&key.Name,
+			&key.Prefix,
+			pq.Array(&key.Roles),
+			&key.ExpiresAt,
+			&key.LastUsedAt,
+			&key.LastUsedIP,
+			&key.RevokedAt,
+			&key.CreatedAt,
+		); err != nil {
+			log.Printf("[Repository] スキャンエラー: %v", err)
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	log.Printf("[Repository] 取得成功: %d件の API キー", len(keys))
+	return keys, rows.Err()
+}
+
+// FetchAPIKeyByPrefix はプレフィックスで API キーとハッシュ、所有ユーザー（ID・テナント・権限）を取得します。
+// 所有ユーザーが削除済みの場合は sql.ErrNoRows を返します。
+func FetchAPIKeyByPrefix(prefix string) (*model.APIKey, string, *model.User, error) {
+	var key model.APIKey
+	var owner model.User
+	var keyHash string
+	err := common.DB.QueryRow(`
+		SELECT k.id, k.user_id, k.name, k.prefix, k.roles, k.expires_at, k.revoked_at, k.created_at,
+			k.key_hash, u.id, u.tenant_id, u.role
+		FROM api_keys k
+		INNER JOIN users u ON k.user_id = u.id AND u.deleted_at IS NULL
+		WHERE k.prefix = $1
+	`, prefix).Scan(
+		&key.ID,
+		&key.UserID,
+		&key.Name,
+		&key.Prefix,
+		pq.Array(&key.Roles),
+		&key.ExpiresAt,
+		&key.RevokedAt,
+		&key.CreatedAt,
+		&keyHash,
+		&owner.ID,
+		&owner.TenantID,
+		&owner.Role,
+	)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("[Repository] DBクエリエラー: %v", err)
+		}
+		return nil, "", nil, err
+	}
+	return &key, keyHash, &owner, nil
+}
+
+// TouchAPIKey は API キーの最終使用日時と使用元IPを記録します。
+// 書き込み量を抑えるため、前回の記録から1分以上経過している場合のみ更新します。
+func TouchAPIKey(id, ipAddress string) error {
+	_, err := common.DB.Exec(`
+		UPDATE api_keys
+		SET last_used_at = CURRENT_TIMESTAMP, last_used_ip = $2
+		WHERE id = $1
+			AND (last_used_at IS NULL OR last_used_at < CURRENT_TIMESTAMP - INTERVAL '1 minute' OR last_used_ip IS DISTINCT FROM $2)
+	`, id, ipAddress)
+	if err != nil {
+		log.Printf("[Repository] API キー使用記録エラー: %v", err)
+	}
+	return err
+}
+
+// RevokeAPIKey はユーザーの API キーを失効させます
+func RevokeAPIKey(userID, id string) error {
+	log.Printf("[Repository] RevokeAPIKey - user_id: %s, id: %s", userID, id)
+
+	result, err := common.DB.Exec(`
+		UPDATE api_keys
+		SET revoked_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+	`, id, userID)
+	if err != nil {
+		log.Printf("[Repository] API キー失効エラー: %v", err)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("[Repository] RowsAffected取得エラー: %v", err)
+		return err
+	}
+
+	if rowsAffected == 0 {
+		log.Printf("[Repository] API キーが見つかりません: %s", id)
+		return sql.ErrNoRows
+	}
+
+	log.Printf("[Repository] API キー失効成功: %s", id)
+	return nil
+}
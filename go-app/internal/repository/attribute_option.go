@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+
+	"go-hsm-app/internal/common"
+	"go-hsm-app/internal/model"
+)
+
+var (
+	// ErrAttributeOptionExists は同じ属性に同じコードの選択肢が既に存在する場合のエラーです
+	ErrAttributeOptionExists = errors.New("attribute option code already exists")
+	// ErrAttributeOptionNotFound は enum 属性の値が選択肢にない場合のエラーです
+	ErrAttributeOptionNotFound = errors.New("attribute option not found")
+	// ErrAttributeOptionInUse は削除しようとした選択肢がアイテムで使用されている場合のエラーです
+	ErrAttributeOptionInUse = errors.New("attribute option is in use")
+)
+
+// FetchAttributeOptions は属性の選択肢を表示順に取得します
+func FetchAttributeOptions(tenantID, attributeID string) ([]model.AttributeOption, error) {
+	log.Printf("[Repository] FetchAttributeOptions - tenant_id: %s, attribute_id: %s", tenantID, attributeID)
+
+	rows, err := common.DB.Query(`
+		SELECT id, attribute_id, code, label, sort_order, created_at, updated_at
+		FROM attribute_options
+		WHERE tenant_id = $1 AND attribute_id = $2
+		ORDER BY sort_order, code
+	`, tenantID, attributeID)
+	if err != nil {
+		log.Printf("[Repository] DB クエリエラー: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	options := []model.AttributeOption{}
+	for rows.Next() {
+		var option model.AttributeOption
+		if err := rows.Scan(
+			&option.ID,
+			&option.AttributeID,
+			&option.Code,
+			&option.Label,
+			&option.SortOrder,
+			&option.CreatedAt,
+			&option.UpdatedAt,
+		); err != nil {
+			log.Printf("[Repository] スキャンエラー: %v", err)
+			return nil, err
+		}
+		options = append(options, option)
+	}
+
+	log.Printf("[Repository] 取得成功: %d件の選択肢", len(options))
+	return options, rows.Err()
+}
+
+// CreateAttributeOption は属性に選択肢を追加します
+// sortOrder を指定しない場合は末尾に追加します。同じコードの選択肢がある場合は ErrAttributeOptionExists を返します
+func CreateAttributeOption(tenantID, attributeID, code, label string, sortOrder *int) (*model.AttributeOption, error) {
+	log.Printf("[Repository] CreateAttributeOption - tenant_id: %s, attribute_id: %s, code: %s", tenantID, attributeID, code)
+
+	var option model.AttributeOption
+	err := common.DB.QueryRow(`
+		INSERT INTO attribute_options (tenant_id, attribute_id, code, label, sort_order)
+		SELECT $1, $2, $3, $4, COALESCE($5::INTEGER, (
+			SELECT COALESCE(MAX(sort_order), 0) + 1 FROM attribute_options WHERE attribute_id = $2
+		))
+		RETURNING id, attribute_id, code, label, sort_order, created_at, updated_at
+	`, tenantID, attributeID, code, label, sortOrder).Scan(
+		&option.ID,
+		&option.AttributeID,
+		&option.Code,
+		&option.Label,
+		&option.SortOrder,
+		&option.CreatedAt,
+		&option.UpdatedAt,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, fmt.Errorf("%w: %s", ErrAttributeOptionExists, code)
+		}
+		log.Printf("[Repository] 選択肢作成エラー: %v", err)
+		return nil, err
+	}
+
+	log.Printf("[Repository] 選択肢作成成功: %s (code: %s)", option.ID, option.Code)
+	return &option, nil
+}
+
+// UpdateAttributeOption は選択肢の表示名・表示順を更新します
+// 選択肢のコードは属性値として保存されているため変更できません。sortOrder が nil の場合は表示順を変更しません
+func UpdateAttributeOption(tenantID, attributeID, code, label string, sortOrder *int) (*model.AttributeOption, error) {
+	log.Printf("[Repository] UpdateAttributeOption - tenant_id: %s, attribute_id: %s, code: %s", tenantID, attributeID, code)
+
+	var option model.AttributeOption
+	err := common.DB.QueryRow(`
+		UPDATE attribute_options
+		SET label = $4, sort_order = COALESCE($5::INTEGER, sort_order), updated_at = CURRENT_TIMESTAMP
+		WHERE tenant_id = $1 AND attribute_id = $2 AND code = $3
+		RETURNING id, attribute_id, code, label, sort_order, created_at, updated_at
+	`, tenantID, attributeID, code, label, sortOrder).Scan(
+		&option.ID,
+		&option.AttributeID,
+		&option.Code,
+		&option.Label,
+		&option.SortOrder,
+		&option.CreatedAt,
+		&option.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Printf("[Repository] 選択肢が見つかりません: %s", code)
+		} else {
+			log.Printf("[Repository] 選択肢更新エラー: %v", err)
+		}
+		return nil, err
+	}
+
+	log.Printf("[Repository] 選択肢更新成功: %s", option.ID)
+	return &option, nil
+}
+
+// DeleteAttributeOption は選択肢を削除します
+// アイテム（削除済みを含む）で使用中の場合は削除せず、使用しているアイテム数とともに ErrAttributeOptionInUse を返します
+func DeleteAttributeOption(tenantID, attributeID, code string) error {
+	log.Printf("[Repository] DeleteAttributeOption - tenant_id: %s, attribute_id: %s, code: %s", tenantID, attributeID, code)
+
+	tx, err := common.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// 削除と属性値の書き込みが同時に行われないよう、選択肢をロックしてから使用状況を確認する
+	var id string
+	err = tx.QueryRow(`
+		SELECT id FROM attribute_options
+		WHERE tenant_id = $1 AND attribute_id = $2 AND code = $3
+		FOR UPDATE
+	`, tenantID, attributeID, code).Scan(&id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Printf("[Repository] 選択肢が見つかりません: %s", code)
+		}
+		return err
+	}
+
+	var inUse int
+	if err := tx.QueryRow(`
+		SELECT COUNT(*) FROM item_attributes
+		WHERE tenant_id = $1 AND attribute_id = $2 AND value = $3
+	`, tenantID, attributeID, code).Scan(&inUse); err != nil {
+		log.Printf("[Repository] 使用状況の確認エラー: %v", err)
+		return err
+	}
+	if inUse > 0 {
+		log.Printf("[Repository] 選択肢は使用中です: %s (%d件)", code, inUse)
+		return fmt.Errorf("%w: used by %d item(s)", ErrAttributeOptionInUse, inUse)
+	}
+
+	if _, err := tx.Exec(`DELETE FROM attribute_options WHERE id = $1`, id); err != nil {
+		log.Printf("[Repository] 選択肢削除エラー: %v", err)
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	log.Printf("[Repository] 選択肢削除成功: %s", id)
+	return nil
+}
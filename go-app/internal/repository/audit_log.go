@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"go-hsm-app/internal/common"
+	"go-hsm-app/internal/model"
+	"log"
+)
+
+// CreateAuditLog は監査ログを記録します
+func CreateAuditLog(entry model.AuditLog) error {
+	log.Printf("[Repository] CreateAuditLog - action: %s, resource: %s", entry.Action, entry.Resource)
+	return createAuditLog(common.DB, entry)
+}
+
+// createAuditLog は指定した接続（トランザクション）で監査ログを記録します
+func createAuditLog(db execer, entry model.AuditLog) error {
+	var diff interface{}
+	if len(entry.Diff) > 0 {
+		diff = []byte(entry.Diff)
+	}
+
+	_, err := db.Exec(`
+		INSERT INTO audit_logs (user_id, impersonator_id, action, resource, resource_id, diff, request_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, entry.UserID, entry.ImpersonatorID, entry.Action, entry.Resource, entry.ResourceID, diff, entry.RequestID)
+	if err != nil {
+		log.Printf("[Repository] 監査ログ記録エラー: %v", err)
+		return err
+	}
+	return nil
+}
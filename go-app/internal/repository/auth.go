@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"database/sql"
+	"go-hsm-app/internal/common"
+	"go-hsm-app/internal/model"
+	"log"
+)
+
+// FetchUserCredentialsByEmail はメールアドレスでユーザーとパスワードハッシュを取得します
+func FetchUserCredentialsByEmail(email string) (*model.User, string, error) {
+	log.Printf("[Repository] FetchUserCredentialsByEmail - email: %s", email)
+
+	var user model.User
+	var passwordHash string
+	err := common.DB.QueryRow(`
+		SELECT id, tenant_id, email, role, password_hash, version, created_at, updated_at
+		FROM users
+		WHERE email = $1 AND deleted_at IS NULL
+	`, email).Scan(
+		&user.ID,
+		&user.TenantID,
+		&user.Email,
+		&user.Role,
+		&passwordHash,
+		&user.Version,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Printf("[Repository] ユーザーが見つかりません: %s", email)
+			return nil, "", sql.ErrNoRows
+		}
+		log.Printf("[Repository] DBクエリエラー: %v", err)
+		return nil, "", err
+	}
+
+	return &user, passwordHash, nil
+}
+
+// FetchUserByID はIDでユーザーを取得します
+func FetchUserByID(id string) (*model.User, error) {
+	log.Printf("[Repository] FetchUserByID - id: %s", id)
+
+	var user model.User
+	err := common.DB.QueryRow(`
+		SELECT id, tenant_id, email, role, version, created_at, updated_at
+		FROM users
+		WHERE id = $1 AND deleted_at IS NULL
+	`, id).Scan(
+		&user.ID,
+		&user.TenantID,
+		&user.Email,
+		&user.Role,
+		&user.Version,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Printf("[Repository] ユーザーが見つかりません: %s", id)
+			return nil, sql.ErrNoRows
+		}
+		log.Printf("[Repository] DBクエリエラー: %v", err)
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// FetchUserByEmail はメールアドレス（大文字小文字を区別しない）でユーザーを取得します
+func FetchUserByEmail(email string) (*model.User, error) {
+	log.Printf("[Repository] FetchUserByEmail - email: %s", email)
+
+	var user model.User
+	err := common.DB.QueryRow(`
+		SELECT id, tenant_id, email, role, version, created_at, updated_at
+		FROM users
+		WHERE LOWER(email) = LOWER($1) AND deleted_at IS NULL
+		ORDER BY created_at
+		LIMIT 1
+	`, email).Scan(
+		&user.ID,
+		&user.TenantID,
+		&user.Email,
+		&user.Role,
+		&user.Version,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("[Repository] DBクエリエラー: %v", err)
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// FetchTenantUser はテナント内のユーザーを ID で取得します。
+// 別テナントのユーザーの場合は sql.ErrNoRows を返します。
+func FetchTenantUser(tenantID, id string) (*model.User, error) {
+	user, err := FetchUserByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if user.TenantID != tenantID {
+		log.Printf("[Repository] 別テナントのユーザーです: %s", id)
+		return nil, sql.ErrNoRows
+	}
+	return user, nil
+}
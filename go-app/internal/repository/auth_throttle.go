@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"database/sql"
+	"go-hsm-app/internal/common"
+	"go-hsm-app/internal/model"
+	"log"
+	"time"
+)
+
+// FetchActiveThrottle は対象が現在認証を制限されている場合にその状態を返します。
+// 制限されていない場合は sql.ErrNoRows を返します。
+func FetchActiveThrottle(scope, subject string) (*model.AuthThrottle, error) {
+	var t model.AuthThrottle
+	err := common.DB.QueryRow(`
+		SELECT scope, subject, failure_count, lockout_count, last_failure_at, locked_until, lock_reason
+		FROM auth_throttles
+		WHERE scope = $1 AND subject = $2 AND locked_until > CURRENT_TIMESTAMP
+	`, scope, subject).Scan(
+		&t.Scope,
+		&t.Subject,
+		&t.FailureCount,
+		&t.LockoutCount,
+		&t.LastFailureAt,
+		&t.LockedUntil,
+		&t.LockReason,
+	)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("[Repository] 認証制限の取得エラー: %v", err)
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+// IncrementAuthFailure は認証失敗回数を加算し、更新後の状態を返します。
+// 最終失敗から window 以上経過している場合は失敗回数を 1 から数え直します。
+func IncrementAuthFailure(scope, subject string, window time.Duration) (*model.AuthThrottle, error) {
+	var t model.AuthThrottle
+	err := common.DB.QueryRow(`
+		INSERT INTO auth_throttles (scope, subject, failure_count, last_failure_at)
+		VALUES ($1, $2, 1, CURRENT_TIMESTAMP)
+		ON CONFLICT (scope, subject) DO UPDATE SET
+			failure_count = CASE
+				WHEN auth_throttles.last_failure_at < CURRENT_TIMESTAMP - make_interval(secs => $3) THEN 1
+				ELSE auth_throttles.failure_count + 1
+			END,
+			last_failure_at = CURRENT_TIMESTAMP
+		RETURNING scope, subject, failure_count, lockout_count, last_failure_at, locked_until, lock_reason
+	`, scope, subject, window.Seconds()).Scan(
+		&t.Scope,
+		&t.Subject,
+		&t.FailureCount,
+		&t.LockoutCount,
+		&t.LastFailureAt,
+		&t.LockedUntil,
+		&t.LockReason,
+	)
+	if err != nil {
+		log.Printf("[Repository] 認証失敗の記録エラー: %v", err)
+		return nil, err
+	}
+	return &t, nil
+}
+
+// SetAuthThrottleLock は認証制限を設定します。
+// lockout の場合はロック回数を加算し、失敗回数をリセットします。
+func SetAuthThrottleLock(scope, subject string, lockedUntil time.Time, reason string) error {
+	_, err := common.DB.Exec(`
+		UPDATE auth_throttles
+		SET locked_until = $3,
+			lock_reason = $4,
+			lockout_count = lockout_count + CASE WHEN $4 = 'lockout' THEN 1 ELSE 0 END,
+			failure_count = CASE WHEN $4 = 'lockout' THEN 0 ELSE failure_count END
+		WHERE scope = $1 AND subject = $2
+	`, scope, subject, lockedUntil, reason)
+	if err != nil {
+		log.Printf("[Repository] 認証制限の設定エラー: %v", err)
+	}
+	return err
+}
+
+// ClearAuthThrottle は対象の認証失敗記録とロックを削除し、削除前にロック中だったかを返します
+func ClearAuthThrottle(scope, subject string) (bool, error) {
+	log.Printf("[Repository] ClearAuthThrottle - scope: %s, subject: %s", scope, subject)
+
+	var wasLocked bool
+	err := common.DB.QueryRow(`
+		DELETE FROM auth_throttles
+		WHERE scope = $1 AND subject = $2
+		RETURNING COALESCE(locked_until > CURRENT_TIMESTAMP, FALSE)
+	`, scope, subject).Scan(&wasLocked)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		log.Printf("[Repository] 認証制限の削除エラー: %v", err)
+		return false, err
+	}
+	return wasLocked, nil
+}
@@ -0,0 +1,260 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"go-hsm-app/internal/common"
+	"go-hsm-app/internal/model"
+
+	"github.com/lib/pq"
+)
+
+// ErrTemplateViolation はアイテムの属性値がカテゴリの属性テンプレートを満たさない場合のエラーです
+var ErrTemplateViolation = errors.New("item attributes violate the category template")
+
+// TemplateViolationError はカテゴリの属性テンプレートに違反する属性コードを保持するエラーです
+// errors.Is(err, ErrTemplateViolation) で判定できます
+type TemplateViolationError struct {
+	Missing       []string // 値のない必須の属性コード
+	NotApplicable []string // テンプレートにない属性コード
+}
+
+func (e *TemplateViolationError) Error() string {
+	var details []string
+	if len(e.Missing) > 0 {
+		details = append(details, "missing required: "+strings.Join(e.Missing, ", "))
+	}
+	if len(e.NotApplicable) > 0 {
+		details = append(details, "not applicable: "+strings.Join(e.NotApplicable, ", "))
+	}
+	return fmt.Sprintf("%v (%s)", ErrTemplateViolation, strings.Join(details, "; "))
+}
+
+func (e *TemplateViolationError) Unwrap() error {
+	return ErrTemplateViolation
+}
+
+// FetchCategoryAttributes はカテゴリの属性テンプレートを表示順に取得します
+func FetchCategoryAttributes(tenantID, categoryID string) ([]model.CategoryAttribute, error) {
+	log.Printf("[Repository] FetchCategoryAttributes - tenant_id: %s, category_id: %s", tenantID, categoryID)
+
+	rows, err := common.DB.Query(`
+		SELECT a.id, a.code, a.name, a.value_type, ca.required, ca.default_value, ca.sort_order
+		FROM category_attributes ca
+		INNER JOIN attributes a ON ca.attribute_id = a.id AND a.deleted_at IS NULL
+		WHERE ca.tenant_id = $1 AND ca.category_id = $2
+		ORDER BY ca.sort_order, a.code
+	`, tenantID, categoryID)
+	if err != nil {
+		log.Printf("[Repository] DB クエリエラー: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	attributes := []model.CategoryAttribute{}
+	for rows.Next() {
+		var attribute model.CategoryAttribute
+		if err := rows.Scan(
+			&attribute.AttributeID,
+			&attribute.Code,
+			&attribute.Name,
+			&attribute.ValueType,
+			&attribute.Required,
+			&attribute.DefaultValue,
+			&attribute.SortOrder,
+		); err != nil {
+			log.Printf("[Repository] スキャンエラー: %v", err)
+			return nil, err
+		}
+		attributes = append(attributes, attribute)
+	}
+
+	log.Printf("[Repository] 取得成功: %d件のテンプレート属性", len(attributes))
+	return attributes, rows.Err()
+}
+
+// ReplaceCategoryAttributes はカテゴリの属性テンプレートを attributes の内容で置き換えます
+// 属性は Code で指定し、表示順は attributes の並び順になります。カテゴリが存在しない場合は sql.ErrNoRows を返します
+func ReplaceCategoryAttributes(tenantID, categoryID string, attributes []model.CategoryAttribute) ([]model.CategoryAttribute, error) {
+	log.Printf("[Repository] ReplaceCategoryAttributes - tenant_id: %s, category_id: %s, count: %d", tenantID, categoryID, len(attributes))
+
+	tx, err := common.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var id string
+	err = tx.QueryRow(`
+		SELECT id FROM categories
+		WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL
+		FOR UPDATE
+	`, categoryID, tenantID).Scan(&id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Printf("[Repository] カテゴリが見つかりません: %s", categoryID)
+		}
+		return nil, err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM category_attributes WHERE category_id = $1`, id); err != nil {
+		log.Printf("[Repository] テンプレート削除エラー: %v", err)
+		return nil, err
+	}
+
+	for i, attribute := range attributes {
+		result, err := tx.Exec(`
+			INSERT INTO category_attributes (tenant_id, category_id, attribute_id, required, default_value, sort_order)
+			SELECT $1, $2, a.id, $4, $5, $6
+			FROM attributes a
+			WHERE a.tenant_id = $1 AND a.code = $3 AND a.deleted_at IS NULL
+		`, tenantID, id, attribute.Code, attribute.Required, attribute.DefaultValue, i+1)
+		if err != nil {
+			log.Printf("[Repository] テンプレート登録エラー (code: %s): %v", attribute.Code, err)
+			return nil, err
+		}
+		if n, err := result.RowsAffected(); err != nil {
+			return nil, err
+		} else if n == 0 {
+			log.Printf("[Repository] 属性が見つかりません: %s", attribute.Code)
+			return nil, fmt.Errorf("%w: %s", ErrAttributeNotFound, attribute.Code)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	log.Printf("[Repository] テンプレート更新成功: %s", id)
+	return FetchCategoryAttributes(tenantID, id)
+}
+
+// templateViolationKey はアイテムのテンプレート違反（カテゴリ・属性コード・違反の種類）を表します
+type templateViolationKey struct {
+	categoryID string
+	code       string
+	kind       string
+}
+
+// fetchItemTemplateViolations はトランザクション内でアイテムの現在のテンプレート違反を取得します
+// 変更前に取得して applyCategoryTemplate に渡すと、変更で新たに生じた違反のみを判定できます
+func fetchItemTemplateViolations(tx *sql.Tx, tenantID, itemID string) (map[templateViolationKey]bool, error) {
+	rows, err := tx.Query(`
+		SELECT category_id, attribute_code, violation
+		FROM item_template_violations
+		WHERE item_id = $1 AND tenant_id = $2
+		ORDER BY attribute_code
+	`, itemID, tenantID)
+	if err != nil {
+		log.Printf("[Repository] テンプレート違反の確認エラー: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	violations := map[templateViolationKey]bool{}
+	for rows.Next() {
+		var key templateViolationKey
+		if err := rows.Scan(&key.categoryID, &key.code, &key.kind); err != nil {
+			return nil, err
+		}
+		violations[key] = true
+	}
+	return violations, rows.Err()
+}
+
+// applyCategoryTemplate はトランザクション内でアイテムにカテゴリの属性テンプレートを適用します
+// 未設定の属性に既定値を設定し（fillDefaults が false の場合は必須の属性のみ）、
+// テンプレートを満たさない場合は TemplateViolationError を返します。カテゴリ未設定のアイテムは対象外です。
+// existing（変更前の違反）に含まれる違反は、テンプレートの変更前から残っているものとして許容し、新たに生じた違反のみ拒否します
+func applyCategoryTemplate(tx *sql.Tx, tenantID, itemID string, fillDefaults bool, existing map[templateViolationKey]bool) error {
+	if _, err := tx.Exec(`
+		INSERT INTO item_attributes (tenant_id, item_id, attribute_id, value)
+		SELECT i.tenant_id, i.id, ca.attribute_id, ca.default_value
+		FROM items i
+		INNER JOIN category_attributes ca ON ca.category_id = i.category_id
+		INNER JOIN attributes a ON ca.attribute_id = a.id AND a.deleted_at IS NULL
+		WHERE i.id = $1 AND i.tenant_id = $2
+		  AND ca.default_value IS NOT NULL AND ($3 OR ca.required)
+		ON CONFLICT (item_id, attribute_id) DO NOTHING
+	`, itemID, tenantID, fillDefaults); err != nil {
+		log.Printf("[Repository] 既定値の設定エラー: %v", err)
+		return err
+	}
+
+	violations, err := fetchItemTemplateViolations(tx, tenantID, itemID)
+	if err != nil {
+		return err
+	}
+
+	violation := &TemplateViolationError{}
+	for key := range violations {
+		if existing[key] {
+			continue
+		}
+		if key.kind == "missing" {
+			violation.Missing = append(violation.Missing, key.code)
+		} else {
+			violation.NotApplicable = append(violation.NotApplicable, key.code)
+		}
+	}
+	if len(violation.Missing) > 0 || len(violation.NotApplicable) > 0 {
+		sort.Strings(violation.Missing)
+		sort.Strings(violation.NotApplicable)
+		log.Printf("[Repository] テンプレート違反: %s %v", itemID, violation)
+		return violation
+	}
+	return nil
+}
+
+// FetchTemplateViolations はカテゴリの属性テンプレートを満たさない既存のアイテムを取得します
+// categoryID を指定した場合はそのカテゴリのアイテムのみ対象とし、scope を指定した場合は権限付与の範囲内のアイテムのみ対象とします
+func FetchTemplateViolations(tenantID string, scope *model.AccessScope, categoryID string) ([]model.TemplateViolation, error) {
+	log.Printf("[Repository] FetchTemplateViolations - tenant_id: %s, category_id: %s", tenantID, categoryID)
+
+	access := newAccessFilter(scope, 3)
+	args := append([]interface{}{tenantID, categoryID}, access.args()...)
+
+	rows, err := common.DB.Query(access.with()+`
+		SELECT i.id, i.code, i.name, c.id, c.code,
+			COALESCE(array_agg(v.attribute_code ORDER BY v.attribute_code) FILTER (WHERE v.violation = 'missing'), '{}'),
+			COALESCE(array_agg(v.attribute_code ORDER BY v.attribute_code) FILTER (WHERE v.violation = 'not_applicable'), '{}')
+		FROM item_template_violations v
+		INNER JOIN items i ON v.item_id = i.id
+		INNER JOIN categories c ON v.category_id = c.id
+		WHERE v.tenant_id = $1 AND ($2 = '' OR v.category_id = $2)
+		  AND `+access.itemCond("i")+`
+		GROUP BY i.id, i.code, i.name, c.id, c.code
+		ORDER BY c.code, i.code
+	`, args...)
+	if err != nil {
+		log.Printf("[Repository] DB クエリエラー: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	violations := []model.TemplateViolation{}
+	for rows.Next() {
+		var v model.TemplateViolation
+		if err := rows.Scan(
+			&v.ItemID,
+			&v.ItemCode,
+			&v.ItemName,
+			&v.CategoryID,
+			&v.CategoryCode,
+			pq.Array(&v.Missing),
+			pq.Array(&v.NotApplicable),
+		); err != nil {
+			log.Printf("[Repository] スキャンエラー: %v", err)
+			return nil, err
+		}
+		violations = append(violations, v)
+	}
+
+	log.Printf("[Repository] 取得成功: %d件のテンプレート違反", len(violations))
+	return violations, rows.Err()
+}
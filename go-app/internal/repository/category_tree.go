@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+
+	"go-hsm-app/internal/common"
+	"go-hsm-app/internal/model"
+)
+
+var (
+	// ErrParentCategoryNotFound は親に指定したカテゴリが存在しない場合のエラーです
+	ErrParentCategoryNotFound = errors.New("parent category not found")
+	// ErrCategoryCycle はカテゴリを自身または子孫の配下に移動しようとした場合のエラーです
+	ErrCategoryCycle = errors.New("category cannot be moved under itself or its descendants")
+	// ErrCategoryHasChildren は子カテゴリのあるカテゴリを削除しようとした場合のエラーです
+	ErrCategoryHasChildren = errors.New("category has child categories")
+)
+
+// lockCategoryTree はトランザクションの終了まで、テナントのカテゴリ階層の変更を排他します
+// 同時に行われた移動どうしで循環が生じたり、削除中のカテゴリの配下に移動したりしないようにします
+func lockCategoryTree(tx *sql.Tx, tenantID string) error {
+	_, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext('category_tree:' || $1))`, tenantID)
+	return err
+}
+
+// MoveCategory はカテゴリの親を変更し、子孫のカテゴリを含むサブツリーを移動します
+// parentID が nil の場合は最上位に移動します。親の存在確認・循環の検出・更新は 1 つのトランザクションで行い、
+// 移動先が自身または子孫の場合は ErrCategoryCycle、親カテゴリが存在しない場合は ErrParentCategoryNotFound を返します
+// version を指定した場合は現在のバージョンと一致するときのみ移動し、一致しなければ ErrVersionConflict を返します
+func MoveCategory(tenantID, id string, parentID *string, version *int) (*model.Category, error) {
+	log.Printf("[Repository] MoveCategory - tenant_id: %s, id: %s, parent_id: %v", tenantID, id, parentID)
+
+	tx, err := common.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := lockCategoryTree(tx, tenantID); err != nil {
+		log.Printf("[Repository] ロック取得エラー: %v", err)
+		return nil, err
+	}
+
+	if parentID != nil {
+		var exists, cycle bool
+		err := tx.QueryRow(`
+			WITH RECURSIVE ancestors AS (
+				SELECT id, parent_id FROM categories
+				WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL
+				UNION
+				SELECT c.id, c.parent_id FROM categories c
+				INNER JOIN ancestors a ON c.id = a.parent_id
+			)
+			SELECT EXISTS (SELECT 1 FROM ancestors WHERE id = $1),
+			       EXISTS (SELECT 1 FROM ancestors WHERE id = $3)
+		`, *parentID, tenantID, id).Scan(&exists, &cycle)
+		if err != nil {
+			log.Printf("[Repository] 親カテゴリの確認エラー: %v", err)
+			return nil, err
+		}
+		if !exists {
+			log.Printf("[Repository] 親カテゴリが見つかりません: %s", *parentID)
+			return nil, ErrParentCategoryNotFound
+		}
+		if cycle {
+			log.Printf("[Repository] 循環する移動です: %s -> %s", id, *parentID)
+			return nil, ErrCategoryCycle
+		}
+	}
+
+	var category model.Category
+	err = tx.QueryRow(`
+		UPDATE categories
+		SET parent_id = $3, updated_at = CURRENT_TIMESTAMP, version = version + 1
+		WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL
+		  AND ($4::INTEGER IS NULL OR version = $4)
+		RETURNING id, code, name, parent_id, description, version, created_at, updated_at
+	`, id, tenantID, parentID, version).Scan(
+		&category.ID,
+		&category.Code,
+		&category.Name,
+		&category.ParentID,
+		&category.Description,
+		&category.Version,
+		&category.CreatedAt,
+		&category.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, versionConflictOrNotFound("categories", tenantID, id)
+	}
+	if err != nil {
+		log.Printf("[Repository] カテゴリ移動エラー: %v", err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	log.Printf("[Repository] カテゴリ移動成功: %s", category.ID)
+	return &category, nil
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+
+	"go-hsm-app/internal/common"
+)
+
+// ErrVersionConflict は更新対象のバージョンが指定と一致しない場合のエラーです（楽観的ロック）
+var ErrVersionConflict = errors.New("version conflict")
+
+// versionConflictOrNotFound はバージョン指定付きの更新で対象行がなかった場合の原因を判定します。
+// 行が存在すればバージョン不一致として ErrVersionConflict を、存在しなければ sql.ErrNoRows を返します。
+// table はパッケージ内の固定値のみを渡してください。
+func versionConflictOrNotFound(table, tenantID, id string) error {
+	var exists bool
+	err := common.DB.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM `+table+` WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL)
+	`, id, tenantID).Scan(&exists)
+	if err != nil {
+		log.Printf("[Repository] 存在確認エラー (%s: %s): %v", table, id, err)
+		return err
+	}
+	if exists {
+		log.Printf("[Repository] バージョンが一致しません (%s: %s)", table, id)
+		return ErrVersionConflict
+	}
+	log.Printf("[Repository] 更新対象が見つかりません (%s: %s)", table, id)
+	return sql.ErrNoRows
+}
@@ -0,0 +1,264 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"go-hsm-app/internal/common"
+	"go-hsm-app/internal/model"
+	"log"
+
+	"github.com/lib/pq"
+)
+
+// FetchUserGrants はユーザーの権限付与一覧を取得します
+func FetchUserGrants(tenantID, userID string) ([]model.UserGrant, error) {
+	log.Printf("[Repository] FetchUserGrants - tenant_id: %s, user_id: %s", tenantID, userID)
+
+	rows, err := common.DB.Query(`
+		SELECT id, user_id, scope_type, scope_id, role, created_by, created_at
+		FROM user_grants
+		WHERE tenant_id = $1 AND user_id = $2
+		ORDER BY scope_type, scope_id
+	`, tenantID, userID)
+	if err != nil {
+		log.Printf("[Repository] DB クエリエラー: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	grants := []model.UserGrant{}
+	for rows.Next() {
+		var grant model.UserGrant
+		if err := rows.Scan(
+			&grant.ID,
+			&grant.UserID,
+			&grant.ScopeType,
+			&grant.ScopeID,
+			&grant.Role,
+			&grant.CreatedBy,
+			&grant.CreatedAt,
+		); err != nil {
+			log.Printf("[Repository] スキャンエラー: %v", err)
+			return nil, err
+		}
+		grants = append(grants, grant)
+	}
+
+	log.Printf("[Repository] 取得成功: %d件の権限付与", len(grants))
+	return grants, rows.Err()
+}
+
+// CreateUserGrant はユーザーに権限を付与します。
+// 同じ範囲に既に付与されている場合はロールを更新します。
+// 範囲の対象（ロケーション・カテゴリ）がテナント内に存在しない場合は sql.ErrNoRows を返します。
+func CreateUserGrant(tenantID, userID, scopeType, scopeID, role string, createdBy *string) (*model.UserGrant, error) {
+	log.Printf("[Repository] CreateUserGrant - tenant_id: %s, user_id: %s, scope: %s:%s, role: %s", tenantID, userID, scopeType, scopeID, role)
+
+	var table string
+	switch scopeType {
+	case model.GrantScopeLocation:
+		table = "locations"
+	case model.GrantScopeCategory:
+		table = "categories"
+	default:
+		return nil, fmt.Errorf("unknown grant scope type: %s", scopeType)
+	}
+
+	var grant model.UserGrant
+	err := common.DB.QueryRow(`
+		INSERT INTO user_grants (tenant_id, user_id, scope_type, scope_id, role, created_by)
+		SELECT $1, $2, $3, t.id, $5, $6
+		FROM `+table+` t
+		WHERE t.id = $4 AND t.tenant_id = $1 AND t.deleted_at IS NULL
+		ON CONFLICT (user_id, scope_type, scope_id)
+		DO UPDATE SET role = EXCLUDED.role, created_by = EXCLUDED.created_by, created_at = CURRENT_TIMESTAMP
+		RETURNING id, user_id, scope_type, scope_id, role, created_by, created_at
+	`, tenantID, userID, scopeType, scopeID, role, createdBy).Scan(
+		&grant.ID,
+		&grant.UserID,
+		&grant.ScopeType,
+		&grant.ScopeID,
+		&grant.Role,
+		&grant.CreatedBy,
+		&grant.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Printf("[Repository] 権限付与の対象が見つかりません: %s:%s", scopeType, scopeID)
+		} else {
+			log.Printf("[Repository] 権限付与エラー: %v", err)
+		}
+		return nil, err
+	}
+
+	log.Printf("[Repository] 権限付与成功: %s", grant.ID)
+	return &grant, nil
+}
+
+// DeleteUserGrant はユーザーの権限付与を削除します
+func DeleteUserGrant(tenantID, userID, id string) error {
+	log.Printf("[Repository] DeleteUserGrant - tenant_id: %s, user_id: %s, id: %s", tenantID, userID, id)
+
+	result, err := common.DB.Exec(`
+		DELETE FROM user_grants
+		WHERE id = $1 AND user_id = $2 AND tenant_id = $3
+	`, id, userID, tenantID)
+	if err != nil {
+		log.Printf("[Repository] 権限付与削除エラー: %v", err)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("[Repository] RowsAffected取得エラー: %v", err)
+		return err
+	}
+
+	if rowsAffected == 0 {
+		log.Printf("[Repository] 権限付与が見つかりません: %s", id)
+		return sql.ErrNoRows
+	}
+
+	log.Printf("[Repository] 権限付与削除成功: %s", id)
+	return nil
+}
+
+// accessFilter は権限付与による絞り込みの SQL を組み立てます。
+// scope が nil の場合は絞り込みを行いません。
+type accessFilter struct {
+	scope *model.AccessScope
+	user  string // ユーザーIDのプレースホルダ
+	roles string // 付与ロールのプレースホルダ
+}
+
+// newAccessFilter は argIndex 番目と argIndex+1 番目のプレースホルダを使用する accessFilter を返します
+func newAccessFilter(scope *model.AccessScope, argIndex int) accessFilter {
+	return accessFilter{
+		scope: scope,
+		user:  fmt.Sprintf("$%d", argIndex),
+		roles: fmt.Sprintf("$%d", argIndex+1),
+	}
+}
+
+// args はプレースホルダに渡す引数を返します（scope が nil の場合は空）
+func (f accessFilter) args() []interface{} {
+	if f.scope == nil {
+		return nil
+	}
+	return []interface{}{f.scope.UserID, pq.Array(f.scope.Roles)}
+}
+
+// with はクエリの先頭に付与する WITH 句を返します。
+// granted_locations は付与ロケーションとその子孫、granted_categories は付与カテゴリとその子孫、
+// unrestricted は権限付与が 1 件もない（制限なし）かどうかを表します。
+func (f accessFilter) with() string {
+	if f.scope == nil {
+		return ""
+	}
+	return fmt.Sprintf(`WITH RECURSIVE granted_locations AS (
+			SELECT g.scope_id AS id FROM user_grants g
+			WHERE g.user_id = %[1]s AND g.scope_type = 'location' AND g.role = ANY(%[2]s)
+			UNION
+			SELECT l.id FROM locations l
+			INNER JOIN granted_locations gl ON l.parent_id = gl.id
+			WHERE l.deleted_at IS NULL
+		), granted_categories AS (
+			SELECT g.scope_id AS id FROM user_grants g
+			WHERE g.user_id = %[1]s AND g.scope_type = 'category' AND g.role = ANY(%[2]s)
+			UNION
+			SELECT c.id FROM categories c
+			INNER JOIN granted_categories gc ON c.parent_id = gc.id
+			WHERE c.deleted_at IS NULL
+		), unrestricted AS (
+			SELECT NOT EXISTS (SELECT 1 FROM user_grants WHERE user_id = %[1]s) AS ok
+		)
+		`, f.user, f.roles)
+}
+
+// itemCond はアイテム（別名 alias）が範囲内かを判定する条件を返します。
+// カテゴリが付与範囲、または付与ロケーションに在庫があるアイテムが対象です。
+func (f accessFilter) itemCond(alias string) string {
+	if f.scope == nil {
+		return "TRUE"
+	}
+	return fmt.Sprintf(`((SELECT ok FROM unrestricted)
+			OR %[1]s.category_id IN (SELECT id FROM granted_categories)
+			OR EXISTS (
+				SELECT 1 FROM stocks s
+				WHERE s.item_id = %[1]s.id AND s.location_id IN (SELECT id FROM granted_locations)
+			))`, alias)
+}
+
+// historyCond は在庫履歴（別名 alias）が範囲内かを判定する条件を返します。
+// アイテムのカテゴリ、または移動元・移動先ロケーションが付与範囲の履歴が対象です。
+func (f accessFilter) historyCond(alias string) string {
+	if f.scope == nil {
+		return "TRUE"
+	}
+	return fmt.Sprintf(`((SELECT ok FROM unrestricted)
+			OR %[1]s.location_from IN (SELECT id FROM granted_locations)
+			OR %[1]s.location_to IN (SELECT id FROM granted_locations)
+			OR EXISTS (
+				SELECT 1 FROM items hi
+				WHERE hi.id = %[1]s.item_id AND hi.category_id IN (SELECT id FROM granted_categories)
+			))`, alias)
+}
+
+// locationCond はアイテム（別名 alias）をロケーション location で扱えるかを判定する条件を返します。
+// ロケーションが付与範囲、またはアイテムのカテゴリが付与範囲の場合に扱えます。
+func (f accessFilter) locationCond(alias, location string) string {
+	if f.scope == nil {
+		return "TRUE"
+	}
+	return fmt.Sprintf(`((SELECT ok FROM unrestricted)
+			OR %[2]s IN (SELECT id FROM granted_locations)
+			OR %[1]s.category_id IN (SELECT id FROM granted_categories))`, alias, location)
+}
+
+// lockItemInScope はトランザクション内でアイテムの行をロックし、権限付与の範囲（scope）外であれば sql.ErrNoRows を返します。
+// 範囲外のアイテムは存在しないものとして扱い、更新・削除の対象にしません（scope が nil の場合は確認しません）
+func lockItemInScope(tx *sql.Tx, tenantID string, scope *model.AccessScope, id string) error {
+	if scope == nil {
+		return nil
+	}
+	access := newAccessFilter(scope, 3)
+	var allowed bool
+	err := tx.QueryRow(access.with()+`
+		SELECT `+access.itemCond("i")+`
+		FROM items i
+		WHERE i.id = $1 AND i.tenant_id = $2 AND i.deleted_at IS NULL
+		FOR UPDATE
+	`, append([]interface{}{id, tenantID}, access.args()...)...).Scan(&allowed)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		log.Printf("[Repository] 権限付与の範囲外のアイテムです: %s", id)
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// checkItemInScope はトランザクション内で、作成・更新後のアイテムが権限付与の範囲（scope）内か確認し、
+// 範囲外であれば ErrAccessDenied を返します。範囲外のカテゴリへの変更で、自身の範囲からアイテムを外せないようにします
+// （scope が nil の場合は確認しません）
+func checkItemInScope(tx *sql.Tx, tenantID string, scope *model.AccessScope, id string) error {
+	if scope == nil {
+		return nil
+	}
+	access := newAccessFilter(scope, 3)
+	var allowed bool
+	err := tx.QueryRow(access.with()+`
+		SELECT `+access.itemCond("i")+`
+		FROM items i
+		WHERE i.id = $1 AND i.tenant_id = $2
+	`, append([]interface{}{id, tenantID}, access.args()...)...).Scan(&allowed)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		log.Printf("[Repository] 権限付与の範囲外になるため変更できません: %s", id)
+		return ErrAccessDenied
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+
+	"go-hsm-app/internal/common"
+	"go-hsm-app/internal/model"
+
+	"github.com/lib/pq"
+)
+
+// ErrAttributeNotFound は指定した属性コードがテナントに存在しない場合のエラーです
+var ErrAttributeNotFound = errors.New("attribute not found")
+
+// ErrValueTypeInUse は属性値や既定値がある属性の型（value_type）を変更しようとした場合のエラーです
+var ErrValueTypeInUse = errors.New("value_type cannot be changed while items or category templates have values for the attribute")
+
+// UpdateItemAttributes はアイテムの属性値を変更し、アイテムのバージョンを更新します
+// version を指定した場合は現在のバージョンと一致するときのみ変更し、一致しなければ ErrVersionConflict を返します
+func UpdateItemAttributes(tenantID, itemID string, changes model.ItemAttributeChanges, version *int) error {
+	log.Printf("[Repository] UpdateItemAttributes - tenant_id: %s, item_id: %s", tenantID, itemID)
+
+	tx, err := common.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var id string
+	err = tx.QueryRow(`
+		UPDATE items
+		SET updated_at = CURRENT_TIMESTAMP, version = version + 1
+		WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL
+		  AND ($3::INTEGER IS NULL OR version = $3)
+		RETURNING id
+	`, itemID, tenantID, version).Scan(&id)
+	if err == sql.ErrNoRows {
+		return versionConflictOrNotFound("items", tenantID, itemID)
+	}
+	if err != nil {
+		log.Printf("[Repository] アイテム更新エラー: %v", err)
+		return err
+	}
+
+	existing, err := fetchItemTemplateViolations(tx, tenantID, itemID)
+	if err != nil {
+		return err
+	}
+	if err := writeItemAttributes(tx, tenantID, itemID, &changes); err != nil {
+		return err
+	}
+	if err := applyCategoryTemplate(tx, tenantID, itemID, false, existing); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	log.Printf("[Repository] 属性値を更新しました: %s", itemID)
+	return nil
+}
+
+// writeItemAttributes はトランザクション内でアイテムの属性値を変更します
+// changes が nil の場合は何もしません。設定されていない属性の削除は無視します
+func writeItemAttributes(tx *sql.Tx, tenantID, itemID string, changes *model.ItemAttributeChanges) error {
+	if changes == nil {
+		return nil
+	}
+
+	codes := make([]string, 0, len(changes.Set))
+	for code := range changes.Set {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	if changes.Replace {
+		if _, err := tx.Exec(`
+			DELETE FROM item_attributes ia
+			USING attributes a
+			WHERE ia.attribute_id = a.id AND ia.item_id = $1 AND ia.tenant_id = $2
+			  AND NOT (a.code = ANY($3))
+		`, itemID, tenantID, pq.Array(codes)); err != nil {
+			log.Printf("[Repository] 属性値の削除エラー: %v", err)
+			return err
+		}
+	}
+
+	for _, code := range codes {
+		result, err := tx.Exec(`
+			INSERT INTO item_attributes (tenant_id, item_id, attribute_id, value)
+			SELECT $1, $2, a.id, $4
+			FROM attributes a
+			WHERE a.tenant_id = $1 AND a.code = $3 AND a.deleted_at IS NULL
+			  AND (a.value_type <> 'enum' OR EXISTS (
+				SELECT 1 FROM attribute_options o
+				WHERE o.attribute_id = a.id AND o.code = $4
+				FOR SHARE
+			  ))
+			ON CONFLICT (item_id, attribute_id)
+			DO UPDATE SET value = EXCLUDED.value, updated_at = CURRENT_TIMESTAMP
+		`, tenantID, itemID, code, changes.Set[code])
+		if err != nil {
+			log.Printf("[Repository] 属性値の設定エラー (code: %s): %v", code, err)
+			return err
+		}
+		if n, err := result.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return missingAttributeOrOption(tx, tenantID, code, changes.Set[code])
+		}
+	}
+
+	if len(changes.Delete) > 0 {
+		if _, err := tx.Exec(`
+			DELETE FROM item_attributes ia
+			USING attributes a
+			WHERE ia.attribute_id = a.id AND ia.item_id = $1 AND ia.tenant_id = $2
+			  AND a.code = ANY($3)
+		`, itemID, tenantID, pq.Array(changes.Delete)); err != nil {
+			log.Printf("[Repository] 属性値の削除エラー: %v", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// missingAttributeOrOption は属性値を設定できなかった原因が属性の未存在か、enum 属性の選択肢の未存在かを判別してエラーを返します
+func missingAttributeOrOption(tx *sql.Tx, tenantID, code, value string) error {
+	var valueType string
+	err := tx.QueryRow(`
+		SELECT value_type FROM attributes
+		WHERE tenant_id = $1 AND code = $2 AND deleted_at IS NULL
+	`, tenantID, code).Scan(&valueType)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	if err == nil && valueType == model.AttributeTypeEnum {
+		log.Printf("[Repository] 選択肢が見つかりません: %s=%s", code, value)
+		return fmt.Errorf("%w: %s=%s", ErrAttributeOptionNotFound, code, value)
+	}
+	log.Printf("[Repository] 属性が見つかりません: %s", code)
+	return fmt.Errorf("%w: %s", ErrAttributeNotFound, code)
+}
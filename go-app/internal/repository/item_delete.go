@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+
+	"go-hsm-app/internal/common"
+	"go-hsm-app/internal/model"
+)
+
+// ErrItemDeleteForbidden は削除のポリシーによりアイテムを削除できない場合のエラーです
+var ErrItemDeleteForbidden = errors.New("item deletion is forbidden by the policy")
+
+// ItemDeleteForbiddenError はアイテムの削除を拒否した規則と、在庫・入出庫履歴の状況を保持するエラーです
+// errors.Is(err, ErrItemDeleteForbidden) で判定できます
+type ItemDeleteForbiddenError struct {
+	Policy       string  // 適用した削除のポリシー
+	Rule         string  // 削除を拒否した規則（model.ItemDeleteRuleStockExists など）
+	Stock        float64 // 在庫数量
+	HistoryCount int     // 入出庫履歴の件数
+}
+
+func (e *ItemDeleteForbiddenError) Error() string {
+	return fmt.Sprintf("%v (policy: %s, rule: %s)", ErrItemDeleteForbidden, e.Policy, e.Rule)
+}
+
+func (e *ItemDeleteForbiddenError) Unwrap() error {
+	return ErrItemDeleteForbidden
+}
+
+// writeOffReason は削除時の在庫の除却（ADJUST）の理由です
+const writeOffReason = "アイテム削除に伴う在庫の除却"
+
+// DeleteItem はアイテムを削除します（論理削除）
+// 在庫・入出庫履歴がある場合は policy（model.ItemDeletePolicyForbid など）に従い、削除できなければ ItemDeleteForbiddenError を返します。
+// policy が force の場合は、残っている在庫をロケーションごとの調整（ADJUST）で 0 にしてから削除し、記録した履歴を返します
+// scope を指定した場合は権限付与の範囲内のアイテムのみ削除し、範囲外であれば sql.ErrNoRows を返します
+func DeleteItem(tenantID string, scope *model.AccessScope, id, policy string, deletedBy *string) ([]model.StockHistory, error) {
+	log.Printf("[Repository] DeleteItem - tenant_id: %s, id: %s, policy: %s", tenantID, id, policy)
+
+	tx, err := common.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := lockItemInScope(tx, tenantID, scope, id); err != nil {
+		if err == sql.ErrNoRows {
+			log.Printf("[Repository] アイテムが見つかりません: %s", id)
+		}
+		return nil, err
+	}
+
+	// 入出庫と同じくアイテムの行をロックし、確認から削除までの間に在庫が変わらないようにする
+	var quantity, stock float64
+	var historyCount int
+	err = tx.QueryRow(`
+		SELECT
+			COALESCE(i.quantity, 0),
+			COALESCE((SELECT SUM(qty) FROM stocks WHERE item_id = i.id), 0),
+			(SELECT COUNT(*) FROM stock_history WHERE item_id = i.id)
+		FROM items i
+		WHERE i.id = $1 AND i.tenant_id = $2 AND i.deleted_at IS NULL
+		FOR UPDATE
+	`, id, tenantID).Scan(&quantity, &stock, &historyCount)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Printf("[Repository] アイテムが見つかりません: %s", id)
+		} else {
+			log.Printf("[Repository] DBクエリエラー: %v", err)
+		}
+		return nil, err
+	}
+
+	// ロケーション別在庫のないアイテムは、アイテムの在庫数量を在庫とみなす
+	if stock == 0 {
+		stock = quantity
+	}
+
+	forbidden := &ItemDeleteForbiddenError{Policy: policy, Stock: stock, HistoryCount: historyCount}
+	switch {
+	case policy == model.ItemDeletePolicyForce:
+	case stock != 0:
+		forbidden.Rule = model.ItemDeleteRuleStockExists
+	case historyCount > 0 && policy != model.ItemDeletePolicyZeroStock:
+		forbidden.Rule = model.ItemDeleteRuleHistoryExists
+	}
+	if forbidden.Rule != "" {
+		log.Printf("[Repository] アイテムを削除できません: %s (%v)", id, forbidden)
+		return nil, forbidden
+	}
+
+	writeOffs := []model.StockHistory{}
+	if stock != 0 {
+		if writeOffs, err = writeOffStocks(tx, tenantID, id, deletedBy); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE items
+		SET deleted_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`, id); err != nil {
+		log.Printf("[Repository] アイテム削除エラー: %v", err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	log.Printf("[Repository] アイテム削除成功: %s (除却: %d件)", id, len(writeOffs))
+	return writeOffs, nil
+}
+
+// writeOffStocks はトランザクション内でアイテムの在庫を調整（ADJUST）で 0 にし、記録した履歴を返します
+// ロケーション別在庫ごとに 1 件、ロケーション別在庫に割り当てられていない在庫数量は location_to なしで 1 件記録します
+func writeOffStocks(tx *sql.Tx, tenantID, itemID string, createdBy *string) ([]model.StockHistory, error) {
+	rows, err := tx.Query(`
+		WITH located AS (
+			SELECT location_id, qty FROM stocks
+			WHERE item_id = $1 AND qty <> 0
+		), unlocated AS (
+			SELECT NULL::TEXT AS location_id, COALESCE(i.quantity, 0)::NUMERIC AS qty
+			FROM items i
+			WHERE i.id = $1 AND NOT EXISTS (SELECT 1 FROM located)
+		)
+		INSERT INTO stock_history (tenant_id, item_id, qty_delta, kind, location_to, reason, meta, unit_price, total_amount, created_by)
+		SELECT $2, i.id, -w.qty, $3, w.location_id, $4, '{"write_off": true}'::JSONB,
+			COALESCE(i.unit_price, 0), ROUND(ABS(w.qty) * COALESCE(i.unit_price, 0)), $5
+		FROM (SELECT * FROM located UNION ALL SELECT * FROM unlocated WHERE qty <> 0) w
+		CROSS JOIN items i
+		WHERE i.id = $1
+		RETURNING id, item_id, qty_delta, kind, location_from, location_to, reason, meta, unit_price, total_amount, created_by, created_at
+	`, itemID, tenantID, model.StockKindAdjust, writeOffReason, createdBy)
+	if err != nil {
+		log.Printf("[Repository] 在庫の除却エラー: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	histories := []model.StockHistory{}
+	for rows.Next() {
+		var history model.StockHistory
+		if err := rows.Scan(
+			&history.ID,
+			&history.ItemID,
+			&history.QtyDelta,
+			&history.Kind,
+			&history.LocationFrom,
+			&history.LocationTo,
+			&history.Reason,
+			&history.Meta,
+			&history.UnitPrice,
+			&history.TotalAmount,
+			&history.CreatedBy,
+			&history.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		histories = append(histories, history)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE stocks SET qty = 0, updated_at = CURRENT_TIMESTAMP
+		WHERE item_id = $1 AND qty <> 0
+	`, itemID); err != nil {
+		log.Printf("[Repository] 在庫更新エラー: %v", err)
+		return nil, err
+	}
+	if _, err := tx.Exec(`
+		UPDATE items SET quantity = 0, updated_at = CURRENT_TIMESTAMP, version = version + 1
+		WHERE id = $1
+	`, itemID); err != nil {
+		log.Printf("[Repository] アイテム在庫数量の更新エラー: %v", err)
+		return nil, err
+	}
+
+	log.Printf("[Repository] 在庫を除却しました: %s (%d件)", itemID, len(histories))
+	return histories, nil
+}
@@ -0,0 +1,357 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+	"sort"
+
+	"go-hsm-app/internal/common"
+	"go-hsm-app/internal/model"
+
+	"github.com/lib/pq"
+)
+
+// ErrMergeSourceNotFound は統合元のアイテムが存在しない場合のエラーです
+var ErrMergeSourceNotFound = errors.New("merge source item not found")
+
+// mergeItem は統合するアイテムの統合前の状態です
+type mergeItem struct {
+	unitID   string
+	version  int
+	quantity float64 // アイテムの在庫数量
+	stock    float64 // ロケーション別在庫の合計
+}
+
+// unlocated はロケーション別在庫に計上されていない在庫数量を返します
+// ロケーション別在庫のないアイテムは、アイテムの在庫数量をロケーションに計上されていない在庫とみなします
+func (m mergeItem) unlocated() float64 {
+	if m.stock == 0 {
+		return m.quantity
+	}
+	return 0
+}
+
+// MergeItems は統合元のアイテム（sourceID）を統合先のアイテム（targetID）に 1 つのトランザクションで統合します
+// 入出庫履歴・ロケーション別在庫・属性値を統合先に付け替え（在庫はロケーションごとに合算）、統合元を論理削除し、
+// 付け替えの内容を監査ログに記録します。統合元と統合先の基本単位が異なる場合は、統合元の数量を統合先の基本単位に換算し、
+// 換算できなければ ErrIncompatibleUnit を返します。入出庫履歴の単価は統合先の基本単位あたりの単価に換算します。
+// 属性値は統合先に値がない属性のみ移し、統合後の属性値が統合先のカテゴリの属性テンプレートを満たさない場合は
+// TemplateViolationError を返します（統合前から統合先に残っている違反は許容します）。
+// 一方だけがロケーション別在庫を持つ場合、もう一方の在庫数量は unlocatedLocationID のロケーションに計上し、
+// 指定がなければ ErrUnlocatedStock を返します。統合先の在庫数量は、入出庫と同じくロケーション別在庫の合計に揃えます。
+// 統合先が見つからない場合は sql.ErrNoRows、統合元が見つからない場合は ErrMergeSourceNotFound を返します。
+// version を指定した場合は統合先のバージョンと一致するときのみ統合し、一致しなければ ErrVersionConflict を返します。
+// dryRun が true の場合は同じ処理を行ったうえでロールバックし、統合した場合の結果を返します
+func MergeItems(tenantID string, scope *model.AccessScope, sourceID, targetID string, unlocatedLocationID *string, version *int, dryRun bool, mergedBy *string) (*model.ItemMerge, error) {
+	log.Printf("[Repository] MergeItems - tenant_id: %s, source: %s, target: %s, dry_run: %v", tenantID, sourceID, targetID, dryRun)
+
+	tx, err := common.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	items, err := lockMergeItems(tx, tenantID, scope, sourceID, targetID)
+	if err != nil {
+		return nil, err
+	}
+	source, target := items[sourceID], items[targetID]
+	if version != nil && *version != target.version {
+		log.Printf("[Repository] バージョンが一致しません (items: %s)", targetID)
+		return nil, ErrVersionConflict
+	}
+
+	result := &model.ItemMerge{
+		SourceID: sourceID,
+		TargetID: targetID,
+		DryRun:   dryRun,
+		Factor:   1,
+	}
+	if source.unitID != target.unitID {
+		if result.Factor, err = unitFactor(tx, tenantID, targetID, target.unitID, source.unitID); err != nil {
+			return nil, err
+		}
+	}
+	convert := func(qty float64) float64 {
+		return math.Round(qty*result.Factor*10000) / 10000
+	}
+
+	if result.StockHistory, err = mergeStockHistory(tx, sourceID, targetID, result.Factor); err != nil {
+		return nil, err
+	}
+	if result.Stocks, err = mergeStocks(tx, tenantID, sourceID, targetID, convert); err != nil {
+		return nil, err
+	}
+	// 統合前から統合先に残っている違反（テンプレートの変更後に未対応のもの）は、この統合では拒否しない
+	existing, err := fetchItemTemplateViolations(tx, tenantID, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if result.Attributes, err = mergeItemAttributes(tx, sourceID, targetID); err != nil {
+		return nil, err
+	}
+	if err := applyCategoryTemplate(tx, tenantID, targetID, false, existing); err != nil {
+		return nil, err
+	}
+
+	result.UnlocatedQty = target.unlocated() + convert(source.unlocated())
+	if source.stock == 0 && target.stock == 0 {
+		// どちらもロケーション別在庫がない場合は、アイテムの在庫数量のみ合算する
+		result.Quantity = math.Round(result.UnlocatedQty)
+		if _, err := tx.Exec(`
+			UPDATE items
+			SET quantity = $2, updated_at = CURRENT_TIMESTAMP, version = version + 1
+			WHERE id = $1
+		`, targetID, int64(result.Quantity)); err != nil {
+			log.Printf("[Repository] アイテム在庫数量の更新エラー: %v", err)
+			return nil, err
+		}
+	} else {
+		// ロケーション別在庫の合計に揃えると計上されていない数量が失われるため、指定のロケーションに計上する
+		if result.UnlocatedQty != 0 {
+			if unlocatedLocationID == nil {
+				log.Printf("[Repository] ロケーション別在庫のない在庫数量の計上先が指定されていません: %v", result.UnlocatedQty)
+				return nil, ErrUnlocatedStock
+			}
+			if err := checkStockLocation(tx, tenantID, scope, targetID, *unlocatedLocationID); err != nil {
+				return nil, err
+			}
+			if err := addStock(tx, tenantID, targetID, *unlocatedLocationID, result.UnlocatedQty); err != nil {
+				return nil, err
+			}
+			result.UnlocatedTo = unlocatedLocationID
+		}
+		if err := tx.QueryRow(`
+			UPDATE items
+			SET quantity = (SELECT ROUND(COALESCE(SUM(qty), 0)) FROM stocks WHERE item_id = $1),
+				updated_at = CURRENT_TIMESTAMP, version = version + 1
+			WHERE id = $1
+			RETURNING quantity
+		`, targetID).Scan(&result.Quantity); err != nil {
+			log.Printf("[Repository] アイテム在庫数量の更新エラー: %v", err)
+			return nil, err
+		}
+	}
+	if _, err := tx.Exec(`
+		UPDATE items
+		SET quantity = 0, deleted_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP, version = version + 1
+		WHERE id = $1
+	`, sourceID); err != nil {
+		log.Printf("[Repository] 統合元アイテムの削除エラー: %v", err)
+		return nil, err
+	}
+
+	diff, err := json.Marshal(map[string]interface{}{
+		"tenant_id": tenantID,
+		"merge":     result,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := createAuditLog(tx, model.AuditLog{
+		UserID:     mergedBy,
+		Action:     "merge",
+		Resource:   "items",
+		ResourceID: &targetID,
+		Diff:       diff,
+	}); err != nil {
+		return nil, err
+	}
+
+	if dryRun {
+		log.Printf("[Repository] ドライランのため統合をロールバックします: %s → %s", sourceID, targetID)
+		return result, nil
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	log.Printf("[Repository] アイテム統合成功: %s → %s (履歴: %d件, 在庫: %d件, 属性値: %d件)",
+		sourceID, targetID, len(result.StockHistory), len(result.Stocks), len(result.Attributes))
+	return result, nil
+}
+
+// lockMergeItems はトランザクション内で統合元・統合先のアイテムの行をロックし、統合前の状態を返します
+// デッドロックを避けるため ID の順にロックします。scope を指定した場合、権限付与の範囲外のアイテムは見つからないものとして扱います
+func lockMergeItems(tx *sql.Tx, tenantID string, scope *model.AccessScope, sourceID, targetID string) (map[string]mergeItem, error) {
+	rows, err := tx.Query(`
+		SELECT id, unit_id, version, COALESCE(quantity, 0)
+		FROM items
+		WHERE id = ANY($1) AND tenant_id = $2 AND deleted_at IS NULL
+		ORDER BY id
+		FOR UPDATE
+	`, pq.Array([]string{sourceID, targetID}), tenantID)
+	if err != nil {
+		log.Printf("[Repository] DBクエリエラー: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := map[string]mergeItem{}
+	for rows.Next() {
+		var id string
+		var item mergeItem
+		if err := rows.Scan(&id, &item.unitID, &item.version, &item.quantity); err != nil {
+			return nil, err
+		}
+		items[id] = item
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	access := newAccessFilter(scope, 3)
+	for _, id := range []string{targetID, sourceID} {
+		item, ok := items[id]
+		if ok {
+			var allowed bool
+			err := tx.QueryRow(access.with()+`
+				SELECT `+access.itemCond("i")+`, COALESCE((SELECT SUM(qty) FROM stocks WHERE item_id = i.id), 0)
+				FROM items i
+				WHERE i.id = $1 AND i.tenant_id = $2
+			`, append([]interface{}{id, tenantID}, access.args()...)...).Scan(&allowed, &item.stock)
+			if err != nil {
+				return nil, err
+			}
+			ok = allowed
+			items[id] = item
+		}
+		if !ok {
+			log.Printf("[Repository] アイテムが見つかりません: %s", id)
+			if id == sourceID {
+				return nil, ErrMergeSourceNotFound
+			}
+			return nil, sql.ErrNoRows
+		}
+	}
+	return items, nil
+}
+
+// mergeStockHistory はトランザクション内で統合元の入出庫履歴を統合先に付け替え、付け替えた履歴のIDを返します
+// 数量は統合先の基本単位に換算し（単価は係数で割って基本単位あたりに換算し、取引金額は変えない）、
+// 補足情報（meta）に統合元のアイテムID（merged_from）と換算係数（merge_factor）を残します
+func mergeStockHistory(tx *sql.Tx, sourceID, targetID string, factor float64) ([]string, error) {
+	rows, err := tx.Query(`
+		UPDATE stock_history
+		SET item_id = $2,
+			qty_delta = ROUND(qty_delta * $3::NUMERIC, 4),
+			unit_price = ROUND(unit_price / $3::NUMERIC),
+			meta = meta || jsonb_build_object('merged_from', $1::TEXT, 'merge_factor', $3::NUMERIC)
+		WHERE item_id = $1
+		RETURNING id
+	`, sourceID, targetID, factor)
+	if err != nil {
+		log.Printf("[Repository] 入出庫履歴の付け替えエラー: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// mergeStocks はトランザクション内で統合元のロケーション別在庫を統合先に合算し、ロケーションごとの合算の内容を返します
+// convert は統合元の数量を統合先の基本単位に換算する関数です
+func mergeStocks(tx *sql.Tx, tenantID, sourceID, targetID string, convert func(float64) float64) ([]model.ItemMergeStock, error) {
+	rows, err := tx.Query(`
+		SELECT s.location_id, s.qty, COALESCE(t.qty, 0)
+		FROM stocks s
+		LEFT JOIN stocks t ON t.item_id = $2 AND t.location_id = s.location_id
+		WHERE s.item_id = $1
+		ORDER BY s.location_id
+	`, sourceID, targetID)
+	if err != nil {
+		log.Printf("[Repository] 在庫取得エラー: %v", err)
+		return nil, err
+	}
+
+	stocks := []model.ItemMergeStock{}
+	for rows.Next() {
+		var stock model.ItemMergeStock
+		if err := rows.Scan(&stock.LocationID, &stock.SourceQty, &stock.TargetQty); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		stock.SourceQty = convert(stock.SourceQty)
+		stock.MergedQty = stock.TargetQty + stock.SourceQty
+		stocks = append(stocks, stock)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, stock := range stocks {
+		if err := addStock(tx, tenantID, targetID, stock.LocationID, stock.SourceQty); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := tx.Exec(`DELETE FROM stocks WHERE item_id = $1`, sourceID); err != nil {
+		log.Printf("[Repository] 統合元の在庫の削除エラー: %v", err)
+		return nil, err
+	}
+	return stocks, nil
+}
+
+// mergeItemAttributes はトランザクション内で統合元の属性値を統合先に移し、属性ごとの扱いを返します
+// 統合先に値がある属性は統合先の値を残し、統合元の属性値は削除します
+func mergeItemAttributes(tx *sql.Tx, sourceID, targetID string) ([]model.ItemMergeAttribute, error) {
+	rows, err := tx.Query(`
+		SELECT a.code, s.value, t.value
+		FROM item_attributes s
+		INNER JOIN attributes a ON s.attribute_id = a.id
+		LEFT JOIN item_attributes t ON t.item_id = $2 AND t.attribute_id = s.attribute_id
+		WHERE s.item_id = $1
+		ORDER BY a.code
+	`, sourceID, targetID)
+	if err != nil {
+		log.Printf("[Repository] 属性値の取得エラー: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	attributes := []model.ItemMergeAttribute{}
+	for rows.Next() {
+		var attribute model.ItemMergeAttribute
+		if err := rows.Scan(&attribute.Code, &attribute.SourceValue, &attribute.TargetValue); err != nil {
+			return nil, err
+		}
+		attribute.Action = model.ItemMergeAttributeMoved
+		if attribute.TargetValue != nil {
+			attribute.Action = model.ItemMergeAttributeKept
+		}
+		attributes = append(attributes, attribute)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO item_attributes (tenant_id, item_id, attribute_id, value)
+		SELECT tenant_id, $2, attribute_id, value FROM item_attributes
+		WHERE item_id = $1
+		ON CONFLICT (item_id, attribute_id) DO NOTHING
+	`, sourceID, targetID); err != nil {
+		log.Printf("[Repository] 属性値の付け替えエラー: %v", err)
+		return nil, err
+	}
+	if _, err := tx.Exec(`DELETE FROM item_attributes WHERE item_id = $1`, sourceID); err != nil {
+		log.Printf("[Repository] 統合元の属性値の削除エラー: %v", err)
+		return nil, err
+	}
+	return attributes, nil
+}
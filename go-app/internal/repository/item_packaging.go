@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+
+	"go-hsm-app/internal/common"
+	"go-hsm-app/internal/model"
+)
+
+var (
+	// ErrPackagingIsBaseUnit は荷姿にアイテムの基本単位を指定した場合のエラーです
+	ErrPackagingIsBaseUnit = errors.New("packaging unit must differ from the item's base unit")
+	// ErrPackagingInUse は他の荷姿の内容物になっている荷姿を削除しようとした場合のエラーです
+	ErrPackagingInUse = errors.New("packaging is contained in another packaging")
+)
+
+// FetchItemPackagings はアイテムの荷姿を入数の昇順に取得します
+func FetchItemPackagings(tenantID, itemID string) ([]model.ItemPackaging, error) {
+	log.Printf("[Repository] FetchItemPackagings - tenant_id: %s, item_id: %s", tenantID, itemID)
+
+	rows, err := common.DB.Query(`
+		SELECT p.item_id, u.id, u.code, u.name, p.factor, p.contains_unit_id, cu.code, p.created_at, p.updated_at
+		FROM item_packagings p
+		INNER JOIN units u ON p.unit_id = u.id AND u.deleted_at IS NULL
+		LEFT JOIN units cu ON p.contains_unit_id = cu.id
+		WHERE p.tenant_id = $1 AND p.item_id = $2
+		ORDER BY p.factor, u.code
+	`, tenantID, itemID)
+	if err != nil {
+		log.Printf("[Repository] DB クエリエラー: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	packagings := []model.ItemPackaging{}
+	for rows.Next() {
+		var packaging model.ItemPackaging
+		if err := rows.Scan(
+			&packaging.ItemID,
+			&packaging.UnitID,
+			&packaging.UnitCode,
+			&packaging.UnitName,
+			&packaging.Factor,
+			&packaging.ContainsUnitID,
+			&packaging.ContainsUnitCode,
+			&packaging.CreatedAt,
+			&packaging.UpdatedAt,
+		); err != nil {
+			log.Printf("[Repository] スキャンエラー: %v", err)
+			return nil, err
+		}
+		packagings = append(packagings, packaging)
+	}
+
+	log.Printf("[Repository] 取得成功: %d件の荷姿", len(packagings))
+	return packagings, rows.Err()
+}
+
+// SetItemPackaging はアイテムの荷姿（荷姿の単位 1 あたりの内容物の単位での入数）を登録・更新します
+// containsUnitID が nil または基本単位の場合、内容物はアイテムの基本単位になります。
+// アイテムが存在しない場合は sql.ErrNoRows、単位が存在しない場合は ErrUnitNotFound、
+// 単位がアイテムの基本単位の場合は ErrPackagingIsBaseUnit、
+// 内容物から基本単位まで換算できない場合（循環を含む）は ErrIncompatibleUnit を返します
+func SetItemPackaging(tenantID, itemID, unitID string, containsUnitID *string, factor float64) (*model.ItemPackaging, error) {
+	log.Printf("[Repository] SetItemPackaging - tenant_id: %s, item_id: %s, unit_id: %s, factor: %v", tenantID, itemID, unitID, factor)
+
+	tx, err := common.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var baseUnitID string
+	err = tx.QueryRow(`
+		SELECT unit_id FROM items
+		WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL
+		FOR SHARE
+	`, itemID, tenantID).Scan(&baseUnitID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Printf("[Repository] アイテムが見つかりません: %s", itemID)
+		}
+		return nil, err
+	}
+	if unitID == baseUnitID {
+		return nil, ErrPackagingIsBaseUnit
+	}
+	if containsUnitID != nil && *containsUnitID == baseUnitID {
+		containsUnitID = nil
+	}
+	if containsUnitID != nil && *containsUnitID == unitID {
+		log.Printf("[Repository] 荷姿の内容物に自身は指定できません: %s", unitID)
+		return nil, fmt.Errorf("%w: packaging cannot contain itself", ErrIncompatibleUnit)
+	}
+
+	packaging := model.ItemPackaging{ItemID: itemID}
+	err = tx.QueryRow(`
+		SELECT id, code, name FROM units
+		WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL
+		FOR SHARE
+	`, unitID, tenantID).Scan(&packaging.UnitID, &packaging.UnitCode, &packaging.UnitName)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Printf("[Repository] 単位が見つかりません: %s", unitID)
+			return nil, ErrUnitNotFound
+		}
+		return nil, err
+	}
+
+	if containsUnitID != nil {
+		var containsCode string
+		err = tx.QueryRow(`
+			SELECT code FROM units
+			WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL
+			FOR SHARE
+		`, *containsUnitID, tenantID).Scan(&containsCode)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				log.Printf("[Repository] 内容物の単位が見つかりません: %s", *containsUnitID)
+				return nil, ErrUnitNotFound
+			}
+			return nil, err
+		}
+		packaging.ContainsUnitCode = &containsCode
+	}
+
+	err = tx.QueryRow(`
+		INSERT INTO item_packagings (tenant_id, item_id, unit_id, contains_unit_id, factor)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (item_id, unit_id)
+		DO UPDATE SET contains_unit_id = EXCLUDED.contains_unit_id, factor = EXCLUDED.factor, updated_at = CURRENT_TIMESTAMP
+		RETURNING contains_unit_id, factor, created_at, updated_at
+	`, tenantID, itemID, unitID, containsUnitID, factor).Scan(
+		&packaging.ContainsUnitID,
+		&packaging.Factor,
+		&packaging.CreatedAt,
+		&packaging.UpdatedAt,
+	)
+	if err != nil {
+		log.Printf("[Repository] 荷姿の登録エラー: %v", err)
+		return nil, err
+	}
+
+	// 入れ子の荷姿が基本単位までたどれること（循環していないこと）を確認します
+	if _, err := unitFactor(tx, tenantID, itemID, baseUnitID, unitID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	log.Printf("[Repository] 荷姿の登録成功: %s (unit_id: %s)", itemID, unitID)
+	return &packaging, nil
+}
+
+// DeleteItemPackaging はアイテムの荷姿を削除します
+// 他の荷姿の内容物になっている場合は ErrPackagingInUse を返します
+func DeleteItemPackaging(tenantID, itemID, unitID string) error {
+	log.Printf("[Repository] DeleteItemPackaging - tenant_id: %s, item_id: %s, unit_id: %s", tenantID, itemID, unitID)
+
+	tx, err := common.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// 同じアイテムの荷姿の登録と競合しないようにアイテムを共有ロックします
+	if _, err := tx.Exec(`
+		SELECT 1 FROM items WHERE id = $1 AND tenant_id = $2 FOR SHARE
+	`, itemID, tenantID); err != nil {
+		return err
+	}
+
+	var inUse bool
+	if err := tx.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM item_packagings
+			WHERE tenant_id = $1 AND item_id = $2 AND contains_unit_id = $3
+		)
+	`, tenantID, itemID, unitID).Scan(&inUse); err != nil {
+		return err
+	}
+	if inUse {
+		log.Printf("[Repository] 他の荷姿の内容物になっています: %s (unit_id: %s)", itemID, unitID)
+		return ErrPackagingInUse
+	}
+
+	result, err := tx.Exec(`
+		DELETE FROM item_packagings
+		WHERE tenant_id = $1 AND item_id = $2 AND unit_id = $3
+	`, tenantID, itemID, unitID)
+	if err != nil {
+		log.Printf("[Repository] 荷姿の削除エラー: %v", err)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("[Repository] RowsAffected取得エラー: %v", err)
+		return err
+	}
+	if rowsAffected == 0 {
+		log.Printf("[Repository] 荷姿が見つかりません: %s (unit_id: %s)", itemID, unitID)
+		return sql.ErrNoRows
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	log.Printf("[Repository] 荷姿の削除成功: %s (unit_id: %s)", itemID, unitID)
+	return nil
+}
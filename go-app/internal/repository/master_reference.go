@@ -0,0 +1,401 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"go-hsm-app/internal/common"
+	"go-hsm-app/internal/model"
+)
+
+var (
+	// ErrMasterInUse は削除しようとしたマスタをアイテムが参照している場合のエラーです
+	ErrMasterInUse = errors.New("master is referenced by items")
+	// ErrInvalidReplacement は参照の付け替え先のマスタが不正な場合のエラーです
+	ErrInvalidReplacement = errors.New("invalid replacement")
+)
+
+// MaxReferencingItems は MasterInUseError に含める参照元アイテムの最大件数です
+const MaxReferencingItems = 100
+
+// MasterInUseError は削除しようとしたマスタを参照しているアイテムを保持するエラーです
+// errors.Is(err, ErrMasterInUse) で判定できます
+type MasterInUseError struct {
+	Items []model.ItemReference // 参照元のアイテム（コード順、最大 MaxReferencingItems 件）
+	Total int                   // 参照元のアイテムの件数
+}
+
+func (e *MasterInUseError) Error() string {
+	return fmt.Sprintf("%v (%d item(s))", ErrMasterInUse, e.Total)
+}
+
+func (e *MasterInUseError) Unwrap() error {
+	return ErrMasterInUse
+}
+
+// masterReference はアイテムから参照されるマスタの削除方法を表します
+type masterReference struct {
+	table    string                                                     // マスタのテーブル名
+	itemCond string                                                     // マスタ（$2）を参照するアイテム i の条件
+	check    func(tx *sql.Tx, tenantID, id string) error                // 削除前の追加の確認（任意）
+	reassign func(tx *sql.Tx, tenantID, id, replacementID string) error // 参照の付け替え
+}
+
+// deleteMaster はトランザクション内でマスタを論理削除します
+// 削除していないアイテムから参照されている場合は MasterInUseError を返します。
+// replacementID を指定した場合は、削除済みのアイテムを含む参照を付け替えてから削除します
+func deleteMaster(tx *sql.Tx, tenantID, id string, replacementID *string, ref masterReference) error {
+	// マスタの行をロックし、参照の確認から削除までの間に新しい参照が作られないようにする
+	var locked string
+	err := tx.QueryRow(`
+		SELECT id FROM `+ref.table+`
+		WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL
+		FOR UPDATE
+	`, id, tenantID).Scan(&locked)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Printf("[Repository] 削除対象が見つかりません: %s (%s)", id, ref.table)
+		}
+		return err
+	}
+
+	if ref.check != nil {
+		if err := ref.check(tx, tenantID, id); err != nil {
+			return err
+		}
+	}
+
+	if replacementID != nil {
+		if *replacementID == id {
+			return fmt.Errorf("%w: cannot reassign to the master being deleted", ErrInvalidReplacement)
+		}
+		var replacement string
+		err := tx.QueryRow(`
+			SELECT id FROM `+ref.table+`
+			WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL
+			FOR SHARE
+		`, *replacementID, tenantID).Scan(&replacement)
+		if err == sql.ErrNoRows {
+			log.Printf("[Repository] 付け替え先が見つかりません: %s (%s)", *replacementID, ref.table)
+			return fmt.Errorf("%w: %s not found", ErrInvalidReplacement, *replacementID)
+		}
+		if err != nil {
+			return err
+		}
+		if err := ref.reassign(tx, tenantID, id, replacement); err != nil {
+			return err
+		}
+		log.Printf("[Repository] 参照を付け替えました: %s -> %s (%s)", id, replacement, ref.table)
+	}
+
+	if err := checkItemReferences(tx, tenantID, id, ref.itemCond); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE `+ref.table+`
+		SET deleted_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND tenant_id = $2
+	`, id, tenantID); err != nil {
+		log.Printf("[Repository] 論理削除エラー (%s): %v", ref.table, err)
+		return err
+	}
+	return nil
+}
+
+// checkItemReferences はマスタ（$2）を参照している削除していないアイテムを確認し、あれば MasterInUseError を返します
+func checkItemReferences(tx *sql.Tx, tenantID, id, itemCond string) error {
+	rows, err := tx.Query(`
+		SELECT i.id, i.code, i.name, COUNT(*) OVER ()
+		FROM items i
+		WHERE i.tenant_id = $1 AND i.deleted_at IS NULL AND `+itemCond+`
+		ORDER BY i.code
+		LIMIT $3
+	`, tenantID, id, MaxReferencingItems)
+	if err != nil {
+		log.Printf("[Repository] 参照元アイテムの確認エラー: %v", err)
+		return err
+	}
+	defer rows.Close()
+
+	inUse := &MasterInUseError{Items: []model.ItemReference{}}
+	for rows.Next() {
+		var item model.ItemReference
+		if err := rows.Scan(&item.ID, &item.Code, &item.Name, &inUse.Total); err != nil {
+			return err
+		}
+		inUse.Items = append(inUse.Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if inUse.Total > 0 {
+		log.Printf("[Repository] アイテムから参照されているため削除できません: %s (%d件)", id, inUse.Total)
+		return inUse
+	}
+	return nil
+}
+
+// categoryReference はカテゴリの削除方法です。子カテゴリがある場合は ErrCategoryHasChildren を返します
+// 付け替えたアイテムには付け替え先のカテゴリの属性テンプレートを適用し、満たさないアイテムがあれば ErrInvalidReplacement を返します
+var categoryReference = masterReference{
+	table:    "categories",
+	itemCond: "i.category_id = $2",
+	check: func(tx *sql.Tx, tenantID, id string) error {
+		var hasChildren bool
+		if err := tx.QueryRow(`
+			SELECT EXISTS (
+				SELECT 1 FROM categories
+				WHERE parent_id = $1 AND tenant_id = $2 AND deleted_at IS NULL
+			)
+		`, id, tenantID).Scan(&hasChildren); err != nil {
+			log.Printf("[Repository] 子カテゴリの確認エラー: %v", err)
+			return err
+		}
+		if hasChildren {
+			log.Printf("[Repository] 子カテゴリがあるため削除できません: %s", id)
+			return ErrCategoryHasChildren
+		}
+		return nil
+	},
+	reassign: func(tx *sql.Tx, tenantID, id, replacementID string) error {
+		rows, err := tx.Query(`
+			UPDATE items
+			SET category_id = $3, updated_at = CURRENT_TIMESTAMP, version = version + 1
+			WHERE tenant_id = $1 AND category_id = $2
+			RETURNING id, code, deleted_at IS NULL
+		`, tenantID, id, replacementID)
+		if err != nil {
+			return err
+		}
+		type reassigned struct{ id, code string }
+		var items []reassigned
+		for rows.Next() {
+			var item reassigned
+			var active bool
+			if err := rows.Scan(&item.id, &item.code, &active); err != nil {
+				rows.Close()
+				return err
+			}
+			// 削除済みのアイテムはテンプレートの対象外
+			if active {
+				items = append(items, item)
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		// 付け替え先のカテゴリの既定値を未設定の属性に設定し、テンプレートを満たさないアイテムを集める
+		var violating []string
+		for _, item := range items {
+			err := applyCategoryTemplate(tx, tenantID, item.id, true, nil)
+			var verr *TemplateViolationError
+			if errors.As(err, &verr) {
+				violating = append(violating, item.code)
+				continue
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if len(violating) > 0 {
+			log.Printf("[Repository] 付け替え先のカテゴリの属性テンプレートを満たさないアイテムがあります: %s (%d 件)", replacementID, len(violating))
+			sort.Strings(violating)
+			if len(violating) > MaxReferencingItems {
+				violating = violating[:MaxReferencingItems]
+			}
+			return fmt.Errorf("%w: items do not satisfy the attribute template of the replacement category: %s", ErrInvalidReplacement, strings.Join(violating, ", "))
+		}
+		return nil
+	},
+}
+
+// unitReference は単位の削除方法です
+// アイテムの基本単位だけでなく、荷姿の単位・内容物の単位として参照されている場合も削除できません。
+// 付け替えでは数量を換算しないため、在庫・入出庫履歴のあるアイテムの基本単位は付け替えません（ErrInvalidReplacement）。
+// 荷姿の単位・内容物の単位も付け替え先に置き換えるため、付け替え先は同じ尺度の単位を指定してください
+var unitReference = masterReference{
+	table:    "units",
+	itemCond: "(i.unit_id = $2 OR EXISTS (SELECT 1 FROM item_packagings p WHERE p.item_id = i.id AND (p.unit_id = $2 OR p.contains_unit_id = $2)))",
+	reassign: func(tx *sql.Tx, tenantID, id, replacementID string) error {
+		// 在庫・入出庫履歴は基本単位で記録されているため、換算せずに単位を変えると数量の意味が変わる
+		stocked, err := queryItemCodes(tx, `
+			SELECT i.code FROM items i
+			WHERE i.tenant_id = $1 AND i.unit_id = $2
+			  AND (i.quantity <> 0
+				OR EXISTS (SELECT 1 FROM stocks s WHERE s.item_id = i.id AND s.qty <> 0)
+				OR EXISTS (SELECT 1 FROM stock_history h WHERE h.item_id = i.id))
+			ORDER BY i.code
+			LIMIT $3
+		`, tenantID, id, MaxReferencingItems)
+		if err != nil {
+			return err
+		}
+		if len(stocked) > 0 {
+			return fmt.Errorf("%w: unit cannot be reassigned for items with stock or stock history: %s", ErrInvalidReplacement, strings.Join(stocked, ", "))
+		}
+
+		// 荷姿の単位を付け替えると、付け替え先の荷姿・基本単位と重なるか、自身を内容物とする荷姿になるもの
+		conflicts, err := queryItemCodes(tx, `
+			SELECT DISTINCT i.code
+			FROM item_packagings p
+			INNER JOIN items i ON p.item_id = i.id
+			WHERE p.tenant_id = $1
+			  AND (
+				(p.unit_id = $2 AND (
+					i.unit_id = $3
+					OR p.contains_unit_id = $3
+					OR EXISTS (SELECT 1 FROM item_packagings r WHERE r.item_id = p.item_id AND r.unit_id = $3)
+				))
+				OR (p.unit_id = $3 AND p.contains_unit_id = $2)
+			  )
+			ORDER BY i.code
+			LIMIT $4
+		`, tenantID, id, replacementID, MaxReferencingItems)
+		if err != nil {
+			return err
+		}
+		if len(conflicts) > 0 {
+			return fmt.Errorf("%w: packagings cannot be moved for items %s", ErrInvalidReplacement, strings.Join(conflicts, ", "))
+		}
+
+		if _, err := tx.Exec(`
+			UPDATE items
+			SET unit_id = $3, updated_at = CURRENT_TIMESTAMP, version = version + 1
+			WHERE tenant_id = $1 AND unit_id = $2
+		`, tenantID, id, replacementID); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`
+			UPDATE item_packagings
+			SET unit_id = $3, updated_at = CURRENT_TIMESTAMP
+			WHERE tenant_id = $1 AND unit_id = $2
+		`, tenantID, id, replacementID); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`
+			UPDATE item_packagings
+			SET contains_unit_id = $3, updated_at = CURRENT_TIMESTAMP
+			WHERE tenant_id = $1 AND contains_unit_id = $2
+		`, tenantID, id, replacementID); err != nil {
+			return err
+		}
+		// 付け替え先の単位が基本単位になったアイテムでは、その単位の荷姿は不要になり、
+		// その単位を内容物とする荷姿は基本単位を内容物とする（contains_unit_id = NULL）
+		if _, err := tx.Exec(`
+			UPDATE item_packagings p
+			SET contains_unit_id = NULL, updated_at = CURRENT_TIMESTAMP
+			FROM items i
+			WHERE p.item_id = i.id AND i.tenant_id = $1 AND i.unit_id = $2 AND p.contains_unit_id = $2
+		`, tenantID, replacementID); err != nil {
+			return err
+		}
+		_, err = tx.Exec(`
+			DELETE FROM item_packagings p
+			USING items i
+			WHERE p.item_id = i.id AND i.tenant_id = $1 AND i.unit_id = $2 AND p.unit_id = $2
+		`, tenantID, replacementID)
+		return err
+	},
+}
+
+// queryItemCodes はトランザクション内でアイテムコードの一覧を取得します
+func queryItemCodes(tx *sql.Tx, query string, args ...interface{}) ([]string, error) {
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	codes := []string{}
+	for rows.Next() {
+		var code string
+		if err := rows.Scan(&code); err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+	}
+	return codes, rows.Err()
+}
+
+// attributeReference は属性の削除方法です
+// 付け替えは属性値を付け替え先の属性に移します。付け替え先は同じ型の属性とし、
+// enum の場合は全ての値が付け替え先の選択肢にあること、付け替え先の値を既に持つアイテムがないことが必要です
+var attributeReference = masterReference{
+	table:    "attributes",
+	itemCond: "EXISTS (SELECT 1 FROM item_attributes ia WHERE ia.item_id = i.id AND ia.attribute_id = $2)",
+	reassign: func(tx *sql.Tx, tenantID, id, replacementID string) error {
+		var valueType, replacementType string
+		if err := tx.QueryRow(`
+			SELECT a.value_type, r.value_type
+			FROM attributes a, attributes r
+			WHERE a.id = $1 AND r.id = $2
+		`, id, replacementID).Scan(&valueType, &replacementType); err != nil {
+			return err
+		}
+		if valueType != replacementType {
+			return fmt.Errorf("%w: value_type %s does not match %s", ErrInvalidReplacement, replacementType, valueType)
+		}
+
+		conflicts, err := queryItemCodes(tx, `
+			SELECT i.code
+			FROM item_attributes ia
+			INNER JOIN items i ON ia.item_id = i.id
+			WHERE ia.tenant_id = $1 AND ia.attribute_id = $2
+			  AND (
+				EXISTS (SELECT 1 FROM item_attributes r WHERE r.item_id = ia.item_id AND r.attribute_id = $3)
+				OR ($4 AND NOT EXISTS (
+					SELECT 1 FROM attribute_options o WHERE o.attribute_id = $3 AND o.code = ia.value
+				))
+			  )
+			ORDER BY i.code
+			LIMIT $5
+		`, tenantID, id, replacementID, valueType == model.AttributeTypeEnum, MaxReferencingItems)
+		if err != nil {
+			return err
+		}
+		if len(conflicts) > 0 {
+			return fmt.Errorf("%w: values cannot be moved for items %s", ErrInvalidReplacement, strings.Join(conflicts, ", "))
+		}
+
+		if _, err := tx.Exec(`
+			UPDATE items
+			SET updated_at = CURRENT_TIMESTAMP, version = version + 1
+			WHERE tenant_id = $1 AND id IN (SELECT item_id FROM item_attributes WHERE attribute_id = $2)
+		`, tenantID, id); err != nil {
+			return err
+		}
+		_, err = tx.Exec(`
+			UPDATE item_attributes
+			SET attribute_id = $3, updated_at = CURRENT_TIMESTAMP
+			WHERE tenant_id = $1 AND attribute_id = $2
+		`, tenantID, id, replacementID)
+		return err
+	},
+}
+
+// deleteMasterTx はマスタの削除を 1 つのトランザクションで行います
+func deleteMasterTx(tenantID, id string, replacementID *string, ref masterReference, lock func(tx *sql.Tx) error) error {
+	tx, err := common.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if lock != nil {
+		if err := lock(tx); err != nil {
+			log.Printf("[Repository] ロック取得エラー: %v", err)
+			return err
+		}
+	}
+	if err := deleteMaster(tx, tenantID, id, replacementID, ref); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"database/sql"
+	"go-hsm-app/internal/common"
+	"log"
+	"time"
+)
+
+// CreateOIDCAuthRequest は OIDC 認可リクエストを保存します。
+// あわせて期限切れのリクエストを削除します。
+func CreateOIDCAuthRequest(stateHash, nonce, codeVerifier string, expiresAt time.Time) error {
+	log.Printf("[Repository] CreateOIDCAuthRequest")
+
+	if _, err := common.DB.Exec(`DELETE FROM oidc_auth_requests WHERE expires_at < CURRENT_TIMESTAMP`); err != nil {
+		log.Printf("[Repository] 期限切れ認可リクエスト削除エラー: %v", err)
+		return err
+	}
+
+	_, err := common.DB.Exec(`
+		INSERT INTO oidc_auth_requests (state_hash, nonce, code_verifier, expires_at)
+		VALUES ($1, $2, $3, $4)
+	`, stateHash, nonce, codeVerifier, expiresAt)
+	if err != nil {
+		log.Printf("[Repository] 認可リクエスト保存エラー: %v", err)
+		return err
+	}
+	return nil
+}
+
+// ConsumeOIDCAuthRequest は state に対応する有効な認可リクエストを取得し、削除します。
+// 該当がない・期限切れの場合は sql.ErrNoRows を返します。
+func ConsumeOIDCAuthRequest(stateHash string) (nonce, codeVerifier string, err error) {
+	log.Printf("[Repository] ConsumeOIDCAuthRequest")
+
+	err = common.DB.QueryRow(`
+		DELETE FROM oidc_auth_requests
+		WHERE state_hash = $1 AND expires_at > CURRENT_TIMESTAMP
+		RETURNING nonce, code_verifier
+	`, stateHash).Scan(&nonce, &codeVerifier)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("[Repository] 認可リクエスト取得エラー: %v", err)
+		}
+		return "", "", err
+	}
+	return nonce, codeVerifier, nil
+}
@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"go-hsm-app/internal/common"
+	"go-hsm-app/internal/model"
+	"log"
+	"time"
+)
+
+// リフレッシュトークン失効理由
+const (
+	RevokeReasonLogout          = "logout"
+	RevokeReasonReuseDetected   = "reuse_detected"
+	RevokeReasonAdminRevoked    = "admin_revoked"
+	RevokeReasonUserDeleted     = "user_deleted"
+	RevokeReasonPasswordChanged = "password_changed"
+	RevokeReasonRoleDowngraded  = "role_downgraded"
+)
+
+var (
+	// ErrRefreshTokenRevoked は失効済みまたは期限切れのトークンが提示された場合のエラーです
+	ErrRefreshTokenRevoked = errors.New("refresh token has been revoked or expired")
+	// ErrRefreshTokenReused はローテーション済みトークンが再利用された場合のエラーです
+	ErrRefreshTokenReused = errors.New("refresh token reuse detected")
+)
+
+// CreateRefreshToken はリフレッシュトークンを保存します
+func CreateRefreshToken(userID, familyID, tokenHash string, expiresAt time.Time, userAgent, ipAddress string) error {
+	log.Printf("[Repository] CreateRefreshToken - user_id: %s, family_id: %s", userID, familyID)
+
+	_, err := common.DB.Exec(`
+		INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at, user_agent, ip_address)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, userID, familyID, tokenHash, expiresAt, userAgent, ipAddress)
+	if err != nil {
+		log.Printf("[Repository] リフレッシュトークン保存エラー: %v", err)
+		return err
+	}
+	return nil
+}
+
+// RotateRefreshToken はリフレッシュトークンをローテーションします。
+// 旧トークンを使用済みにし、同じファミリーに新しいトークンを追加します。
+// 使用済みトークンが再利用された場合はファミリー全体を失効させ ErrRefreshTokenReused を返します。
+func RotateRefreshToken(tokenHash, newTokenHash string, newExpiresAt time.Time, userAgent, ipAddress string) (*model.RefreshToken, error) {
+	log.Printf("[Repository] RotateRefreshToken")
+
+	tx, err := common.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var token model.RefreshToken
+	err = tx.QueryRow(`
+		SELECT id, user_id, family_id, expires_at, rotated_at, revoked_at, created_at
+		FROM refresh_tokens
+		WHERE token_hash = $1
+		FOR UPDATE
+	`, tokenHash).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.FamilyID,
+		&token.ExpiresAt,
+		&token.RotatedAt,
+		&token.RevokedAt,
+		&token.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Printf("[Repository] リフレッシュトークンが見つかりません")
+			return nil, sql.ErrNoRows
+		}
+		log.Printf("[Repository] DBクエリエラー: %v", err)
+		return nil, err
+	}
+
+	if token.RevokedAt != nil || time.Now().After(token.ExpiresAt) {
+		log.Printf("[Repository] 失効済みのリフレッシュトークン: %s", token.ID)
+		return nil, ErrRefreshTokenRevoked
+	}
+
+	if token.RotatedAt != nil {
+		// 使用済みトークンの再利用は漏洩の兆候のため、ファミリー全体を失効させる
+		log.Printf("[Repository] リフレッシュトークンの再利用を検知しました (family_id: %s)", token.FamilyID)
+		if _, err := revokeFamily(tx, token.FamilyID, RevokeReasonReuseDetected); err != nil {
+			return nil, err
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, err
+		}
+		return &token, ErrRefreshTokenReused
+	}
+
+	var newID string
+	err = tx.QueryRow(`
+		INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at, user_agent, ip_address)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`, token.UserID, token.FamilyID, newTokenHash, newExpiresAt, userAgent, ipAddress).Scan(&newID)
+	if err != nil {
+		log.Printf("[Repository] リフレッシュトークン保存エラー: %v", err)
+		return nil, err
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE refresh_tokens
+		SET rotated_at = CURRENT_TIMESTAMP, replaced_by = $2
+		WHERE id = $1
+	`, token.ID, newID); err != nil {
+		log.Printf("[Repository] リフレッシュトークン更新エラー: %v", err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	log.Printf("[Repository] リフレッシュトークンをローテーションしました: %s -> %s", token.ID, newID)
+	return &token, nil
+}
+
+// FetchRefreshTokenByHash はハッシュでリフレッシュトークンを取得します
+func FetchRefreshTokenByHash(tokenHash string) (*model.RefreshToken, error) {
+	var token model.RefreshToken
+	err := common.DB.QueryRow(`
+		SELECT id, user_id, family_id, expires_at, rotated_at, revoked_at, revoked_reason, created_at
+		FROM refresh_tokens
+		WHERE token_hash = $1
+	`, tokenHash).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.FamilyID,
+		&token.ExpiresAt,
+		&token.RotatedAt,
+		&token.RevokedAt,
+		&token.RevokedReason,
+		&token.CreatedAt,
+	)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("[Repository] DBクエリエラー: %v", err)
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// RevokeRefreshTokenFamily はトークンファミリー（セッション）を失効させます
+func RevokeRefreshTokenFamily(familyID, reason string) (int64, error) {
+	log.Printf("[Repository] RevokeRefreshTokenFamily - family_id: %s, reason: %s", familyID, reason)
+	return revokeFamily(common.DB, familyID, reason)
+}
+
+// RevokeUserRefreshTokens はユーザーの全セッションを失効させ、失効したセッション数を返します
+func RevokeUserRefreshTokens(userID, reason string) (int64, error) {
+	log.Printf("[Repository] RevokeUserRefreshTokens - user_id: %s, reason: %s", userID, reason)
+
+	var sessions int64
+	err := common.DB.QueryRow(`
+		WITH revoked AS (
+			UPDATE refresh_tokens
+			SET revoked_at = CURRENT_TIMESTAMP, revoked_reason = $2
+			WHERE user_id = $1 AND revoked_at IS NULL
+			RETURNING family_id
+		)
+		SELECT COUNT(DISTINCT family_id) FROM revoked
+	`, userID, reason).Scan(&sessions)
+	if err != nil {
+		log.Printf("[Repository] セッション失効エラー: %v", err)
+		return 0, err
+	}
+
+	log.Printf("[Repository] セッション失効成功: %d件 (user_id: %s)", sessions, userID)
+	return sessions, nil
+}
+
+// IsSessionActive はセッション（トークンファミリー）が失効していないか確認します
+func IsSessionActive(familyID string) (bool, error) {
+	var active bool
+	err := common.DB.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM refresh_tokens
+			WHERE family_id = $1 AND revoked_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+		)
+	`, familyID).Scan(&active)
+	if err != nil {
+		log.Printf("[Repository] セッション確認エラー: %v", err)
+		return false, err
+	}
+	return active, nil
+}
+
+// execer は *sql.DB と *sql.Tx の共通インターフェースです
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// revokeFamily はファミリー内の未失効トークンを全て失効させます
+func revokeFamily(db execer, familyID, reason string) (int64, error) {
+	result, err := db.Exec(`
+		UPDATE refresh_tokens
+		SET revoked_at = CURRENT_TIMESTAMP, revoked_reason = $2
+		WHERE family_id = $1 AND revoked_at IS NULL
+	`, familyID, reason)
+	if err != nil {
+		log.Printf("[Repository] トークンファミリー失効エラー: %v", err)
+		return 0, err
+	}
+	return result.RowsAffected()
+}
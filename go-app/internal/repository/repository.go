@@ -2,32 +2,212 @@ package repository
 
 import (
 	"database/sql"
+	"fmt"
 	"go-hsm-app/internal/common"
 	"go-hsm-app/internal/model"
 	"log"
+	"strings"
+
+	"github.com/lib/pq"
+	"golang.org/x/text/unicode/norm"
 )
 
-// FetchRecentItems はデータベースから直近のアイテムを取得します
+// itemSortColumns はアイテム一覧でソートに使用できる項目と列の対応です
+var itemSortColumns = map[string]string{
+	"code":       "i.code",
+	"name":       "i.name",
+	"updated_at": "i.updated_at",
+	"quantity":   "i.quantity",
+	"created_at": "i.created_at",
+}
+
+// likeEscaper は LIKE 検索のワイルドカードをエスケープします
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// searchPattern は検索語のプレースホルダを正規化し、LIKE のワイルドカードをエスケープする式を返します
+// 正規化（NFKC）で全角の ％ ＿ ＼ が半角になるため、エスケープは正規化の後に行います
+func searchPattern(placeholder string) string {
+	return `replace(replace(replace(normalize_search_text(` + placeholder + `), '\', '\\'), '%', '\%'), '_', '\_')`
+}
+
+// normalizeSearchText は DB の normalize_search_text() と同じ正規化（NFKC・ひらがな→カタカナ・小文字化）を行います
+func normalizeSearchText(text string) string {
+	text = strings.Map(func(r rune) rune {
+		// ぁ(U+3041)〜ゖ(U+3096)、ゝゞ は 0x60 後ろのカタカナに対応する
+		if (r >= 'ぁ' && r <= 'ゖ') || r == 'ゝ' || r == 'ゞ' {
+			return r + 0x60
+		}
+		return r
+	}, norm.NFKC.String(text))
+	return strings.ToLower(text)
+}
+
+// prefixPattern は検索語を正規化してワイルドカードをエスケープし、前方一致の LIKE パターンを返します
+// 1 つのパラメータとして渡すことで、text_pattern_ops の式インデックス（DB/13）を前方一致の検索に使用できます
+func prefixPattern(prefix string) string {
+	return likeEscaper.Replace(normalizeSearchText(prefix)) + "%"
+}
+
+// itemRelevance は検索語に対するアイテムの関連度を求める式を返します
+// コード完全一致 > 名称完全一致 > 名称前方一致 > コード前方一致 > 名称部分一致 > 属性値のみ一致 の順に高く、
+// 同順位では名称との類似度の高いものを優先します
+func itemRelevance(placeholder string) string {
+	q, pattern := "normalize_search_text("+placeholder+")", searchPattern(placeholder)
+	return `(CASE
+			WHEN normalize_search_text(i.code) = ` + q + ` THEN 100
+			WHEN normalize_search_text(i.name) = ` + q + ` THEN 80
+			WHEN normalize_search_text(i.name) LIKE ` + pattern + ` || '%' THEN 60
+			WHEN normalize_search_text(i.code) LIKE ` + pattern + ` || '%' THEN 50
+			WHEN normalize_search_text(i.name) LIKE '%' || ` + pattern + ` || '%' THEN 40
+			ELSE 10
+		END + similarity(normalize_search_text(i.name), ` + q + `))`
+}
+
+// attributeValueExpr は属性の型に応じて比較に使用する属性値の式と、比較する値のキャスト先を返します
+// 数値・日付に変換できない値は NULL となり、どの比較にも一致しません
+func attributeValueExpr(valueType string) (expr, cast string) {
+	switch valueType {
+	case model.AttributeTypeNumber:
+		return "attribute_numeric(ia.value)", "NUMERIC"
+	case model.AttributeTypeDate:
+		return "attribute_date(ia.value)", "DATE"
+	}
+	return "ia.value", "TEXT"
+}
+
+// attributeFilterCond は属性値の条件を SQL の条件式に変換します
+// 値は arg でプレースホルダとして追加します
+func attributeFilterCond(filter model.AttributeFilter, arg func(v interface{}) string) string {
+	expr, cast := attributeValueExpr(filter.ValueType)
+	value := func(i int) string {
+		return arg(filter.Values[i]) + "::" + cast
+	}
+
+	var cmp string
+	switch filter.Operator {
+	case model.AttributeFilterLt:
+		cmp = expr + " < " + value(0)
+	case model.AttributeFilterGt:
+		cmp = expr + " > " + value(0)
+	case model.AttributeFilterBetween:
+		cmp = expr + " BETWEEN " + value(0) + " AND " + value(1)
+	case model.AttributeFilterContains:
+		cmp = "ia.value ILIKE '%' || " + arg(likeEscaper.Replace(filter.Values[0])) + " || '%'"
+	case model.AttributeFilterIn:
+		cmp = expr + " = ANY(" + arg(pq.Array(filter.Values)) + "::" + cast + "[])"
+	default: // eq, ne
+		cmp = expr + " = " + value(0)
+	}
+
+	exists := `EXISTS (
+			SELECT 1 FROM item_attributes ia
+			INNER JOIN attributes a ON ia.attribute_id = a.id AND a.deleted_at IS NULL
+			WHERE ia.item_id = i.id AND a.code = ` + arg(filter.Code) + ` AND ` + cmp + `
+		)`
+	if filter.Operator == model.AttributeFilterNe {
+		return "NOT " + exists
+	}
+	return exists
+}
+
+// FetchItems は検索条件に一致するアイテムと、ページネーション前の総件数を取得します
 // カテゴリと単位はマスタテーブルから結合して取得し、属性は別途取得します
-func FetchRecentItems(limit int) ([]model.Item, error) {
-	log.Printf("[Repository] FetchRecentItems - limit: %d", limit)
+// scope を指定した場合は権限付与の範囲内のアイテムのみ取得します
+func FetchItems(tenantID string, scope *model.AccessScope, query model.ItemQuery) ([]model.Item, int, error) {
+	log.Printf("[Repository] FetchItems - tenant_id: %s, query: %+v", tenantID, query)
+
+	args := []interface{}{tenantID}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
 
-	rows, err := common.DB.Query(`
+	conds := []string{"i.tenant_id = $1", "i.deleted_at IS NULL"}
+	keywords := strings.Fields(query.Keyword)
+	for _, keyword := range keywords {
+		conds = append(conds, "i.search_text LIKE '%' || "+searchPattern(arg(keyword))+" || '%'")
+	}
+	if query.Code != "" {
+		conds = append(conds, "i.code ILIKE '%' || "+arg(likeEscaper.Replace(query.Code))+" || '%'")
+	}
+	if query.Name != "" {
+		conds = append(conds, "i.name ILIKE '%' || "+arg(likeEscaper.Replace(query.Name))+" || '%'")
+	}
+	if len(query.Categories) > 0 {
+		p := arg(pq.Array(query.Categories))
+		if query.IncludeDescendants {
+			// 指定カテゴリとその子孫のカテゴリ
+			conds = append(conds, `i.category_id IN (
+				WITH RECURSIVE subtree AS (
+					SELECT id FROM categories
+					WHERE tenant_id = $1 AND deleted_at IS NULL AND (id = ANY(`+p+`) OR code = ANY(`+p+`))
+					UNION
+					SELECT ch.id FROM categories ch
+					INNER JOIN subtree st ON ch.parent_id = st.id
+					WHERE ch.deleted_at IS NULL
+				)
+				SELECT id FROM subtree
+			)`)
+		} else {
+			conds = append(conds, "(i.category_id = ANY("+p+") OR c.code = ANY("+p+"))")
+		}
+	}
+	if query.Status != "" {
+		conds = append(conds, "i.status = "+arg(query.Status))
+	}
+	switch query.Stock {
+	case model.StockFilterIn:
+		conds = append(conds, "i.quantity > 0")
+	case model.StockFilterOut:
+		conds = append(conds, "COALESCE(i.quantity, 0) <= 0")
+	}
+
+	for _, filter := range query.Attributes {
+		conds = append(conds, attributeFilterCond(filter, arg))
+	}
+
+	access := newAccessFilter(scope, len(args)+1)
+	args = append(args, access.args()...)
+	conds = append(conds, access.itemCond("i"))
+
+	from := `
+        FROM items i
+        LEFT JOIN categories c ON i.category_id = c.id AND c.deleted_at IS NULL
+        INNER JOIN units u ON i.unit_id = u.id
+        WHERE ` + strings.Join(conds, " AND ")
+
+	// ページネーション前の総件数を取得
+	var total int
+	if err := common.DB.QueryRow(access.with()+`SELECT COUNT(*)`+from, args...).Scan(&total); err != nil {
+		log.Printf("[Repository] 総件数取得エラー: %v", err)
+		return nil, 0, err
+	}
+
+	sortKey, direction := strings.TrimPrefix(query.Sort, "-"), "ASC"
+	if strings.HasPrefix(query.Sort, "-") {
+		direction = "DESC"
+	}
+	column, ok := itemSortColumns[sortKey]
+	if !ok {
+		column, direction = "i.created_at", "DESC"
+	}
+	orderBy := fmt.Sprintf("%s %s NULLS LAST, i.id %s", column, direction, direction)
+	if sortKey == "relevance" && len(keywords) > 0 {
+		// 関連度の高い順（-relevance の指定も関連度順として扱う）
+		orderBy = itemRelevance(arg(strings.Join(keywords, " "))) + " DESC, i.name ASC, i.id ASC"
+	}
+
+	rows, err := common.DB.Query(access.with()+`
         SELECT 
-					i.id, i.code, i.name, i.category_id, i.unit_id, i.quantity, i.unit_price, i.status, 
+					i.id, i.code, i.name, i.category_id, i.unit_id, i.quantity, i.unit_price, i.status, i.version,
 					i.created_at, i.updated_at,
 					c.id, c.code, c.name,
-					u.id, u.code, u.name
-        FROM items i
-        LEFT JOIN categories c ON i.category_id = c.id AND c.deleted_at IS NULL
-        INNER JOIN units u ON i.unit_id = u.id AND u.deleted_at IS NULL
-        WHERE i.deleted_at IS NULL
-        ORDER BY i.created_at DESC
-        LIMIT $1
-    `, limit)
+					u.id, u.code, u.name`+from+`
+        ORDER BY `+orderBy+`
+        LIMIT `+arg(query.Limit)+` OFFSET `+arg(query.Offset), args...)
 	if err != nil {
 		log.Printf("[Repository] DB クエリエラー: %v", err)
-		return nil, err
+		return nil, 0, err
 	}
 	defer rows.Close()
 
@@ -47,6 +227,7 @@ func FetchRecentItems(limit int) ([]model.Item, error) {
 			&item.Quantity,
 			&item.UnitPrice,
 			&item.Status,
+			&item.Version,
 			&item.CreatedAt,
 			&item.UpdatedAt,
 			&categoryID,
@@ -57,7 +238,7 @@ func FetchRecentItems(limit int) ([]model.Item, error) {
 			&unitName,
 		); err != nil {
 			log.Printf("[Repository] スキャンエラー: %v", err)
-			return nil, err
+			return nil, 0, err
 		}
 
 		// カテゴリ情報をセット（NULLの場合はnilのまま）
@@ -76,47 +257,93 @@ func FetchRecentItems(limit int) ([]model.Item, error) {
 			Name: unitName,
 		}
 
-		// 属性情報を取得
-		attributes, err := fetchItemAttributes(item.ID)
-		if err != nil {
-			log.Printf("[Repository] 属性取得エラー (item_id: %s): %v", item.ID, err)
-			// エラーがあっても続行（属性は空配列）
-		} else {
-			item.Attributes = attributes
-		}
-
 		items = append(items, item)
 	}
 
 	// rows の反復中にエラーがないか確認
+	if err = rows.Err(); err != nil {
+		log.Printf("[Repository] rows.Err(): %v", err)
+		return nil, 0, err
+	}
+
+	// 属性情報を 1 回のクエリでまとめて取得
+	ids := make([]string, len(items))
+	for i := range items {
+		ids[i] = items[i].ID
+	}
+	if attributes, err := fetchItemAttributesByItem(tenantID, ids); err != nil {
+		log.Printf("[Repository] 属性取得エラー: %v", err)
+		// エラーがあっても続行（属性は空配列）
+	} else {
+		for i := range items {
+			items[i].Attributes = attributes[items[i].ID]
+		}
+	}
+
+	log.Printf("[Repository] 取得成功: %d件のアイテム (総件数: %d)", len(items), total)
+	return items, total, nil
+}
+
+// SuggestItems は入力途中の検索語に前方一致するアイテムを入力補完候補として取得します
+// 名称またはコードを正規化して比較し、名称の前方一致を優先して短い名称から返します
+func SuggestItems(tenantID string, scope *model.AccessScope, prefix string, limit int) ([]model.ItemSuggestion, error) {
+	log.Printf("[Repository] SuggestItems - tenant_id: %s, prefix: %s, limit: %d", tenantID, prefix, limit)
+
+	access := newAccessFilter(scope, 4)
+	rows, err := common.DB.Query(access.with()+`
+        SELECT i.id, i.code, i.name
+        FROM items i
+        WHERE i.tenant_id = $1 AND i.deleted_at IS NULL
+          AND (normalize_search_text(i.name) LIKE $2 OR normalize_search_text(i.code) LIKE $2)
+          AND `+access.itemCond("i")+`
+        ORDER BY (normalize_search_text(i.name) LIKE $2) DESC, length(i.name), i.name, i.id
+        LIMIT $3
+    `, append([]interface{}{tenantID, prefixPattern(prefix), limit}, access.args()...)...)
+	if err != nil {
+		log.Printf("[Repository] DB クエリエラー: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	suggestions := []model.ItemSuggestion{}
+	for rows.Next() {
+		var s model.ItemSuggestion
+		if err := rows.Scan(&s.ID, &s.Code, &s.Name); err != nil {
+			log.Printf("[Repository] スキャンエラー: %v", err)
+			return nil, err
+		}
+		suggestions = append(suggestions, s)
+	}
 	if err = rows.Err(); err != nil {
 		log.Printf("[Repository] rows.Err(): %v", err)
 		return nil, err
 	}
 
-	log.Printf("[Repository] 取得成功: %d件のアイテム", len(items))
-	return items, nil
+	log.Printf("[Repository] 取得成功: %d件の候補", len(suggestions))
+	return suggestions, nil
 }
 
 // FetchItemByID はIDでアイテムを取得します
-func FetchItemByID(id string) (*model.Item, error) {
-	log.Printf("[Repository] FetchItemByID - id: %s", id)
+// scope を指定した場合、権限付与の範囲外のアイテムは sql.ErrNoRows となります
+func FetchItemByID(tenantID string, scope *model.AccessScope, id string) (*model.Item, error) {
+	log.Printf("[Repository] FetchItemByID - tenant_id: %s, id: %s", tenantID, id)
 
 	var item model.Item
 	var categoryID, categoryCode, categoryName sql.NullString
 	var unitID, unitCode, unitName string
 
-	err := common.DB.QueryRow(`
+	access := newAccessFilter(scope, 3)
+	err := common.DB.QueryRow(access.with()+`
         SELECT 
-			i.id, i.code, i.name, i.category_id, i.unit_id, i.quantity, i.unit_price, i.status, 
+			i.id, i.code, i.name, i.category_id, i.unit_id, i.quantity, i.unit_price, i.status, i.version,
 			i.created_at, i.updated_at,
 			c.id, c.code, c.name,
 			u.id, u.code, u.name
         FROM items i
         LEFT JOIN categories c ON i.category_id = c.id AND c.deleted_at IS NULL
-        INNER JOIN units u ON i.unit_id = u.id AND u.deleted_at IS NULL
-        WHERE i.id = $1 AND i.deleted_at IS NULL
-    `, id).Scan(
+        INNER JOIN units u ON i.unit_id = u.id
+        WHERE i.id = $1 AND i.tenant_id = $2 AND i.deleted_at IS NULL AND `+access.itemCond("i")+`
+    `, append([]interface{}{id, tenantID}, access.args()...)...).Scan(
 		&item.ID,
 		&item.Code,
 		&item.Name,
@@ -125,6 +352,7 @@ func FetchItemByID(id string) (*model.Item, error) {
 		&item.Quantity,
 		&item.UnitPrice,
 		&item.Status,
+		&item.Version,
 		&item.CreatedAt,
 		&item.UpdatedAt,
 		&categoryID,
@@ -161,7 +389,7 @@ func FetchItemByID(id string) (*model.Item, error) {
 	}
 
 	// 属性情報を取得
-	attributes, err := fetchItemAttributes(item.ID)
+	attributes, err := fetchItemAttributes(tenantID, item.ID)
 	if err != nil {
 		log.Printf("[Repository] 属性取得エラー (item_id: %s): %v", item.ID, err)
 		// エラーがあっても続行（属性は空配列）
@@ -174,41 +402,56 @@ func FetchItemByID(id string) (*model.Item, error) {
 }
 
 // fetchItemAttributes は指定されたアイテムIDの属性情報を取得します
-func fetchItemAttributes(itemID string) ([]model.ItemAttributeDetail, error) {
+func fetchItemAttributes(tenantID, itemID string) ([]model.ItemAttributeDetail, error) {
+	attributes, err := fetchItemAttributesByItem(tenantID, []string{itemID})
+	if err != nil {
+		return nil, err
+	}
+	return attributes[itemID], nil
+}
+
+// fetchItemAttributesByItem は複数のアイテムの属性値を 1 回のクエリで取得し、アイテムIDごとに返します
+func fetchItemAttributesByItem(tenantID string, itemIDs []string) (map[string][]model.ItemAttributeDetail, error) {
+	attributes := map[string][]model.ItemAttributeDetail{}
+	if len(itemIDs) == 0 {
+		return attributes, nil
+	}
+
 	rows, err := common.DB.Query(`
-        SELECT a.code, a.name, a.value_type, ia.value
+        SELECT ia.item_id, a.code, a.name, a.value_type, ia.value, o.label
         FROM item_attributes ia
         INNER JOIN attributes a ON ia.attribute_id = a.id AND a.deleted_at IS NULL
-        WHERE ia.item_id = $1
-        ORDER BY a.code
-    `, itemID)
+        LEFT JOIN attribute_options o ON a.value_type = 'enum' AND o.attribute_id = a.id AND o.code = ia.value
+        WHERE ia.item_id = ANY($1) AND ia.tenant_id = $2
+        ORDER BY ia.item_id, a.code
+    `, pq.Array(itemIDs), tenantID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var attributes []model.ItemAttributeDetail
 	for rows.Next() {
+		var itemID string
 		var attr model.ItemAttributeDetail
-		if err := rows.Scan(&attr.Code, &attr.Name, &attr.ValueType, &attr.Value); err != nil {
+		if err := rows.Scan(&itemID, &attr.Code, &attr.Name, &attr.ValueType, &attr.Value, &attr.Label); err != nil {
 			return nil, err
 		}
-		attributes = append(attributes, attr)
+		attributes[itemID] = append(attributes[itemID], attr)
 	}
 
 	return attributes, rows.Err()
 }
 
 // FetchCategories はデータベースから全カテゴリを取得します
-func FetchCategories() ([]model.Category, error) {
-	log.Printf("[Repository] FetchCategories")
+func FetchCategories(tenantID string) ([]model.Category, error) {
+	log.Printf("[Repository] FetchCategories - tenant_id: %s", tenantID)
 
 	rows, err := common.DB.Query(`
-        SELECT id, code, name, description, created_at, updated_at
+        SELECT id, code, name, parent_id, description, version, created_at, updated_at
         FROM categories
-        WHERE deleted_at IS NULL
+        WHERE tenant_id = $1 AND deleted_at IS NULL
         ORDER BY code
-    `)
+    `, tenantID)
 	if err != nil {
 		log.Printf("[Repository] DB クエリエラー: %v", err)
 		return nil, err
@@ -222,7 +465,9 @@ func FetchCategories() ([]model.Category, error) {
 			&category.ID,
 			&category.Code,
 			&category.Name,
+			&category.ParentID,
 			&category.Description,
+			&category.Version,
 			&category.CreatedAt,
 			&category.UpdatedAt,
 		); err != nil {
@@ -236,16 +481,48 @@ func FetchCategories() ([]model.Category, error) {
 	return categories, rows.Err()
 }
 
+// FetchCategoryByID はIDでカテゴリを取得します
+func FetchCategoryByID(tenantID, id string) (*model.Category, error) {
+	log.Printf("[Repository] FetchCategoryByID - tenant_id: %s, id: %s", tenantID, id)
+
+	var category model.Category
+	err := common.DB.QueryRow(`
+		SELECT id, code, name, parent_id, description, version, created_at, updated_at
+		FROM categories
+		WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL
+	`, id, tenantID).Scan(
+		&category.ID,
+		&category.Code,
+		&category.Name,
+		&category.ParentID,
+		&category.Description,
+		&category.Version,
+		&category.CreatedAt,
+		&category.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Printf("[Repository] カテゴリが見つかりません: %s", id)
+			return nil, sql.ErrNoRows
+		}
+		log.Printf("[Repository] DBクエリエラー: %v", err)
+		return nil, err
+	}
+
+	return &category, nil
+}
+
 // FetchUnits はデータベースから全単位を取得します
-func FetchUnits() ([]model.Unit, error) {
-	log.Printf("[Repository] FetchUnits")
+func FetchUnits(tenantID string) ([]model.Unit, error) {
+	log.Printf("[Repository] FetchUnits - tenant_id: %s", tenantID)
 
 	rows, err := common.DB.Query(`
-        SELECT id, code, name, description, created_at, updated_at
+        SELECT id, code, name, description, version, created_at, updated_at
         FROM units
-        WHERE deleted_at IS NULL
+        WHERE tenant_id = $1 AND deleted_at IS NULL
         ORDER BY code
-    `)
+    `, tenantID)
 	if err != nil {
 		log.Printf("[Repository] DB クエリエラー: %v", err)
 		return nil, err
@@ -260,6 +537,7 @@ func FetchUnits() ([]model.Unit, error) {
 			&unit.Code,
 			&unit.Name,
 			&unit.Description,
+			&unit.Version,
 			&unit.CreatedAt,
 			&unit.UpdatedAt,
 		); err != nil {
@@ -273,16 +551,47 @@ func FetchUnits() ([]model.Unit, error) {
 	return units, rows.Err()
 }
 
+// FetchUnitByID はIDで単位を取得します
+func FetchUnitByID(tenantID, id string) (*model.Unit, error) {
+	log.Printf("[Repository] FetchUnitByID - tenant_id: %s, id: %s", tenantID, id)
+
+	var unit model.Unit
+	err := common.DB.QueryRow(`
+		SELECT id, code, name, description, version, created_at, updated_at
+		FROM units
+		WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL
+	`, id, tenantID).Scan(
+		&unit.ID,
+		&unit.Code,
+		&unit.Name,
+		&unit.Description,
+		&unit.Version,
+		&unit.CreatedAt,
+		&unit.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Printf("[Repository] 単位が見つかりません: %s", id)
+			return nil, sql.ErrNoRows
+		}
+		log.Printf("[Repository] DBクエリエラー: %v", err)
+		return nil, err
+	}
+
+	return &unit, nil
+}
+
 // FetchAttributes はデータベースから全属性を取得します
-func FetchAttributes() ([]model.Attribute, error) {
-	log.Printf("[Repository] FetchAttributes")
+func FetchAttributes(tenantID string) ([]model.Attribute, error) {
+	log.Printf("[Repository] FetchAttributes - tenant_id: %s", tenantID)
 
 	rows, err := common.DB.Query(`
-        SELECT id, code, name, value_type, description, created_at, updated_at
+        SELECT id, code, name, value_type, description, version, created_at, updated_at
         FROM attributes
-        WHERE deleted_at IS NULL
+        WHERE tenant_id = $1 AND deleted_at IS NULL
         ORDER BY code
-    `)
+    `, tenantID)
 	if err != nil {
 		log.Printf("[Repository] DB クエリエラー: %v", err)
 		return nil, err
@@ -298,6 +607,7 @@ func FetchAttributes() ([]model.Attribute, error) {
 			&attribute.Name,
 			&attribute.ValueType,
 			&attribute.Description,
+			&attribute.Version,
 			&attribute.CreatedAt,
 			&attribute.UpdatedAt,
 		); err != nil {
@@ -311,16 +621,48 @@ func FetchAttributes() ([]model.Attribute, error) {
 	return attributes, rows.Err()
 }
 
+// FetchAttributeByID はIDで属性を取得します
+func FetchAttributeByID(tenantID, id string) (*model.Attribute, error) {
+	log.Printf("[Repository] FetchAttributeByID - tenant_id: %s, id: %s", tenantID, id)
+
+	var attribute model.Attribute
+	err := common.DB.QueryRow(`
+		SELECT id, code, name, value_type, description, version, created_at, updated_at
+		FROM attributes
+		WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL
+	`, id, tenantID).Scan(
+		&attribute.ID,
+		&attribute.Code,
+		&attribute.Name,
+		&attribute.ValueType,
+		&attribute.Description,
+		&attribute.Version,
+		&attribute.CreatedAt,
+		&attribute.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Printf("[Repository] 属性が見つかりません: %s", id)
+			return nil, sql.ErrNoRows
+		}
+		log.Printf("[Repository] DBクエリエラー: %v", err)
+		return nil, err
+	}
+
+	return &attribute, nil
+}
+
 // FetchUsers はデータベースから全ユーザーを取得します
-func FetchUsers() ([]model.User, error) {
-	log.Printf("[Repository] FetchUsers")
+func FetchUsers(tenantID string) ([]model.User, error) {
+	log.Printf("[Repository] FetchUsers - tenant_id: %s", tenantID)
 
 	rows, err := common.DB.Query(`
-        SELECT id, email, role, created_at, updated_at
+        SELECT id, tenant_id, email, role, version, created_at, updated_at
         FROM users
-        WHERE deleted_at IS NULL
+        WHERE tenant_id = $1 AND deleted_at IS NULL
         ORDER BY created_at DESC
-    `)
+    `, tenantID)
 	if err != nil {
 		log.Printf("[Repository] DB クエリエラー: %v", err)
 		return nil, err
@@ -332,8 +674,10 @@ func FetchUsers() ([]model.User, error) {
 		var user model.User
 		if err := rows.Scan(
 			&user.ID,
+			&user.TenantID,
 			&user.Email,
 			&user.Role,
+			&user.Version,
 			&user.CreatedAt,
 			&user.UpdatedAt,
 		); err != nil {
@@ -348,29 +692,40 @@ func FetchUsers() ([]model.User, error) {
 }
 
 // CreateCategory はカテゴリを作成します
-func CreateCategory(code, name, description string) (*model.Category, error) {
-	log.Printf("[Repository] CreateCategory - code: %s, name: %s", code, name)
+// parentID を指定した場合は、そのカテゴリの子として作成します。親カテゴリが存在しない場合は ErrParentCategoryNotFound を返します
+func CreateCategory(tenantID, code, name, description string, parentID *string) (*model.Category, error) {
+	log.Printf("[Repository] CreateCategory - tenant_id: %s, code: %s, name: %s", tenantID, code, name)
 
 	var category model.Category
 	err := common.DB.QueryRow(`
 		WITH new_id AS (
 			SELECT 'C' || LPAD(nextval('categories_id_seq')::TEXT, 8, '0') as id
 		)
-		INSERT INTO categories (id, code, name, description)
-		SELECT id, $1, $2, $3 FROM new_id
-		RETURNING id, code, name, description, created_at, updated_at
-	`, code, name, description).Scan(
+		INSERT INTO categories (id, tenant_id, code, name, description, parent_id)
+		SELECT id, $4, $1, $2, $3, $5 FROM new_id
+		WHERE $5::TEXT IS NULL OR EXISTS (
+			SELECT 1 FROM categories WHERE id = $5 AND tenant_id = $4 AND deleted_at IS NULL
+			FOR SHARE
+		)
+		RETURNING id, code, name, parent_id, description, version, created_at, updated_at
+	`, code, name, description, tenantID, parentID).Scan(
 		&category.ID,
 		&category.Code,
 		&category.Name,
+		&category.ParentID,
 		&category.Description,
+		&category.Version,
 		&category.CreatedAt,
 		&category.UpdatedAt,
 	)
 
+	if err == sql.ErrNoRows {
+		log.Printf("[Repository] 親カテゴリが見つかりません: %v", *parentID)
+		return nil, ErrParentCategoryNotFound
+	}
 	if err != nil {
 		log.Printf("[Repository] カテゴリ作成エラー: %v", err)
-		return nil, err
+		return nil, duplicateError(err, ErrDuplicateCode, code)
 	}
 
 	log.Printf("[Repository] カテゴリ作成成功: %s (code: %s)", category.ID, category.Code)
@@ -378,27 +733,34 @@ func CreateCategory(code, name, description string) (*model.Category, error) {
 }
 
 // UpdateCategory はカテゴリを更新します
-func UpdateCategory(id, code, name, description string) (*model.Category, error) {
-	log.Printf("[Repository] UpdateCategory - id: %s, code: %s, name: %s", id, code, name)
+// version を指定した場合は現在のバージョンと一致するときのみ更新し、一致しなければ ErrVersionConflict を返します
+func UpdateCategory(tenantID, id, code, name string, description *string, version *int) (*model.Category, error) {
+	log.Printf("[Repository] UpdateCategory - tenant_id: %s, id: %s, code: %s, name: %s", tenantID, id, code, name)
 
 	var category model.Category
 	err := common.DB.QueryRow(`
 		UPDATE categories
-		SET code = $2, name = $3, description = $4, updated_at = CURRENT_TIMESTAMP
-		WHERE id = $1 AND deleted_at IS NULL
-		RETURNING id, code, name, description, created_at, updated_at
-	`, id, code, name, description).Scan(
+		SET code = $2, name = $3, description = $4, updated_at = CURRENT_TIMESTAMP, version = version + 1
+		WHERE id = $1 AND tenant_id = $5 AND deleted_at IS NULL
+		  AND ($6::INTEGER IS NULL OR version = $6)
+		RETURNING id, code, name, parent_id, description, version, created_at, updated_at
+	`, id, code, name, description, tenantID, version).Scan(
 		&category.ID,
 		&category.Code,
 		&category.Name,
+		&category.ParentID,
 		&category.Description,
+		&category.Version,
 		&category.CreatedAt,
 		&category.UpdatedAt,
 	)
 
+	if err == sql.ErrNoRows {
+		return nil, versionConflictOrNotFound("categories", tenantID, id)
+	}
 	if err != nil {
 		log.Printf("[Repository] カテゴリ更新エラー: %v", err)
-		return nil, err
+		return nil, duplicateError(err, ErrDuplicateCode, code)
 	}
 
 	log.Printf("[Repository] カテゴリ更新成功: %s", category.ID)
@@ -406,59 +768,48 @@ func UpdateCategory(id, code, name, description string) (*model.Category, error)
 }
 
 // DeleteCategory はカテゴリを削除します（論理削除）
-func DeleteCategory(id string) error {
-	log.Printf("[Repository] DeleteCategory - id: %s", id)
-
-	result, err := common.DB.Exec(`
-		UPDATE categories
-		SET deleted_at = CURRENT_TIMESTAMP
-		WHERE id = $1 AND deleted_at IS NULL
-	`, id)
-
+// 子カテゴリがある場合は ErrCategoryHasChildren、アイテムから参照されている場合は MasterInUseError を返します。
+// replacementID を指定した場合は、アイテムのカテゴリを付け替えてから同じトランザクションで削除します
+func DeleteCategory(tenantID, id string, replacementID *string) error {
+	log.Printf("[Repository] DeleteCategory - tenant_id: %s, id: %s, replacement: %v", tenantID, id, replacementID)
+
+	err := deleteMasterTx(tenantID, id, replacementID, categoryReference, func(tx *sql.Tx) error {
+		return lockCategoryTree(tx, tenantID)
+	})
 	if err != nil {
 		log.Printf("[Repository] カテゴリ削除エラー: %v", err)
 		return err
 	}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		log.Printf("[Repository] RowsAffected取得エラー: %v", err)
-		return err
-	}
-
-	if rowsAffected == 0 {
-		log.Printf("[Repository] カテゴリが見つかりません: %s", id)
-		return sql.ErrNoRows
-	}
-
 	log.Printf("[Repository] カテゴリ削除成功: %s", id)
 	return nil
 }
 
 // CreateUnit は単位を作成します
-func CreateUnit(code, name, description string) (*model.Unit, error) {
-	log.Printf("[Repository] CreateUnit - code: %s, name: %s", code, name)
+func CreateUnit(tenantID, code, name, description string) (*model.Unit, error) {
+	log.Printf("[Repository] CreateUnit - tenant_id: %s, code: %s, name: %s", tenantID, code, name)
 
 	var unit model.Unit
 	err := common.DB.QueryRow(`
 		WITH new_id AS (
 			SELECT 'UN' || LPAD(nextval('units_id_seq')::TEXT, 8, '0') as id
 		)
-		INSERT INTO units (id, code, name, description)
-		SELECT id, $1, $2, $3 FROM new_id
-		RETURNING id, code, name, description, created_at, updated_at
-	`, code, name, description).Scan(
+		INSERT INTO units (id, tenant_id, code, name, description)
+		SELECT id, $4, $1, $2, $3 FROM new_id
+		RETURNING id, code, name, description, version, created_at, updated_at
+	`, code, name, description, tenantID).Scan(
 		&unit.ID,
 		&unit.Code,
 		&unit.Name,
 		&unit.Description,
+		&unit.Version,
 		&unit.CreatedAt,
 		&unit.UpdatedAt,
 	)
 
 	if err != nil {
 		log.Printf("[Repository] 単位作成エラー: %v", err)
-		return nil, err
+		return nil, duplicateError(err, ErrDuplicateCode, code)
 	}
 
 	log.Printf("[Repository] 単位作成成功: %s (code: %s)", unit.ID, unit.Code)
@@ -466,27 +817,33 @@ func CreateUnit(code, name, description string) (*model.Unit, error) {
 }
 
 // UpdateUnit は単位を更新します
-func UpdateUnit(id, code, name, description string) (*model.Unit, error) {
-	log.Printf("[Repository] UpdateUnit - id: %s, code: %s, name: %s", id, code, name)
+// version を指定した場合は現在のバージョンと一致するときのみ更新し、一致しなければ ErrVersionConflict を返します
+func UpdateUnit(tenantID, id, code, name string, description *string, version *int) (*model.Unit, error) {
+	log.Printf("[Repository] UpdateUnit - tenant_id: %s, id: %s, code: %s, name: %s", tenantID, id, code, name)
 
 	var unit model.Unit
 	err := common.DB.QueryRow(`
 		UPDATE units
-		SET code = $2, name = $3, description = $4, updated_at = CURRENT_TIMESTAMP
-		WHERE id = $1 AND deleted_at IS NULL
-		RETURNING id, code, name, description, created_at, updated_at
-	`, id, code, name, description).Scan(
+		SET code = $2, name = $3, description = $4, updated_at = CURRENT_TIMESTAMP, version = version + 1
+		WHERE id = $1 AND tenant_id = $5 AND deleted_at IS NULL
+		  AND ($6::INTEGER IS NULL OR version = $6)
+		RETURNING id, code, name, description, version, created_at, updated_at
+	`, id, code, name, description, tenantID, version).Scan(
 		&unit.ID,
 		&unit.Code,
 		&unit.Name,
 		&unit.Description,
+		&unit.Version,
 		&unit.CreatedAt,
 		&unit.UpdatedAt,
 	)
 
+	if err == sql.ErrNoRows {
+		return nil, versionConflictOrNotFound("units", tenantID, id)
+	}
 	if err != nil {
 		log.Printf("[Repository] 単位更新エラー: %v", err)
-		return nil, err
+		return nil, duplicateError(err, ErrDuplicateCode, code)
 	}
 
 	log.Printf("[Repository] 単位更新成功: %s", unit.ID)
@@ -494,60 +851,47 @@ func UpdateUnit(id, code, name, description string) (*model.Unit, error) {
 }
 
 // DeleteUnit は単位を削除します（論理削除）
-func DeleteUnit(id string) error {
-	log.Printf("[Repository] DeleteUnit - id: %s", id)
+// アイテム・荷姿から参照されている場合は MasterInUseError を返します。
+// replacementID を指定した場合は、アイテム・荷姿の単位を付け替えてから同じトランザクションで削除します
+// （在庫・入出庫履歴のあるアイテムの基本単位は付け替えず ErrInvalidReplacement を返します）
+func DeleteUnit(tenantID, id string, replacementID *string) error {
+	log.Printf("[Repository] DeleteUnit - tenant_id: %s, id: %s, replacement: %v", tenantID, id, replacementID)
 
-	result, err := common.DB.Exec(`
-		UPDATE units
-		SET deleted_at = CURRENT_TIMESTAMP
-		WHERE id = $1 AND deleted_at IS NULL
-	`, id)
-
-	if err != nil {
+	if err := deleteMasterTx(tenantID, id, replacementID, unitReference, nil); err != nil {
 		log.Printf("[Repository] 単位削除エラー: %v", err)
 		return err
 	}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		log.Printf("[Repository] RowsAffected取得エラー: %v", err)
-		return err
-	}
-
-	if rowsAffected == 0 {
-		log.Printf("[Repository] 単位が見つかりません: %s", id)
-		return sql.ErrNoRows
-	}
-
 	log.Printf("[Repository] 単位削除成功: %s", id)
 	return nil
 }
 
 // CreateAttribute は属性を作成します
-func CreateAttribute(code, name, valueType, description string) (*model.Attribute, error) {
-	log.Printf("[Repository] CreateAttribute - code: %s, name: %s, valueType: %s", code, name, valueType)
+func CreateAttribute(tenantID, code, name, valueType, description string) (*model.Attribute, error) {
+	log.Printf("[Repository] CreateAttribute - tenant_id: %s, code: %s, name: %s, valueType: %s", tenantID, code, name, valueType)
 
 	var attribute model.Attribute
 	err := common.DB.QueryRow(`
 		WITH new_id AS (
 			SELECT 'A' || LPAD(nextval('attributes_id_seq')::TEXT, 8, '0') as id
 		)
-		INSERT INTO attributes (id, code, name, value_type, description)
-		SELECT id, $1, $2, $3, $4 FROM new_id
-		RETURNING id, code, name, value_type, description, created_at, updated_at
-	`, code, name, valueType, description).Scan(
+		INSERT INTO attributes (id, tenant_id, code, name, value_type, description)
+		SELECT id, $5, $1, $2, $3, $4 FROM new_id
+		RETURNING id, code, name, value_type, description, version, created_at, updated_at
+	`, code, name, valueType, description, tenantID).Scan(
 		&attribute.ID,
 		&attribute.Code,
 		&attribute.Name,
 		&attribute.ValueType,
 		&attribute.Description,
+		&attribute.Version,
 		&attribute.CreatedAt,
 		&attribute.UpdatedAt,
 	)
 
 	if err != nil {
 		log.Printf("[Repository] 属性作成エラー: %v", err)
-		return nil, err
+		return nil, duplicateError(err, ErrDuplicateCode, code)
 	}
 
 	log.Printf("[Repository] 属性作成成功: %s (code: %s)", attribute.ID, attribute.Code)
@@ -555,27 +899,66 @@ func CreateAttribute(code, name, valueType, description string) (*model.Attribut
 }
 
 // UpdateAttribute は属性を更新します
-func UpdateAttribute(id, code, name, valueType, description string) (*model.Attribute, error) {
-	log.Printf("[Repository] UpdateAttribute - id: %s, code: %s, name: %s, valueType: %s", id, code, name, valueType)
+// version を指定した場合は現在のバージョンと一致するときのみ更新し、一致しなければ ErrVersionConflict を返します
+// 属性値（削除済みのアイテムを含む）やカテゴリの既定値がある場合は、値が新しい型に合わなくなるため型を変更せず ErrValueTypeInUse を返します
+func UpdateAttribute(tenantID, id, code, name, valueType string, description *string, version *int) (*model.Attribute, error) {
+	log.Printf("[Repository] UpdateAttribute - tenant_id: %s, id: %s, code: %s, name: %s, valueType: %s", tenantID, id, code, name, valueType)
+
+	tx, err := common.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// 属性の行をロックし、型の確認から更新までの間に属性値が追加されないようにする
+	var currentType string
+	var inUse bool
+	err = tx.QueryRow(`
+		SELECT a.value_type,
+			EXISTS (SELECT 1 FROM item_attributes WHERE attribute_id = a.id)
+			OR EXISTS (SELECT 1 FROM category_attributes WHERE attribute_id = a.id AND default_value IS NOT NULL)
+		FROM attributes a
+		WHERE a.id = $1 AND a.tenant_id = $2 AND a.deleted_at IS NULL
+		FOR UPDATE
+	`, id, tenantID).Scan(&currentType, &inUse)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Printf("[Repository] 属性が見つかりません: %s", id)
+		}
+		return nil, err
+	}
+	if valueType != currentType && inUse {
+		log.Printf("[Repository] 属性値があるため型を変更できません: %s (%s → %s)", id, currentType, valueType)
+		return nil, ErrValueTypeInUse
+	}
 
 	var attribute model.Attribute
-	err := common.DB.QueryRow(`
+	err = tx.QueryRow(`
 		UPDATE attributes
-		SET code = $2, name = $3, value_type = $4, description = $5, updated_at = CURRENT_TIMESTAMP
-		WHERE id = $1 AND deleted_at IS NULL
-		RETURNING id, code, name, value_type, description, created_at, updated_at
-	`, id, code, name, valueType, description).Scan(
+		SET code = $2, name = $3, value_type = $4, description = $5, updated_at = CURRENT_TIMESTAMP, version = version + 1
+		WHERE id = $1 AND tenant_id = $6 AND deleted_at IS NULL
+		  AND ($7::INTEGER IS NULL OR version = $7)
+		RETURNING id, code, name, value_type, description, version, created_at, updated_at
+	`, id, code, name, valueType, description, tenantID, version).Scan(
 		&attribute.ID,
 		&attribute.Code,
 		&attribute.Name,
 		&attribute.ValueType,
 		&attribute.Description,
+		&attribute.Version,
 		&attribute.CreatedAt,
 		&attribute.UpdatedAt,
 	)
 
+	if err == sql.ErrNoRows {
+		return nil, versionConflictOrNotFound("attributes", tenantID, id)
+	}
 	if err != nil {
 		log.Printf("[Repository] 属性更新エラー: %v", err)
+		return nil, duplicateError(err, ErrDuplicateCode, code)
+	}
+
+	if err := tx.Commit(); err != nil {
 		return nil, err
 	}
 
@@ -584,58 +967,47 @@ func UpdateAttribute(id, code, name, valueType, description string) (*model.Attr
 }
 
 // DeleteAttribute は属性を削除します（論理削除）
-func DeleteAttribute(id string) error {
-	log.Printf("[Repository] DeleteAttribute - id: %s", id)
-
-	result, err := common.DB.Exec(`
-		UPDATE attributes
-		SET deleted_at = CURRENT_TIMESTAMP
-		WHERE id = $1 AND deleted_at IS NULL
-	`, id)
+// アイテムが属性値を持つ場合は MasterInUseError を返します。
+// replacementID を指定した場合は、属性値を付け替え先の属性に移してから同じトランザクションで削除します
+func DeleteAttribute(tenantID, id string, replacementID *string) error {
+	log.Printf("[Repository] DeleteAttribute - tenant_id: %s, id: %s, replacement: %v", tenantID, id, replacementID)
 
-	if err != nil {
+	if err := deleteMasterTx(tenantID, id, replacementID, attributeReference, nil); err != nil {
 		log.Printf("[Repository] 属性削除エラー: %v", err)
 		return err
 	}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		log.Printf("[Repository] RowsAffected取得エラー: %v", err)
-		return err
-	}
-
-	if rowsAffected == 0 {
-		log.Printf("[Repository] 属性が見つかりません: %s", id)
-		return sql.ErrNoRows
-	}
-
 	log.Printf("[Repository] 属性削除成功: %s", id)
 	return nil
 }
 
 // CreateUser はユーザーを作成します
-func CreateUser(email, role string) (*model.User, error) {
-	log.Printf("[Repository] CreateUser - email: %s, role: %s", email, role)
+// passwordHash が空の場合はパスワード未設定のユーザーとして作成され、ログインできません
+// メールアドレスは全テナントで一意のため、他のユーザーと重複する場合は ErrDuplicateEmail を返します
+func CreateUser(tenantID, email, passwordHash, role string) (*model.User, error) {
+	log.Printf("[Repository] CreateUser - tenant_id: %s, email: %s, role: %s", tenantID, email, role)
 
 	var user model.User
 	err := common.DB.QueryRow(`
 		WITH new_id AS (
 			SELECT 'U' || LPAD(nextval('users_id_seq')::TEXT, 8, '0') as id
 		)
-		INSERT INTO users (id, email, password_hash, role)
-		SELECT id, $1, 'temp_hash', $2 FROM new_id
-		RETURNING id, email, role, created_at, updated_at
-	`, email, role).Scan(
+		INSERT INTO users (id, tenant_id, email, password_hash, role)
+		SELECT id, $4, $1, $2, $3 FROM new_id
+		RETURNING id, tenant_id, email, role, version, created_at, updated_at
+	`, email, passwordHash, role, tenantID).Scan(
 		&user.ID,
+		&user.TenantID,
 		&user.Email,
 		&user.Role,
+		&user.Version,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
 
 	if err != nil {
 		log.Printf("[Repository] ユーザー作成エラー: %v", err)
-		return nil, err
+		return nil, duplicateError(err, ErrDuplicateEmail, email)
 	}
 
 	log.Printf("[Repository] ユーザー作成成功: %s", user.ID)
@@ -643,25 +1015,69 @@ func CreateUser(email, role string) (*model.User, error) {
 }
 
 // UpdateUser はユーザーを更新します
-func UpdateUser(id, email, role string) (*model.User, error) {
-	log.Printf("[Repository] UpdateUser - id: %s, email: %s, role: %s", id, email, role)
+// version を指定した場合は現在のバージョンと一致するときのみ更新し、一致しなければ ErrVersionConflict を返します
+// 権限を下げた場合は、下げる前の権限で発行したトークンを使えないよう同じトランザクションで全セッションを失効させます
+// メールアドレスが他のユーザー（他のテナントを含む）と重複する場合は ErrDuplicateEmail を返します
+func UpdateUser(tenantID, id, email, role string, version *int) (*model.User, error) {
+	log.Printf("[Repository] UpdateUser - tenant_id: %s, id: %s, email: %s, role: %s", tenantID, id, email, role)
+
+	tx, err := common.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var previousRole string
+	err = tx.QueryRow(`
+		SELECT role FROM users
+		WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL
+		FOR UPDATE
+	`, id, tenantID).Scan(&previousRole)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Printf("[Repository] ユーザーが見つかりません: %s", id)
+		}
+		return nil, err
+	}
 
 	var user model.User
-	err := common.DB.QueryRow(`
+	err = tx.QueryRow(`
 		UPDATE users
-		SET email = $2, role = $3, updated_at = CURRENT_TIMESTAMP
-		WHERE id = $1 AND deleted_at IS NULL
-		RETURNING id, email, role, created_at, updated_at
-	`, id, email, role).Scan(
+		SET email = $2, role = $3, updated_at = CURRENT_TIMESTAMP, version = version + 1
+		WHERE id = $1 AND tenant_id = $4 AND deleted_at IS NULL
+		  AND ($5::INTEGER IS NULL OR version = $5)
+		RETURNING id, tenant_id, email, role, version, created_at, updated_at
+	`, id, email, role, tenantID, version).Scan(
 		&user.ID,
+		&user.TenantID,
 		&user.Email,
 		&user.Role,
+		&user.Version,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
 
+	if err == sql.ErrNoRows {
+		return nil, versionConflictOrNotFound("users", tenantID, id)
+	}
 	if err != nil {
 		log.Printf("[Repository] ユーザー更新エラー: %v", err)
+		return nil, duplicateError(err, ErrDuplicateEmail, email)
+	}
+
+	if model.RoleRank[user.Role] < model.RoleRank[previousRole] {
+		if _, err := tx.Exec(`
+			UPDATE refresh_tokens
+			SET revoked_at = CURRENT_TIMESTAMP, revoked_reason = $2
+			WHERE user_id = $1 AND revoked_at IS NULL
+		`, user.ID, RevokeReasonRoleDowngraded); err != nil {
+			log.Printf("[Repository] セッション失効エラー: %v", err)
+			return nil, err
+		}
+		log.Printf("[Repository] 権限の変更に伴いセッションを失効しました: %s (%s → %s)", user.ID, previousRole, user.Role)
+	}
+
+	if err := tx.Commit(); err != nil {
 		return nil, err
 	}
 
@@ -670,14 +1086,14 @@ func UpdateUser(id, email, role string) (*model.User, error) {
 }
 
 // DeleteUser はユーザーを削除します（論理削除）
-func DeleteUser(id string) error {
-	log.Printf("[Repository] DeleteUser - id: %s", id)
+func DeleteUser(tenantID, id string) error {
+	log.Printf("[Repository] DeleteUser - tenant_id: %s, id: %s", tenantID, id)
 
 	result, err := common.DB.Exec(`
 		UPDATE users
 		SET deleted_at = CURRENT_TIMESTAMP
-		WHERE id = $1 AND deleted_at IS NULL
-	`, id)
+		WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL
+	`, id, tenantID)
 
 	if err != nil {
 		log.Printf("[Repository] ユーザー削除エラー: %v", err)
@@ -700,28 +1116,33 @@ func DeleteUser(id string) error {
 }
 
 // FetchStockHistory は在庫履歴を取得します
-func FetchStockHistory(limit, offset int) ([]model.StockHistory, int, error) {
-	log.Printf("[Repository] FetchStockHistory - limit: %d, offset: %d", limit, offset)
+// scope を指定した場合は権限付与の範囲内の履歴のみ取得します
+func FetchStockHistory(tenantID string, scope *model.AccessScope, limit, offset int) ([]model.StockHistory, int, error) {
+	log.Printf("[Repository] FetchStockHistory - tenant_id: %s, limit: %d, offset: %d", tenantID, limit, offset)
 
 	// 総件数を取得
 	var total int
-	err := common.DB.QueryRow(`
-		SELECT COUNT(*) FROM stock_history
-	`).Scan(&total)
+	countAccess := newAccessFilter(scope, 2)
+	err := common.DB.QueryRow(countAccess.with()+`
+		SELECT COUNT(*) FROM stock_history h
+		WHERE h.tenant_id = $1 AND `+countAccess.historyCond("h")+`
+	`, append([]interface{}{tenantID}, countAccess.args()...)...).Scan(&total)
 	if err != nil {
 		log.Printf("[Repository] 在庫履歴の総件数取得エラー: %v", err)
 		return nil, 0, err
 	}
 
 	// 履歴データを取得
-	rows, err := common.DB.Query(`
+	access := newAccessFilter(scope, 4)
+	rows, err := common.DB.Query(access.with()+`
 		SELECT 
-			id, item_id, qty_delta, kind, location_from, location_to, 
-			reason, meta, unit_price, total_amount, created_by, created_at
-		FROM stock_history
-		ORDER BY created_at DESC
-		LIMIT $1 OFFSET $2
-	`, limit, offset)
+			h.id, h.item_id, h.qty_delta, h.kind, h.location_from, h.location_to, 
+			h.reason, h.meta, h.unit_price, h.total_amount, h.created_by, h.created_at
+		FROM stock_history h
+		WHERE h.tenant_id = $1 AND `+access.historyCond("h")+`
+		ORDER BY h.created_at DESC
+		LIMIT $2 OFFSET $3
+	`, append([]interface{}{tenantID, limit, offset}, access.args()...)...)
 	if err != nil {
 		log.Printf("[Repository] 在庫履歴取得エラー: %v", err)
 		return nil, 0, err
@@ -761,15 +1182,24 @@ func FetchStockHistory(limit, offset int) ([]model.StockHistory, int, error) {
 }
 
 // CreateItem はアイテムを作成します
-func CreateItem(code, name, unitID string, categoryID *string, quantity *int, unitPrice *int) (*model.Item, error) {
-	log.Printf("[Repository] CreateItem - code: %s, name: %s", code, name)
+// attributes を指定した場合は、アイテムの作成と同一トランザクションで属性値を設定します
+// カテゴリの属性テンプレートの既定値を未設定の属性に設定し、テンプレートを満たさない場合は TemplateViolationError を返します
+// scope を指定した場合、権限付与の範囲外のカテゴリ（未設定を含む）のアイテムは作成せず ErrAccessDenied を返します
+func CreateItem(tenantID string, scope *model.AccessScope, code, name, unitID string, categoryID *string, quantity *int, unitPrice *int, attributes *model.ItemAttributeChanges) (*model.Item, error) {
+	log.Printf("[Repository] CreateItem - tenant_id: %s, code: %s, name: %s", tenantID, code, name)
+
+	tx, err := common.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
 
 	var item model.Item
-	err := common.DB.QueryRow(`
-		INSERT INTO items (code, name, category_id, unit_id, quantity, unit_price, status, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, 'active', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
-		RETURNING id, code, name, category_id, unit_id, quantity, unit_price, status, created_at, updated_at
-	`, code, name, categoryID, unitID, quantity, unitPrice).Scan(
+	err = tx.QueryRow(`
+		INSERT INTO items (tenant_id, code, name, category_id, unit_id, quantity, unit_price, status, created_at, updated_at)
+		VALUES ($7, $1, $2, $3, $4, $5, $6, 'active', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		RETURNING id, code, name, category_id, unit_id, quantity, unit_price, status, version, created_at, updated_at
+	`, code, name, categoryID, unitID, quantity, unitPrice, tenantID).Scan(
 		&item.ID,
 		&item.Code,
 		&item.Name,
@@ -778,30 +1208,81 @@ func CreateItem(code, name, unitID string, categoryID *string, quantity *int, un
 		&item.Quantity,
 		&item.UnitPrice,
 		&item.Status,
+		&item.Version,
 		&item.CreatedAt,
 		&item.UpdatedAt,
 	)
 
 	if err != nil {
 		log.Printf("[Repository] アイテム作成エラー: %v", err)
+		return nil, duplicateError(err, ErrDuplicateCode, code)
+	}
+
+	if err := writeItemAttributes(tx, tenantID, item.ID, attributes); err != nil {
+		return nil, err
+	}
+	if err := applyCategoryTemplate(tx, tenantID, item.ID, true, nil); err != nil {
+		return nil, err
+	}
+	if err := checkItemInScope(tx, tenantID, scope, item.ID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
 		return nil, err
 	}
 
+	// 属性情報を取得
+	if attributes, err := fetchItemAttributes(tenantID, item.ID); err != nil {
+		log.Printf("[Repository] 属性取得エラー (item_id: %s): %v", item.ID, err)
+	} else {
+		item.Attributes = attributes
+	}
+
 	log.Printf("[Repository] アイテム作成成功: %s", item.ID)
 	return &item, nil
 }
 
 // UpdateItem はアイテムを更新します
-func UpdateItem(id, code, name, unitID string, categoryID *string, quantity *int, unitPrice *int, status string) (*model.Item, error) {
-	log.Printf("[Repository] UpdateItem - id: %s", id)
+// attributes を指定した場合は、同一トランザクションで属性値も変更します
+// 更新後の属性値がカテゴリの属性テンプレートを満たさない場合は TemplateViolationError を返します
+// version を指定した場合は現在のバージョンと一致するときのみ更新し、一致しなければ ErrVersionConflict を返します
+// scope を指定した場合は権限付与の範囲内のアイテムのみ更新し、範囲外であれば sql.ErrNoRows、
+// 更新後のアイテムが範囲外になる場合（範囲外のカテゴリへの変更など）は ErrAccessDenied を返します
+// 在庫や入出庫履歴がある場合は、数量の意味が変わるため基本単位（unit_id）を変更せず ErrItemUnitInUse を返します
+func UpdateItem(tenantID string, scope *model.AccessScope, id, code, name, unitID string, categoryID *string, quantity *int, unitPrice *int, status string, attributes *model.ItemAttributeChanges, version *int) (*model.Item, error) {
+	log.Printf("[Repository] UpdateItem - tenant_id: %s, id: %s", tenantID, id)
+
+	tx, err := common.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := lockItemInScope(tx, tenantID, scope, id); err != nil {
+		return nil, err
+	}
+	if err := checkItemUnitChange(tx, tenantID, id, unitID); err != nil {
+		return nil, err
+	}
+	// 変更前から残っている違反（テンプレートの変更後に未対応のもの）は、カテゴリを変更しない限りこの更新では拒否しない
+	existing, err := fetchItemTemplateViolations(tx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
 
 	var item model.Item
-	err := common.DB.QueryRow(`
+	var categoryChanged bool
+	err = tx.QueryRow(`
+		WITH previous AS (
+			SELECT category_id FROM items WHERE id = $1 AND tenant_id = $9
+		)
 		UPDATE items
-		SET code = $2, name = $3, category_id = $4, unit_id = $5, quantity = $6, unit_price = $7, status = $8, updated_at = CURRENT_TIMESTAMP
-		WHERE id = $1 AND deleted_at IS NULL
-		RETURNING id, code, name, category_id, unit_id, quantity, unit_price, status, created_at, updated_at
-	`, id, code, name, categoryID, unitID, quantity, unitPrice, status).Scan(
+		SET code = $2, name = $3, category_id = $4, unit_id = $5, quantity = $6, unit_price = $7, status = $8, updated_at = CURRENT_TIMESTAMP, version = version + 1
+		WHERE id = $1 AND tenant_id = $9 AND deleted_at IS NULL
+		  AND ($10::INTEGER IS NULL OR version = $10)
+		RETURNING id, code, name, category_id, unit_id, quantity, unit_price, status, version, created_at, updated_at,
+			category_id IS DISTINCT FROM (SELECT category_id FROM previous)
+	`, id, code, name, categoryID, unitID, quantity, unitPrice, status, tenantID, version).Scan(
 		&item.ID,
 		&item.Code,
 		&item.Name,
@@ -810,45 +1291,45 @@ func UpdateItem(id, code, name, unitID string, categoryID *string, quantity *int
 		&item.Quantity,
 		&item.UnitPrice,
 		&item.Status,
+		&item.Version,
 		&item.CreatedAt,
 		&item.UpdatedAt,
+		&categoryChanged,
 	)
 
+	if err == sql.ErrNoRows {
+		return nil, versionConflictOrNotFound("items", tenantID, id)
+	}
 	if err != nil {
 		log.Printf("[Repository] アイテム更新エラー: %v", err)
-		return nil, err
+		return nil, duplicateError(err, ErrDuplicateCode, code)
 	}
 
-	log.Printf("[Repository] アイテム更新成功: %s", item.ID)
-	return &item, nil
-}
-
-// DeleteItem はアイテムを削除します（論理削除）
-func DeleteItem(id string) error {
-	log.Printf("[Repository] DeleteItem - id: %s", id)
-
-	result, err := common.DB.Exec(`
-		UPDATE items
-		SET deleted_at = CURRENT_TIMESTAMP
-		WHERE id = $1 AND deleted_at IS NULL
-	`, id)
-
-	if err != nil {
-		log.Printf("[Repository] アイテム削除エラー: %v", err)
-		return err
+	if err := writeItemAttributes(tx, tenantID, item.ID, attributes); err != nil {
+		return nil, err
 	}
-
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		log.Printf("[Repository] RowsAffected取得エラー: %v", err)
-		return err
+	// カテゴリを変更した場合は、新しいカテゴリの既定値を未設定の属性に設定し、
+	// 変更前の違反は元のカテゴリのテンプレートに対するものなので許容せず、新しいカテゴリのテンプレートをすべて満たすことを求める
+	if categoryChanged {
+		existing = nil
+	}
+	if err := applyCategoryTemplate(tx, tenantID, item.ID, categoryChanged, existing); err != nil {
+		return nil, err
+	}
+	if err := checkItemInScope(tx, tenantID, scope, item.ID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
 	}
 
-	if rowsAffected == 0 {
-		log.Printf("[Repository] アイテムが見つかりません: %s", id)
-		return sql.ErrNoRows
+	// 属性情報を取得
+	if attributes, err := fetchItemAttributes(tenantID, item.ID); err != nil {
+		log.Printf("[Repository] 属性取得エラー (item_id: %s): %v", item.ID, err)
+	} else {
+		item.Attributes = attributes
 	}
 
-	log.Printf("[Repository] アイテム削除成功: %s", id)
-	return nil
+	log.Printf("[Repository] アイテム更新成功: %s", item.ID)
+	return &item, nil
 }
@@ -0,0 +1,19 @@
+package repository
+
+import "testing"
+
+func TestPrefixPattern(t *testing.T) {
+	for prefix, want := range map[string]string{
+		"ｷｯﾁﾝﾍﾟｰﾊﾟｰ": "キッチンペーパー%",
+		"きっちんぺーぱー":   "キッチンペーパー%",
+		"ＡＢＣ":        "abc%",
+		"100%_off":   `100\%\_off%`,
+		"１００％":       `100\%%`,
+		`C:\`:        `c:\\%`,
+		"ゝゞ":         "ヽヾ%",
+	} {
+		if got := prefixPattern(prefix); got != want {
+			t.Errorf("prefixPattern(%q) = %q, want %q", prefix, got, want)
+		}
+	}
+}
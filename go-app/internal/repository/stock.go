@@ -0,0 +1,237 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"go-hsm-app/internal/common"
+	"go-hsm-app/internal/model"
+	"log"
+	"math"
+)
+
+var (
+	// ErrAccessDenied は権限付与の範囲外のデータを操作しようとした場合のエラーです
+	ErrAccessDenied = errors.New("outside of the granted scope")
+	// ErrLocationNotFound は指定したロケーションが存在しない場合のエラーです
+	ErrLocationNotFound = errors.New("location not found")
+	// ErrInsufficientStock は在庫が不足し、数量が負になる場合のエラーです
+	ErrInsufficientStock = errors.New("insufficient stock")
+	// ErrUnlocatedStock はロケーション別在庫のないアイテムの在庫数量を計上するロケーションが指定されていない場合のエラーです
+	ErrUnlocatedStock = errors.New("unlocated_location_id is required to book the quantity without location stock")
+)
+
+// CreateStockMovement は入出庫を記録し、ロケーション別の在庫とアイテムの在庫数量を更新します。
+// 在庫の更新と履歴の追加は同一トランザクションで行います。
+// m.UnitID を指定した場合は数量をアイテムの基本単位に換算して記録し、換算できない単位は ErrIncompatibleUnit を返します。
+// アイテムの在庫数量はロケーション別在庫の合計に揃えるため、ロケーション別在庫のないアイテムの在庫数量は
+// 先に m.UnlocatedLocationID のロケーションに計上し、指定がなければ ErrUnlocatedStock を返します。
+// scope を指定した場合、対象ロケーションごとに権限付与の範囲内か確認し、範囲外であれば ErrAccessDenied を返します。
+func CreateStockMovement(tenantID string, scope *model.AccessScope, m model.StockMovement) (*model.StockHistory, error) {
+	log.Printf("[Repository] CreateStockMovement - tenant_id: %s, item_id: %s, kind: %s, qty: %v", tenantID, m.ItemID, m.Kind, m.Qty)
+
+	tx, err := common.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var unitPrice int
+	var baseUnitID string
+	err = tx.QueryRow(`
+		SELECT COALESCE(unit_price, 0), unit_id
+		FROM items
+		WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL
+		FOR UPDATE
+	`, m.ItemID, tenantID).Scan(&unitPrice, &baseUnitID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Printf("[Repository] アイテムが見つかりません: %s", m.ItemID)
+		} else {
+			log.Printf("[Repository] DBクエリエラー: %v", err)
+		}
+		return nil, err
+	}
+
+	for _, location := range []*string{m.LocationFrom, m.LocationTo} {
+		if location == nil {
+			continue
+		}
+		if err := checkStockLocation(tx, tenantID, scope, m.ItemID, *location); err != nil {
+			return nil, err
+		}
+	}
+
+	// 数量は基本単位に換算し、指定された単位・数量は履歴の補足情報に残す
+	details := map[string]interface{}{}
+	if m.UnitID != nil && *m.UnitID != baseUnitID {
+		factor, err := unitFactor(tx, tenantID, m.ItemID, baseUnitID, *m.UnitID)
+		if err != nil {
+			return nil, err
+		}
+		details["unit_id"] = *m.UnitID
+		details["qty"] = m.Qty
+		details["factor"] = factor
+		m.Qty = math.Round(m.Qty*factor*10000) / 10000
+	}
+
+	// ロケーション別在庫の合計に揃えると計上されていない在庫数量が失われるため、先に指定のロケーションに計上する
+	unlocated, err := bookUnlocatedStock(tx, tenantID, scope, m.ItemID, m.UnlocatedLocationID)
+	if err != nil {
+		return nil, err
+	}
+	if unlocated != 0 {
+		details["unlocated_qty"] = unlocated
+		details["unlocated_to"] = *m.UnlocatedLocationID
+	}
+
+	var meta *string
+	if len(details) > 0 {
+		b, err := json.Marshal(details)
+		if err != nil {
+			return nil, err
+		}
+		encoded := string(b)
+		meta = &encoded
+	}
+
+	delta := m.Qty
+	switch m.Kind {
+	case model.StockKindOut:
+		delta = -m.Qty
+	}
+
+	if m.LocationFrom != nil {
+		if err := addStock(tx, tenantID, m.ItemID, *m.LocationFrom, -m.Qty); err != nil {
+			return nil, err
+		}
+	}
+	if m.LocationTo != nil {
+		if err := addStock(tx, tenantID, m.ItemID, *m.LocationTo, delta); err != nil {
+			return nil, err
+		}
+	}
+
+	// アイテムの在庫数量はロケーション別在庫の合計に揃える
+	if _, err := tx.Exec(`
+		UPDATE items
+		SET quantity = (SELECT ROUND(COALESCE(SUM(qty), 0)) FROM stocks WHERE item_id = $1),
+			updated_at = CURRENT_TIMESTAMP, version = version + 1
+		WHERE id = $1
+	`, m.ItemID); err != nil {
+		log.Printf("[Repository] アイテム在庫数量の更新エラー: %v", err)
+		return nil, err
+	}
+
+	var history model.StockHistory
+	err = tx.QueryRow(`
+		INSERT INTO stock_history (tenant_id, item_id, qty_delta, kind, location_from, location_to, reason, meta, unit_price, total_amount, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, COALESCE($10::JSONB, '{}'::JSONB), $8, ROUND(ABS($3::NUMERIC) * $8), $9)
+		RETURNING id, item_id, qty_delta, kind, location_from, location_to, reason, meta, unit_price, total_amount, created_by, created_at
+	`, tenantID, m.ItemID, delta, m.Kind, m.LocationFrom, m.LocationTo, m.Reason, unitPrice, m.CreatedBy, meta).Scan(
+		&history.ID,
+		&history.ItemID,
+		&history.QtyDelta,
+		&history.Kind,
+		&history.LocationFrom,
+		&history.LocationTo,
+		&history.Reason,
+		&history.Meta,
+		&history.UnitPrice,
+		&history.TotalAmount,
+		&history.CreatedBy,
+		&history.CreatedAt,
+	)
+	if err != nil {
+		log.Printf("[Repository] 在庫履歴の追加エラー: %v", err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	log.Printf("[Repository] 入出庫を記録しました: %s", history.ID)
+	return &history, nil
+}
+
+// bookUnlocatedStock はトランザクション内で、ロケーション別在庫のないアイテムの在庫数量を locationID のロケーションに計上し、
+// 計上した数量を返します。計上する数量がない場合は何もせず 0 を返し、計上が必要で locationID が nil の場合は ErrUnlocatedStock を返します
+func bookUnlocatedStock(tx *sql.Tx, tenantID string, scope *model.AccessScope, itemID string, locationID *string) (float64, error) {
+	var unlocated float64
+	err := tx.QueryRow(`
+		SELECT COALESCE(i.quantity, 0)
+		FROM items i
+		WHERE i.id = $1
+		  AND NOT EXISTS (SELECT 1 FROM stocks WHERE item_id = i.id AND qty <> 0)
+	`, itemID).Scan(&unlocated)
+	if err == sql.ErrNoRows || (err == nil && unlocated == 0) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if locationID == nil {
+		log.Printf("[Repository] ロケーション別在庫のない在庫数量の計上先が指定されていません: %s (%v)", itemID, unlocated)
+		return 0, ErrUnlocatedStock
+	}
+	if err := checkStockLocation(tx, tenantID, scope, itemID, *locationID); err != nil {
+		return 0, err
+	}
+	if err := addStock(tx, tenantID, itemID, *locationID, unlocated); err != nil {
+		return 0, err
+	}
+	log.Printf("[Repository] ロケーション別在庫のない在庫数量を計上しました: %s (%v → %s)", itemID, unlocated, *locationID)
+	return unlocated, nil
+}
+
+// checkStockLocation はロケーションがテナント内に存在し、アイテムをそのロケーションで扱えるか確認します
+func checkStockLocation(tx *sql.Tx, tenantID string, scope *model.AccessScope, itemID, locationID string) error {
+	access := newAccessFilter(scope, 4)
+
+	var exists, allowed bool
+	err := tx.QueryRow(access.with()+`
+		SELECT
+			EXISTS (SELECT 1 FROM locations WHERE id = $2 AND tenant_id = $3 AND deleted_at IS NULL),
+			`+access.locationCond("i", "$2")+`
+		FROM items i
+		WHERE i.id = $1
+	`, append([]interface{}{itemID, locationID, tenantID}, access.args()...)...).Scan(&exists, &allowed)
+	if err != nil {
+		log.Printf("[Repository] ロケーション確認エラー: %v", err)
+		return err
+	}
+
+	if !exists {
+		log.Printf("[Repository] ロケーションが見つかりません: %s", locationID)
+		return ErrLocationNotFound
+	}
+	if !allowed {
+		log.Printf("[Repository] 権限付与の範囲外のロケーションです: %s (item_id: %s)", locationID, itemID)
+		return ErrAccessDenied
+	}
+	return nil
+}
+
+// addStock はロケーション別在庫に増減量を加算します。在庫が負になる場合は ErrInsufficientStock を返します。
+func addStock(tx *sql.Tx, tenantID, itemID, locationID string, delta float64) error {
+	var qty float64
+	err := tx.QueryRow(`
+		INSERT INTO stocks (tenant_id, item_id, location_id, qty)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (item_id, location_id)
+		DO UPDATE SET qty = stocks.qty + EXCLUDED.qty, updated_at = CURRENT_TIMESTAMP
+		RETURNING qty
+	`, tenantID, itemID, locationID, delta).Scan(&qty)
+	if err != nil {
+		log.Printf("[Repository] 在庫更新エラー: %v", err)
+		return err
+	}
+
+	if qty < 0 {
+		log.Printf("[Repository] 在庫が不足しています (item_id: %s, location_id: %s, qty: %v)", itemID, locationID, qty)
+		return ErrInsufficientStock
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"database/sql"
+	"go-hsm-app/internal/common"
+	"go-hsm-app/internal/model"
+	"log"
+)
+
+// FetchTenantByID は ID でテナントを取得します
+func FetchTenantByID(id string) (*model.Tenant, error) {
+	log.Printf("[Repository] FetchTenantByID - id: %s", id)
+	return fetchTenant(`id = $1`, id)
+}
+
+// FetchTenantByCode はテナントコードでテナントを取得します
+func FetchTenantByCode(code string) (*model.Tenant, error) {
+	log.Printf("[Repository] FetchTenantByCode - code: %s", code)
+	return fetchTenant(`code = $1`, code)
+}
+
+// fetchTenant は条件に一致する有効なテナントを取得します
+func fetchTenant(cond string, arg string) (*model.Tenant, error) {
+	var tenant model.Tenant
+	err := common.DB.QueryRow(`
+		SELECT id, code, name, created_at, updated_at
+		FROM tenants
+		WHERE `+cond+` AND deleted_at IS NULL
+	`, arg).Scan(
+		&tenant.ID,
+		&tenant.Code,
+		&tenant.Name,
+		&tenant.CreatedAt,
+		&tenant.UpdatedAt,
+	)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("[Repository] DBクエリエラー: %v", err)
+		}
+		return nil, err
+	}
+	return &tenant, nil
+}
+
+// CreateTenant はテナントと、その管理者ユーザー（パスワード未設定）を同一トランザクションで作成します
+func CreateTenant(code, name, adminEmail string) (*model.Tenant, *model.User, error) {
+	log.Printf("[Repository] CreateTenant - code: %s, name: %s, admin: %s", code, name, adminEmail)
+
+	tx, err := common.DB.Begin()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	var tenant model.Tenant
+	err = tx.QueryRow(`
+		INSERT INTO tenants (code, name)
+		VALUES ($1, $2)
+		RETURNING id, code, name, created_at, updated_at
+	`, code, name).Scan(
+		&tenant.ID,
+		&tenant.Code,
+		&tenant.Name,
+		&tenant.CreatedAt,
+		&tenant.UpdatedAt,
+	)
+	if err != nil {
+		log.Printf("[Repository] テナント作成エラー: %v", err)
+		return nil, nil, duplicateError(err, ErrDuplicateCode, code)
+	}
+
+	var admin model.User
+	err = tx.QueryRow(`
+		WITH new_id AS (
+			SELECT 'U' || LPAD(nextval('users_id_seq')::TEXT, 8, '0') as id
+		)
+		INSERT INTO users (id, tenant_id, email, password_hash, role)
+		SELECT id, $1, $2, '', $3 FROM new_id
+		RETURNING id, tenant_id, email, role, version, created_at, updated_at
+	`, tenant.ID, adminEmail, model.RoleAdmin).Scan(
+		&admin.ID,
+		&admin.TenantID,
+		&admin.Email,
+		&admin.Role,
+		&admin.Version,
+		&admin.CreatedAt,
+		&admin.UpdatedAt,
+	)
+	if err != nil {
+		log.Printf("[Repository] テナント管理者作成エラー: %v", err)
+		return nil, nil, duplicateError(err, ErrDuplicateEmail, adminEmail)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+
+	log.Printf("[Repository] テナント作成成功: %s (admin: %s)", tenant.ID, admin.ID)
+	return &tenant, &admin, nil
+}
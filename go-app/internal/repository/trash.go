@@ -0,0 +1,420 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"go-hsm-app/internal/common"
+	"go-hsm-app/internal/model"
+
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrCodeConflict は復元するデータのコードを有効なデータが使用している場合のエラーです
+	ErrCodeConflict = errors.New("code is used by another record")
+	// ErrRestoreDependency は復元するデータが参照するマスタが削除されている場合のエラーです
+	ErrRestoreDependency = errors.New("referenced record is deleted")
+)
+
+// CodeConflictError は復元するデータとコードが重複する有効なデータを保持するエラーです
+// errors.Is(err, ErrCodeConflict) で判定できます
+type CodeConflictError struct {
+	Code          string // 重複するコード
+	ConflictingID string // コードを使用している有効なデータのID（特定できない場合は空）
+}
+
+func (e *CodeConflictError) Error() string {
+	if e.ConflictingID == "" {
+		return fmt.Sprintf("%v: %s", ErrCodeConflict, e.Code)
+	}
+	return fmt.Sprintf("%v: %s (id: %s)", ErrCodeConflict, e.Code, e.ConflictingID)
+}
+
+func (e *CodeConflictError) Unwrap() error {
+	return ErrCodeConflict
+}
+
+// FetchTrash はゴミ箱のデータ（論理削除したアイテム・マスタ）を削除日時の新しい順に取得し、全件数とともに返します
+// resource を指定した場合はその種別のみ対象とし、scope を指定した場合は権限付与の範囲内のアイテムのみ対象とします
+func FetchTrash(tenantID string, scope *model.AccessScope, resource string, limit, offset int) ([]model.TrashEntry, int, error) {
+	log.Printf("[Repository] FetchTrash - tenant_id: %s, resource: %s, limit: %d, offset: %d", tenantID, resource, limit, offset)
+
+	access := newAccessFilter(scope, 5)
+	args := append([]interface{}{tenantID, resource, limit, offset}, access.args()...)
+
+	rows, err := common.DB.Query(access.with()+`
+		SELECT resource, id, code, name, deleted_at, COUNT(*) OVER ()
+		FROM (
+			SELECT 'items' AS resource, i.id, i.code, i.name, i.deleted_at
+			FROM items i
+			WHERE i.tenant_id = $1 AND i.deleted_at IS NOT NULL AND `+access.itemCond("i")+`
+			UNION ALL
+			SELECT 'categories', id, code, name, deleted_at
+			FROM categories WHERE tenant_id = $1 AND deleted_at IS NOT NULL
+			UNION ALL
+			SELECT 'units', id, code, name, deleted_at
+			FROM units WHERE tenant_id = $1 AND deleted_at IS NOT NULL
+			UNION ALL
+			SELECT 'attributes', id, code, name, deleted_at
+			FROM attributes WHERE tenant_id = $1 AND deleted_at IS NOT NULL
+		) t
+		WHERE $2 = '' OR resource = $2
+		ORDER BY deleted_at DESC, resource, code
+		LIMIT $3 OFFSET $4
+	`, args...)
+	if err != nil {
+		log.Printf("[Repository] DB クエリエラー: %v", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	entries := []model.TrashEntry{}
+	total := 0
+	for rows.Next() {
+		var entry model.TrashEntry
+		if err := rows.Scan(
+			&entry.Resource,
+			&entry.ID,
+			&entry.Code,
+			&entry.Name,
+			&entry.DeletedAt,
+			&total,
+		); err != nil {
+			log.Printf("[Repository] スキャンエラー: %v", err)
+			return nil, 0, err
+		}
+		entries = append(entries, entry)
+	}
+
+	log.Printf("[Repository] 取得成功: %d件のゴミ箱のデータ（全%d件）", len(entries), total)
+	return entries, total, rows.Err()
+}
+
+// restoreTarget は論理削除したデータの復元方法を表します
+type restoreTarget struct {
+	table string                                      // テーブル名
+	check func(tx *sql.Tx, tenantID, id string) error // 復元前の追加の確認（任意）
+}
+
+// restoreRecord は論理削除したデータを 1 つのトランザクションで復元し、監査ログ（action: restore）に記録します
+// 削除済みのデータがない場合は sql.ErrNoRows、コードを有効なデータが使用している場合は CodeConflictError を返します
+func restoreRecord(tenantID, id string, target restoreTarget, lock func(tx *sql.Tx) error, restoredBy *string) error {
+	tx, err := common.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if lock != nil {
+		if err := lock(tx); err != nil {
+			log.Printf("[Repository] ロック取得エラー: %v", err)
+			return err
+		}
+	}
+
+	var code string
+	var deletedAt time.Time
+	err = tx.QueryRow(`
+		SELECT code, deleted_at FROM `+target.table+`
+		WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NOT NULL
+		FOR UPDATE
+	`, id, tenantID).Scan(&code, &deletedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Printf("[Repository] 削除済みのデータが見つかりません: %s (%s)", id, target.table)
+		}
+		return err
+	}
+
+	var conflictingID string
+	err = tx.QueryRow(`
+		SELECT id FROM `+target.table+`
+		WHERE tenant_id = $1 AND code = $2 AND id <> $3 AND deleted_at IS NULL
+		LIMIT 1
+	`, tenantID, code, id).Scan(&conflictingID)
+	if err == nil {
+		log.Printf("[Repository] コードが重複するため復元できません: %s (code: %s, id: %s)", id, code, conflictingID)
+		return &CodeConflictError{Code: code, ConflictingID: conflictingID}
+	}
+	if err != sql.ErrNoRows {
+		return err
+	}
+
+	if target.check != nil {
+		if err := target.check(tx, tenantID, id); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE `+target.table+`
+		SET deleted_at = NULL, updated_at = CURRENT_TIMESTAMP, version = version + 1
+		WHERE id = $1
+	`, id); err != nil {
+		if isUniqueViolation(err) {
+			return &CodeConflictError{Code: code}
+		}
+		log.Printf("[Repository] 復元エラー (%s): %v", target.table, err)
+		return err
+	}
+
+	diff, err := json.Marshal(map[string]interface{}{
+		"tenant_id":  tenantID,
+		"code":       code,
+		"deleted_at": deletedAt,
+	})
+	if err != nil {
+		return err
+	}
+	if err := createAuditLog(tx, model.AuditLog{
+		UserID:     restoredBy,
+		Action:     "restore",
+		Resource:   target.table,
+		ResourceID: &id,
+		Diff:       diff,
+	}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	log.Printf("[Repository] 復元成功: %s (%s)", id, target.table)
+	return nil
+}
+
+// RestoreItem は論理削除したアイテムを復元します
+// 単位・カテゴリが削除されている場合は ErrRestoreDependency を返します。
+// scope を指定した場合、権限付与の範囲外のアイテムは sql.ErrNoRows を返します
+func RestoreItem(tenantID string, scope *model.AccessScope, id string, restoredBy *string) error {
+	log.Printf("[Repository] RestoreItem - tenant_id: %s, id: %s", tenantID, id)
+
+	return restoreRecord(tenantID, id, restoreTarget{
+		table: "items",
+		check: func(tx *sql.Tx, tenantID, id string) error {
+			access := newAccessFilter(scope, 3)
+			var allowed, unitDeleted, categoryDeleted bool
+			var unitID string
+			var categoryID sql.NullString
+			err := tx.QueryRow(access.with()+`
+				SELECT `+access.itemCond("i")+`, i.unit_id, u.deleted_at IS NOT NULL,
+					i.category_id, COALESCE(c.deleted_at IS NOT NULL, FALSE)
+				FROM items i
+				INNER JOIN units u ON i.unit_id = u.id
+				LEFT JOIN categories c ON i.category_id = c.id
+				WHERE i.id = $1 AND i.tenant_id = $2
+			`, append([]interface{}{id, tenantID}, access.args()...)...).Scan(
+				&allowed, &unitID, &unitDeleted, &categoryID, &categoryDeleted,
+			)
+			if err != nil {
+				return err
+			}
+			switch {
+			case !allowed:
+				log.Printf("[Repository] 権限付与の範囲外のアイテムです: %s", id)
+				return sql.ErrNoRows
+			case unitDeleted:
+				return fmt.Errorf("%w: unit %s must be restored first", ErrRestoreDependency, unitID)
+			case categoryDeleted:
+				return fmt.Errorf("%w: category %s must be restored first", ErrRestoreDependency, categoryID.String)
+			}
+			return nil
+		},
+	}, nil, restoredBy)
+}
+
+// RestoreCategory は論理削除したカテゴリを復元します
+// 親カテゴリが削除されている場合は ErrRestoreDependency を返します
+func RestoreCategory(tenantID, id string, restoredBy *string) error {
+	log.Printf("[Repository] RestoreCategory - tenant_id: %s, id: %s", tenantID, id)
+
+	return restoreRecord(tenantID, id, restoreTarget{
+		table: "categories",
+		check: func(tx *sql.Tx, tenantID, id string) error {
+			var parentID sql.NullString
+			var parentDeleted bool
+			if err := tx.QueryRow(`
+				SELECT c.parent_id, COALESCE(p.deleted_at IS NOT NULL, FALSE)
+				FROM categories c
+				LEFT JOIN categories p ON c.parent_id = p.id
+				WHERE c.id = $1
+			`, id).Scan(&parentID, &parentDeleted); err != nil {
+				return err
+			}
+			if parentDeleted {
+				return fmt.Errorf("%w: parent category %s must be restored first", ErrRestoreDependency, parentID.String)
+			}
+			return nil
+		},
+	}, func(tx *sql.Tx) error {
+		return lockCategoryTree(tx, tenantID)
+	}, restoredBy)
+}
+
+// RestoreUnit は論理削除した単位を復元します
+func RestoreUnit(tenantID, id string, restoredBy *string) error {
+	log.Printf("[Repository] RestoreUnit - tenant_id: %s, id: %s", tenantID, id)
+	return restoreRecord(tenantID, id, restoreTarget{table: "units"}, nil, restoredBy)
+}
+
+// RestoreAttribute は論理削除した属性を復元します
+func RestoreAttribute(tenantID, id string, restoredBy *string) error {
+	log.Printf("[Repository] RestoreAttribute - tenant_id: %s, id: %s", tenantID, id)
+	return restoreRecord(tenantID, id, restoreTarget{table: "attributes"}, nil, restoredBy)
+}
+
+// PurgeTrash は before より前に論理削除したデータを物理削除し、監査ログに記録します
+// 入出庫履歴のあるアイテムと、他のデータ（削除済みを含む）から参照されているマスタは、
+// 履歴・参照の整合性を保つため削除せず、件数を Skipped に返します
+func PurgeTrash(tenantID string, before time.Time, purgedBy *string) (*model.TrashPurge, error) {
+	log.Printf("[Repository] PurgeTrash - tenant_id: %s, before: %s", tenantID, before.Format(time.RFC3339))
+
+	tx, err := common.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result := &model.TrashPurge{
+		Before:  before,
+		Purged:  map[string][]string{},
+		Skipped: map[string]int{},
+	}
+
+	// アイテム: 在庫の残っていないロケーション別在庫とともに削除する（属性値・荷姿は連鎖して削除される）
+	itemIDs, err := purgeIDs(tx, `
+		SELECT i.id FROM items i
+		WHERE i.tenant_id = $1 AND i.deleted_at < $2
+		  AND NOT EXISTS (SELECT 1 FROM stock_history h WHERE h.item_id = i.id)
+		  AND NOT EXISTS (SELECT 1 FROM stocks s WHERE s.item_id = i.id AND s.qty <> 0)
+		FOR UPDATE
+	`, tenantID, before)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(`DELETE FROM stocks WHERE item_id = ANY($1)`, pq.Array(itemIDs)); err != nil {
+		log.Printf("[Repository] 在庫の物理削除エラー: %v", err)
+		return nil, err
+	}
+	if _, err := tx.Exec(`DELETE FROM items WHERE id = ANY($1)`, pq.Array(itemIDs)); err != nil {
+		log.Printf("[Repository] アイテムの物理削除エラー: %v", err)
+		return nil, err
+	}
+	result.Purged[model.TrashResourceItems] = itemIDs
+
+	// 属性: 属性値が残っていないもの（選択肢・テンプレートは連鎖して削除される）
+	if result.Purged[model.TrashResourceAttributes], err = purgeIDs(tx, `
+		DELETE FROM attributes a
+		WHERE a.tenant_id = $1 AND a.deleted_at < $2
+		  AND NOT EXISTS (SELECT 1 FROM item_attributes ia WHERE ia.attribute_id = a.id)
+		RETURNING a.id
+	`, tenantID, before); err != nil {
+		return nil, err
+	}
+
+	// 単位: アイテム・荷姿から参照されていないもの（単位換算は連鎖して削除される）
+	if result.Purged[model.TrashResourceUnits], err = purgeIDs(tx, `
+		DELETE FROM units u
+		WHERE u.tenant_id = $1 AND u.deleted_at < $2
+		  AND NOT EXISTS (SELECT 1 FROM items i WHERE i.unit_id = u.id)
+		  AND NOT EXISTS (SELECT 1 FROM item_packagings p WHERE p.unit_id = u.id OR p.contains_unit_id = u.id)
+		RETURNING u.id
+	`, tenantID, before); err != nil {
+		return nil, err
+	}
+
+	// カテゴリ: アイテム・子カテゴリから参照されていないもの。子から順に削除できるよう、削除できなくなるまで繰り返す
+	categoryIDs := []string{}
+	for {
+		ids, err := purgeIDs(tx, `
+			DELETE FROM categories c
+			WHERE c.tenant_id = $1 AND c.deleted_at < $2
+			  AND NOT EXISTS (SELECT 1 FROM items i WHERE i.category_id = c.id)
+			  AND NOT EXISTS (SELECT 1 FROM categories ch WHERE ch.parent_id = c.id)
+			RETURNING c.id
+		`, tenantID, before)
+		if err != nil {
+			return nil, err
+		}
+		if len(ids) == 0 {
+			break
+		}
+		categoryIDs = append(categoryIDs, ids...)
+	}
+	if _, err := tx.Exec(`
+		DELETE FROM user_grants
+		WHERE tenant_id = $1 AND scope_type = $2 AND scope_id = ANY($3)
+	`, tenantID, model.GrantScopeCategory, pq.Array(categoryIDs)); err != nil {
+		log.Printf("[Repository] 権限付与の削除エラー: %v", err)
+		return nil, err
+	}
+	result.Purged[model.TrashResourceCategories] = categoryIDs
+
+	for _, resource := range []string{
+		model.TrashResourceItems,
+		model.TrashResourceCategories,
+		model.TrashResourceUnits,
+		model.TrashResourceAttributes,
+	} {
+		var skipped int
+		if err := tx.QueryRow(`
+			SELECT COUNT(*) FROM `+resource+`
+			WHERE tenant_id = $1 AND deleted_at < $2
+		`, tenantID, before).Scan(&skipped); err != nil {
+			return nil, err
+		}
+		result.Skipped[resource] = skipped
+	}
+
+	diff, err := json.Marshal(map[string]interface{}{
+		"tenant_id": tenantID,
+		"before":    before,
+		"purged":    result.Purged,
+		"skipped":   result.Skipped,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := createAuditLog(tx, model.AuditLog{
+		UserID:   purgedBy,
+		Action:   "purge",
+		Resource: "trash",
+		Diff:     diff,
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	log.Printf("[Repository] 物理削除成功: items %d, categories %d, units %d, attributes %d",
+		len(itemIDs), len(categoryIDs), len(result.Purged[model.TrashResourceUnits]), len(result.Purged[model.TrashResourceAttributes]))
+	return result, nil
+}
+
+// purgeIDs はトランザクション内でクエリを実行し、返された ID の一覧を返します
+func purgeIDs(tx *sql.Tx, query string, args ...interface{}) ([]string, error) {
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		log.Printf("[Repository] 物理削除エラー: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrDuplicateCode はコードが有効なデータと重複する場合のエラーです
+	ErrDuplicateCode = errors.New("code is already in use")
+	// ErrDuplicateEmail はメールアドレスが他のユーザーと重複する場合のエラーです
+	ErrDuplicateEmail = errors.New("email is already in use")
+)
+
+// uniqueViolationCode は PostgreSQL の一意制約違反の SQLSTATE です
+const uniqueViolationCode = "23505"
+
+// isUniqueViolation は err が一意制約違反かどうかを判定します
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == uniqueViolationCode
+}
+
+// duplicateError は一意制約違反を sentinel（ErrDuplicateCode など）でラップしたエラーに変換します
+// 一意制約違反でない場合は err をそのまま返します
+func duplicateError(err, sentinel error, value string) error {
+	if isUniqueViolation(err) {
+		return fmt.Errorf("%w: %s", sentinel, value)
+	}
+	return err
+}
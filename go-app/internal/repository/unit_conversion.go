@@ -0,0 +1,279 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+
+	"go-hsm-app/internal/common"
+	"go-hsm-app/internal/model"
+)
+
+var (
+	// ErrUnitNotFound は指定した単位が存在しない場合のエラーです
+	ErrUnitNotFound = errors.New("unit not found")
+	// ErrIncompatibleUnit は指定した単位をアイテムの基本単位に換算できない場合のエラーです
+	ErrIncompatibleUnit = errors.New("unit cannot be converted to the item's base unit")
+	// ErrItemUnitInUse は在庫や入出庫履歴があるアイテムの基本単位を変更しようとした場合のエラーです
+	ErrItemUnitInUse = errors.New("unit cannot be changed while the item has stock or stock history")
+)
+
+// FetchUnitConversions は単位を換算元または換算先とする単位換算を取得します
+func FetchUnitConversions(tenantID, unitID string) ([]model.UnitConversion, error) {
+	log.Printf("[Repository] FetchUnitConversions - tenant_id: %s, unit_id: %s", tenantID, unitID)
+
+	rows, err := common.DB.Query(`
+		SELECT uc.from_unit_id, fu.code, uc.to_unit_id, tu.code, uc.factor, uc.created_at, uc.updated_at
+		FROM unit_conversions uc
+		INNER JOIN units fu ON uc.from_unit_id = fu.id AND fu.deleted_at IS NULL
+		INNER JOIN units tu ON uc.to_unit_id = tu.id AND tu.deleted_at IS NULL
+		WHERE uc.tenant_id = $1 AND (uc.from_unit_id = $2 OR uc.to_unit_id = $2)
+		ORDER BY fu.code, tu.code
+	`, tenantID, unitID)
+	if err != nil {
+		log.Printf("[Repository] DB クエリエラー: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	conversions := []model.UnitConversion{}
+	for rows.Next() {
+		var conversion model.UnitConversion
+		if err := rows.Scan(
+			&conversion.FromUnitID,
+			&conversion.FromUnitCode,
+			&conversion.ToUnitID,
+			&conversion.ToUnitCode,
+			&conversion.Factor,
+			&conversion.CreatedAt,
+			&conversion.UpdatedAt,
+		); err != nil {
+			log.Printf("[Repository] スキャンエラー: %v", err)
+			return nil, err
+		}
+		conversions = append(conversions, conversion)
+	}
+
+	log.Printf("[Repository] 取得成功: %d件の単位換算", len(conversions))
+	return conversions, rows.Err()
+}
+
+// SetUnitConversion は単位換算（1 fromUnitID = factor toUnitID）を登録・更新します
+// 逆方向の換算が登録済みの場合は置き換えます。いずれかの単位が存在しない場合は ErrUnitNotFound を返します
+func SetUnitConversion(tenantID, fromUnitID, toUnitID string, factor float64) (*model.UnitConversion, error) {
+	log.Printf("[Repository] SetUnitConversion - tenant_id: %s, from: %s, to: %s, factor: %v", tenantID, fromUnitID, toUnitID, factor)
+
+	tx, err := common.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var conversion model.UnitConversion
+	err = tx.QueryRow(`
+		SELECT fu.id, fu.code, tu.id, tu.code
+		FROM units fu, units tu
+		WHERE fu.id = $1 AND fu.tenant_id = $3 AND fu.deleted_at IS NULL
+		  AND tu.id = $2 AND tu.tenant_id = $3 AND tu.deleted_at IS NULL
+		FOR SHARE
+	`, fromUnitID, toUnitID, tenantID).Scan(
+		&conversion.FromUnitID,
+		&conversion.FromUnitCode,
+		&conversion.ToUnitID,
+		&conversion.ToUnitCode,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Printf("[Repository] 単位が見つかりません: %s, %s", fromUnitID, toUnitID)
+			return nil, ErrUnitNotFound
+		}
+		return nil, err
+	}
+
+	if _, err := tx.Exec(`
+		DELETE FROM unit_conversions WHERE from_unit_id = $1 AND to_unit_id = $2
+	`, toUnitID, fromUnitID); err != nil {
+		log.Printf("[Repository] 逆方向の単位換算の削除エラー: %v", err)
+		return nil, err
+	}
+
+	err = tx.QueryRow(`
+		INSERT INTO unit_conversions (tenant_id, from_unit_id, to_unit_id, factor)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (from_unit_id, to_unit_id)
+		DO UPDATE SET factor = EXCLUDED.factor, updated_at = CURRENT_TIMESTAMP
+		RETURNING factor, created_at, updated_at
+	`, tenantID, fromUnitID, toUnitID, factor).Scan(
+		&conversion.Factor,
+		&conversion.CreatedAt,
+		&conversion.UpdatedAt,
+	)
+	if err != nil {
+		log.Printf("[Repository] 単位換算の登録エラー: %v", err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	log.Printf("[Repository] 単位換算の登録成功: %s -> %s", fromUnitID, toUnitID)
+	return &conversion, nil
+}
+
+// DeleteUnitConversion は 2 つの単位の間の単位換算を削除します（登録の向きは問いません）
+func DeleteUnitConversion(tenantID, unitID, otherUnitID string) error {
+	log.Printf("[Repository] DeleteUnitConversion - tenant_id: %s, unit_id: %s, other: %s", tenantID, unitID, otherUnitID)
+
+	result, err := common.DB.Exec(`
+		DELETE FROM unit_conversions
+		WHERE tenant_id = $1
+		  AND ((from_unit_id = $2 AND to_unit_id = $3) OR (from_unit_id = $3 AND to_unit_id = $2))
+	`, tenantID, unitID, otherUnitID)
+	if err != nil {
+		log.Printf("[Repository] 単位換算の削除エラー: %v", err)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("[Repository] RowsAffected取得エラー: %v", err)
+		return err
+	}
+	if rowsAffected == 0 {
+		log.Printf("[Repository] 単位換算が見つかりません: %s, %s", unitID, otherUnitID)
+		return sql.ErrNoRows
+	}
+
+	log.Printf("[Repository] 単位換算の削除成功: %s, %s", unitID, otherUnitID)
+	return nil
+}
+
+// checkItemUnitChange はトランザクション内でアイテムの行をロックし、基本単位を unitID に変更できるか確認します
+// 在庫・入出庫履歴はすべて基本単位で記録されているため、いずれかがある場合は ErrItemUnitInUse を返します
+func checkItemUnitChange(tx *sql.Tx, tenantID, itemID, unitID string) error {
+	var currentUnitID string
+	var inUse bool
+	err := tx.QueryRow(`
+		SELECT i.unit_id,
+			i.quantity <> 0
+			OR EXISTS (SELECT 1 FROM stocks WHERE item_id = i.id AND qty <> 0)
+			OR EXISTS (SELECT 1 FROM stock_history WHERE item_id = i.id)
+		FROM items i
+		WHERE i.id = $1 AND i.tenant_id = $2 AND i.deleted_at IS NULL
+		FOR UPDATE
+	`, itemID, tenantID).Scan(&currentUnitID, &inUse)
+	if err == sql.ErrNoRows {
+		// 存在しない・削除済みのアイテムは更新時に判定する
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if unitID != currentUnitID && inUse {
+		log.Printf("[Repository] 在庫・入出庫履歴があるため単位を変更できません: %s (%s → %s)", itemID, currentUnitID, unitID)
+		return ErrItemUnitInUse
+	}
+	return nil
+}
+
+// maxUnitConversionDepth は換算でたどる荷姿・単位換算の最大段数です
+const maxUnitConversionDepth = 5
+
+// unitEdge は換算の 1 段（from の数量 1 = to の数量 factor）を表します
+type unitEdge struct {
+	to     string
+	factor float64
+}
+
+// unitFactor はトランザクション内で、unitID の数量 1 をアイテムの基本単位 baseUnitID に換算する係数を返します
+// アイテムの荷姿（入れ子の荷姿を含む）を優先し、次に単位換算（逆方向は係数の逆数）を用いて、
+// 基本単位まで最大 maxUnitConversionDepth 段をたどり係数を掛け合わせます。換算できない場合は ErrIncompatibleUnit を返します
+func unitFactor(tx *sql.Tx, tenantID, itemID, baseUnitID, unitID string) (float64, error) {
+	if unitID == baseUnitID {
+		return 1, nil
+	}
+
+	var unitCode, baseCode sql.NullString
+	if err := tx.QueryRow(`
+		SELECT
+			(SELECT code FROM units WHERE id = $1 AND tenant_id = $3 AND deleted_at IS NULL),
+			(SELECT code FROM units WHERE id = $2 AND tenant_id = $3)
+	`, unitID, baseUnitID, tenantID).Scan(&unitCode, &baseCode); err != nil {
+		log.Printf("[Repository] 単位の取得エラー: %v", err)
+		return 0, err
+	}
+	if !unitCode.Valid {
+		log.Printf("[Repository] 単位が見つかりません: %s", unitID)
+		return 0, fmt.Errorf("%w: %s", ErrUnitNotFound, unitID)
+	}
+
+	edges, err := fetchUnitEdges(tx, tenantID, itemID, baseUnitID)
+	if err != nil {
+		return 0, err
+	}
+
+	// 段数の少ない経路を優先する幅優先探索（同じ段数では荷姿を優先）
+	factors := map[string]float64{unitID: 1}
+	frontier := []string{unitID}
+	for depth := 0; depth < maxUnitConversionDepth && len(frontier) > 0; depth++ {
+		var next []string
+		for _, from := range frontier {
+			for _, edge := range edges[from] {
+				if _, visited := factors[edge.to]; visited {
+					continue
+				}
+				factors[edge.to] = factors[from] * edge.factor
+				if edge.to == baseUnitID {
+					return factors[edge.to], nil
+				}
+				next = append(next, edge.to)
+			}
+		}
+		frontier = next
+	}
+
+	log.Printf("[Repository] 換算できない単位です: %s -> %s (item_id: %s)", unitCode.String, baseCode.String, itemID)
+	return 0, fmt.Errorf("%w: %s to %s", ErrIncompatibleUnit, unitCode.String, baseCode.String)
+}
+
+// fetchUnitEdges はトランザクション内で、アイテムの荷姿とテナントの単位換算を換算の辺として取得します
+// 荷姿は内容物の単位（未指定の場合は基本単位）への辺、単位換算は両方向の辺（逆方向は係数の逆数）になり、
+// 各単位の辺は荷姿が先に並びます
+func fetchUnitEdges(tx *sql.Tx, tenantID, itemID, baseUnitID string) (map[string][]unitEdge, error) {
+	rows, err := tx.Query(`
+		SELECT from_unit_id, to_unit_id, factor
+		FROM (
+			SELECT 1 AS priority, p.unit_id AS from_unit_id, COALESCE(p.contains_unit_id, $3) AS to_unit_id, p.factor
+			FROM item_packagings p
+			WHERE p.tenant_id = $1 AND p.item_id = $2
+			UNION ALL
+			SELECT 2, uc.from_unit_id, uc.to_unit_id, uc.factor
+			FROM unit_conversions uc
+			WHERE uc.tenant_id = $1
+			UNION ALL
+			SELECT 2, uc.to_unit_id, uc.from_unit_id, 1 / uc.factor
+			FROM unit_conversions uc
+			WHERE uc.tenant_id = $1
+		) e
+		ORDER BY priority
+	`, tenantID, itemID, baseUnitID)
+	if err != nil {
+		log.Printf("[Repository] 換算係数の取得エラー: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	edges := map[string][]unitEdge{}
+	for rows.Next() {
+		var from string
+		var edge unitEdge
+		if err := rows.Scan(&from, &edge.to, &edge.factor); err != nil {
+			log.Printf("[Repository] スキャンエラー: %v", err)
+			return nil, err
+		}
+		edges[from] = append(edges[from], edge)
+	}
+	return edges, rows.Err()
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"go-hsm-app/internal/common"
+	"go-hsm-app/internal/model"
+	"log"
+	"time"
+)
+
+// ErrUserTokenInvalid は使用済み・失効済み・期限切れのユーザートークンが提示された場合のエラーです
+var ErrUserTokenInvalid = errors.New("user token is used, revoked or expired")
+
+// CreateUserToken は招待・パスワード再設定用のトークンを保存します。
+// 同じユーザー・用途の未使用トークンは失効させます。
+func CreateUserToken(userID, purpose, tokenHash string, expiresAt time.Time, createdBy *string) error {
+	log.Printf("[Repository] CreateUserToken - user_id: %s, purpose: %s", userID, purpose)
+
+	tx, err := common.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		UPDATE user_tokens
+		SET revoked_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL AND revoked_at IS NULL
+	`, userID, purpose); err != nil {
+		log.Printf("[Repository] 旧トークン失効エラー: %v", err)
+		return err
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO user_tokens (user_id, purpose, token_hash, expires_at, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+	`, userID, purpose, tokenHash, expiresAt, createdBy); err != nil {
+		log.Printf("[Repository] ユーザートークン保存エラー: %v", err)
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ConsumeUserToken はトークンを使用済みにし、ユーザーのパスワードを設定します。
+// パスワード変更に伴い、そのユーザーの既存セッションは全て失効させます。
+func ConsumeUserToken(purpose, tokenHash, passwordHash string) (*model.User, error) {
+	log.Printf("[Repository] ConsumeUserToken - purpose: %s", purpose)
+
+	tx, err := common.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var tokenID string
+	var expiresAt time.Time
+	var usedAt, revokedAt *time.Time
+	var user model.User
+	err = tx.QueryRow(`
+		SELECT t.id, t.expires_at, t.used_at, t.revoked_at,
+			u.id, u.tenant_id, u.email, u.role, u.version, u.created_at, u.updated_at
+		FROM user_tokens t
+		INNER JOIN users u ON t.user_id = u.id AND u.deleted_at IS NULL
+		WHERE t.token_hash = $1 AND t.purpose = $2
+		FOR UPDATE OF t
+	`, tokenHash, purpose).Scan(
+		&tokenID,
+		&expiresAt,
+		&usedAt,
+		&revokedAt,
+		&user.ID,
+		&user.TenantID,
+		&user.Email,
+		&user.Role,
+		&user.Version,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Printf("[Repository] ユーザートークンが見つかりません")
+			return nil, sql.ErrNoRows
+		}
+		log.Printf("[Repository] DBクエリエラー: %v", err)
+		return nil, err
+	}
+
+	if usedAt != nil || revokedAt != nil || time.Now().After(expiresAt) {
+		log.Printf("[Repository] 無効なユーザートークン: %s", tokenID)
+		return nil, ErrUserTokenInvalid
+	}
+
+	if err := tx.QueryRow(`
+		UPDATE users
+		SET password_hash = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		RETURNING updated_at
+	`, user.ID, passwordHash).Scan(&user.UpdatedAt); err != nil {
+		log.Printf("[Repository] パスワード更新エラー: %v", err)
+		return nil, err
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE user_tokens SET used_at = CURRENT_TIMESTAMP WHERE id = $1
+	`, tokenID); err != nil {
+		log.Printf("[Repository] ユーザートークン更新エラー: %v", err)
+		return nil, err
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE refresh_tokens
+		SET revoked_at = CURRENT_TIMESTAMP, revoked_reason = $2
+		WHERE user_id = $1 AND revoked_at IS NULL
+	`, user.ID, RevokeReasonPasswordChanged); err != nil {
+		log.Printf("[Repository] セッション失効エラー: %v", err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	log.Printf("[Repository] ユーザートークン使用成功: %s (user_id: %s)", tokenID, user.ID)
+	return &user, nil
+}
+
+// UserHasPassword はユーザーにパスワードが設定済みか確認します
+func UserHasPassword(userID string) (bool, error) {
+	var hasPassword bool
+	err := common.DB.QueryRow(`
+		SELECT password_hash <> '' AND password_hash <> 'temp_hash'
+		FROM users
+		WHERE id = $1 AND deleted_at IS NULL
+	`, userID).Scan(&hasPassword)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("[Repository] DBクエリエラー: %v", err)
+		}
+		return false, err
+	}
+	return hasPassword, nil
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"go-hsm-app/internal/auth"
+	"go-hsm-app/internal/model"
+	"go-hsm-app/internal/repository"
+)
+
+var (
+	// ErrInvalidAPIKeyRoles は API キーのロール指定が不正な場合のエラーです
+	ErrInvalidAPIKeyRoles = errors.New("roles must be a non-empty subset of admin, operator, viewer")
+	// ErrAPIKeyRoleExceedsUser はユーザーの権限を超えるロールを API キーに付与しようとした場合のエラーです
+	ErrAPIKeyRoleExceedsUser = errors.New("api key roles must not exceed the user's role")
+	// ErrAPIKeyExpiryInPast は有効期限に過去の日時が指定された場合のエラーです
+	ErrAPIKeyExpiryInPast = errors.New("expires_at must be in the future")
+)
+
+// CreatedAPIKey は作成した API キーと、一度だけ返却するキー本体です
+type CreatedAPIKey struct {
+	model.APIKey
+	Key string `json:"key"` // API キー本体（作成時のみ返却）
+}
+
+// CreateAPIKey はユーザーの API キーを作成します。
+// ロールはユーザー自身の権限以下に限定されます。
+func CreateAPIKey(tenantID, userID, name string, roles []string, expiresAt *time.Time) (*CreatedAPIKey, error) {
+	user, err := repository.FetchTenantUser(tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err = normalizeAPIKeyRoles(roles)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range roles {
+		if !auth.HasRole(user.Role, r) {
+			return nil, ErrAPIKeyRoleExceedsUser
+		}
+	}
+
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return nil, ErrAPIKeyExpiryInPast
+	}
+
+	key, prefix, keyHash, err := auth.GenerateAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	apiKey, err := repository.CreateAPIKey(user.ID, strings.TrimSpace(name), prefix, keyHash, roles, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	return &CreatedAPIKey{APIKey: *apiKey, Key: key}, nil
+}
+
+// GetAPIKeys はユーザーの API キー一覧を取得します
+func GetAPIKeys(tenantID, userID string) ([]model.APIKey, error) {
+	if _, err := repository.FetchTenantUser(tenantID, userID); err != nil {
+		return nil, err
+	}
+	return repository.FetchAPIKeysByUser(userID)
+}
+
+// RevokeAPIKey はユーザーの API キーを失効させます
+func RevokeAPIKey(tenantID, userID, keyID string) error {
+	if _, err := repository.FetchTenantUser(tenantID, userID); err != nil {
+		return err
+	}
+	return repository.RevokeAPIKey(userID, keyID)
+}
+
+// normalizeAPIKeyRoles はロール指定を検証し、重複を除いて返します
+func normalizeAPIKeyRoles(roles []string) ([]string, error) {
+	if len(roles) == 0 {
+		return nil, ErrInvalidAPIKeyRoles
+	}
+
+	seen := map[string]bool{}
+	normalized := make([]string, 0, len(roles))
+	for _, r := range roles {
+		r = strings.ToLower(strings.TrimSpace(r))
+		switch r {
+		case model.RoleAdmin, model.RoleOperator, model.RoleViewer:
+		default:
+			return nil, ErrInvalidAPIKeyRoles
+		}
+		if !seen[r] {
+			seen[r] = true
+			normalized = append(normalized, r)
+		}
+	}
+	return normalized, nil
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"go-hsm-app/internal/model"
+	"go-hsm-app/internal/repository"
+)
+
+var (
+	// ErrAttributeNotEnum は列挙型でない属性の選択肢を操作しようとした場合のエラーです
+	ErrAttributeNotEnum = errors.New("attribute value_type is not enum")
+	// ErrInvalidAttributeOption は選択肢の入力内容が不正な場合のエラーです
+	ErrInvalidAttributeOption = errors.New("invalid attribute option")
+)
+
+// GetAttributeOptions は属性の選択肢を表示順に返します
+func GetAttributeOptions(tenantID, attributeID string) ([]model.AttributeOption, error) {
+	if _, err := repository.FetchAttributeByID(tenantID, attributeID); err != nil {
+		return nil, err
+	}
+	return repository.FetchAttributeOptions(tenantID, attributeID)
+}
+
+// CreateAttributeOption は列挙型の属性に選択肢を追加します
+// sortOrder を指定しない場合は末尾に追加します
+func CreateAttributeOption(tenantID, attributeID, code, label string, sortOrder *int) (*model.AttributeOption, error) {
+	if err := requireEnumAttribute(tenantID, attributeID); err != nil {
+		return nil, err
+	}
+	code, label = strings.TrimSpace(code), strings.TrimSpace(label)
+	if code == "" || label == "" {
+		return nil, fmt.Errorf("%w: code and label are required", ErrInvalidAttributeOption)
+	}
+	return repository.CreateAttributeOption(tenantID, attributeID, code, label, sortOrder)
+}
+
+// UpdateAttributeOption は選択肢の表示名・表示順を更新します
+// 属性値には選択肢のコードを保存しているため、表示名を変更してもアイテムのデータは変わりません
+func UpdateAttributeOption(tenantID, attributeID, code, label string, sortOrder *int) (*model.AttributeOption, error) {
+	if err := requireEnumAttribute(tenantID, attributeID); err != nil {
+		return nil, err
+	}
+	if label = strings.TrimSpace(label); label == "" {
+		return nil, fmt.Errorf("%w: label is required", ErrInvalidAttributeOption)
+	}
+	return repository.UpdateAttributeOption(tenantID, attributeID, code, label, sortOrder)
+}
+
+// DeleteAttributeOption は選択肢を削除します
+// アイテムで使用中の場合は repository.ErrAttributeOptionInUse を返します
+func DeleteAttributeOption(tenantID, attributeID, code string) error {
+	if err := requireEnumAttribute(tenantID, attributeID); err != nil {
+		return err
+	}
+	return repository.DeleteAttributeOption(tenantID, attributeID, code)
+}
+
+// requireEnumAttribute は属性が存在し、列挙型であることを確認します
+func requireEnumAttribute(tenantID, attributeID string) error {
+	attribute, err := repository.FetchAttributeByID(tenantID, attributeID)
+	if err != nil {
+		return err
+	}
+	if attribute.ValueType != model.AttributeTypeEnum {
+		return ErrAttributeNotEnum
+	}
+	return nil
+}
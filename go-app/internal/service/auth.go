@@ -0,0 +1,161 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+
+	"go-hsm-app/internal/auth"
+	"go-hsm-app/internal/model"
+	"go-hsm-app/internal/repository"
+)
+
+var (
+	// ErrInvalidCredentials はメールアドレスまたはパスワードが誤っている場合のエラーです
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	// ErrRefreshTokenReused はローテーション済みのリフレッシュトークンが再利用された場合のエラーです
+	ErrRefreshTokenReused = errors.New("refresh token reuse detected")
+)
+
+// ClientInfo はリクエスト元クライアントの情報です
+type ClientInfo struct {
+	UserAgent string // User-Agent ヘッダ
+	IPAddress string // クライアントIP
+	RequestID string // リクエストID
+}
+
+// Login はメールアドレスとパスワードで認証し、新しいセッションのトークンを発行します。
+// アカウント・接続元IPごとに失敗を記録し、制限中は *auth.ThrottledError を返します。
+func Login(email, password string, client ClientInfo) (*auth.TokenPair, *model.User, error) {
+	keys := []auth.ThrottleKey{auth.AccountKey(email), auth.IPKey(client.IPAddress)}
+	if err := auth.CheckThrottle(keys...); err != nil {
+		return nil, nil, err
+	}
+
+	user, passwordHash, err := repository.FetchUserCredentialsByEmail(email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// ユーザーが存在しない場合もハッシュ比較を行い応答時間を揃える
+			auth.CheckPassword("", password)
+			auth.RecordAuthFailure(client.authEvent(), keys...)
+			return nil, nil, ErrInvalidCredentials
+		}
+		return nil, nil, err
+	}
+
+	if !auth.CheckPassword(passwordHash, password) {
+		auth.RecordAuthFailure(client.authEvent(), keys...)
+		return nil, nil, ErrInvalidCredentials
+	}
+	auth.RecordAuthSuccess(auth.AccountKey(email))
+
+	tokens, err := startSession(user, client)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tokens, user, nil
+}
+
+// RefreshTokens はリフレッシュトークンをローテーションし、新しいトークンを発行します。
+// ユーザーの権限は最新の状態を反映します。
+func RefreshTokens(refreshToken string, client ClientInfo) (*auth.TokenPair, error) {
+	old, err := repository.FetchRefreshTokenByHash(auth.HashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, auth.ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	user, err := repository.FetchUserByID(old.UserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			repository.RevokeRefreshTokenFamily(old.FamilyID, repository.RevokeReasonUserDeleted)
+			return nil, auth.ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	tokens, newHash, err := auth.NewTokenPair(user, old.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = repository.RotateRefreshToken(auth.HashToken(refreshToken), newHash, auth.RefreshTokenExpiry(), client.UserAgent, client.IPAddress)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRefreshTokenReused):
+			return nil, ErrRefreshTokenReused
+		case errors.Is(err, repository.ErrRefreshTokenRevoked), errors.Is(err, sql.ErrNoRows):
+			return nil, auth.ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// Logout はリフレッシュトークンが属するセッションを失効させます
+func Logout(refreshToken string) error {
+	token, err := repository.FetchRefreshTokenByHash(auth.HashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return auth.ErrInvalidToken
+		}
+		return err
+	}
+
+	_, err = repository.RevokeRefreshTokenFamily(token.FamilyID, repository.RevokeReasonLogout)
+	return err
+}
+
+// RevokeUserSessions はテナント内のユーザーの全セッションを失効させ、失効したセッション数を返します
+func RevokeUserSessions(tenantID, userID string) (int64, error) {
+	if _, err := repository.FetchTenantUser(tenantID, userID); err != nil {
+		return 0, err
+	}
+	return repository.RevokeUserRefreshTokens(userID, repository.RevokeReasonAdminRevoked)
+}
+
+// UnlockUser は管理者がユーザーのログインと API キーの認証制限を解除し、解除した対象を返します。
+// ipAddress を指定した場合は、その接続元IPの制限もあわせて解除します。
+func UnlockUser(tenantID, userID, ipAddress string, adminID *string, client ClientInfo) ([]auth.ThrottleKey, error) {
+	user, err := repository.FetchTenantUser(tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []auth.ThrottleKey{auth.AccountKey(user.Email)}
+	apiKeys, err := repository.FetchAPIKeysByUser(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range apiKeys {
+		keys = append(keys, auth.APIKeyKey(k.Prefix))
+	}
+	if ipAddress != "" {
+		keys = append(keys, auth.IPKey(ipAddress))
+	}
+
+	ev := client.authEvent()
+	ev.UserID = adminID
+	return auth.Unlock(ev, keys...)
+}
+
+// authEvent は認証イベントの記録に使用する情報を返します
+func (c ClientInfo) authEvent() auth.AuthEvent {
+	return auth.AuthEvent{IPAddress: c.IPAddress, RequestID: c.RequestID}
+}
+
+// startSession は新しいセッション（トークンファミリー）を開始し、トークンを発行します
+func startSession(user *model.User, client ClientInfo) (*auth.TokenPair, error) {
+	sessionID := auth.NewSessionID()
+	tokens, refreshHash, err := auth.NewTokenPair(user, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := repository.CreateRefreshToken(user.ID, sessionID, refreshHash, auth.RefreshTokenExpiry(), client.UserAgent, client.IPAddress); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"go-hsm-app/internal/model"
+	"go-hsm-app/internal/repository"
+)
+
+// CategoryAttributeInput はカテゴリの属性テンプレートに登録する属性です
+type CategoryAttributeInput struct {
+	Code     string      `json:"code"`     // 属性コード
+	Required bool        `json:"required"` // 必須かどうか
+	Default  interface{} `json:"default"`  // 既定値（属性の型に応じて検証・正規化。null は既定値なし）
+}
+
+// GetCategoryAttributes はカテゴリの属性テンプレートを返します
+func GetCategoryAttributes(tenantID, categoryID string) ([]model.CategoryAttribute, error) {
+	if _, err := repository.FetchCategoryByID(tenantID, categoryID); err != nil {
+		return nil, err
+	}
+	return repository.FetchCategoryAttributes(tenantID, categoryID)
+}
+
+// SetCategoryAttributes はカテゴリの属性テンプレートを inputs の内容で置き換えます
+// 既定値は属性の型に応じて検証・正規化し、不正な場合は attributes.<code> 単位の ValidationError を返します
+// 空の inputs を指定するとテンプレートを削除し、カテゴリのアイテムは任意の属性を設定できるようになります
+func SetCategoryAttributes(tenantID, categoryID string, inputs []CategoryAttributeInput) ([]model.CategoryAttribute, error) {
+	if _, err := repository.FetchCategoryByID(tenantID, categoryID); err != nil {
+		return nil, err
+	}
+
+	definitions, err := repository.FetchAttributes(tenantID)
+	if err != nil {
+		return nil, err
+	}
+	attributes := make(map[string]model.Attribute, len(definitions))
+	for _, a := range definitions {
+		attributes[a.Code] = a
+	}
+
+	entries := make([]model.CategoryAttribute, 0, len(inputs))
+	seen := map[string]bool{}
+	verr := &ValidationError{}
+	for i, input := range inputs {
+		code := strings.TrimSpace(input.Code)
+		field := "attributes." + code
+		if code == "" {
+			verr.add(fmt.Sprintf("attributes[%d].code", i), "required", "code is required")
+			continue
+		}
+		if seen[code] {
+			verr.add(field, "duplicate", fmt.Sprintf("attribute %q is specified more than once", code))
+			continue
+		}
+		seen[code] = true
+
+		attribute, ok := attributes[code]
+		if !ok {
+			verr.add(field, "unknown_attribute", fmt.Sprintf("attribute %q does not exist", code))
+			continue
+		}
+
+		entry := model.CategoryAttribute{Code: code, Required: input.Required}
+		if input.Default != nil {
+			normalized, fieldErr := NormalizeAttributeValue(attribute.ValueType, input.Default)
+			if fieldErr == nil && attribute.ValueType == model.AttributeTypeEnum {
+				if fieldErr, err = checkAttributeOption(tenantID, attribute.ID, normalized); err != nil {
+					return nil, err
+				}
+			}
+			if fieldErr != nil {
+				fieldErr.Field = field + ".default"
+				verr.Fields = append(verr.Fields, *fieldErr)
+				continue
+			}
+			entry.DefaultValue = &normalized
+		}
+		entries = append(entries, entry)
+	}
+	if err := verr.errOrNil(); err != nil {
+		return nil, err
+	}
+
+	return repository.ReplaceCategoryAttributes(tenantID, categoryID, entries)
+}
+
+// GetTemplateViolations はカテゴリの属性テンプレートを満たさない既存のアイテムを返します
+// categoryID を指定した場合はそのカテゴリのアイテムのみ返します
+func GetTemplateViolations(tenantID string, scope *model.AccessScope, categoryID string) ([]model.TemplateViolation, error) {
+	return repository.FetchTemplateViolations(tenantID, scope, categoryID)
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"go-hsm-app/internal/model"
+	"go-hsm-app/internal/repository"
+)
+
+// GetCategoryTree はテナントのカテゴリを階層（ツリー）にして、最上位のカテゴリの一覧を返します
+// 子カテゴリはコード順に並びます。親が削除済みのカテゴリは最上位として扱います
+func GetCategoryTree(tenantID string) ([]*model.CategoryNode, error) {
+	categories, err := repository.FetchCategories(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[string]*model.CategoryNode, len(categories))
+	for _, category := range categories {
+		nodes[category.ID] = &model.CategoryNode{Category: category, Children: []*model.CategoryNode{}}
+	}
+
+	// FetchCategories はコード順のため、子の並びもコード順になる
+	roots := []*model.CategoryNode{}
+	for _, category := range categories {
+		node := nodes[category.ID]
+		if category.ParentID != nil {
+			if parent, ok := nodes[*category.ParentID]; ok {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+	return roots, nil
+}
+
+// MoveCategory はカテゴリの親を変更し、子孫を含むサブツリーを移動します（parentID が nil の場合は最上位に移動）
+// 自身または子孫の配下への移動は repository.ErrCategoryCycle を返します
+func MoveCategory(tenantID, id string, parentID *string, version *int) (*model.Category, error) {
+	if parentID != nil && *parentID == "" {
+		parentID = nil
+	}
+	return repository.MoveCategory(tenantID, id, parentID, version)
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+
+	"go-hsm-app/internal/auth"
+	"go-hsm-app/internal/model"
+	"go-hsm-app/internal/repository"
+)
+
+var (
+	// ErrInvalidGrant は権限付与の範囲の種別またはロールが不正な場合のエラーです
+	ErrInvalidGrant = errors.New("scope_type must be location or category, and role must be operator or viewer")
+	// ErrGrantScopeNotFound は権限付与の対象のロケーション・カテゴリが存在しない場合のエラーです
+	ErrGrantScopeNotFound = errors.New("grant scope not found")
+	// ErrGrantRoleExceedsUser はユーザーの権限を超えるロールを付与しようとした場合のエラーです
+	ErrGrantRoleExceedsUser = errors.New("grant role must not exceed the user's role")
+	// ErrGrantForAdmin は管理者に権限付与しようとした場合のエラーです（管理者は範囲を限定できません）
+	ErrGrantForAdmin = errors.New("admin users cannot be restricted by grants")
+)
+
+// GetUserGrants はテナント内のユーザーの権限付与一覧を取得します
+func GetUserGrants(tenantID, userID string) ([]model.UserGrant, error) {
+	if _, err := repository.FetchTenantUser(tenantID, userID); err != nil {
+		return nil, err
+	}
+	return repository.FetchUserGrants(tenantID, userID)
+}
+
+// CreateUserGrant はユーザーにロケーションまたはカテゴリ単位の権限を付与します。
+// 権限付与のあるユーザーは、付与された範囲のデータのみ扱えるようになります。
+func CreateUserGrant(tenantID, userID, scopeType, scopeID, role string, createdBy *string) (*model.UserGrant, error) {
+	user, err := repository.FetchTenantUser(tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	scopeType = strings.ToLower(strings.TrimSpace(scopeType))
+	role = strings.ToLower(strings.TrimSpace(role))
+	if scopeType != model.GrantScopeLocation && scopeType != model.GrantScopeCategory {
+		return nil, ErrInvalidGrant
+	}
+	if role != model.RoleOperator && role != model.RoleViewer {
+		return nil, ErrInvalidGrant
+	}
+	if user.Role == model.RoleAdmin {
+		return nil, ErrGrantForAdmin
+	}
+	if !auth.HasRole(user.Role, role) {
+		return nil, ErrGrantRoleExceedsUser
+	}
+
+	grant, err := repository.CreateUserGrant(tenantID, user.ID, scopeType, strings.TrimSpace(scopeID), role, createdBy)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrGrantScopeNotFound
+	}
+	return grant, err
+}
+
+// DeleteUserGrant はユーザーの権限付与を削除します
+func DeleteUserGrant(tenantID, userID, grantID string) error {
+	return repository.DeleteUserGrant(tenantID, userID, grantID)
+}
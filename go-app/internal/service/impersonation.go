@@ -0,0 +1,64 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+
+	"go-hsm-app/internal/auth"
+	"go-hsm-app/internal/model"
+	"go-hsm-app/internal/repository"
+)
+
+var (
+	// ErrImpersonateAdmin は管理者になりすまそうとした場合のエラーです
+	ErrImpersonateAdmin = errors.New("admin users cannot be impersonated")
+	// ErrImpersonateSelf は自分自身になりすまそうとした場合のエラーです
+	ErrImpersonateSelf = errors.New("cannot impersonate yourself")
+)
+
+// Impersonate は管理者がテナント内のユーザーになりすますためのトークンを発行します。
+// トークンは管理者のセッションに紐づき、なりすましの開始は監査ログに記録されます。
+func Impersonate(tenantID, adminID, sessionID, targetID string, client ClientInfo) (*auth.ImpersonationToken, *model.User, error) {
+	if adminID == targetID {
+		return nil, nil, ErrImpersonateSelf
+	}
+
+	target, err := repository.FetchTenantUser(tenantID, targetID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if target.Role == model.RoleAdmin {
+		return nil, nil, ErrImpersonateAdmin
+	}
+
+	token, err := auth.NewImpersonationToken(adminID, target, sessionID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	raw, _ := json.Marshal(map[string]interface{}{
+		"target_user_id": target.ID,
+		"target_role":    target.Role,
+		"expires_in":     token.ExpiresIn,
+		"ip_address":     client.IPAddress,
+		"user_agent":     client.UserAgent,
+	})
+	entry := model.AuditLog{
+		UserID:     &adminID,
+		Action:     "impersonate",
+		Resource:   "users",
+		ResourceID: &target.ID,
+		Diff:       raw,
+	}
+	if client.RequestID != "" {
+		entry.RequestID = &client.RequestID
+	}
+	// なりすましの開始を記録できない場合はトークンを返さない
+	if err := repository.CreateAuditLog(entry); err != nil {
+		return nil, nil, err
+	}
+
+	log.Printf("[Service] なりすましを開始しました (admin: %s, target: %s)", adminID, target.ID)
+	return token, target, nil
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"go-hsm-app/internal/auth"
+	"go-hsm-app/internal/common"
+	"go-hsm-app/internal/mail"
+	"go-hsm-app/internal/model"
+	"go-hsm-app/internal/repository"
+)
+
+var (
+	// ErrUserAlreadyActive は既にパスワードを設定済みのユーザーを招待しようとした場合のエラーです
+	ErrUserAlreadyActive = errors.New("user has already set a password")
+	// ErrInvalidUserToken は招待・パスワード再設定トークンが無効な場合のエラーです
+	ErrInvalidUserToken = errors.New("invalid or expired token")
+)
+
+var (
+	inviteTokenTTL        = common.GetEnvDuration("INVITE_TOKEN_TTL", 72*time.Hour)
+	passwordResetTokenTTL = common.GetEnvDuration("PASSWORD_RESET_TOKEN_TTL", time.Hour)
+	appBaseURL            = common.GetEnv("APP_BASE_URL", "http://localhost:3000")
+)
+
+// SendInvitation はテナント内のパスワード未設定のユーザーに招待メールを送信します
+func SendInvitation(tenantID, userID string, invitedBy *string) error {
+	user, err := repository.FetchTenantUser(tenantID, userID)
+	if err != nil {
+		return err
+	}
+
+	hasPassword, err := repository.UserHasPassword(user.ID)
+	if err != nil {
+		return err
+	}
+	if hasPassword {
+		return ErrUserAlreadyActive
+	}
+
+	link, err := issueUserToken(user.ID, model.UserTokenPurposeInvite, inviteTokenTTL, invitedBy, "/invite/accept")
+	if err != nil {
+		return err
+	}
+
+	return mail.Default.Send(mail.Message{
+		To:      user.Email,
+		Subject: "【HomeStock】アカウントへの招待",
+		Body: fmt.Sprintf(
+			"HomeStock に招待されました。\n\n"+
+				"以下のリンクからパスワードを設定して利用を開始してください。\n%s\n\n"+
+				"このリンクの有効期限は %s です。\n",
+			link, time.Now().Add(inviteTokenTTL).Format("2006-01-02 15:04"),
+		),
+	})
+}
+
+// AcceptInvitation は招待トークンを使用してパスワードを設定します
+func AcceptInvitation(token, password string) (*model.User, error) {
+	return consumeUserToken(model.UserTokenPurposeInvite, token, password)
+}
+
+// RequestPasswordReset はパスワード再設定メールの送信を受け付けます。
+// メールアドレスの存在を推測されないよう、ユーザーの検索・メール送信はバックグラウンドで行い、
+// 該当ユーザーの有無や送信の成否にかかわらず同じ応答時間・結果になるようにします（失敗はログ出力のみ）。
+// 受信箱への大量送信を防ぐため、ログインと同じくアカウント・接続元IPごとに回数を記録し、
+// 制限中は *auth.ThrottledError を返します。
+func RequestPasswordReset(email string, client ClientInfo) error {
+	keys := []auth.ThrottleKey{auth.AccountKey(email), auth.IPKey(client.IPAddress)}
+	if err := auth.CheckThrottle(keys...); err != nil {
+		return err
+	}
+	auth.RecordAuthFailure(client.authEvent(), keys...)
+
+	go func() {
+		if err := sendPasswordReset(email); err != nil {
+			log.Printf("[Service] エラー: パスワード再設定メールの送信に失敗しました: %v", err)
+		}
+	}()
+	return nil
+}
+
+// sendPasswordReset は該当ユーザーがいればパスワード再設定トークンを発行し、メールを送信します
+func sendPasswordReset(email string) error {
+	user, _, err := repository.FetchUserCredentialsByEmail(email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Printf("[Service] パスワード再設定: 該当ユーザーなし (%s)", email)
+			return nil
+		}
+		return err
+	}
+
+	link, err := issueUserToken(user.ID, model.UserTokenPurposePasswordReset, passwordResetTokenTTL, nil, "/password/reset")
+	if err != nil {
+		return err
+	}
+
+	return mail.Default.Send(mail.Message{
+		To:      user.Email,
+		Subject: "【HomeStock】パスワード再設定のご案内",
+		Body: fmt.Sprintf(
+			"パスワード再設定のリクエストを受け付けました。\n\n"+
+				"以下のリンクから新しいパスワードを設定してください。\n%s\n\n"+
+				"このリンクの有効期限は %s です。\n"+
+				"心当たりがない場合は、このメールを破棄してください。\n",
+			link, time.Now().Add(passwordResetTokenTTL).Format("2006-01-02 15:04"),
+		),
+	})
+}
+
+// ResetPassword はパスワード再設定トークンを使用してパスワードを変更します
+func ResetPassword(token, password string) (*model.User, error) {
+	return consumeUserToken(model.UserTokenPurposePasswordReset, token, password)
+}
+
+// issueUserToken は一回限りのトークンを発行し、フロントエンドのリンクを返します
+func issueUserToken(userID, purpose string, ttl time.Duration, createdBy *string, path string) (string, error) {
+	token, tokenHash, err := auth.NewOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	if err := repository.CreateUserToken(userID, purpose, tokenHash, time.Now().Add(ttl), createdBy); err != nil {
+		return "", err
+	}
+
+	return appBaseURL + path + "?token=" + url.QueryEscape(token), nil
+}
+
+// consumeUserToken はトークンを検証してパスワードを設定します
+func consumeUserToken(purpose, token, password string) (*model.User, error) {
+	if err := auth.ValidatePassword(password); err != nil {
+		return nil, err
+	}
+
+	passwordHash, err := auth.HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := repository.ConsumeUserToken(purpose, auth.HashToken(token), passwordHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) || errors.Is(err, repository.ErrUserTokenInvalid) {
+			return nil, ErrInvalidUserToken
+		}
+		return nil, err
+	}
+	return user, nil
+}
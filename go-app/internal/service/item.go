@@ -0,0 +1,187 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"go-hsm-app/internal/model"
+	"go-hsm-app/internal/repository"
+)
+
+// ErrInvalidItemQuery はアイテム一覧の検索条件が不正な場合のエラーです
+var ErrInvalidItemQuery = errors.New("invalid item query")
+
+// アイテム一覧の取得件数
+const (
+	DefaultItemLimit = 20  // limit 未指定時の取得件数
+	MaxItemLimit     = 200 // limit の上限（それ以上はページングで取得する）
+
+	DefaultSuggestLimit    = 10  // 入力補完候補の既定件数
+	MaxSuggestLimit        = 50  // 入力補完候補の上限
+	MaxSearchKeywordLength = 100 // 検索語の最大文字数
+
+	MaxAttributeFilterValues = 100 // 属性フィルタ（in）に指定できる値の最大数
+)
+
+// itemSortKeys はアイテム一覧でソートに使用できる項目です
+var itemSortKeys = map[string]bool{
+	"code":       true,
+	"name":       true,
+	"updated_at": true,
+	"quantity":   true,
+	"created_at": true,
+}
+
+// GetItems は検索条件に一致するアイテムと総件数を返します
+// scope を指定した場合は権限付与の範囲内のアイテムのみ返します
+func GetItems(tenantID string, scope *model.AccessScope, query model.ItemQuery) ([]model.Item, int, error) {
+	if err := normalizeItemQuery(&query); err != nil {
+		return nil, 0, err
+	}
+	if err := resolveAttributeFilters(tenantID, query.Attributes); err != nil {
+		return nil, 0, err
+	}
+	return repository.FetchItems(tenantID, scope, query)
+}
+
+// SuggestItems は入力途中の検索語に前方一致するアイテムを入力補完候補として返します
+// ひらがな/カタカナ・全角/半角・大文字/小文字の違いは区別しません
+func SuggestItems(tenantID string, scope *model.AccessScope, prefix string, limit int) ([]model.ItemSuggestion, error) {
+	prefix = strings.TrimSpace(prefix)
+	if prefix == "" {
+		return nil, fmt.Errorf("%w: q is required", ErrInvalidItemQuery)
+	}
+	if utf8.RuneCountInString(prefix) > MaxSearchKeywordLength {
+		return nil, fmt.Errorf("%w: q must be at most %d characters", ErrInvalidItemQuery, MaxSearchKeywordLength)
+	}
+	switch {
+	case limit == 0:
+		limit = DefaultSuggestLimit
+	case limit < 0 || limit > MaxSuggestLimit:
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidItemQuery, MaxSuggestLimit)
+	}
+	return repository.SuggestItems(tenantID, scope, prefix, limit)
+}
+
+// normalizeItemQuery は検索条件を検証し、未指定の項目に既定値を設定します
+func normalizeItemQuery(query *model.ItemQuery) error {
+	switch {
+	case query.Limit == 0:
+		query.Limit = DefaultItemLimit
+	case query.Limit < 0 || query.Limit > MaxItemLimit:
+		return fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidItemQuery, MaxItemLimit)
+	}
+	if query.Offset < 0 {
+		return fmt.Errorf("%w: offset must not be negative", ErrInvalidItemQuery)
+	}
+
+	query.Keyword = strings.TrimSpace(query.Keyword)
+	if utf8.RuneCountInString(query.Keyword) > MaxSearchKeywordLength {
+		return fmt.Errorf("%w: q must be at most %d characters", ErrInvalidItemQuery, MaxSearchKeywordLength)
+	}
+
+	// キーワード検索時は関連度順、それ以外は新しい順を既定とする
+	switch {
+	case query.Sort == "" && query.Keyword != "":
+		query.Sort = "relevance"
+	case query.Sort == "":
+		query.Sort = "-created_at"
+	case strings.TrimPrefix(query.Sort, "-") == "relevance":
+		if query.Keyword == "" {
+			return fmt.Errorf("%w: sort=relevance requires q", ErrInvalidItemQuery)
+		}
+	case !itemSortKeys[strings.TrimPrefix(query.Sort, "-")]:
+		return fmt.Errorf("%w: sort must be one of relevance, code, name, updated_at, quantity, created_at (prefix - for descending)", ErrInvalidItemQuery)
+	}
+
+	switch query.Status {
+	case "", "active", "inactive":
+	default:
+		return fmt.Errorf("%w: status must be active or inactive", ErrInvalidItemQuery)
+	}
+	switch query.Stock {
+	case "", model.StockFilterIn, model.StockFilterOut:
+	default:
+		return fmt.Errorf("%w: stock must be in or out", ErrInvalidItemQuery)
+	}
+
+	for _, filter := range query.Attributes {
+		if filter.Code == "" {
+			return fmt.Errorf("%w: attribute code is required", ErrInvalidItemQuery)
+		}
+	}
+	return nil
+}
+
+// attributeFilterOperators は属性の型ごとに使用できる比較演算子です
+var attributeFilterOperators = map[string][]string{
+	model.AttributeTypeText:    {model.AttributeFilterEq, model.AttributeFilterNe, model.AttributeFilterContains, model.AttributeFilterIn},
+	model.AttributeTypeNumber:  {model.AttributeFilterEq, model.AttributeFilterNe, model.AttributeFilterLt, model.AttributeFilterGt, model.AttributeFilterBetween, model.AttributeFilterIn},
+	model.AttributeTypeBoolean: {model.AttributeFilterEq, model.AttributeFilterNe, model.AttributeFilterIn},
+	model.AttributeTypeDate:    {model.AttributeFilterEq, model.AttributeFilterNe, model.AttributeFilterLt, model.AttributeFilterGt, model.AttributeFilterBetween, model.AttributeFilterIn},
+	model.AttributeTypeEnum:    {model.AttributeFilterEq, model.AttributeFilterNe, model.AttributeFilterIn},
+}
+
+// resolveAttributeFilters は属性フィルタを属性定義と照合し、演算子と値を属性の型に応じて検証・正規化します
+// 正規化した値と属性の型は filters に書き戻します
+func resolveAttributeFilters(tenantID string, filters []model.AttributeFilter) error {
+	if len(filters) == 0 {
+		return nil
+	}
+	definitions, err := repository.FetchAttributes(tenantID)
+	if err != nil {
+		return err
+	}
+	valueTypes := make(map[string]string, len(definitions))
+	for _, a := range definitions {
+		valueTypes[a.Code] = a.ValueType
+	}
+
+	for i := range filters {
+		filter := &filters[i]
+		invalid := func(format string, args ...interface{}) error {
+			return fmt.Errorf("%w: attr.%s: %s", ErrInvalidItemQuery, filter.Code, fmt.Sprintf(format, args...))
+		}
+
+		valueType, ok := valueTypes[filter.Code]
+		if !ok {
+			return invalid("attribute does not exist")
+		}
+		if filter.Operator == "" {
+			filter.Operator = model.AttributeFilterEq
+		}
+		operators := attributeFilterOperators[valueType]
+		if !slices.Contains(operators, filter.Operator) {
+			return invalid("operator %q is not supported for %s attributes (use one of %s)", filter.Operator, valueType, strings.Join(operators, ", "))
+		}
+
+		switch count := len(filter.Values); {
+		case filter.Operator == model.AttributeFilterBetween && count != 2:
+			return invalid("between requires two values (lower,upper)")
+		case filter.Operator == model.AttributeFilterIn && (count == 0 || count > MaxAttributeFilterValues):
+			return invalid("in requires 1 to %d values", MaxAttributeFilterValues)
+		case filter.Operator != model.AttributeFilterBetween && filter.Operator != model.AttributeFilterIn && count != 1:
+			return invalid("%s requires a single value", filter.Operator)
+		}
+
+		for j, value := range filter.Values {
+			if filter.Operator == model.AttributeFilterContains {
+				if value = strings.TrimSpace(value); value == "" {
+					return invalid("value must not be empty")
+				}
+				filter.Values[j] = value
+				continue
+			}
+			normalized, fieldErr := NormalizeAttributeValue(valueType, value)
+			if fieldErr != nil {
+				return invalid("%s", fieldErr.Message)
+			}
+			filter.Values[j] = normalized
+		}
+		filter.ValueType = valueType
+	}
+	return nil
+}
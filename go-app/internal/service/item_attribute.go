@@ -0,0 +1,263 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-hsm-app/internal/model"
+	"go-hsm-app/internal/repository"
+)
+
+// ErrItemAttributeNotSet は削除対象の属性値がアイテムに設定されていない場合のエラーです
+var ErrItemAttributeNotSet = errors.New("attribute is not set on the item")
+
+// maxTextAttributeLength は text 型の属性値の最大文字数です
+const maxTextAttributeLength = 1000
+
+// maxNumberAttributeLength は number 型の属性値として受け付ける文字列の最大長です
+const maxNumberAttributeLength = 100
+
+// decimalPattern は number 型の属性値として受け付ける10進数の書式です（指数は 3 桁まで）
+var decimalPattern = regexp.MustCompile(`^[+-]?(\d+\.?\d*|\.\d+)([eE][+-]?\d{1,3})?$`)
+
+// 日付型の属性値として受け付ける書式（正規形は先頭の YYYY-MM-DD）
+var attributeDateLayouts = []string{"2006-01-02", "2006/01/02"}
+
+// SetItemAttribute はアイテムの属性値を属性コードで設定（追加または変更）し、更新後のアイテムを返します
+// 値は属性の型に応じて検証・正規化し、不正な場合は ValidationError を返します
+func SetItemAttribute(tenantID string, scope *model.AccessScope, itemID, code string, value interface{}, version *int) (*model.Item, error) {
+	if _, err := repository.FetchItemByID(tenantID, scope, itemID); err != nil {
+		return nil, err
+	}
+
+	changes, err := buildAttributeChanges(tenantID, map[string]interface{}{code: value}, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(changes.Delete) > 0 {
+		verr := &ValidationError{}
+		verr.add("value", "required", "value is required")
+		return nil, verr
+	}
+
+	if err := repository.UpdateItemAttributes(tenantID, itemID, *changes, version); err != nil {
+		return nil, templateValidationError(err)
+	}
+	return repository.FetchItemByID(tenantID, scope, itemID)
+}
+
+// DeleteItemAttribute はアイテムの属性値を属性コードで削除し、更新後のアイテムを返します
+// 属性値が設定されていない場合は ErrItemAttributeNotSet を返します
+func DeleteItemAttribute(tenantID string, scope *model.AccessScope, itemID, code string, version *int) (*model.Item, error) {
+	item, err := repository.FetchItemByID(tenantID, scope, itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	found := false
+	for _, attr := range item.Attributes {
+		if attr.Code == code {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, ErrItemAttributeNotSet
+	}
+
+	if err := repository.UpdateItemAttributes(tenantID, itemID, model.ItemAttributeChanges{Delete: []string{code}}, version); err != nil {
+		return nil, templateValidationError(err)
+	}
+	return repository.FetchItemByID(tenantID, scope, itemID)
+}
+
+// buildAttributeChanges は属性コードと値の組を検証・正規化して、属性値の変更内容に変換します
+// replace が true の場合は指定した属性で置き換え、false の場合は指定した属性のみ変更します（null は削除）
+// 不正な値は attributes.<code> 単位の ValidationError としてまとめて返します
+func buildAttributeChanges(tenantID string, values map[string]interface{}, replace bool) (*model.ItemAttributeChanges, error) {
+	definitions, err := repository.FetchAttributes(tenantID)
+	if err != nil {
+		return nil, err
+	}
+	attributes := make(map[string]model.Attribute, len(definitions))
+	for _, a := range definitions {
+		attributes[a.Code] = a
+	}
+
+	codes := make([]string, 0, len(values))
+	for code := range values {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	changes := &model.ItemAttributeChanges{Replace: replace, Set: map[string]string{}}
+	verr := &ValidationError{}
+	for _, code := range codes {
+		field := "attributes." + code
+		attribute, ok := attributes[code]
+		if !ok {
+			verr.add(field, "unknown_attribute", fmt.Sprintf("attribute %q does not exist", code))
+			continue
+		}
+		if values[code] == nil {
+			if !replace {
+				changes.Delete = append(changes.Delete, code)
+			}
+			continue
+		}
+		normalized, fieldErr := NormalizeAttributeValue(attribute.ValueType, values[code])
+		if fieldErr == nil && attribute.ValueType == model.AttributeTypeEnum {
+			fieldErr, err = checkAttributeOption(tenantID, attribute.ID, normalized)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if fieldErr != nil {
+			fieldErr.Field = field
+			verr.Fields = append(verr.Fields, *fieldErr)
+			continue
+		}
+		changes.Set[code] = normalized
+	}
+	if err := verr.errOrNil(); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// checkAttributeOption は列挙型の属性値が選択肢のコードのいずれかであることを確認します
+func checkAttributeOption(tenantID, attributeID, value string) (*FieldError, error) {
+	options, err := repository.FetchAttributeOptions(tenantID, attributeID)
+	if err != nil {
+		return nil, err
+	}
+	codes := make([]string, 0, len(options))
+	for _, option := range options {
+		if option.Code == value {
+			return nil, nil
+		}
+		codes = append(codes, option.Code)
+	}
+	return &FieldError{Code: "invalid_option", Message: fmt.Sprintf("value must be one of: %s", strings.Join(codes, ", "))}, nil
+}
+
+// NormalizeAttributeValue は属性値を型に応じて検証し、保存用の正規形の文字列に変換します
+//
+//	text:    文字列（前後の空白を除去。空文字は不可）
+//	number:  数値または数値の文字列 → 指数表記なしの10進数（例: "1.50" → "1.5", "1e3" → "1000"）。
+//	         float64 を経由せずに解析するため、JSON の数値（json.Number）や文字列は桁を丸めずに保存します
+//	boolean: 真偽値または true/false/1/0/yes/no/on/off → "true" / "false"
+//	date:    YYYY-MM-DD または YYYY/MM/DD → "YYYY-MM-DD"
+//	enum:    選択肢のコード（前後の空白を除去。選択肢に含まれるかは呼び出し側で確認）
+//
+// 不正な値の場合は Field を空にした FieldError を返します
+func NormalizeAttributeValue(valueType string, value interface{}) (string, *FieldError) {
+	invalid := func(code, message string) (string, *FieldError) {
+		return "", &FieldError{Code: code, Message: message}
+	}
+
+	switch valueType {
+	case model.AttributeTypeText:
+		s, ok := value.(string)
+		if !ok {
+			return invalid("invalid_text", "value must be a string")
+		}
+		s = strings.TrimSpace(s)
+		if s == "" {
+			return invalid("required", "value must not be empty")
+		}
+		if len([]rune(s)) > maxTextAttributeLength {
+			return invalid("too_long", fmt.Sprintf("value must be at most %d characters", maxTextAttributeLength))
+		}
+		return s, nil
+
+	case model.AttributeTypeNumber:
+		var s string
+		switch v := value.(type) {
+		case json.Number:
+			s = v.String()
+		case string:
+			s = strings.TrimSpace(v)
+		case int:
+			s = strconv.Itoa(v)
+		case int64:
+			s = strconv.FormatInt(v, 10)
+		case float64:
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				return invalid("invalid_number", "value must be a finite number")
+			}
+			s = strconv.FormatFloat(v, 'g', -1, 64)
+		default:
+			return invalid("invalid_number", "value must be a number")
+		}
+		normalized, ok := canonicalDecimal(s)
+		if !ok {
+			return invalid("invalid_number", "value must be a number")
+		}
+		return normalized, nil
+
+	case model.AttributeTypeBoolean:
+		switch v := value.(type) {
+		case bool:
+			return strconv.FormatBool(v), nil
+		case string:
+			switch strings.ToLower(strings.TrimSpace(v)) {
+			case "true", "1", "yes", "on":
+				return "true", nil
+			case "false", "0", "no", "off":
+				return "false", nil
+			}
+		}
+		return invalid("invalid_boolean", "value must be true or false")
+
+	case model.AttributeTypeDate:
+		s, ok := value.(string)
+		if !ok {
+			return invalid("invalid_date", "value must be a date in YYYY-MM-DD format")
+		}
+		for _, layout := range attributeDateLayouts {
+			if t, err := time.Parse(layout, strings.TrimSpace(s)); err == nil {
+				return t.Format(attributeDateLayouts[0]), nil
+			}
+		}
+		return invalid("invalid_date", "value must be a date in YYYY-MM-DD format")
+
+	case model.AttributeTypeEnum:
+		s, ok := value.(string)
+		if !ok || strings.TrimSpace(s) == "" {
+			return invalid("invalid_option", "value must be an option code")
+		}
+		return strings.TrimSpace(s), nil
+	}
+
+	return invalid("unsupported_type", fmt.Sprintf("attribute value type %q is not supported", valueType))
+}
+
+// canonicalDecimal は10進数の文字列を正確に解析し、指数表記・末尾の 0・負の 0 を除いた正規形に変換します
+func canonicalDecimal(s string) (string, bool) {
+	if len(s) > maxNumberAttributeLength || !decimalPattern.MatchString(s) {
+		return "", false
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return "", false
+	}
+
+	// 10進数の分母は 2^a × 5^b のため、小数点以下 max(a, b) 桁で正確に表せる
+	twos := r.Denom().TrailingZeroBits()
+	rest := new(big.Int).Rsh(r.Denom(), twos)
+	fives := 0
+	for one, five := big.NewInt(1), big.NewInt(5); rest.Cmp(one) > 0; fives++ {
+		rest.Quo(rest, five)
+	}
+	digits := max(int(twos), fives)
+	return r.FloatString(digits), true
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+
+	"go-hsm-app/internal/model"
+)
+
+func TestNormalizeNumberAttributeValue(t *testing.T) {
+	for _, tc := range []struct {
+		value interface{}
+		want  string
+	}{
+		{json.Number("1.50"), "1.5"},
+		{json.Number("1e3"), "1000"},
+		{json.Number("-0"), "0"},
+		{json.Number("12345678901234567890.123456789"), "12345678901234567890.123456789"},
+		{json.Number("0.1"), "0.1"},
+		{"  2.5E-3 ", "0.0025"},
+		{".5", "0.5"},
+		{int64(42), "42"},
+		{0.1, "0.1"},
+		{1e21, "1000000000000000000000"},
+	} {
+		got, fieldErr := NormalizeAttributeValue(model.AttributeTypeNumber, tc.value)
+		if fieldErr != nil || got != tc.want {
+			t.Errorf("NormalizeAttributeValue(%#v) = %q, %v; want %q", tc.value, got, fieldErr, tc.want)
+		}
+	}
+
+	for _, value := range []interface{}{"NaN", "Inf", "0x10", "1e1000", "1,000", "", true, json.Number("1e99999")} {
+		if got, fieldErr := NormalizeAttributeValue(model.AttributeTypeNumber, value); fieldErr == nil {
+			t.Errorf("NormalizeAttributeValue(%#v) = %q; want invalid_number", value, got)
+		}
+	}
+}
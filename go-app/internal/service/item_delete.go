@@ -0,0 +1,35 @@
+package service
+
+import (
+	"log"
+	"strings"
+
+	"go-hsm-app/internal/common"
+	"go-hsm-app/internal/model"
+	"go-hsm-app/internal/repository"
+)
+
+// itemDeletePolicy は在庫・入出庫履歴があるアイテムの削除の扱いです（ITEM_DELETE_POLICY）
+var itemDeletePolicy = loadItemDeletePolicy()
+
+// loadItemDeletePolicy はアイテム削除のポリシーを読み込みます。
+// 不正な値の場合は forbid を使用します。
+func loadItemDeletePolicy() string {
+	policy := strings.ToLower(common.GetEnv("ITEM_DELETE_POLICY", model.ItemDeletePolicyForbid))
+	switch policy {
+	case model.ItemDeletePolicyForbid, model.ItemDeletePolicyZeroStock, model.ItemDeletePolicyForce:
+		return policy
+	}
+	log.Printf("⚠ ITEM_DELETE_POLICY の値が不正なため forbid を使用します: %s", policy)
+	return model.ItemDeletePolicyForbid
+}
+
+// DeleteItem はアイテムを削除します（scope を指定した場合は権限付与の範囲内のアイテムのみ）
+// 在庫・入出庫履歴がある場合は ITEM_DELETE_POLICY に従い、削除できなければ repository.ItemDeleteForbiddenError を返します。
+//
+//	forbid:     在庫・入出庫履歴のいずれかがあれば削除しない（既定）
+//	zero_stock: 在庫が 0 であれば、入出庫履歴があっても削除する
+//	force:      残っている在庫を調整（ADJUST）で 0 にしてから削除し、記録した履歴を返す
+func DeleteItem(tenantID string, scope *model.AccessScope, id string, deletedBy *string) ([]model.StockHistory, error) {
+	return repository.DeleteItem(tenantID, scope, id, itemDeletePolicy, deletedBy)
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"go-hsm-app/internal/model"
+	"go-hsm-app/internal/repository"
+)
+
+// ErrInvalidItemMerge はアイテムの統合の指定が不正な場合のエラーです
+var ErrInvalidItemMerge = errors.New("invalid item merge")
+
+// MergeItems は統合元のアイテム（sourceID）を統合先のアイテム（targetID）に統合し、結果と統合後の統合先アイテムを返します
+// dryRun が true の場合は変更を保存せず、統合した場合の結果のみ返します
+// unlocatedLocationID はロケーション別在庫のないアイテムの在庫数量を計上するロケーションです（一方だけがロケーション別在庫を持つ場合に必要）
+// 統合後の属性値がカテゴリの属性テンプレートを満たさない場合は ValidationError を返します
+func MergeItems(tenantID string, scope *model.AccessScope, sourceID, targetID string, unlocatedLocationID *string, version *int, dryRun bool, mergedBy *string) (*model.ItemMerge, error) {
+	sourceID = strings.TrimSpace(sourceID)
+	if sourceID == "" {
+		return nil, fmt.Errorf("%w: source_id is required", ErrInvalidItemMerge)
+	}
+	if sourceID == targetID {
+		return nil, fmt.Errorf("%w: cannot merge an item into itself", ErrInvalidItemMerge)
+	}
+
+	result, err := repository.MergeItems(tenantID, scope, sourceID, targetID, unlocatedLocationID, version, dryRun, mergedBy)
+	if err != nil {
+		return nil, templateValidationError(err)
+	}
+	if dryRun {
+		return result, nil
+	}
+	if result.Item, err = repository.FetchItemByID(tenantID, scope, targetID); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
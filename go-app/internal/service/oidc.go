@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"go-hsm-app/internal/auth"
+	"go-hsm-app/internal/common"
+	"go-hsm-app/internal/model"
+	"go-hsm-app/internal/repository"
+)
+
+var (
+	// ErrOIDCStateInvalid は state が不明・使用済み・期限切れの場合のエラーです
+	ErrOIDCStateInvalid = errors.New("oidc state is invalid or expired")
+	// ErrOIDCUserNotProvisioned は該当ユーザーがおらず、JIT プロビジョニングが無効な場合のエラーです
+	ErrOIDCUserNotProvisioned = errors.New("no user is registered for this email")
+)
+
+var (
+	oidcStateTTL        = common.GetEnvDuration("OIDC_STATE_TTL", 10*time.Minute)
+	oidcJITProvisioning = common.GetEnv("OIDC_JIT_PROVISIONING", "true") != "false"
+	oidcDefaultRole     = loadOIDCDefaultRole()
+	oidcTenantCode      = common.GetEnv("OIDC_TENANT", "default")
+)
+
+// loadOIDCDefaultRole は JIT プロビジョニング時に付与するロールを読み込みます。
+// 不正な値の場合は viewer を使用します。
+func loadOIDCDefaultRole() string {
+	role := strings.ToLower(common.GetEnv("OIDC_DEFAULT_ROLE", model.RoleViewer))
+	switch role {
+	case model.RoleAdmin, model.RoleOperator, model.RoleViewer:
+		return role
+	}
+	log.Printf("⚠ OIDC_DEFAULT_ROLE の値が不正なため viewer を使用します: %s", role)
+	return model.RoleViewer
+}
+
+// OIDCLoginRequest は開始した OIDC ログインの情報です
+type OIDCLoginRequest struct {
+	AuthURL   string    // IdP の認可エンドポイントの URL
+	State     string    // state（開始したブラウザに Cookie で保存し、コールバックで照合する）
+	ExpiresAt time.Time // 有効期限
+}
+
+// StartOIDCLogin は OIDC ログインを開始し、IdP の認可エンドポイントの URL と state を返します
+func StartOIDCLogin(ctx context.Context) (*OIDCLoginRequest, error) {
+	if !auth.OIDCEnabled() {
+		return nil, auth.ErrOIDCDisabled
+	}
+
+	state, stateHash, err := auth.NewOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	nonce, _, err := auth.NewOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	verifier := auth.NewOIDCVerifier()
+
+	authURL, err := auth.OIDCAuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(oidcStateTTL)
+	if err := repository.CreateOIDCAuthRequest(stateHash, nonce, verifier, expiresAt); err != nil {
+		return nil, err
+	}
+	return &OIDCLoginRequest{AuthURL: authURL, State: state, ExpiresAt: expiresAt}, nil
+}
+
+// CompleteOIDCLogin は IdP からのコールバックを処理し、新しいセッションのトークンを発行します。
+// ID トークンのメールアドレスで既存ユーザーを検索し、存在しない場合は JIT プロビジョニングを行います。
+func CompleteOIDCLogin(ctx context.Context, state, code string, client ClientInfo) (*auth.TokenPair, *model.User, error) {
+	nonce, verifier, err := repository.ConsumeOIDCAuthRequest(auth.HashToken(state))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, ErrOIDCStateInvalid
+		}
+		return nil, nil, err
+	}
+
+	identity, err := auth.ExchangeOIDCCode(ctx, code, verifier, nonce)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user, err := findOrProvisionOIDCUser(identity)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tokens, err := startSession(user, client)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tokens, user, nil
+}
+
+// oidcUserStore は OIDC ログインでのユーザーの検索・作成に使用する関数です（テストでは差し替えます）
+var oidcUserStore = struct {
+	fetchUserByEmail  func(email string) (*model.User, error)
+	fetchTenantByCode func(code string) (*model.Tenant, error)
+	createUser        func(tenantID, email, passwordHash, role string) (*model.User, error)
+}{
+	fetchUserByEmail:  repository.FetchUserByEmail,
+	fetchTenantByCode: repository.FetchTenantByCode,
+	createUser:        repository.CreateUser,
+}
+
+// findOrProvisionOIDCUser は ID トークンのメールアドレスでユーザーを検索し、存在しない場合は JIT プロビジョニングを行います
+func findOrProvisionOIDCUser(identity *auth.OIDCIdentity) (*model.User, error) {
+	user, err := oidcUserStore.fetchUserByEmail(identity.Email)
+	if !errors.Is(err, sql.ErrNoRows) {
+		return user, err
+	}
+	if !oidcJITProvisioning {
+		log.Printf("[Service] OIDC: 未登録のユーザーです (%s)", identity.Email)
+		return nil, ErrOIDCUserNotProvisioned
+	}
+	return provisionOIDCUser(identity)
+}
+
+// provisionOIDCUser は OIDC_TENANT で指定したテナントに OIDC ログインのユーザーを作成します
+func provisionOIDCUser(identity *auth.OIDCIdentity) (*model.User, error) {
+	tenant, err := oidcUserStore.fetchTenantByCode(oidcTenantCode)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Printf("[Service] OIDC: プロビジョニング先のテナントが見つかりません (%s)", oidcTenantCode)
+			return nil, ErrOIDCUserNotProvisioned
+		}
+		return nil, err
+	}
+
+	log.Printf("[Service] OIDC: ユーザーを作成します (tenant: %s, email: %s, role: %s, sub: %s)", tenant.Code, identity.Email, oidcDefaultRole, identity.Subject)
+	return oidcUserStore.createUser(tenant.ID, identity.Email, "", oidcDefaultRole)
+}
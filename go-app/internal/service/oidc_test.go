@@ -0,0 +1,274 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"go-hsm-app/internal/auth"
+	"go-hsm-app/internal/model"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// mockIdP は OIDC のディスカバリ・認可・トークン・JWKS のエンドポイントを持つテスト用の IdP です
+type mockIdP struct {
+	server   *httptest.Server
+	key      *rsa.PrivateKey
+	clientID string
+	email    string
+
+	mu    sync.Mutex
+	codes map[string]mockAuthRequest // 認可コード → 認可リクエスト
+}
+
+type mockAuthRequest struct {
+	challenge string
+	nonce     string
+}
+
+func newMockIdP(t *testing.T, clientID, email string) *mockIdP {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp := &mockIdP{key: key, clientID: clientID, email: email, codes: map[string]mockAuthRequest{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", idp.discovery)
+	mux.HandleFunc("/authorize", idp.authorize)
+	mux.HandleFunc("/token", idp.token)
+	mux.HandleFunc("/jwks", idp.jwks)
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+func (idp *mockIdP) discovery(w http.ResponseWriter, r *http.Request) {
+	issuer := idp.server.URL
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"issuer":                                issuer,
+		"authorization_endpoint":                issuer + "/authorize",
+		"token_endpoint":                        issuer + "/token",
+		"jwks_uri":                              issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+	})
+}
+
+// authorize は利用者が同意したものとして、認可コードを付けてリダイレクトします
+func (idp *mockIdP) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("client_id") != idp.clientID || q.Get("code_challenge_method") != "S256" {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	code := "code-" + q.Get("state")
+	idp.mu.Lock()
+	idp.codes[code] = mockAuthRequest{challenge: q.Get("code_challenge"), nonce: q.Get("nonce")}
+	idp.mu.Unlock()
+
+	redirect, _ := url.Parse(q.Get("redirect_uri"))
+	values := redirect.Query()
+	values.Set("code", code)
+	values.Set("state", q.Get("state"))
+	redirect.RawQuery = values.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+// token は認可コードを PKCE のコードベリファイアとともに検証し、署名した ID トークンを返します
+func (idp *mockIdP) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	idp.mu.Lock()
+	request, ok := idp.codes[r.PostForm.Get("code")]
+	delete(idp.codes, r.PostForm.Get("code"))
+	idp.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || base64.RawURLEncoding.EncodeToString(sum[:]) != request.challenge {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":            idp.server.URL,
+		"sub":            "mock-subject",
+		"aud":            idp.clientID,
+		"exp":            time.Now().Add(time.Minute).Unix(),
+		"iat":            time.Now().Unix(),
+		"nonce":          request.nonce,
+		"email":          idp.email,
+		"email_verified": true,
+	})
+	idToken.Header["kid"] = "mock"
+	signed, err := idToken.SignedString(idp.key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"access_token": "mock-access-token",
+		"token_type":   "Bearer",
+		"expires_in":   60,
+		"id_token":     signed,
+	})
+}
+
+func (idp *mockIdP) jwks(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "mock",
+			"alg": "RS256",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(idp.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(idp.key.E)).Bytes()),
+		}},
+	})
+}
+
+// loginWithMockIdP はモックの IdP でディスカバリ・認可・コード交換を行い、ID トークンの利用者情報を返します
+func loginWithMockIdP(t *testing.T, idp *mockIdP, tamperVerifier bool) (*auth.OIDCIdentity, error) {
+	t.Helper()
+	auth.ConfigureOIDC(auth.OIDCConfig{
+		IssuerURL:            idp.server.URL,
+		ClientID:             idp.clientID,
+		RedirectURL:          "http://app.test/api/auth/oidc/callback",
+		Scopes:               []string{"openid", "email"},
+		RequireVerifiedEmail: true,
+	})
+	t.Cleanup(func() { auth.ConfigureOIDC(auth.OIDCConfig{}) })
+
+	ctx := context.Background()
+	verifier := auth.NewOIDCVerifier()
+	authURL, err := auth.OIDCAuthCodeURL(ctx, "test-state", "test-nonce", verifier)
+	if err != nil {
+		t.Fatalf("OIDCAuthCodeURL: %v", err)
+	}
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(authURL)
+	if err != nil {
+		t.Fatalf("authorize: %v", err)
+	}
+	resp.Body.Close()
+	callback, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil || callback.Query().Get("state") != "test-state" {
+		t.Fatalf("unexpected callback: %q", resp.Header.Get("Location"))
+	}
+
+	if tamperVerifier {
+		verifier = auth.NewOIDCVerifier()
+	}
+	return auth.ExchangeOIDCCode(ctx, callback.Query().Get("code"), verifier, "test-nonce")
+}
+
+// stubOIDCUserStore はユーザーの検索・作成をメモリ上の users に差し替えます
+func stubOIDCUserStore(t *testing.T, users map[string]*model.User) {
+	t.Helper()
+	original := oidcUserStore
+	t.Cleanup(func() { oidcUserStore = original })
+
+	oidcUserStore.fetchUserByEmail = func(email string) (*model.User, error) {
+		if user, ok := users[email]; ok {
+			return user, nil
+		}
+		return nil, sql.ErrNoRows
+	}
+	oidcUserStore.fetchTenantByCode = func(code string) (*model.Tenant, error) {
+		return &model.Tenant{ID: "T00000001", Code: code}, nil
+	}
+	oidcUserStore.createUser = func(tenantID, email, passwordHash, role string) (*model.User, error) {
+		user := &model.User{ID: "U-new", TenantID: tenantID, Email: email, Role: role}
+		users[email] = user
+		return user, nil
+	}
+}
+
+func TestOIDCLoginProvisionsNewUser(t *testing.T) {
+	idp := newMockIdP(t, "hsm-app", "new@example.com")
+	users := map[string]*model.User{}
+	stubOIDCUserStore(t, users)
+
+	identity, err := loginWithMockIdP(t, idp, false)
+	if err != nil {
+		t.Fatalf("ExchangeOIDCCode: %v", err)
+	}
+	if identity.Email != "new@example.com" || identity.Subject != "mock-subject" || identity.Issuer != idp.server.URL {
+		t.Fatalf("unexpected identity: %+v", identity)
+	}
+
+	user, err := findOrProvisionOIDCUser(identity)
+	if err != nil {
+		t.Fatalf("findOrProvisionOIDCUser: %v", err)
+	}
+	if user.ID != "U-new" || user.Role != oidcDefaultRole || user.TenantID != "T00000001" {
+		t.Fatalf("unexpected provisioned user: %+v", user)
+	}
+}
+
+func TestOIDCLoginUsesExistingUser(t *testing.T) {
+	idp := newMockIdP(t, "hsm-app", "known@example.com")
+	existing := &model.User{ID: "U00000001", Email: "known@example.com", Role: model.RoleAdmin}
+	users := map[string]*model.User{existing.Email: existing}
+	stubOIDCUserStore(t, users)
+
+	identity, err := loginWithMockIdP(t, idp, false)
+	if err != nil {
+		t.Fatalf("ExchangeOIDCCode: %v", err)
+	}
+	user, err := findOrProvisionOIDCUser(identity)
+	if err != nil {
+		t.Fatalf("findOrProvisionOIDCUser: %v", err)
+	}
+	if user != existing || len(users) != 1 {
+		t.Fatalf("expected the existing user, got %+v", user)
+	}
+}
+
+func TestOIDCLoginWithoutJITProvisioning(t *testing.T) {
+	idp := newMockIdP(t, "hsm-app", "new@example.com")
+	stubOIDCUserStore(t, map[string]*model.User{})
+	original := oidcJITProvisioning
+	oidcJITProvisioning = false
+	t.Cleanup(func() { oidcJITProvisioning = original })
+
+	identity, err := loginWithMockIdP(t, idp, false)
+	if err != nil {
+		t.Fatalf("ExchangeOIDCCode: %v", err)
+	}
+	if _, err := findOrProvisionOIDCUser(identity); !errors.Is(err, ErrOIDCUserNotProvisioned) {
+		t.Fatalf("expected ErrOIDCUserNotProvisioned, got %v", err)
+	}
+}
+
+func TestOIDCLoginRejectsWrongCodeVerifier(t *testing.T) {
+	idp := newMockIdP(t, "hsm-app", "new@example.com")
+
+	if _, err := loginWithMockIdP(t, idp, true); !errors.Is(err, auth.ErrInvalidToken) {
+		t.Fatalf("expected ErrInvalidToken, got %v", err)
+	}
+}
@@ -0,0 +1,309 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"go-hsm-app/internal/model"
+	"go-hsm-app/internal/repository"
+)
+
+// ErrInvalidPatch は JSON Merge Patch（RFC 7396）の内容が不正な場合のエラーです
+var ErrInvalidPatch = errors.New("invalid merge patch")
+
+// PATCH で変更できる項目。レスポンスの JSON と同じフィールド名を使用します
+type (
+	itemPatchDocument struct {
+		Code       string  `json:"code"`
+		Name       string  `json:"name"`
+		CategoryID *string `json:"category_id"`
+		UnitID     string  `json:"unit_id"`
+		Quantity   *int    `json:"quantity"`
+		UnitPrice  *int    `json:"unit_price"`
+		Status     string  `json:"status"`
+	}
+
+	masterPatchDocument struct {
+		Code        string  `json:"code"`
+		Name        string  `json:"name"`
+		Description *string `json:"description"`
+	}
+
+	attributePatchDocument struct {
+		Code        string  `json:"code"`
+		Name        string  `json:"name"`
+		ValueType   string  `json:"value_type"`
+		Description *string `json:"description"`
+	}
+
+	userPatchDocument struct {
+		Email string `json:"email"`
+		Role  string `json:"role"`
+	}
+)
+
+// PatchItem はアイテムに JSON Merge Patch を適用して更新します
+// 指定のないフィールドは変更せず、null を指定したフィールドは値をクリアします（category_id, quantity, unit_price）
+// version を指定しない場合（If-Match: *）はバージョンを条件にせず上書きします
+// 在庫や入出庫履歴があるアイテムの unit_id を変更する場合は repository.ErrItemUnitInUse、
+// 更新後のアイテムが権限付与の範囲外になる場合は repository.ErrAccessDenied を返します
+func PatchItem(tenantID string, scope *model.AccessScope, id string, patch []byte, version *int) (*model.Item, error) {
+	current, err := repository.FetchItemByID(tenantID, scope, id)
+	if err != nil {
+		return nil, err
+	}
+	if version, err = patchVersion(version, current.Version); err != nil {
+		return nil, err
+	}
+
+	// attributes は属性コードをキーとするオブジェクトとしてマージする（null の属性は削除、attributes: null は全削除）
+	patch, attributes, err := splitAttributesPatch(tenantID, patch)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := itemPatchDocument{
+		Code:       current.Code,
+		Name:       current.Name,
+		CategoryID: current.CategoryID,
+		UnitID:     current.UnitID,
+		Quantity:   current.Quantity,
+		UnitPrice:  current.UnitPrice,
+		Status:     current.Status,
+	}
+	if err := applyMergePatch(&doc, patch, "code", "name", "unit_id", "status"); err != nil {
+		return nil, err
+	}
+	if err := requireFields(map[string]string{"code": doc.Code, "name": doc.Name, "unit_id": doc.UnitID}); err != nil {
+		return nil, err
+	}
+	if doc.Status != "active" && doc.Status != "inactive" {
+		return nil, fmt.Errorf("%w: status must be active or inactive", ErrInvalidPatch)
+	}
+
+	item, err := repository.UpdateItem(tenantID, scope, id, doc.Code, doc.Name, doc.UnitID, doc.CategoryID, doc.Quantity, doc.UnitPrice, doc.Status, attributes, version)
+	return item, templateValidationError(err)
+}
+
+// PatchCategory はカテゴリに JSON Merge Patch を適用して更新します
+func PatchCategory(tenantID, id string, patch []byte, version *int) (*model.Category, error) {
+	current, err := repository.FetchCategoryByID(tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if version, err = patchVersion(version, current.Version); err != nil {
+		return nil, err
+	}
+
+	doc := masterPatchDocument{Code: current.Code, Name: current.Name, Description: current.Description}
+	if err := applyMasterPatch(&doc, patch); err != nil {
+		return nil, err
+	}
+	return repository.UpdateCategory(tenantID, id, doc.Code, doc.Name, doc.Description, version)
+}
+
+// PatchUnit は単位に JSON Merge Patch を適用して更新します
+func PatchUnit(tenantID, id string, patch []byte, version *int) (*model.Unit, error) {
+	current, err := repository.FetchUnitByID(tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if version, err = patchVersion(version, current.Version); err != nil {
+		return nil, err
+	}
+
+	doc := masterPatchDocument{Code: current.Code, Name: current.Name, Description: current.Description}
+	if err := applyMasterPatch(&doc, patch); err != nil {
+		return nil, err
+	}
+	return repository.UpdateUnit(tenantID, id, doc.Code, doc.Name, doc.Description, version)
+}
+
+// PatchAttribute は属性に JSON Merge Patch を適用して更新します
+// 属性値がある属性の型を変更する場合は repository.ErrValueTypeInUse を返します
+func PatchAttribute(tenantID, id string, patch []byte, version *int) (*model.Attribute, error) {
+	current, err := repository.FetchAttributeByID(tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if version, err = patchVersion(version, current.Version); err != nil {
+		return nil, err
+	}
+
+	doc := attributePatchDocument{Code: current.Code, Name: current.Name, ValueType: current.ValueType, Description: current.Description}
+	if err := applyMergePatch(&doc, patch, "code", "name", "value_type"); err != nil {
+		return nil, err
+	}
+	if err := requireFields(map[string]string{"code": doc.Code, "name": doc.Name}); err != nil {
+		return nil, err
+	}
+	switch doc.ValueType {
+	case "text", "number", "boolean", "date", "enum":
+	default:
+		return nil, fmt.Errorf("%w: value_type must be one of text, number, boolean, date, enum", ErrInvalidPatch)
+	}
+	return repository.UpdateAttribute(tenantID, id, doc.Code, doc.Name, doc.ValueType, doc.Description, version)
+}
+
+// PatchUser はユーザーに JSON Merge Patch を適用して更新します
+// 権限を下げた場合は、そのユーザーの全セッションを失効させます
+func PatchUser(tenantID, id string, patch []byte, version *int) (*model.User, error) {
+	current, err := repository.FetchTenantUser(tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if version, err = patchVersion(version, current.Version); err != nil {
+		return nil, err
+	}
+
+	doc := userPatchDocument{Email: current.Email, Role: current.Role}
+	if err := applyMergePatch(&doc, patch, "email", "role"); err != nil {
+		return nil, err
+	}
+	if err := requireFields(map[string]string{"email": doc.Email}); err != nil {
+		return nil, err
+	}
+	switch doc.Role {
+	case model.RoleAdmin, model.RoleOperator, model.RoleViewer:
+	default:
+		return nil, fmt.Errorf("%w: role must be one of admin, operator, viewer", ErrInvalidPatch)
+	}
+	return repository.UpdateUser(tenantID, id, doc.Email, doc.Role, version)
+}
+
+// splitAttributesPatch はアイテムのパッチから attributes を取り出し、属性値の変更内容に変換します
+// attributes を含まない場合は変更内容を nil で返します
+func splitAttributesPatch(tenantID string, patch []byte) ([]byte, *model.ItemAttributeChanges, error) {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(patch, &members); err != nil {
+		return nil, nil, fmt.Errorf("%w: patch must be a JSON object", ErrInvalidPatch)
+	}
+	raw, ok := members["attributes"]
+	if !ok {
+		return patch, nil, nil
+	}
+	delete(members, "attributes")
+	rest, err := json.Marshal(members)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if string(bytes.TrimSpace(raw)) == "null" {
+		return rest, &model.ItemAttributeChanges{Replace: true}, nil
+	}
+	// 数値の属性値を丸めずに扱うため json.Number として読み込む
+	var values map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&values); err != nil {
+		return nil, nil, fmt.Errorf("%w: attributes must be an object keyed by attribute code", ErrInvalidPatch)
+	}
+	changes, err := buildAttributeChanges(tenantID, values, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	return rest, changes, nil
+}
+
+// applyMasterPatch はカテゴリ・単位に共通の項目に JSON Merge Patch を適用します
+func applyMasterPatch(doc *masterPatchDocument, patch []byte) error {
+	if err := applyMergePatch(doc, patch, "code", "name"); err != nil {
+		return err
+	}
+	return requireFields(map[string]string{"code": doc.Code, "name": doc.Name})
+}
+
+// patchVersion は更新の条件とするバージョンを決めます
+// If-Match で指定されたバージョンが読み込んだ時点で既に古い場合は、パッチを適用せずに ErrVersionConflict を返します
+// If-Match: * でバージョンが指定されていない場合は、明示的な上書きとしてバージョンを条件にしません
+func patchVersion(requested *int, current int) (*int, error) {
+	if requested == nil {
+		return nil, nil
+	}
+	if *requested != current {
+		return nil, repository.ErrVersionConflict
+	}
+	return requested, nil
+}
+
+// applyMergePatch は doc（現在の値）に RFC 7396 の JSON Merge Patch を適用し、結果を doc に書き戻します
+// 変更できないフィールドの指定は ErrInvalidPatch になり、nonNullable に含まれるフィールドへの null も拒否します
+func applyMergePatch(doc interface{}, patch []byte, nonNullable ...string) error {
+	var p interface{}
+	if err := json.Unmarshal(patch, &p); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	patchObj, ok := p.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%w: patch must be a JSON object", ErrInvalidPatch)
+	}
+	for _, field := range nonNullable {
+		if v, ok := patchObj[field]; ok && v == nil {
+			return fmt.Errorf("%w: %s cannot be null", ErrInvalidPatch, field)
+		}
+	}
+
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	var target interface{}
+	if err := json.Unmarshal(raw, &target); err != nil {
+		return err
+	}
+	merged, err := json.Marshal(mergePatch(target, patchObj))
+	if err != nil {
+		return err
+	}
+
+	// パッチで削除したフィールドに現在の値が残らないよう、ゼロ値に戻してからデコードする
+	v := reflect.ValueOf(doc).Elem()
+	v.Set(reflect.Zero(v.Type()))
+	decoder := json.NewDecoder(bytes.NewReader(merged))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(doc); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	return nil
+}
+
+// mergePatch は RFC 7396 の MergePatch アルゴリズムです
+// patch がオブジェクトでなければ patch で置き換え、オブジェクトであればメンバーごとに再帰的に適用し、null のメンバーは削除します
+func mergePatch(target, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = map[string]interface{}{}
+	}
+	for name, value := range patchObj {
+		if value == nil {
+			delete(targetObj, name)
+			continue
+		}
+		targetObj[name] = mergePatch(targetObj[name], value)
+	}
+	return targetObj
+}
+
+// requireFields は必須項目が空でないことを確認します
+func requireFields(fields map[string]string) error {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if strings.TrimSpace(fields[name]) == "" {
+			return fmt.Errorf("%w: %s must not be empty", ErrInvalidPatch, name)
+		}
+	}
+	return nil
+}
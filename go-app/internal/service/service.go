@@ -1,116 +1,192 @@
 package service
 
 import (
+	"strings"
+
+	"go-hsm-app/internal/auth"
 	"go-hsm-app/internal/model"
 	"go-hsm-app/internal/repository"
 )
 
-// GetRecentItems はリポジトリから最新のアイテムを取得して返します
-func GetRecentItems(limit int) ([]model.Item, error) {
-	return repository.FetchRecentItems(limit)
-}
-
 // GetItemByID はIDでアイテムを取得します
-func GetItemByID(id string) (*model.Item, error) {
-	return repository.FetchItemByID(id)
+func GetItemByID(tenantID string, scope *model.AccessScope, id string) (*model.Item, error) {
+	return repository.FetchItemByID(tenantID, scope, id)
 }
 
 // GetCategories はリポジトリからカテゴリ一覧を取得して返します
-func GetCategories() ([]model.Category, error) {
-	return repository.FetchCategories()
+func GetCategories(tenantID string) ([]model.Category, error) {
+	return repository.FetchCategories(tenantID)
 }
 
 // GetUnits はリポジトリから単位一覧を取得して返します
-func GetUnits() ([]model.Unit, error) {
-	return repository.FetchUnits()
+func GetUnits(tenantID string) ([]model.Unit, error) {
+	return repository.FetchUnits(tenantID)
+}
+
+// GetCategoryByID はIDでカテゴリを取得します
+func GetCategoryByID(tenantID, id string) (*model.Category, error) {
+	return repository.FetchCategoryByID(tenantID, id)
+}
+
+// GetUnitByID はIDで単位を取得します
+func GetUnitByID(tenantID, id string) (*model.Unit, error) {
+	return repository.FetchUnitByID(tenantID, id)
 }
 
 // GetAttributes はリポジトリから属性一覧を取得して返します
-func GetAttributes() ([]model.Attribute, error) {
-	return repository.FetchAttributes()
+func GetAttributes(tenantID string) ([]model.Attribute, error) {
+	return repository.FetchAttributes(tenantID)
+}
+
+// GetAttributeByID はIDで属性を取得します
+func GetAttributeByID(tenantID, id string) (*model.Attribute, error) {
+	return repository.FetchAttributeByID(tenantID, id)
 }
 
 // GetUsers はリポジトリからユーザー一覧を取得して返します
-func GetUsers() ([]model.User, error) {
-	return repository.FetchUsers()
+func GetUsers(tenantID string) ([]model.User, error) {
+	return repository.FetchUsers(tenantID)
+}
+
+// GetUserByID はテナント内のユーザーをIDで取得します
+func GetUserByID(tenantID, id string) (*model.User, error) {
+	return repository.FetchTenantUser(tenantID, id)
 }
 
 // CreateCategory はカテゴリを作成します
-func CreateCategory(code, name, description string) (*model.Category, error) {
-	return repository.CreateCategory(code, name, description)
+// parentID を指定した場合は、そのカテゴリの子として作成します
+func CreateCategory(tenantID, code, name, description string, parentID *string) (*model.Category, error) {
+	return repository.CreateCategory(tenantID, code, name, description, parentID)
 }
 
 // UpdateCategory はカテゴリを更新します
-func UpdateCategory(id, code, name, description string) (*model.Category, error) {
-	return repository.UpdateCategory(id, code, name, description)
+// version を指定した場合は現在のバージョンと一致するときのみ更新します
+func UpdateCategory(tenantID, id, code, name string, description *string, version *int) (*model.Category, error) {
+	return repository.UpdateCategory(tenantID, id, code, name, description, version)
 }
 
 // DeleteCategory はカテゴリを削除します
-func DeleteCategory(id string) error {
-	return repository.DeleteCategory(id)
+// アイテムから参照されている場合は削除せず repository.MasterInUseError を返します。
+// replacementID を指定した場合は、参照を付け替え先のカテゴリに付け替えてから削除します
+func DeleteCategory(tenantID, id, replacementID string) error {
+	return repository.DeleteCategory(tenantID, id, optionalID(replacementID))
 }
 
 // CreateUnit は単位を作成します
-func CreateUnit(code, name, description string) (*model.Unit, error) {
-	return repository.CreateUnit(code, name, description)
+func CreateUnit(tenantID, code, name, description string) (*model.Unit, error) {
+	return repository.CreateUnit(tenantID, code, name, description)
 }
 
 // UpdateUnit は単位を更新します
-func UpdateUnit(id, code, name, description string) (*model.Unit, error) {
-	return repository.UpdateUnit(id, code, name, description)
+// version を指定した場合は現在のバージョンと一致するときのみ更新します
+func UpdateUnit(tenantID, id, code, name string, description *string, version *int) (*model.Unit, error) {
+	return repository.UpdateUnit(tenantID, id, code, name, description, version)
 }
 
 // DeleteUnit は単位を削除します
-func DeleteUnit(id string) error {
-	return repository.DeleteUnit(id)
+// アイテム・荷姿から参照されている場合は削除せず repository.MasterInUseError を返します。
+// replacementID を指定した場合は、参照を付け替え先の単位に付け替えてから削除します
+func DeleteUnit(tenantID, id, replacementID string) error {
+	return repository.DeleteUnit(tenantID, id, optionalID(replacementID))
 }
 
 // CreateAttribute は属性を作成します
-func CreateAttribute(code, name, valueType, description string) (*model.Attribute, error) {
-	return repository.CreateAttribute(code, name, valueType, description)
+func CreateAttribute(tenantID, code, name, valueType, description string) (*model.Attribute, error) {
+	return repository.CreateAttribute(tenantID, code, name, valueType, description)
 }
 
 // UpdateAttribute は属性を更新します
-func UpdateAttribute(id, code, name, valueType, description string) (*model.Attribute, error) {
-	return repository.UpdateAttribute(id, code, name, valueType, description)
+// version を指定した場合は現在のバージョンと一致するときのみ更新します
+// 属性値がある属性の型を変更する場合は repository.ErrValueTypeInUse を返します
+func UpdateAttribute(tenantID, id, code, name, valueType string, description *string, version *int) (*model.Attribute, error) {
+	return repository.UpdateAttribute(tenantID, id, code, name, valueType, description, version)
 }
 
 // DeleteAttribute は属性を削除します
-func DeleteAttribute(id string) error {
-	return repository.DeleteAttribute(id)
+// アイテムから参照されている場合は削除せず repository.MasterInUseError を返します。
+// replacementID を指定した場合は、参照を付け替え先の属性に付け替えてから削除します
+func DeleteAttribute(tenantID, id, replacementID string) error {
+	return repository.DeleteAttribute(tenantID, id, optionalID(replacementID))
 }
 
 // CreateUser はユーザーを作成します
-func CreateUser(email, role string) (*model.User, error) {
-	return repository.CreateUser(email, role)
+// password が指定された場合はハッシュ化して保存します
+func CreateUser(tenantID, email, password, role string) (*model.User, error) {
+	var passwordHash string
+	if password != "" {
+		if err := auth.ValidatePassword(password); err != nil {
+			return nil, err
+		}
+		hash, err := auth.HashPassword(password)
+		if err != nil {
+			return nil, err
+		}
+		passwordHash = hash
+	}
+	return repository.CreateUser(tenantID, email, passwordHash, role)
 }
 
 // UpdateUser はユーザーを更新します
-func UpdateUser(id, email, role string) (*model.User, error) {
-	return repository.UpdateUser(id, email, role)
+// version を指定した場合は現在のバージョンと一致するときのみ更新します
+// 権限を下げた場合は、そのユーザーの全セッションを失効させます
+func UpdateUser(tenantID, id, email, role string, version *int) (*model.User, error) {
+	return repository.UpdateUser(tenantID, id, email, role, version)
 }
 
-// DeleteUser はユーザーを削除します
-func DeleteUser(id string) error {
-	return repository.DeleteUser(id)
+// DeleteUser はユーザーを削除し、そのユーザーの全セッションを失効させます
+func DeleteUser(tenantID, id string) error {
+	if err := repository.DeleteUser(tenantID, id); err != nil {
+		return err
+	}
+	_, err := repository.RevokeUserRefreshTokens(id, repository.RevokeReasonUserDeleted)
+	return err
 }
 
 // GetStockHistory は在庫履歴を取得します
-func GetStockHistory(limit, offset int) ([]model.StockHistory, int, error) {
-	return repository.FetchStockHistory(limit, offset)
+// scope を指定した場合は権限付与の範囲内の履歴のみ返します
+func GetStockHistory(tenantID string, scope *model.AccessScope, limit, offset int) ([]model.StockHistory, int, error) {
+	return repository.FetchStockHistory(tenantID, scope, limit, offset)
 }
 
 // CreateItem はアイテムを作成します
-func CreateItem(code, name, unitID string, categoryID *string, quantity *int, unitPrice *int) (*model.Item, error) {
-	return repository.CreateItem(code, name, unitID, categoryID, quantity, unitPrice)
+// attributes（属性コード → 値）を指定した場合は、値を属性の型に応じて検証・正規化して設定します
+// カテゴリの属性テンプレートを満たさない場合は ValidationError を返します
+// scope を指定した場合、権限付与の範囲外のカテゴリのアイテムは作成せず repository.ErrAccessDenied を返します
+func CreateItem(tenantID string, scope *model.AccessScope, code, name, unitID string, categoryID *string, quantity *int, unitPrice *int, attributes map[string]interface{}) (*model.Item, error) {
+	var changes *model.ItemAttributeChanges
+	if attributes != nil {
+		var err error
+		if changes, err = buildAttributeChanges(tenantID, attributes, false); err != nil {
+			return nil, err
+		}
+	}
+	item, err := repository.CreateItem(tenantID, scope, code, name, unitID, categoryID, quantity, unitPrice, changes)
+	return item, templateValidationError(err)
 }
 
 // UpdateItem はアイテムを更新します
-func UpdateItem(id, code, name, unitID string, categoryID *string, quantity *int, unitPrice *int, status string) (*model.Item, error) {
-	return repository.UpdateItem(id, code, name, unitID, categoryID, quantity, unitPrice, status)
-}
-
-// DeleteItem はアイテムを削除します
-func DeleteItem(id string) error {
-	return repository.DeleteItem(id)
+// version を指定した場合は現在のバージョンと一致するときのみ更新します
+// attributes（属性コード → 値）を指定した場合は属性値をその内容で置き換え、nil の場合は変更しません
+// 更新後の属性値がカテゴリの属性テンプレートを満たさない場合は ValidationError を返します
+// scope を指定した場合は権限付与の範囲内のアイテムのみ更新します
+// 在庫や入出庫履歴があるアイテムの単位を変更する場合は repository.ErrItemUnitInUse、
+// 更新後のアイテムが権限付与の範囲外になる場合は repository.ErrAccessDenied を返します
+func UpdateItem(tenantID string, scope *model.AccessScope, id, code, name, unitID string, categoryID *string, quantity *int, unitPrice *int, status string, attributes map[string]interface{}, version *int) (*model.Item, error) {
+	var changes *model.ItemAttributeChanges
+	if attributes != nil {
+		var err error
+		if changes, err = buildAttributeChanges(tenantID, attributes, true); err != nil {
+			return nil, err
+		}
+	}
+	item, err := repository.UpdateItem(tenantID, scope, id, code, name, unitID, categoryID, quantity, unitPrice, status, changes, version)
+	return item, templateValidationError(err)
+}
+
+// optionalID は空文字列を未指定（nil）として ID のポインタを返します
+func optionalID(id string) *string {
+	if id = strings.TrimSpace(id); id == "" {
+		return nil
+	}
+	return &id
 }
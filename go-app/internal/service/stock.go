@@ -0,0 +1,75 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"go-hsm-app/internal/model"
+	"go-hsm-app/internal/repository"
+)
+
+// ErrInvalidStockMovement は入出庫の指定が種別と整合しない場合のエラーです
+var ErrInvalidStockMovement = errors.New("invalid stock movement")
+
+// CreateStockMovement は入出庫を記録します。
+// 種別ごとに必要なロケーションと数量を検証してから、権限付与の範囲内で在庫を更新します。
+//
+//	IN:       location_to に qty（正）を入庫
+//	OUT:      location_from から qty（正）を出庫
+//	TRANSFER: location_from から location_to へ qty（正）を移動
+//	ADJUST:   location_to の在庫を qty（符号付き）だけ調整
+//
+// unit_id を指定した場合、qty はその単位の数量として扱い、アイテムの基本単位に換算して記録します。
+func CreateStockMovement(tenantID string, scope *model.AccessScope, m model.StockMovement) (*model.StockHistory, error) {
+	if err := validateStockMovement(m); err != nil {
+		return nil, err
+	}
+	if m.UnitID != nil && *m.UnitID == "" {
+		m.UnitID = nil
+	}
+	if m.UnlocatedLocationID != nil && *m.UnlocatedLocationID == "" {
+		m.UnlocatedLocationID = nil
+	}
+	return repository.CreateStockMovement(tenantID, scope, m)
+}
+
+// validateStockMovement は入出庫の種別とロケーション・数量の組み合わせを検証します
+func validateStockMovement(m model.StockMovement) error {
+	if m.ItemID == "" {
+		return fmt.Errorf("%w: item_id is required", ErrInvalidStockMovement)
+	}
+
+	hasFrom, hasTo := m.LocationFrom != nil && *m.LocationFrom != "", m.LocationTo != nil && *m.LocationTo != ""
+	switch m.Kind {
+	case model.StockKindIn:
+		if !hasTo || hasFrom {
+			return fmt.Errorf("%w: IN requires location_to only", ErrInvalidStockMovement)
+		}
+	case model.StockKindOut:
+		if !hasFrom || hasTo {
+			return fmt.Errorf("%w: OUT requires location_from only", ErrInvalidStockMovement)
+		}
+	case model.StockKindTransfer:
+		if !hasFrom || !hasTo {
+			return fmt.Errorf("%w: TRANSFER requires location_from and location_to", ErrInvalidStockMovement)
+		}
+		if *m.LocationFrom == *m.LocationTo {
+			return fmt.Errorf("%w: location_from and location_to must differ", ErrInvalidStockMovement)
+		}
+	case model.StockKindAdjust:
+		if !hasTo || hasFrom {
+			return fmt.Errorf("%w: ADJUST requires location_to only", ErrInvalidStockMovement)
+		}
+		if m.Qty == 0 {
+			return fmt.Errorf("%w: qty must not be zero", ErrInvalidStockMovement)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: kind must be one of IN, OUT, ADJUST, TRANSFER", ErrInvalidStockMovement)
+	}
+
+	if m.Qty <= 0 {
+		return fmt.Errorf("%w: qty must be positive", ErrInvalidStockMovement)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"strings"
+
+	"go-hsm-app/internal/model"
+	"go-hsm-app/internal/repository"
+)
+
+// ErrInvalidTenant はテナント作成の入力が不足している場合のエラーです
+var ErrInvalidTenant = errors.New("code, name and admin_email are required")
+
+// GetTenant はテナントを取得します
+func GetTenant(tenantID string) (*model.Tenant, error) {
+	return repository.FetchTenantByID(tenantID)
+}
+
+// CreateTenant は新しいテナントとその管理者ユーザーを作成し、管理者に招待メールを送信します。
+// 招待メールの送信に失敗してもテナントの作成は成功とし、招待の再送で対応します。
+func CreateTenant(code, name, adminEmail string, invitedBy *string) (*model.Tenant, *model.User, error) {
+	code = strings.TrimSpace(code)
+	name = strings.TrimSpace(name)
+	adminEmail = strings.TrimSpace(adminEmail)
+	if code == "" || name == "" || adminEmail == "" {
+		return nil, nil, ErrInvalidTenant
+	}
+
+	tenant, admin, err := repository.CreateTenant(code, name, adminEmail)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := SendInvitation(tenant.ID, admin.ID, invitedBy); err != nil {
+		log.Printf("[Service] 警告: テナント管理者への招待メールの送信に失敗しました (ID: %s): %v", admin.ID, err)
+	}
+	return tenant, admin, nil
+}
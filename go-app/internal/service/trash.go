@@ -0,0 +1,83 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go-hsm-app/internal/common"
+	"go-hsm-app/internal/model"
+	"go-hsm-app/internal/repository"
+)
+
+// ErrInvalidTrashResource はゴミ箱の種別・ページネーションの指定が不正な場合のエラーです
+var ErrInvalidTrashResource = errors.New("invalid trash resource")
+
+// ゴミ箱の取得件数
+const (
+	DefaultTrashLimit = 100          // limit 未指定時の取得件数
+	MaxTrashLimit     = MaxItemLimit // limit の上限（アイテム一覧と同じ）
+)
+
+// trashRetention はゴミ箱のデータを物理削除するまでの保持期間です（TRASH_RETENTION）
+var trashRetention = common.GetEnvDuration("TRASH_RETENTION", 30*24*time.Hour)
+
+// GetTrash はゴミ箱のデータ（論理削除したアイテム・マスタ）を削除日時の新しい順に返します
+// resource（items, categories, units, attributes）を指定した場合はその種別のみ返します
+// limit は 1〜MaxTrashLimit（0 の場合は DefaultTrashLimit）で、範囲外の場合は ErrInvalidTrashResource を返します
+func GetTrash(tenantID string, scope *model.AccessScope, resource string, limit, offset int) ([]model.TrashEntry, int, error) {
+	switch resource {
+	case "", model.TrashResourceItems, model.TrashResourceCategories, model.TrashResourceUnits, model.TrashResourceAttributes:
+	default:
+		return nil, 0, fmt.Errorf("%w: resource must be one of items, categories, units, attributes", ErrInvalidTrashResource)
+	}
+	switch {
+	case limit == 0:
+		limit = DefaultTrashLimit
+	case limit < 0 || limit > MaxTrashLimit:
+		return nil, 0, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidTrashResource, MaxTrashLimit)
+	}
+	if offset < 0 {
+		return nil, 0, fmt.Errorf("%w: offset must not be negative", ErrInvalidTrashResource)
+	}
+	return repository.FetchTrash(tenantID, scope, resource, limit, offset)
+}
+
+// RestoreItem は論理削除したアイテムを復元し、復元後のアイテムを返します
+// コードを有効なアイテムが使用している場合は repository.CodeConflictError を返します
+func RestoreItem(tenantID string, scope *model.AccessScope, id string, restoredBy *string) (*model.Item, error) {
+	if err := repository.RestoreItem(tenantID, scope, id, restoredBy); err != nil {
+		return nil, err
+	}
+	return repository.FetchItemByID(tenantID, scope, id)
+}
+
+// RestoreCategory は論理削除したカテゴリを復元し、復元後のカテゴリを返します
+func RestoreCategory(tenantID, id string, restoredBy *string) (*model.Category, error) {
+	if err := repository.RestoreCategory(tenantID, id, restoredBy); err != nil {
+		return nil, err
+	}
+	return repository.FetchCategoryByID(tenantID, id)
+}
+
+// RestoreUnit は論理削除した単位を復元し、復元後の単位を返します
+func RestoreUnit(tenantID, id string, restoredBy *string) (*model.Unit, error) {
+	if err := repository.RestoreUnit(tenantID, id, restoredBy); err != nil {
+		return nil, err
+	}
+	return repository.FetchUnitByID(tenantID, id)
+}
+
+// RestoreAttribute は論理削除した属性を復元し、復元後の属性を返します
+func RestoreAttribute(tenantID, id string, restoredBy *string) (*model.Attribute, error) {
+	if err := repository.RestoreAttribute(tenantID, id, restoredBy); err != nil {
+		return nil, err
+	}
+	return repository.FetchAttributeByID(tenantID, id)
+}
+
+// PurgeTrash は保持期間（TRASH_RETENTION、既定 30 日）を過ぎたゴミ箱のデータを物理削除します
+// 入出庫履歴のあるアイテムと、参照の残っているマスタは削除しません
+func PurgeTrash(tenantID string, purgedBy *string) (*model.TrashPurge, error) {
+	return repository.PurgeTrash(tenantID, time.Now().Add(-trashRetention), purgedBy)
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"go-hsm-app/internal/model"
+	"go-hsm-app/internal/repository"
+)
+
+// ErrInvalidUnitConversion は単位換算・荷姿の入力内容が不正な場合のエラーです
+var ErrInvalidUnitConversion = errors.New("invalid unit conversion")
+
+// GetUnitConversions は単位を換算元または換算先とする単位換算を返します
+func GetUnitConversions(tenantID, unitID string) ([]model.UnitConversion, error) {
+	if _, err := repository.FetchUnitByID(tenantID, unitID); err != nil {
+		return nil, err
+	}
+	return repository.FetchUnitConversions(tenantID, unitID)
+}
+
+// SetUnitConversion は単位換算（1 fromUnitID = factor toUnitID）を登録します
+// 逆方向の換算が登録済みの場合は置き換えます
+func SetUnitConversion(tenantID, fromUnitID, toUnitID string, factor float64) (*model.UnitConversion, error) {
+	if fromUnitID == toUnitID {
+		return nil, fmt.Errorf("%w: cannot convert a unit to itself", ErrInvalidUnitConversion)
+	}
+	if err := validateFactor(factor); err != nil {
+		return nil, err
+	}
+	return repository.SetUnitConversion(tenantID, fromUnitID, toUnitID, factor)
+}
+
+// DeleteUnitConversion は 2 つの単位の間の単位換算を削除します
+func DeleteUnitConversion(tenantID, unitID, otherUnitID string) error {
+	return repository.DeleteUnitConversion(tenantID, unitID, otherUnitID)
+}
+
+// GetItemPackagings はアイテムの荷姿を返します
+// scope を指定した場合、権限付与の範囲外のアイテムは sql.ErrNoRows を返します
+func GetItemPackagings(tenantID string, scope *model.AccessScope, itemID string) ([]model.ItemPackaging, error) {
+	if _, err := repository.FetchItemByID(tenantID, scope, itemID); err != nil {
+		return nil, err
+	}
+	return repository.FetchItemPackagings(tenantID, itemID)
+}
+
+// SetItemPackaging はアイテムの荷姿（荷姿の単位 1 あたりの内容物の単位での入数）を登録します
+// containsUnitID が nil の場合、内容物はアイテムの基本単位になります
+func SetItemPackaging(tenantID string, scope *model.AccessScope, itemID, unitID string, containsUnitID *string, factor float64) (*model.ItemPackaging, error) {
+	if err := validateFactor(factor); err != nil {
+		return nil, err
+	}
+	if _, err := repository.FetchItemByID(tenantID, scope, itemID); err != nil {
+		return nil, err
+	}
+	return repository.SetItemPackaging(tenantID, itemID, unitID, containsUnitID, factor)
+}
+
+// DeleteItemPackaging はアイテムの荷姿を削除します
+func DeleteItemPackaging(tenantID string, scope *model.AccessScope, itemID, unitID string) error {
+	if _, err := repository.FetchItemByID(tenantID, scope, itemID); err != nil {
+		return err
+	}
+	return repository.DeleteItemPackaging(tenantID, itemID, unitID)
+}
+
+// validateFactor は換算係数・入数が正の有限の数か検証します
+func validateFactor(factor float64) error {
+	if factor <= 0 || math.IsInf(factor, 0) || math.IsNaN(factor) {
+		return fmt.Errorf("%w: factor must be a positive number", ErrInvalidUnitConversion)
+	}
+	return nil
+}
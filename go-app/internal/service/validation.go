@@ -0,0 +1,61 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"go-hsm-app/internal/repository"
+)
+
+// FieldError は入力項目ごとのエラーを表します
+type FieldError struct {
+	Field   string `json:"field"`   // 項目名（例: attributes.color）
+	Code    string `json:"code"`    // エラーの種類（例: invalid_number）
+	Message string `json:"message"` // エラーの説明
+}
+
+// ValidationError は入力項目ごとのエラーをまとめたエラーです
+// コントローラーは Fields をそのままレスポンスに含めます
+type ValidationError struct {
+	Fields []FieldError
+}
+
+// Error はエラーの概要を返します
+func (e *ValidationError) Error() string {
+	fields := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		fields = append(fields, f.Field)
+	}
+	return fmt.Sprintf("validation failed: %s", strings.Join(fields, ", "))
+}
+
+// add はエラーを追加します
+func (e *ValidationError) add(field, code, message string) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Code: code, Message: message})
+}
+
+// errOrNil はエラーがあれば自身を、なければ nil を返します
+func (e *ValidationError) errOrNil() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+// templateValidationError はカテゴリの属性テンプレートの違反を attributes.<code> 単位の ValidationError に変換します
+// 違反以外のエラーはそのまま返します
+func templateValidationError(err error) error {
+	var violation *repository.TemplateViolationError
+	if !errors.As(err, &violation) {
+		return err
+	}
+	verr := &ValidationError{}
+	for _, code := range violation.Missing {
+		verr.add("attributes."+code, "required", "attribute is required by the category")
+	}
+	for _, code := range violation.NotApplicable {
+		verr.add("attributes."+code, "not_applicable", "attribute is not applicable to the category")
+	}
+	return verr
+}
@@ -4,10 +4,12 @@ import (
 	"log"
 	"net/http"
 
+	"go-hsm-app/internal/auth"
 	"go-hsm-app/internal/common"
 	"go-hsm-app/internal/controller"
 	"go-hsm-app/internal/lib/graph/generated"
 	graph "go-hsm-app/internal/lib/graph/resolver"
+	"go-hsm-app/internal/mail"
 
 	"github.com/99designs/gqlgen/graphql/handler"
 	"github.com/99designs/gqlgen/graphql/playground"
@@ -22,13 +24,29 @@ func main() {
 	}
 	defer common.CloseDB()
 
+	// メール送信を初期化（MAIL_DRIVER=file の場合は outbox に書き出す）
+	if err := mail.InitMailer(); err != nil {
+		log.Fatalf("メール送信の初期化に失敗しました: %v", err)
+	}
+
 	// Echoインスタンスを作成
 	e := echo.New()
+	// 属性値の数値を丸めずに扱うため、リクエストボディの数値は json.Number として読み込む
+	e.JSONSerializer = controller.JSONSerializer{}
 
 	// ミドルウェア設定
+	e.Use(middleware.RequestID())
 	e.Use(middleware.Logger())
 	e.Use(middleware.Recover())
-	e.Use(middleware.CORS())
+	// ETag は楽観的ロックの If-Match に使用するため、ブラウザから参照できるようにする
+	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
+		ExposeHeaders: []string{"ETag"},
+	}))
+
+	// 認証・認可・なりすまし中の書き込みの監査（権限マトリクスは internal/auth/permission.go を参照）
+	e.Use(auth.Authenticate())
+	e.Use(auth.Authorize())
+	e.Use(auth.AuditImpersonation())
 
 	// ヘルスチェック用エンドポイント
 	e.GET("/health", func(c echo.Context) error {
@@ -40,8 +58,9 @@ func main() {
 
 	// GraphQL ハンドラ
 	srv := handler.NewDefaultServer(generated.NewExecutableSchema(generated.Config{
-		Resolvers: &graph.Resolver{
-			DB: common.DB,
+		Resolvers: &graph.Resolver{},
+		Directives: generated.DirectiveRoot{
+			HasRole: graph.HasRole,
 		},
 	}))
 
@@ -49,40 +68,105 @@ func main() {
 	e.POST("/graphql", echo.WrapHandler(srv))
 	e.GET("/graphql", echo.WrapHandler(playground.Handler("GraphQL Playground", "/graphql")))
 
+	// 認証エンドポイント
+	e.POST("/api/auth/login", controller.Login)
+	e.POST("/api/auth/refresh", controller.RefreshToken)
+	e.POST("/api/auth/logout", controller.Logout)
+	e.POST("/api/auth/invitation/accept", controller.AcceptInvitation)
+	e.POST("/api/auth/password/forgot", controller.ForgotPassword)
+	e.POST("/api/auth/password/reset", controller.ResetPassword)
+	e.GET("/api/auth/oidc/login", controller.OIDCLogin)
+	e.GET("/api/auth/oidc/callback", controller.OIDCCallback)
+
 	// REST API エンドポイント - READ
-	e.GET("/api/items", controller.GetRecentItems)
+	e.GET("/api/items", controller.GetItems)
+	e.GET("/api/items/suggest", controller.SuggestItems)
+	e.GET("/api/items/template-violations", controller.GetTemplateViolations)
 	e.GET("/api/items/:id", controller.GetItemByID)
+	e.GET("/api/items/:id/packagings", controller.GetItemPackagings)
 	e.GET("/api/categories", controller.GetCategories)
+	e.GET("/api/categories/tree", controller.GetCategoryTree)
+	e.GET("/api/categories/:id", controller.GetCategoryByID)
+	e.GET("/api/categories/:id/attributes", controller.GetCategoryAttributes)
 	e.GET("/api/units", controller.GetUnits)
+	e.GET("/api/units/:id", controller.GetUnitByID)
+	e.GET("/api/units/:id/conversions", controller.GetUnitConversions)
 	e.GET("/api/attributes", controller.GetAttributes)
+	e.GET("/api/attributes/:id", controller.GetAttributeByID)
+	e.GET("/api/attributes/:id/options", controller.GetAttributeOptions)
 	e.GET("/api/users", controller.GetUsers)
+	e.GET("/api/users/:id", controller.GetUserByID)
 	e.GET("/api/stock-history", controller.GetStockHistory)
 
 	// REST API エンドポイント - CREATE/UPDATE/DELETE
 	// Items
 	e.POST("/api/items", controller.CreateItem)
 	e.PUT("/api/items/:id", controller.UpdateItem)
+	e.PATCH("/api/items/:id", controller.PatchItem)
 	e.DELETE("/api/items/:id", controller.DeleteItem)
+	e.POST("/api/items/:id/restore", controller.RestoreItem)
+	e.POST("/api/items/:id/merge", controller.MergeItem)
+	e.PUT("/api/items/:id/attributes/:code", controller.SetItemAttribute)
+	e.DELETE("/api/items/:id/attributes/:code", controller.DeleteItemAttribute)
+	e.PUT("/api/items/:id/packagings/:unit", controller.SetItemPackaging)
+	e.DELETE("/api/items/:id/packagings/:unit", controller.DeleteItemPackaging)
+
+	// Stock Movements
+	e.POST("/api/stock-movements", controller.CreateStockMovement)
 
 	// Categories
 	e.POST("/api/categories", controller.CreateCategory)
 	e.PUT("/api/categories/:id", controller.UpdateCategory)
+	e.PATCH("/api/categories/:id", controller.PatchCategory)
 	e.DELETE("/api/categories/:id", controller.DeleteCategory)
+	e.POST("/api/categories/:id/restore", controller.RestoreCategory)
+	e.PUT("/api/categories/:id/attributes", controller.SetCategoryAttributes)
+	e.PUT("/api/categories/:id/parent", controller.MoveCategory)
 
 	// Units
 	e.POST("/api/units", controller.CreateUnit)
 	e.PUT("/api/units/:id", controller.UpdateUnit)
+	e.PATCH("/api/units/:id", controller.PatchUnit)
 	e.DELETE("/api/units/:id", controller.DeleteUnit)
+	e.POST("/api/units/:id/restore", controller.RestoreUnit)
+	e.PUT("/api/units/:id/conversions/:to", controller.SetUnitConversion)
+	e.DELETE("/api/units/:id/conversions/:to", controller.DeleteUnitConversion)
 
 	// Attributes
 	e.POST("/api/attributes", controller.CreateAttribute)
 	e.PUT("/api/attributes/:id", controller.UpdateAttribute)
+	e.PATCH("/api/attributes/:id", controller.PatchAttribute)
 	e.DELETE("/api/attributes/:id", controller.DeleteAttribute)
+	e.POST("/api/attributes/:id/restore", controller.RestoreAttribute)
+	e.POST("/api/attributes/:id/options", controller.CreateAttributeOption)
+	e.PUT("/api/attributes/:id/options/:code", controller.UpdateAttributeOption)
+	e.DELETE("/api/attributes/:id/options/:code", controller.DeleteAttributeOption)
+
+	// Trash（論理削除したデータの参照・物理削除）
+	e.GET("/api/trash", controller.GetTrash)
+	e.POST("/api/trash/purge", controller.PurgeTrash)
 
 	// Users
 	e.POST("/api/users", controller.CreateUser)
 	e.PUT("/api/users/:id", controller.UpdateUser)
+	e.PATCH("/api/users/:id", controller.PatchUser)
 	e.DELETE("/api/users/:id", controller.DeleteUser)
+	e.DELETE("/api/users/:id/sessions", controller.RevokeUserSessions)
+	e.POST("/api/users/:id/invitation", controller.SendInvitation)
+	e.POST("/api/users/:id/unlock", controller.UnlockUser)
+	e.POST("/api/users/:id/impersonate", controller.Impersonate)
+	e.GET("/api/users/:id/grants", controller.GetUserGrants)
+	e.POST("/api/users/:id/grants", controller.CreateUserGrant)
+	e.DELETE("/api/users/:id/grants/:grantId", controller.DeleteUserGrant)
+
+	// API Keys（本人または管理者）
+	e.GET("/api/users/:id/api-keys", controller.GetAPIKeys)
+	e.POST("/api/users/:id/api-keys", controller.CreateAPIKey)
+	e.DELETE("/api/users/:id/api-keys/:keyId", controller.RevokeAPIKey)
+
+	// Tenants
+	e.GET("/api/tenant", controller.GetCurrentTenant)
+	e.POST("/api/tenants", controller.CreateTenant)
 
 	// サーバー起動
 	port := ":8080"